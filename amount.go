@@ -0,0 +1,58 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// MoneyAmount is an amount of money, the left asset of the market. E.g.
+// BTC in BTCETH market. It is distinct from StockAmount to make mixing
+// them up a compile error.
+type MoneyAmount struct {
+	decimal.Decimal
+}
+
+// StockAmount is an amount of stock, the right asset of the market. E.g.
+// ETH in BTCETH market. It is distinct from MoneyAmount to make mixing
+// them up a compile error.
+type StockAmount struct {
+	decimal.Decimal
+}
+
+// NewMoneyAmount wraps decimal as amount of money.
+func NewMoneyAmount(d decimal.Decimal) MoneyAmount {
+	return MoneyAmount{d}
+}
+
+// NewStockAmount wraps decimal as amount of stock.
+func NewStockAmount(d decimal.Decimal) StockAmount {
+	return StockAmount{d}
+}
+
+// ToStock converts money to the amount of stock which may be bought
+// with it for the given price of 1 stock in money.
+func (m MoneyAmount) ToStock(price decimal.Decimal) (StockAmount, error) {
+	if price.Sign() <= 0 {
+		return StockAmount{}, errors.New("price should be positive")
+	}
+	return StockAmount{m.Div(price)}, nil
+}
+
+// ToMoney converts stock to the amount of money it costs for the given
+// price of 1 stock in money.
+func (s StockAmount) ToMoney(price decimal.Decimal) MoneyAmount {
+	return MoneyAmount{s.Mul(price)}
+}
+
+// Buy creates market order to buy stock spending given amount of money,
+// i.e. bid order. E.g. in market BTCETH it spends BTC to buy ETH.
+func (c *Client) Buy(market string, spend MoneyAmount) (Order, error) {
+	return c.createOrder(market, spend.Decimal, OrderBid)
+}
+
+// Sell creates market order to sell given amount of stock for money,
+// i.e. ask order. E.g. in market BTCETH it sells ETH for BTC.
+func (c *Client) Sell(market string, amount StockAmount) (Order, error) {
+	return c.createOrder(market, amount.Decimal, OrderAsk)
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoneyAmount_ToStock(t *testing.T) {
+	stock, err := NewMoneyAmount(dec(1)).ToStock(dec(0.25))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !stock.Equal(dec(4)) {
+		t.Errorf("want 4 stock but got %v", stock)
+	}
+
+	if _, err := NewMoneyAmount(dec(1)).ToStock(dec(0)); err == nil {
+		t.Error("want error on zero price")
+	}
+}
+
+func TestStockAmount_ToMoney(t *testing.T) {
+	money := NewStockAmount(dec(4)).ToMoney(dec(0.25))
+	if !money.Equal(dec(1)) {
+		t.Errorf("want 1 money but got %v", money)
+	}
+}
+
+func TestClient_BuySell(t *testing.T) {
+	const wantMarket = "BTCETH"
+	tests := []struct {
+		name     string
+		create   func(c *Client) (Order, error)
+		wantSide OrderSide
+	}{
+		{
+			name: "buy",
+			create: func(c *Client) (Order, error) {
+				return c.Buy(wantMarket, NewMoneyAmount(dec(0.1)))
+			},
+			wantSide: "bid",
+		},
+		{
+			name: "sell",
+			create: func(c *Client) (Order, error) {
+				return c.Sell(wantMarket, NewStockAmount(dec(0.1)))
+			},
+			wantSide: "ask",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &mockCore{
+				respJSON: `{ "data": { "createMarketOrder": { "id": 1 } } }`,
+			}
+			if _, err := tt.create(&Client{core: backend}); err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+			wantVariables := createOrderRequestVariables{
+				Market: wantMarket,
+				Amount: dec(0.1),
+				Side:   tt.wantSide,
+			}
+			if !reflect.DeepEqual(wantVariables, backend.request.Variables) {
+				t.Errorf("want variables `%#v` but got `%#v`",
+					wantVariables, backend.request.Variables)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"sort"
+	"strings"
+)
+
+// WithAssetCodes sets mapping of local asset codes, e.g. used by
+// accounting system, to exchange asset codes, e.g. {"DSH": "DASH",
+// "BCHABC": "BCH"}. The mapping is applied on the client boundary:
+// assets and markets in requests are translated to exchange codes and
+// the ones in responses are translated back. Assets which are not in
+// the mapping are passed as is.
+func WithAssetCodes(codes map[string]string) Option {
+	return func(o *options) {
+		o.assetCodes = codes
+	}
+}
+
+// assetMapper translates asset codes and markets between local and
+// exchange codes. Nil mapper translates nothing.
+type assetMapper struct {
+	toExchange   map[string]string
+	fromExchange map[string]string
+
+	// localCodes and exchangeCodes are mapped codes sorted by length
+	// in descending order, so the longest code wins in market
+	// translation.
+	localCodes    []string
+	exchangeCodes []string
+}
+
+// newAssetMapper creates new mapper from local to exchange codes.
+func newAssetMapper(codes map[string]string) *assetMapper {
+	m := &assetMapper{
+		toExchange:   make(map[string]string, len(codes)),
+		fromExchange: make(map[string]string, len(codes)),
+	}
+	for local, exchange := range codes {
+		m.toExchange[local] = exchange
+		m.fromExchange[exchange] = local
+		m.localCodes = append(m.localCodes, local)
+		m.exchangeCodes = append(m.exchangeCodes, exchange)
+	}
+	sortByLength(m.localCodes)
+	sortByLength(m.exchangeCodes)
+	return m
+}
+
+// asset translates local asset code to exchange one.
+func (m *assetMapper) asset(asset string) string {
+	if m == nil {
+		return asset
+	}
+	return translate(m.toExchange, asset)
+}
+
+// assets translates local asset codes to exchange ones.
+func (m *assetMapper) assets(assets []string) []string {
+	if m == nil {
+		return assets
+	}
+	res := make([]string, len(assets))
+	for i, a := range assets {
+		res[i] = m.asset(a)
+	}
+	return res
+}
+
+// localAsset translates exchange asset code to local one.
+func (m *assetMapper) localAsset(asset string) string {
+	if m == nil {
+		return asset
+	}
+	return translate(m.fromExchange, asset)
+}
+
+// market translates market of local asset codes to exchange one.
+func (m *assetMapper) market(market string) string {
+	if m == nil {
+		return market
+	}
+	return translateMarket(m.toExchange, m.localCodes, market)
+}
+
+// markets translates markets of local asset codes to exchange ones.
+func (m *assetMapper) markets(markets []string) []string {
+	if m == nil {
+		return markets
+	}
+	res := make([]string, len(markets))
+	for i, market := range markets {
+		res[i] = m.market(market)
+	}
+	return res
+}
+
+// localMarket translates market of exchange asset codes to local one.
+func (m *assetMapper) localMarket(market string) string {
+	if m == nil {
+		return market
+	}
+	return translateMarket(m.fromExchange, m.exchangeCodes, market)
+}
+
+// localMarketStatuses translates markets and assets of the statuses to
+// local codes in place.
+func (m *assetMapper) localMarketStatuses(statuses []MarketStatus) {
+	if m == nil {
+		return
+	}
+	for i := range statuses {
+		s := &statuses[i]
+		s.Market = m.localMarket(s.Market)
+		s.Stock = m.localAsset(s.Stock)
+		s.Money = m.localAsset(s.Money)
+	}
+}
+
+// translate returns mapped code or code itself if it is not mapped.
+func translate(mapping map[string]string, code string) string {
+	if mapped, ok := mapping[code]; ok {
+		return mapped
+	}
+	return code
+}
+
+// translateMarket translates market which is concatenation of money and
+// stock codes. As market has no separator, mapped codes are searched as
+// market prefix (money) and suffix (stock), the longest code wins.
+func translateMarket(mapping map[string]string, codes []string,
+	market string) string {
+
+	for _, code := range codes {
+		if strings.HasPrefix(market, code) && len(code) < len(market) {
+			return mapping[code] + translate(mapping, market[len(code):])
+		}
+	}
+
+	for _, code := range codes {
+		if strings.HasSuffix(market, code) && len(code) < len(market) {
+			return market[:len(market)-len(code)] + mapping[code]
+		}
+	}
+
+	return market
+}
+
+// sortByLength sorts codes by length in descending order.
+func sortByLength(codes []string) {
+	sort.Slice(codes, func(i, j int) bool {
+		if len(codes[i]) != len(codes[j]) {
+			return len(codes[i]) > len(codes[j])
+		}
+		return codes[i] < codes[j]
+	})
+}
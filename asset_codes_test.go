@@ -0,0 +1,97 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssetMapper(t *testing.T) {
+	m := newAssetMapper(map[string]string{
+		"DSH":    "DASH",
+		"BCHABC": "BCH",
+		"XBT":    "BTC",
+	})
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"mapped asset", m.asset("DSH"), "DASH"},
+		{"not mapped asset", m.asset("ETH"), "ETH"},
+		{"local asset", m.localAsset("BCH"), "BCHABC"},
+		{"market with mapped stock", m.market("BTCDSH"), "BTCDASH"},
+		{"market with mapped money", m.market("XBTETH"), "BTCETH"},
+		{"market with both mapped", m.market("XBTBCHABC"), "BTCBCH"},
+		{"not mapped market", m.market("ETHLTC"), "ETHLTC"},
+		{"local market", m.localMarket("BTCDASH"), "XBTDSH"},
+		{"nil mapper", (*assetMapper)(nil).market("BTCDSH"), "BTCDSH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("want `%s` but got `%s`", tt.want, tt.got)
+			}
+		})
+	}
+}
+
+func TestClient_Accounts_assetCodes(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `
+			{ "data": { "accounts": [
+				{ "asset": "DASH" }, { "asset": "ETH" }
+			] } }
+		`,
+	}
+	client := &Client{
+		core:   backend,
+		assets: newAssetMapper(map[string]string{"DSH": "DASH"}),
+	}
+
+	accounts, err := client.Accounts([]string{"DSH", "ETH"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	wantVariables := accountsRequest{Assets: []string{"DASH", "ETH"}}
+	if !reflect.DeepEqual(wantVariables, backend.request.Variables) {
+		t.Errorf("want variables `%#v` but got `%#v`", wantVariables,
+			backend.request.Variables)
+	}
+
+	if accounts[0].Asset != "DSH" || accounts[1].Asset != "ETH" {
+		t.Errorf("want local asset codes but got `%s` and `%s`",
+			accounts[0].Asset, accounts[1].Asset)
+	}
+}
+
+func TestClient_Markets_assetCodes(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `
+			{ "data": { "markets": [
+				{ "market": "BTCDASH", "stock": "DASH", "money": "BTC" }
+			] } }
+		`,
+	}
+	client := &Client{
+		core:   backend,
+		assets: newAssetMapper(map[string]string{"DSH": "DASH"}),
+	}
+
+	statuses, err := client.Markets([]string{"BTCDSH"}, 0)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	wantVariables := MarketsRequest{Markets: []string{"BTCDASH"}}
+	if !reflect.DeepEqual(wantVariables, backend.request.Variables) {
+		t.Errorf("want variables `%#v` but got `%#v`", wantVariables,
+			backend.request.Variables)
+	}
+
+	got := statuses[0]
+	if got.Market != "BTCDSH" || got.Stock != "DSH" || got.Money != "BTC" {
+		t.Errorf("want local codes but got `%#v`", got)
+	}
+}
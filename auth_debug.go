@@ -0,0 +1,144 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/bitlum/macaroon-application-auth"
+	"gopkg.in/macaroon.v2"
+)
+
+// Authorization methods reported by AuthDebugInfo.
+const (
+	AuthMethodMacaroon = "macaroon"
+	AuthMethodJWT      = "jwt"
+	AuthMethodSigner   = "signer"
+)
+
+// AuthDebugInfo is the state of the client authorization for debugging
+// of authorization failures. It carries no secrets, so it may be logged.
+type AuthDebugInfo struct {
+	// Method is the method requests are authorized with, e.g.
+	// AuthMethodMacaroon, empty if the client has no credentials.
+	Method string `json:"method,omitempty"`
+
+	// TokenFingerprint is the hex encoded prefix of SHA-256 hash of the
+	// macaroon or JWT, which tells tokens apart without revealing them.
+	TokenFingerprint string `json:"tokenFingerprint,omitempty"`
+
+	// LastNonce is the nonce of the last macaroon authorized request,
+	// zero if there was none.
+	LastNonce int64 `json:"lastNonce,omitempty"`
+
+	// LastTimeCaveat is the time of the time caveat of the last
+	// macaroon authorized request, including clock skew correction.
+	LastTimeCaveat time.Time `json:"lastTimeCaveat,omitempty"`
+
+	// LastAuthError is the last message of the server rejecting
+	// credentials, e.g. because of expired token or used nonce.
+	LastAuthError string `json:"lastAuthError,omitempty"`
+
+	// LastAuthErrorAt is the time of the last authorization error.
+	LastAuthErrorAt time.Time `json:"lastAuthErrorAt,omitempty"`
+}
+
+// fingerprintLength is the number of bytes of the token hash in the
+// fingerprint.
+const fingerprintLength = 8
+
+// tokenFingerprint returns the fingerprint of the token.
+func tokenFingerprint(token []byte) string {
+	sum := sha256.Sum256(token)
+	return hex.EncodeToString(sum[:fingerprintLength])
+}
+
+// authTrace keeps the values of the last authorization. Nil trace keeps
+// nothing.
+type authTrace struct {
+	mtx         sync.Mutex
+	nonce       int64
+	timeCaveat  time.Time
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// caveats records the caveats of the last macaroon authorized request.
+func (t *authTrace) caveats(nonce int64, timeCaveat time.Time) {
+	if t == nil {
+		return
+	}
+
+	t.mtx.Lock()
+	t.nonce = nonce
+	t.timeCaveat = timeCaveat
+	t.mtx.Unlock()
+}
+
+// rejected records the server message rejecting credentials.
+func (t *authTrace) rejected(body []byte, err error) {
+	if t == nil {
+		return
+	}
+
+	detail := errorDetail(body)
+	if e, ok := err.(*HTTPStatusError); ok {
+		detail = e.Detail
+		if detail == "" {
+			detail = e.Status
+		}
+	}
+
+	t.mtx.Lock()
+	t.lastError = detail
+	t.lastErrorAt = time.Now()
+	t.mtx.Unlock()
+}
+
+// AuthDebugInfo returns the state of the client authorization, zero if
+// requests are sent with WithDoer.
+func (c *Client) AuthDebugInfo() AuthDebugInfo {
+	core, ok := c.core.(*graphQLCore)
+	if !ok {
+		return AuthDebugInfo{}
+	}
+
+	var info AuthDebugInfo
+
+	core.mtx.RLock()
+	mac, jwt := core.macaroon, core.jwt
+	core.mtx.RUnlock()
+
+	switch {
+	case mac != nil:
+		info.Method = AuthMethodMacaroon
+		info.TokenFingerprint = macaroonFingerprint(mac)
+	case jwt != "":
+		info.Method = AuthMethodJWT
+		info.TokenFingerprint = tokenFingerprint([]byte(jwt))
+	case core.signer != nil:
+		info.Method = AuthMethodSigner
+	}
+
+	if t := core.authTrace; t != nil {
+		t.mtx.Lock()
+		info.LastNonce = t.nonce
+		info.LastTimeCaveat = t.timeCaveat
+		info.LastAuthError = t.lastError
+		info.LastAuthErrorAt = t.lastErrorAt
+		t.mtx.Unlock()
+	}
+
+	return info
+}
+
+// macaroonFingerprint returns the fingerprint of the hex encoded
+// macaroon, empty if it can not be encoded.
+func macaroonFingerprint(m *macaroon.Macaroon) string {
+	token, err := auth.EncodeMacaroon(m)
+	if err != nil {
+		return ""
+	}
+	return tokenFingerprint([]byte(token))
+}
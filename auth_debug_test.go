@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitlum/macaroon-application-auth"
+	"gopkg.in/macaroon.v2"
+)
+
+func TestClient_AuthDebugInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message": "time caveat is in the future"}`))
+		}))
+	defer server.Close()
+
+	m, err := macaroon.New([]byte("root key"), []byte("id"), "",
+		macaroon.LatestVersion)
+	if err != nil {
+		t.Fatalf("failed to create macaroon: %v", err)
+	}
+	token, err := auth.EncodeMacaroon(m)
+	if err != nil {
+		t.Fatalf("failed to encode macaroon: %v", err)
+	}
+
+	client, err := NewClient(server.URL, token, "")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if info := client.AuthDebugInfo(); info.Method != AuthMethodMacaroon ||
+		info.LastNonce != 0 || info.LastAuthError != "" {
+
+		t.Errorf("want macaroon without requests but got %+v", info)
+	}
+
+	started := time.Now()
+	if _, err := client.Me(); err == nil {
+		t.Fatal("want error of rejected macaroon")
+	}
+
+	info := client.AuthDebugInfo()
+	if len(info.TokenFingerprint) != 2*fingerprintLength ||
+		strings.Contains(token, info.TokenFingerprint) {
+
+		t.Errorf("want fingerprint of the token but got `%v`",
+			info.TokenFingerprint)
+	}
+	if info.LastNonce == 0 {
+		t.Error("want last nonce recorded")
+	}
+	if info.LastTimeCaveat.Before(started) {
+		t.Errorf("want time caveat of the request but got %v",
+			info.LastTimeCaveat)
+	}
+	if info.LastAuthError != "time caveat is in the future" ||
+		info.LastAuthErrorAt.Before(started) {
+
+		t.Errorf("want server auth error but got `%v` at %v",
+			info.LastAuthError, info.LastAuthErrorAt)
+	}
+}
+
+func TestClient_AuthDebugInfoJWT(t *testing.T) {
+	client, err := NewClient("", "", "jwt")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	info := client.AuthDebugInfo()
+	if info.Method != AuthMethodJWT ||
+		info.TokenFingerprint != tokenFingerprint([]byte("jwt")) {
+
+		t.Errorf("want JWT fingerprint but got %+v", info)
+	}
+
+	client, err = NewClient("", "", "", WithDoer(DoerFunc(
+		func(ctx context.Context, r Request) ([]byte, error) {
+			return nil, nil
+		})))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if info := client.AuthDebugInfo(); info != (AuthDebugInfo{}) {
+		t.Errorf("want no info of doer but got %+v", info)
+	}
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceSnapshot is the state of the account balances at the moment.
+type BalanceSnapshot struct {
+	// Time when the balances were requested.
+	Time time.Time
+
+	// Asset is the asset of the account.
+	Asset string
+
+	// Available is the funds which can be used in trading.
+	Available decimal.Decimal
+
+	// Freezed is the funds occupied in trades.
+	Freezed decimal.Decimal
+
+	// Pending is the funds awaiting confirmation.
+	Pending decimal.Decimal
+}
+
+// BalanceSink is a receiver of balance snapshots, e.g. time series
+// database or file.
+type BalanceSink interface {
+	// WriteSnapshots writes snapshots of all accounts taken at once.
+	WriteSnapshots(snapshots []BalanceSnapshot) error
+}
+
+// BalanceRecorderConfig is the configuration of balance snapshots
+// recording.
+type BalanceRecorderConfig struct {
+	// Assets is the assets which accounts are recorded.
+	Assets []string
+
+	// Interval is the period between snapshots.
+	Interval time.Duration
+
+	// Sink is the receiver of snapshots.
+	Sink BalanceSink
+
+	// OnError is called on Accounts or sink error, the recording goes
+	// on with the next snapshot. Errors are discarded if nil.
+	OnError func(err error)
+}
+
+// RecordBalances takes snapshot of Accounts every interval and writes
+// it to the sink until ctx is done. First snapshot is taken
+// immediately. It blocks, so it is supposed to be run in its own
+// goroutine as a background job.
+func (c *Client) RecordBalances(ctx context.Context,
+	cfg BalanceRecorderConfig) error {
+
+	if cfg.Interval <= 0 {
+		return errors.New("interval should be positive")
+	}
+	if cfg.Sink == nil {
+		return errors.New("sink is not specified")
+	}
+
+	client := c.WithContext(ctx)
+
+	onError := func(err error) {
+		if cfg.OnError != nil {
+			cfg.OnError(err)
+		}
+	}
+
+	for {
+		if !c.maintenance.wait(ctx) {
+			return ctx.Err()
+		}
+
+		var (
+			accounts []Account
+			err      error
+		)
+		now := time.Now()
+		if perr := c.pool.Do(ctx, func() {
+			accounts, err = client.Accounts(cfg.Assets)
+		}); perr != nil {
+			err = perr
+		}
+
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			onError(errors.New("failed to get accounts: " + err.Error()))
+		default:
+			snapshots := make([]BalanceSnapshot, len(accounts))
+			for i, a := range accounts {
+				snapshots[i] = BalanceSnapshot{
+					Time:      now,
+					Asset:     a.Asset,
+					Available: a.Available,
+					Freezed:   a.Freezed,
+					Pending:   a.Pending.Amount,
+				}
+			}
+			if err := cfg.Sink.WriteSnapshots(snapshots); err != nil {
+				onError(errors.New("failed to write snapshots: " +
+					err.Error()))
+			}
+		}
+
+		if !sleep(ctx, cfg.Interval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// MetricsBalanceSink sets account_balance gauge labeled by asset and
+// kind (available, freezed or pending) for every snapshot, e.g. to be
+// scraped by Prometheus.
+type MetricsBalanceSink struct {
+	Metrics Metrics
+}
+
+// WriteSnapshots implements BalanceSink.
+func (s MetricsBalanceSink) WriteSnapshots(
+	snapshots []BalanceSnapshot) error {
+
+	for _, b := range snapshots {
+		for kind, value := range map[string]decimal.Decimal{
+			"available": b.Available,
+			"freezed":   b.Freezed,
+			"pending":   b.Pending,
+		} {
+			v, _ := value.Float64()
+			s.Metrics.Set("account_balance", Labels{
+				"asset": b.Asset,
+				"kind":  kind,
+			}, v)
+		}
+	}
+	return nil
+}
+
+// LineProtocolBalanceSink writes snapshots to W in InfluxDB line
+// protocol, one point per account tagged with asset.
+type LineProtocolBalanceSink struct {
+	// W is the writer of lines, e.g. file or HTTP request body.
+	W io.Writer
+
+	// Measurement is the name of the measurement, "balance" if empty.
+	Measurement string
+}
+
+// WriteSnapshots implements BalanceSink.
+func (s LineProtocolBalanceSink) WriteSnapshots(
+	snapshots []BalanceSnapshot) error {
+
+	measurement := s.Measurement
+	if measurement == "" {
+		measurement = "balance"
+	}
+
+	var b strings.Builder
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(&b, "%s,asset=%s available=%s,freezed=%s,pending=%s %d\n",
+			lineProtocolEscape(measurement),
+			lineProtocolEscape(snapshot.Asset),
+			snapshot.Available, snapshot.Freezed, snapshot.Pending,
+			snapshot.Time.UnixNano())
+	}
+
+	_, err := io.WriteString(s.W, b.String())
+	return err
+}
+
+// lineProtocolEscape escapes commas, spaces and equal signs in line
+// protocol measurement and tag values.
+func lineProtocolEscape(s string) string {
+	return strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`).Replace(s)
+}
+
+// CSVBalanceSink writes snapshots as CSV rows, header is written
+// before the first row.
+type CSVBalanceSink struct {
+	mtx           sync.Mutex
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVBalanceSink creates new CSV sink writing to w.
+func NewCSVBalanceSink(w io.Writer) *CSVBalanceSink {
+	return &CSVBalanceSink{w: csv.NewWriter(w)}
+}
+
+// WriteSnapshots implements BalanceSink.
+func (s *CSVBalanceSink) WriteSnapshots(snapshots []BalanceSnapshot) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !s.headerWritten {
+		if err := s.w.Write([]string{"time", "asset", "available",
+			"freezed", "pending"}); err != nil {
+			return err
+		}
+		s.headerWritten = true
+	}
+
+	for _, b := range snapshots {
+		if err := s.w.Write([]string{
+			strconv.FormatInt(b.Time.Unix(), 10),
+			b.Asset,
+			b.Available.String(),
+			b.Freezed.String(),
+			b.Pending.String(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	s.w.Flush()
+	return s.w.Error()
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// snapshotsRecorder is BalanceSink which stores written snapshots.
+type snapshotsRecorder struct {
+	mtx       sync.Mutex
+	snapshots [][]BalanceSnapshot
+	err       error
+}
+
+func (r *snapshotsRecorder) WriteSnapshots(s []BalanceSnapshot) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.snapshots = append(r.snapshots, s)
+	return r.err
+}
+
+func (r *snapshotsRecorder) len() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.snapshots)
+}
+
+func TestClient_RecordBalances(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{"data": {"accounts": [{"asset": "BTC",
+			"available": "1.5", "freezed": "0.5",
+			"pending": {"amount": "0.1"}}]}}`,
+	}
+	client := &Client{core: backend}
+
+	t.Run("records snapshots until ctx is done", func(t *testing.T) {
+		sink := &snapshotsRecorder{}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error)
+		go func() {
+			done <- client.RecordBalances(ctx, BalanceRecorderConfig{
+				Assets:   []string{"BTC"},
+				Interval: time.Millisecond,
+				Sink:     sink,
+			})
+		}()
+
+		for sink.len() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+
+		if err := <-done; err != context.Canceled {
+			t.Errorf("want context.Canceled but got `%v`", err)
+		}
+
+		s := sink.snapshots[0]
+		if len(s) != 1 || s[0].Asset != "BTC" ||
+			!s[0].Available.Equal(dec(1.5)) ||
+			!s[0].Freezed.Equal(dec(0.5)) ||
+			!s[0].Pending.Equal(dec(0.1)) {
+			t.Errorf("want BTC snapshot but got `%v`", s)
+		}
+	})
+	t.Run("reports sink errors", func(t *testing.T) {
+		sink := &snapshotsRecorder{err: errors.New("fail")}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errs := make(chan error, 1)
+		go client.RecordBalances(ctx, BalanceRecorderConfig{
+			Assets:   []string{"BTC"},
+			Interval: time.Hour,
+			Sink:     sink,
+			OnError: func(err error) {
+				errs <- err
+			},
+		})
+
+		if err := <-errs; err.Error() != "failed to write snapshots: fail" {
+			t.Errorf("want sink error but got `%v`", err)
+		}
+	})
+	t.Run("when no sink", func(t *testing.T) {
+		err := client.RecordBalances(context.Background(),
+			BalanceRecorderConfig{Interval: time.Second})
+		if err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+}
+
+var testSnapshots = []BalanceSnapshot{{
+	Time:      time.Unix(1500000000, 0),
+	Asset:     "BTC",
+	Available: dec(1.5),
+	Freezed:   dec(0.5),
+	Pending:   dec(0),
+}}
+
+func TestLineProtocolBalanceSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := LineProtocolBalanceSink{W: &buf}
+	if err := sink.WriteSnapshots(testSnapshots); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	want := "balance,asset=BTC available=1.5,freezed=0.5,pending=0 " +
+		"1500000000000000000\n"
+	if buf.String() != want {
+		t.Errorf("want `%s` but got `%s`", want, buf.String())
+	}
+}
+
+func TestCSVBalanceSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewCSVBalanceSink(&buf)
+	for i := 0; i < 2; i++ {
+		if err := sink.WriteSnapshots(testSnapshots); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+	}
+
+	want := "time,asset,available,freezed,pending\n" +
+		"1500000000,BTC,1.5,0.5,0\n" +
+		"1500000000,BTC,1.5,0.5,0\n"
+	if buf.String() != want {
+		t.Errorf("want `%s` but got `%s`", want, buf.String())
+	}
+}
+
+func TestMetricsBalanceSink(t *testing.T) {
+	metrics := &recordingMetrics{}
+	sink := MetricsBalanceSink{Metrics: metrics}
+	if err := sink.WriteSnapshots(testSnapshots); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if n := metrics.count("account_balance"); n != 3 {
+		t.Errorf("want 3 account_balance gauges but got %d", n)
+	}
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RecordType is the type of the account balance change record.
+type RecordType string
+
+// Balance change record types.
+const (
+	RecordDeposit    RecordType = "deposit"
+	RecordWithdrawal RecordType = "withdrawal"
+)
+
+// BalanceUpdate is the record of the account balance change history,
+// either deposit or withdrawal as told by Type. Only the field of the
+// record type is set.
+type BalanceUpdate struct {
+	// Type is the type of the record.
+	Type RecordType
+
+	// Asset is the asset which balance has been changed.
+	Asset string
+
+	// Deposit is set if the record is deposit.
+	Deposit *Deposit
+
+	// Withdrawal is set if the record is withdrawal.
+	Withdrawal *WithdrawalRecord
+}
+
+// balanceUpdatesRequestVariables is a query variables used in request
+// in client BalanceUpdates method.
+type balanceUpdatesRequestVariables struct {
+	Assets      []string     `json:"assets"`
+	RecordTypes []RecordType `json:"recordTypes,omitempty"`
+	Offset      int64        `json:"offset"`
+	Limit       int64        `json:"limit"`
+}
+
+// BalanceUpdates returns records of balance change history of the
+// assets of given types in given offset and limit. Empty types means
+// records of all types. It allows to rebuild the account ledger with
+// single request instead of merging Deposits and Withdrawals.
+func (c *Client) BalanceUpdates(assets []string, types []RecordType,
+	offset, limit int64) ([]BalanceUpdate, error) {
+
+	var req request
+
+	req.Query = `
+		query GetBalanceUpdateRecords($assets: [Asset!]!,
+$recordTypes: [RecordType!], $offset: Int!, $limit: Int!) {
+			balanceUpdateRecords(assets: $assets, offset: $offset,
+				recordTypes: $recordTypes, limit: $limit) {
+				__typename
+				... on Deposit {
+					asset
+					change
+					time
+					paymentID
+					paymentType
+				}
+				... on Withdrawal {
+					asset
+					change
+					time
+					paymentID
+					paymentAddr
+				}
+			}
+		}
+	`
+
+	req.Variables = balanceUpdatesRequestVariables{
+		Assets:      c.assets.assets(assets),
+		RecordTypes: types,
+		Offset:      offset,
+		Limit:       limit,
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Records []json.RawMessage `json:"balanceUpdateRecords"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	updates := make([]BalanceUpdate, len(resp.Data.Records))
+	for i, raw := range resp.Data.Records {
+		u, err := decodeBalanceUpdate(raw)
+		if err != nil {
+			return nil, decodeError(err)
+		}
+		u.Asset = c.assets.localAsset(u.Asset)
+		if u.Deposit != nil {
+			u.Deposit.Asset = u.Asset
+		}
+		updates[i] = u
+	}
+
+	return updates, nil
+}
+
+// decodeBalanceUpdate decodes balance update record of the GraphQL
+// union by its __typename.
+func decodeBalanceUpdate(raw json.RawMessage) (BalanceUpdate, error) {
+	var u BalanceUpdate
+
+	header := struct {
+		TypeName string `json:"__typename"`
+		Asset    string `json:"asset"`
+	}{}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return u, err
+	}
+	u.Asset = header.Asset
+
+	switch header.TypeName {
+	case "Deposit":
+		u.Type = RecordDeposit
+		u.Deposit = &Deposit{}
+		return u, json.Unmarshal(raw, u.Deposit)
+	case "Withdrawal":
+		u.Type = RecordWithdrawal
+		u.Withdrawal = &WithdrawalRecord{}
+		return u, json.Unmarshal(raw, u.Withdrawal)
+	default:
+		return u, errors.New("unknown balance update record type: " +
+			header.TypeName)
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClient_BalanceUpdates(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{ "data": { "balanceUpdateRecords": [
+			{ "__typename": "Deposit", "asset": "BTC", "change": "1",
+				"time": 10, "paymentID": "a", "paymentType": "blockchain" },
+			{ "__typename": "Withdrawal", "asset": "BTC", "change": "-0.5",
+				"time": 20, "paymentID": "b", "paymentAddr": "addr" }
+		] } }`,
+	}
+	client := &Client{core: backend}
+
+	updates, err := client.BalanceUpdates([]string{"BTC"},
+		[]RecordType{RecordDeposit, RecordWithdrawal}, 0, 10)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	want := []BalanceUpdate{{
+		Type:  RecordDeposit,
+		Asset: "BTC",
+		Deposit: &Deposit{
+			Asset:       "BTC",
+			PaymentID:   "a",
+			PaymentType: "blockchain",
+			Change:      dec(1),
+			Time:        10,
+		},
+	}, {
+		Type:  RecordWithdrawal,
+		Asset: "BTC",
+		Withdrawal: &WithdrawalRecord{
+			PaymentID:   "b",
+			PaymentAddr: "addr",
+			Change:      dec(-0.5),
+			Time:        20,
+		},
+	}}
+	if !reflect.DeepEqual(want, updates) {
+		t.Errorf("want updates `%#v` but got `%#v`", want, updates)
+	}
+
+	wantVariables := balanceUpdatesRequestVariables{
+		Assets:      []string{"BTC"},
+		RecordTypes: []RecordType{RecordDeposit, RecordWithdrawal},
+		Limit:       10,
+	}
+	if !reflect.DeepEqual(wantVariables, backend.request.Variables) {
+		t.Errorf("want variables `%#v` but got `%#v`", wantVariables,
+			backend.request.Variables)
+	}
+
+	t.Run("unknown record type", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `{ "data": { "balanceUpdateRecords": [
+				{ "__typename": "Fee", "change": "1" }
+			] } }`,
+		}
+		client := &Client{core: backend}
+
+		_, err := client.BalanceUpdates([]string{"BTC"}, nil, 0, 10)
+		if _, ok := err.(*DecodeError); !ok {
+			t.Errorf("want decode error but got `%v`", err)
+		}
+	})
+}
@@ -0,0 +1,451 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Batch combines multiple queries into the single GraphQL request using
+// aliases, which cuts round trips of e.g. dashboards which need
+// tickers, depth and accounts at once. Operations are added with Add
+// methods which return results filled once Do returns. Batch is not
+// safe for concurrent use.
+type Batch struct {
+	client *Client
+	ops    []*batchOp
+}
+
+// batchOp is the single operation of the batch.
+type batchOp struct {
+	// field is the selection of the operation with %[1]s placeholders
+	// for the alias, which prefixes its variables.
+	field string
+
+	// params is the variables of the operation.
+	params []batchParam
+
+	needAuth bool
+
+	// decode decodes the data of the operation alias.
+	decode func(data json.RawMessage) error
+
+	// fail sets the error of the operation.
+	fail func(err error)
+}
+
+// batchParam is the variable of the batch operation.
+type batchParam struct {
+	name  string
+	typ   string
+	value interface{}
+}
+
+// BatchTickers is the result of the tickers operation of the batch.
+type BatchTickers struct {
+	Tickers []Ticker
+	Err     error
+}
+
+// BatchMarkets is the result of the markets operation of the batch.
+type BatchMarkets struct {
+	Markets []MarketStatus
+	Err     error
+}
+
+// BatchDepth is the result of the depth operation of the batch.
+type BatchDepth struct {
+	Depth Depth
+	Err   error
+}
+
+// BatchAccounts is the result of the accounts operation of the batch.
+type BatchAccounts struct {
+	Accounts []Account
+	Err      error
+}
+
+// BatchOrders is the result of the open orders operation of the batch.
+type BatchOrders struct {
+	Orders []Order
+	Err    error
+}
+
+// BatchPendingWithdrawals is the result of the pending withdrawals
+// operation of the batch.
+type BatchPendingWithdrawals struct {
+	Withdrawals []PendingWithdrawal
+	Err         error
+}
+
+// BatchServerTime is the result of the server time operation of the
+// batch.
+type BatchServerTime struct {
+	Time time.Time
+	Err  error
+}
+
+// NewBatch creates new empty batch.
+func (c *Client) NewBatch() *Batch {
+	return &Batch{client: c}
+}
+
+// AddTickers adds the request of tickers of the markets.
+func (b *Batch) AddTickers(markets []string) *BatchTickers {
+	res := &BatchTickers{}
+	b.add(&batchOp{
+		field: `%[1]s: markets(markets: $%[1]s_markets,
+			period: $%[1]s_period) {
+				market
+				last
+				bestAsk
+				bestBid
+				volume
+			}`,
+		params: []batchParam{
+			{"markets", "[Market!]!", b.client.assets.markets(markets)},
+			{"period", "Int", int32(tickersPollPeriod)},
+		},
+		decode: func(data json.RawMessage) error {
+			if err := json.Unmarshal(data, &res.Tickers); err != nil {
+				return err
+			}
+			for i := range res.Tickers {
+				res.Tickers[i].Market = b.client.assets.localMarket(
+					res.Tickers[i].Market)
+			}
+			return nil
+		},
+		fail: func(err error) { res.Err = err },
+	})
+	return res
+}
+
+// AddMarkets adds the request of statuses of the markets for the
+// period, see Markets.
+func (b *Batch) AddMarkets(markets []string, period int32) *BatchMarkets {
+	res := &BatchMarkets{}
+	b.add(&batchOp{
+		field: `%[1]s: markets(markets: $%[1]s_markets,
+			period: $%[1]s_period) {
+				market
+				stock
+				money
+				open
+				close
+				high
+				last
+				low
+				volume
+				changeLast
+				changeHigh
+				changeLow
+				bestAsk
+				bestBid
+			}`,
+		params: []batchParam{
+			{"markets", "[Market!]!", b.client.assets.markets(markets)},
+			{"period", "Int", period},
+		},
+		decode: func(data json.RawMessage) error {
+			if err := json.Unmarshal(data, &res.Markets); err != nil {
+				return err
+			}
+			b.client.assets.localMarketStatuses(res.Markets)
+			res.Err = b.client.validator.marketStatuses(res.Markets)
+			return nil
+		},
+		fail: func(err error) { res.Err = err },
+	})
+	return res
+}
+
+// AddDepth adds the request of the market depth, see Depth.
+func (b *Batch) AddDepth(market string, limit uint,
+	interval float64) *BatchDepth {
+
+	res := &BatchDepth{}
+	b.add(&batchOp{
+		field: `%[1]s: depth(market: $%[1]s_market, limit: $%[1]s_limit,
+			interval: $%[1]s_interval) {
+				asks {
+					price
+					volume
+				}
+				bids {
+					price
+					volume
+				}
+			}`,
+		params: []batchParam{
+			{"market", "Market!", b.client.assets.market(market)},
+			{"limit", "Int", limit},
+			{"interval", "Float", interval},
+		},
+		decode: func(data json.RawMessage) error {
+			if err := json.Unmarshal(data, &res.Depth); err != nil {
+				return err
+			}
+			res.Err = b.client.validator.depth(&res.Depth)
+			return nil
+		},
+		fail: func(err error) { res.Err = err },
+	})
+	return res
+}
+
+// AddAccounts adds the request of accounts of the assets, see Accounts.
+// It makes the whole batch authorized.
+func (b *Batch) AddAccounts(assets []string) *BatchAccounts {
+	res := &BatchAccounts{}
+	b.add(&batchOp{
+		field: `%[1]s: accounts(assets: $%[1]s_assets) {
+				asset
+				address
+				available
+				estimation
+				freezed
+				pending {
+					amount
+					transactions {
+						confirmationsLeft
+						confirmations
+						address
+						amount
+						txid
+					}
+				}
+			}`,
+		params: []batchParam{
+			{"assets", "[Asset!]!", b.client.assets.assets(assets)},
+		},
+		needAuth: true,
+		decode: func(data json.RawMessage) error {
+			if err := json.Unmarshal(data, &res.Accounts); err != nil {
+				return err
+			}
+			for i := range res.Accounts {
+				res.Accounts[i].Asset = b.client.assets.localAsset(
+					res.Accounts[i].Asset)
+			}
+			return nil
+		},
+		fail: func(err error) { res.Err = err },
+	})
+	return res
+}
+
+// AddOpenOrders adds the request of open orders, see OpenOrders. It
+// makes the whole batch authorized.
+func (b *Batch) AddOpenOrders() *BatchOrders {
+	res := &BatchOrders{}
+	b.add(&batchOp{
+		field: `%[1]s: openOrders {
+				id
+				status
+				amount
+				price
+				dealStock
+				dealMoney
+				left
+			}`,
+		needAuth: true,
+		decode: func(data json.RawMessage) error {
+			return json.Unmarshal(data, &res.Orders)
+		},
+		fail: func(err error) { res.Err = err },
+	})
+	return res
+}
+
+// AddPendingWithdrawals adds the request of pending withdrawals of the
+// assets, see PendingWithdrawals. It makes the whole batch authorized.
+func (b *Batch) AddPendingWithdrawals(
+	assets []string) *BatchPendingWithdrawals {
+
+	res := &BatchPendingWithdrawals{}
+	b.add(&batchOp{
+		field: `%[1]s: pendingWithdrawals(assets: $%[1]s_assets) {
+				asset
+				change
+				time
+				paymentID
+				paymentAddr
+			}`,
+		params: []batchParam{
+			{"assets", "[Asset!]!", b.client.assets.assets(assets)},
+		},
+		needAuth: true,
+		decode: func(data json.RawMessage) error {
+			if err := json.Unmarshal(data, &res.Withdrawals); err != nil {
+				return err
+			}
+			for i := range res.Withdrawals {
+				res.Withdrawals[i].Asset = b.client.assets.localAsset(
+					res.Withdrawals[i].Asset)
+			}
+			return nil
+		},
+		fail: func(err error) { res.Err = err },
+	})
+	return res
+}
+
+// AddServerTime adds the request of the server time, see Info.
+func (b *Batch) AddServerTime() *BatchServerTime {
+	res := &BatchServerTime{}
+	b.add(&batchOp{
+		field: `%[1]s: info {
+				time
+			}`,
+		decode: func(data json.RawMessage) error {
+			var info Info
+			if err := json.Unmarshal(data, &info); err != nil {
+				return err
+			}
+			t, err := info.ServerTime()
+			if err != nil {
+				return err
+			}
+			res.Time = t
+			return nil
+		},
+		fail: func(err error) { res.Err = err },
+	})
+	return res
+}
+
+// add adds the operation to the batch.
+func (b *Batch) add(op *batchOp) {
+	b.ops = append(b.ops, op)
+}
+
+// Len returns the number of operations in the batch.
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Do sends all operations of the batch in the single request and fills
+// their results. Errors the server reports for the particular operation
+// are set to its result only. If the request itself fails, its error
+// is set to every result and returned.
+func (b *Batch) Do(ctx context.Context) error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+
+	req, needAuth := b.request()
+
+	err := b.do(ctx, req, needAuth)
+	if err != nil {
+		for _, op := range b.ops {
+			op.fail(err)
+		}
+	}
+	return err
+}
+
+// do sends the request and dispatches the response data and errors to
+// the operations.
+func (b *Batch) do(ctx context.Context, req request, needAuth bool) error {
+	respJSON, err := b.client.WithContext(ctx).do(needAuth, req)
+	if err != nil {
+		return transportError(err)
+	}
+
+	resp := struct {
+		responseBase
+		Data map[string]json.RawMessage
+	}{}
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return decodeError(err)
+	}
+
+	opErrors := make(map[string][]responseError)
+	var batchErrors []responseError
+	for _, e := range resp.Errors {
+		alias := ""
+		if len(e.Path) > 0 {
+			alias, _ = e.Path[0].(string)
+		}
+		if !b.hasAlias(alias) {
+			batchErrors = append(batchErrors, e)
+			continue
+		}
+		opErrors[alias] = append(opErrors[alias], e)
+	}
+
+	if len(batchErrors) > 0 {
+		return exchangeError(responseBase{Errors: batchErrors}.Error())
+	}
+
+	for i, op := range b.ops {
+		alias := batchAlias(i)
+
+		if errs := opErrors[alias]; len(errs) > 0 {
+			op.fail(exchangeError(responseBase{Errors: errs}.Error()))
+			continue
+		}
+
+		data, ok := resp.Data[alias]
+		if !ok || string(data) == "null" {
+			op.fail(errors.New("no data in response"))
+			continue
+		}
+
+		if err := op.decode(data); err != nil {
+			op.fail(decodeError(err))
+		}
+	}
+
+	return nil
+}
+
+// request builds the GraphQL request of the batch and tells whether it
+// needs authorization.
+func (b *Batch) request() (request, bool) {
+	var (
+		declarations []string
+		fields       []string
+		needAuth     bool
+	)
+	variables := make(map[string]interface{})
+
+	for i, op := range b.ops {
+		alias := batchAlias(i)
+		for _, p := range op.params {
+			name := alias + "_" + p.name
+			declarations = append(declarations, "$"+name+": "+p.typ)
+			variables[name] = p.value
+		}
+		fields = append(fields, fmt.Sprintf(op.field, alias))
+		needAuth = needAuth || op.needAuth
+	}
+
+	query := "query Batch {\n"
+	if len(declarations) > 0 {
+		query = "query Batch(" + strings.Join(declarations, ", ") + ") {\n"
+	}
+	query += strings.Join(fields, "\n") + "\n}"
+
+	return request{Query: query, Variables: variables}, needAuth
+}
+
+// hasAlias returns whether the alias belongs to an operation of the
+// batch.
+func (b *Batch) hasAlias(alias string) bool {
+	for i := range b.ops {
+		if batchAlias(i) == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// batchAlias returns the alias of the i-th operation of the batch.
+func batchAlias(i int) string {
+	return fmt.Sprintf("op%d", i)
+}
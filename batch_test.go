@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// authCore is the core which records whether the request needs
+// authorization.
+type authCore struct {
+	mockCore
+	needAuth bool
+}
+
+func (c *authCore) do(ctx context.Context, needAuth bool,
+	r request) ([]byte, error) {
+
+	c.needAuth = needAuth
+	return c.mockCore.do(ctx, needAuth, r)
+}
+
+func TestBatch_Do(t *testing.T) {
+	backend := &authCore{mockCore: mockCore{respJSON: `{
+			"data": {
+				"op0": [{"market": "BTCETH", "last": "0.03",
+					"bestAsk": "0.031", "bestBid": "0.029",
+					"volume": "10"}],
+				"op1": {"asks": [{"price": "0.031", "volume": "1"}],
+					"bids": [{"price": "0.029", "volume": "2"}]},
+				"op2": null
+			},
+			"errors": [{"message": "not authorized", "path": ["op2"],
+				"extensions": {"code": "UNAUTHENTICATED"}}]
+		}`}}
+	client := &Client{core: backend}
+
+	b := client.NewBatch()
+	tickers := b.AddTickers([]string{"BTCETH"})
+	depth := b.AddDepth("BTCETH", 10, 0)
+	accounts := b.AddAccounts([]string{"BTC"})
+
+	if b.Len() != 3 {
+		t.Fatalf("want 3 operations but got %v", b.Len())
+	}
+
+	if err := b.Do(context.Background()); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if !backend.needAuth {
+		t.Error("want batch with accounts authorized")
+	}
+
+	req := backend.request
+	for _, want := range []string{
+		"op0: markets(markets: $op0_markets",
+		"op1: depth(market: $op1_market",
+		"op2: accounts(assets: $op2_assets)",
+		"$op1_market: Market!",
+	} {
+		if !strings.Contains(req.Query, want) {
+			t.Errorf("want query to contain `%v` but got `%v`",
+				want, req.Query)
+		}
+	}
+
+	vars := req.Variables.(map[string]interface{})
+	if vars["op1_market"] != "BTCETH" {
+		t.Errorf("want depth market variable BTCETH but got %v",
+			vars["op1_market"])
+	}
+
+	if tickers.Err != nil {
+		t.Fatalf("want no tickers error but got `%v`", tickers.Err)
+	}
+	if len(tickers.Tickers) != 1 ||
+		!tickers.Tickers[0].Last.Equal(dec(0.03)) {
+		t.Errorf("want ticker with last 0.03 but got %v", tickers.Tickers)
+	}
+
+	if depth.Err != nil {
+		t.Fatalf("want no depth error but got `%v`", depth.Err)
+	}
+	if len(depth.Depth.Asks) != 1 || len(depth.Depth.Bids) != 1 {
+		t.Errorf("want one ask and bid but got %v", depth.Depth)
+	}
+
+	var exchangeErr *ExchangeError
+	if !errors.As(accounts.Err, &exchangeErr) ||
+		exchangeErr.Code != "UNAUTHENTICATED" {
+		t.Errorf("want accounts exchange error but got `%v`", accounts.Err)
+	}
+}
+
+func TestBatch_DoFailed(t *testing.T) {
+	client := &Client{core: &mockCore{error: errors.New("refused")}}
+
+	b := client.NewBatch()
+	tickers := b.AddTickers([]string{"BTCETH"})
+	depth := b.AddDepth("BTCETH", 10, 0)
+
+	err := b.Do(context.Background())
+	if err == nil {
+		t.Fatal("want error but got nil")
+	}
+	if tickers.Err != err || depth.Err != err {
+		t.Errorf("want request error set to all results but got `%v`"+
+			" and `%v`", tickers.Err, depth.Err)
+	}
+
+	if err := client.NewBatch().Do(context.Background()); err != nil {
+		t.Errorf("want no error of empty batch but got `%v`", err)
+	}
+}
+
+func TestBatch_DoRequestError(t *testing.T) {
+	client := &Client{core: &mockCore{respJSON: `{
+		"errors": [{"message": "syntax error"}]
+	}`}}
+
+	b := client.NewBatch()
+	tickers := b.AddTickers([]string{"BTCETH"})
+
+	if err := b.Do(context.Background()); err == nil {
+		t.Fatal("want error but got nil")
+	}
+	if tickers.Err == nil {
+		t.Error("want error set to result")
+	}
+}
+
+func TestBatch_requestWithoutVariables(t *testing.T) {
+	client := &Client{}
+
+	b := client.NewBatch()
+	b.AddOpenOrders()
+	b.AddServerTime()
+
+	req, needAuth := b.request()
+	if !strings.HasPrefix(req.Query, "query Batch {") {
+		t.Errorf("want query without variables but got `%v`", req.Query)
+	}
+	if !needAuth {
+		t.Error("want batch with open orders authorized")
+	}
+}
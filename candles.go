@@ -0,0 +1,181 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CandleInterval is the period covered by a candle, in seconds.
+type CandleInterval int64
+
+const (
+	// CandleMinute is the one minute candle.
+	CandleMinute CandleInterval = 60
+
+	// Candle5Minutes is the five minutes candle.
+	Candle5Minutes CandleInterval = 5 * 60
+
+	// Candle15Minutes is the fifteen minutes candle.
+	Candle15Minutes CandleInterval = 15 * 60
+
+	// CandleHour is the one hour candle.
+	CandleHour CandleInterval = 60 * 60
+
+	// Candle4Hours is the four hours candle.
+	Candle4Hours CandleInterval = 4 * 60 * 60
+
+	// CandleDay is the one day candle.
+	CandleDay CandleInterval = 24 * 60 * 60
+
+	// CandleWeek is the one week candle.
+	CandleWeek CandleInterval = 7 * 24 * 60 * 60
+)
+
+// candleIntervals is the names of supported candle intervals.
+var candleIntervals = map[CandleInterval]string{
+	CandleMinute:    "1m",
+	Candle5Minutes:  "5m",
+	Candle15Minutes: "15m",
+	CandleHour:      "1h",
+	Candle4Hours:    "4h",
+	CandleDay:       "1d",
+	CandleWeek:      "1w",
+}
+
+// String returns the short name of the interval, e.g. "1h".
+func (i CandleInterval) String() string {
+	if name, ok := candleIntervals[i]; ok {
+		return name
+	}
+	return i.Duration().String()
+}
+
+// Duration returns the period covered by the candle.
+func (i CandleInterval) Duration() time.Duration {
+	return time.Duration(i) * time.Second
+}
+
+// Candle is the OHLCV summary of market deals over the interval.
+type Candle struct {
+	// Time is the start of the candle interval.
+	Time time.Time
+
+	// Open is the price of the first deal of the interval.
+	Open decimal.Decimal
+
+	// High is the highest deal price of the interval.
+	High decimal.Decimal
+
+	// Low is the lowest deal price of the interval.
+	Low decimal.Decimal
+
+	// Close is the price of the last deal of the interval.
+	Close decimal.Decimal
+
+	// Volume is the traded amount of stock, i.e. right asset of the
+	// market.
+	Volume decimal.Decimal
+
+	// Deal is the traded amount of money, i.e. left asset of the
+	// market.
+	Deal decimal.Decimal
+}
+
+// candlesRequestVariables is a query variables used in request in
+// client Candles method.
+type candlesRequestVariables struct {
+	Market   string  `json:"market"`
+	From     float64 `json:"from"`
+	To       float64 `json:"to"`
+	Interval int64   `json:"interval"`
+}
+
+// Candles returns candles of the market which start in [from, to) in
+// chronological order, e.g. to backtest strategies on historical data.
+func (c *Client) Candles(market string, interval CandleInterval, from,
+	to time.Time) ([]Candle, error) {
+
+	if _, ok := candleIntervals[interval]; !ok {
+		return nil, errors.New("unsupported candle interval " +
+			interval.String())
+	}
+	if !from.Before(to) {
+		return nil, errors.New("from should be before to")
+	}
+
+	var req request
+
+	req.Query = `
+		query Candles($market: Market!, $from: Float!, $to: Float!,
+			$interval: Int!) {
+			candles(market: $market, from: $from, to: $to,
+				interval: $interval) {
+				time
+				open
+				high
+				low
+				close
+				volume
+				deal
+			}
+		}
+	`
+
+	req.Variables = candlesRequestVariables{
+		Market:   c.assets.market(market),
+		From:     unixSeconds(from),
+		To:       unixSeconds(to),
+		Interval: int64(interval),
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Candles []struct {
+				Time   timestamp       `json:"time"`
+				Open   decimal.Decimal `json:"open"`
+				High   decimal.Decimal `json:"high"`
+				Low    decimal.Decimal `json:"low"`
+				Close  decimal.Decimal `json:"close"`
+				Volume decimal.Decimal `json:"volume"`
+				Deal   decimal.Decimal `json:"deal"`
+			} `json:"candles"`
+		}
+	}{}
+
+	respJSON, err := c.do(false, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	candles := make([]Candle, 0, len(resp.Data.Candles))
+	for _, r := range resp.Data.Candles {
+		candles = append(candles, Candle{
+			Time:   time.Time(r.Time),
+			Open:   r.Open,
+			High:   r.High,
+			Low:    r.Low,
+			Close:  r.Close,
+			Volume: r.Volume,
+			Deal:   r.Deal,
+		})
+	}
+
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Time.Before(candles[j].Time)
+	})
+
+	return candles, nil
+}
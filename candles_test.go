@@ -0,0 +1,62 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClient_Candles(t *testing.T) {
+	core := &mockCore{respJSON: `{"data": {"candles": [
+		{"time": 1540000060, "open": "2", "high": "3", "low": "1",
+			"close": "2.5", "volume": "10", "deal": "25"},
+		{"time": 1540000000, "open": "1", "high": "2", "low": "1",
+			"close": "2", "volume": "4", "deal": "6"}
+	]}}`}
+	client := &Client{core: core}
+
+	from := time.Unix(1540000000, 0)
+	candles, err := client.Candles("BTCETH", CandleMinute, from,
+		from.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	vars := core.request.Variables.(candlesRequestVariables)
+	if vars.Interval != 60 || vars.From != 1540000000 ||
+		vars.To != 1540003600 {
+		t.Errorf("want minute candles of the hour requested but got `%v`",
+			vars)
+	}
+
+	if len(candles) != 2 {
+		t.Fatalf("want 2 candles but got %v", len(candles))
+	}
+	if !candles[0].Time.Equal(from) || !candles[0].Open.Equal(dec(1)) {
+		t.Errorf("want candles in chronological order but got `%v`",
+			candles[0])
+	}
+	if c := candles[1]; !c.Close.Equal(dec(2.5)) || !c.Deal.Equal(dec(25)) {
+		t.Errorf("want second candle decoded but got `%v`", c)
+	}
+
+	if _, err := client.Candles("BTCETH", CandleInterval(7), from,
+		from.Add(time.Hour)); err == nil {
+		t.Error("want error of unsupported interval")
+	}
+	if _, err := client.Candles("BTCETH", CandleHour, from,
+		from); err == nil {
+		t.Error("want error of empty range")
+	}
+}
+
+func TestCandleInterval(t *testing.T) {
+	if s := Candle4Hours.String(); s != "4h" {
+		t.Errorf("want `4h` but got `%s`", s)
+	}
+	if d := CandleDay.Duration(); d != 24*time.Hour {
+		t.Errorf("want day but got %v", d)
+	}
+	if s := CandleInterval(90).String(); s != "1m30s" {
+		t.Errorf("want `1m30s` but got `%s`", s)
+	}
+}
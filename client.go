@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -12,12 +13,126 @@ import (
 // Client is the http://exchange.bitlum.io exchange client which wraps the raw GraphQL API.
 type Client struct {
 	core
+
+	// ctx is the context of the client requests, nil means
+	// context.Background().
+	ctx context.Context
+
+	// fallback is used for read-only operations if GraphQL request
+	// fails, nil if fallback is disabled.
+	fallback *restFallback
+
+	// assets translates asset codes between local and exchange ones,
+	// nil if no mapping is configured.
+	assets *assetMapper
+
+	// withdrawals tracks withdrawn amounts, nil if withdrawal limits
+	// are not configured.
+	withdrawals *WithdrawalTracker
+
+	// enforceWithdrawalLimits rejects withdrawals exceeding limits.
+	enforceWithdrawalLimits bool
+
+	// orders caches Order lookups, nil if cache is disabled.
+	orders *orderCache
+
+	// orderStates detects impossible transitions of orders.
+	orderStates *orderStates
+
+	// complexity is the query complexity budget, nil if there is none.
+	complexity *ComplexityConfig
+
+	// validator checks sanity of market data, nil if validation is
+	// disabled.
+	validator *marketDataValidator
+
+	// clock is the clock corrected by server clock offset, nil if
+	// clock skew compensation is disabled.
+	clock *skewClock
+
+	// subs serves the client subscriptions, nil means default
+	// configuration.
+	subs *subscriptions
+
+	// preTradeChecks are invoked before every order placement.
+	preTradeChecks []PreTradeCheck
+
+	// journal records mutations, nil if journaling is disabled.
+	journal *Journal
+
+	// pool runs background work, nil means a goroutine per task.
+	pool *WorkerPool
+
+	// withdrawalLog keeps withdrawal results of the session, nil
+	// disables keeping.
+	withdrawalLog *withdrawalLog
+
+	// deprecations collects deprecations reported by the server.
+	deprecations *deprecations
+
+	// keepAlive is the configuration of keepalive pings.
+	keepAlive KeepAliveConfig
+
+	// dryRun synthesizes mutation results, nil if dry run mode is
+	// disabled.
+	dryRun *dryRun
+
+	// mutationProbes makes ProbePermissions probe trading and withdraw
+	// permissions with mutations.
+	mutationProbes bool
+
+	// inFlight is the inventory of outstanding requests, nil disables
+	// tracking.
+	inFlight *inFlight
+
+	// serial sends requests one by one, nil means requests are sent
+	// concurrently.
+	serial *WorkerPool
+
+	// permissions caches probed capabilities of the credentials, nil
+	// disables caching.
+	permissions *permissions
+
+	// logger logs requests, nil disables logging.
+	logger Logger
+
+	// slowCalls logs sampled slow calls, nil disables it.
+	slowCalls *slowCallLogger
+
+	// maintenance tracks maintenance windows, nil means there are
+	// none.
+	maintenance *maintenance
+
+	// health tracks outcomes of requests, nil disables tracking.
+	health *healthTracker
+
+	// errs reports errors of background goroutines, nil disables panic
+	// recovery.
+	errs *errorReporter
+
+	// nativeTimeInForce makes the client pass time in force of orders
+	// to the server instead of emulating it.
+	nativeTimeInForce bool
+
+	// marketsCache caches supported markets, nil disables caching.
+	marketsCache *marketsCache
+
+	// validation makes the client validate orders and withdrawals
+	// against market and asset metadata.
+	validation bool
 }
 
 // NewClient creates new client for bitlum exchange on specified URL
 // with either JWT token or hex encoded binary macaroon.
 // It returns an error if the macaroon can not be decoded.
-func NewClient(url string, macaroon string, jwt string) (*Client, error) {
+func NewClient(url string, macaroon string, jwt string,
+	opts ...Option) (*Client, error) {
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	var m *gomacaroon.Macaroon
 
 	if macaroon != "" {
@@ -26,34 +141,159 @@ func NewClient(url string, macaroon string, jwt string) (*Client, error) {
 		if err != nil {
 			return nil, err
 		}
+	} else if o.issuedMacaroon != nil {
+		var err error
+		m, err = o.issuedMacaroon.load()
+		if err != nil {
+			return nil, err
+		}
+	}
+	httpClient, transport := newHTTPClient(o)
+
+	var clock *skewClock
+	if o.clockSkew != nil {
+		clock = newSkewClock(*o.clockSkew, o.metrics)
+	}
+
+	deprecations := newDeprecations(o.deprecationHandler)
+
+	var nonces *nonceGenerator
+	if o.nonces != nil {
+		nonces = newNonceGenerator(*o.nonces)
 	}
-	return &Client{
+
+	concurrency := newConcurrencyLimiter(o.maxConcurrentAuth,
+		o.maxConcurrentPublic, o.metrics)
+
+	c := &Client{
 		core: &graphQLCore{
-			url:      url,
-			macaroon: m,
-			jwt:      jwt,
+			url:            url,
+			macaroon:       m,
+			jwt:            jwt,
+			signer:         o.signer,
+			reauth:         o.reauth,
+			tokens:         o.tokenSource,
+			issuer:         o.issuedMacaroon,
+			authTrace:      &authTrace{},
+			har:            o.har,
+			httpClient:     httpClient,
+			transport:      transport,
+			timeout:        o.timeouts.Total,
+			clock:          clock,
+			prober:         o.prober,
+			deprecations:   deprecations,
+			retry:          o.retry,
+			hedge:          o.hedge,
+			msgpack:        o.msgpack,
+			checksumHeader: o.checksumHeader,
+			strictDecimals: o.strictDecimals,
+			rateLimit:      o.rateLimit,
+			concurrency:    concurrency,
+			throttle:       o.throttle,
+			nonces:         nonces,
 		},
-	}, nil
-}
+		enforceWithdrawalLimits: o.enforceWithdrawalLimits,
+		clock:                   clock,
+		subs:                    newSubscriptions(o.subscriptions, nil, o.metrics),
+		preTradeChecks:          o.preTradeChecks,
+		journal:                 o.journal,
+		pool:                    o.pool,
+		withdrawalLog:           newWithdrawalLog(withdrawalLogSize),
+		deprecations:            deprecations,
+		keepAlive:               o.keepAlive,
+		inFlight:                newInFlight(),
+		errs:                    newErrorReporter(o.metrics),
+		health:                  newHealthTracker(),
+		logger:                  o.logger,
+		permissions:             &permissions{},
+		nativeTimeInForce:       o.nativeTimeInForce,
+		orderStates:             newOrderStates(o.stateAnomalyHandler, o.metrics),
+		complexity:              o.complexity,
+	}
+
+	if o.serializedRequests {
+		c.serial = NewWorkerPool(1, 0, o.metrics)
+	}
+	c.subs.pool = o.pool
+	c.subs.keepAlive = o.keepAlive.StreamInterval
+
+	streamURL := o.subscriptions.WebSocketURL
+	if streamURL == "" {
+		streamURL = wsURL(url)
+	}
+	c.subs.dialer = newWSDialer(streamURL, c.core.(*graphQLCore), transport)
+
+	if o.doer != nil {
+		c.core = doerCore{o.doer}
+		c.subs.dialer = nil
+	}
+
+	if o.restFallbackURL != "" {
+		c.fallback = &restFallback{
+			baseURL:    o.restFallbackURL,
+			httpClient: httpClient,
+			transport:  transport,
+			timeout:    o.timeouts.Total,
+		}
+	}
+
+	if o.assetCodes != nil {
+		c.assets = newAssetMapper(o.assetCodes)
+	}
+
+	if o.withdrawalLimits != nil {
+		c.withdrawals = NewWithdrawalTracker(o.withdrawalLimits)
+	}
+
+	if o.maintenance != nil {
+		m, err := newMaintenance(*o.maintenance)
+		if err != nil {
+			return nil, err
+		}
+		m.fetch = c.MaintenanceWindows
+		c.maintenance = m
+		c.subs.maintenance = m
+	}
 
-// Markets return markets supported by exchange
-// TODO: the list should be requested from the backend
-func (c *Client) SupportedMarkets() []string {
-	return []string{
-		"BTCETH",
-		"BTCBCH",
-		"BTCDASH",
-		"BTCLTC",
+	if o.slowCalls != nil {
+		c.slowCalls = newSlowCallLogger(*o.slowCalls, o.metrics)
 	}
+
+	if o.orderCache {
+		c.orders = newOrderCache(o.orderCachePendingTTL, o.metrics)
+	}
+
+	if o.marketDataValidation != nil {
+		c.validator = newMarketDataValidator(*o.marketDataValidation)
+	}
+
+	c.mutationProbes = o.mutationProbes
+
+	if o.dryRun {
+		c.dryRun = &dryRun{}
+	}
+
+	if o.supportedMarketsTTL > 0 {
+		c.marketsCache = newMarketsCache(o.supportedMarketsTTL)
+	}
+
+	if o.validation {
+		c.validation = true
+		if c.marketsCache == nil {
+			c.marketsCache = newMarketsCache(defaultMarketsCacheTTL)
+		}
+	}
+
+	return c, nil
 }
 
 // Me is a structure to hold the result of Me query
 type Me struct {
 	// ID is User's ID
-	ID string
+	ID string `json:"id"`
 	// Email is an email used during the registration of the user
 	// or an email of the user who requested macaroon token
-	Email string
+	Email string `json:"email"`
 }
 
 // Me returns user info on behalf which all
@@ -79,16 +319,15 @@ func (c *Client) Me() (Me, error) {
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return Me{}, errors.New("failed to do request: " + err.Error())
+		return Me{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return Me{}, errors.New("failed to json.Unmarshal resp: " +
-			err.Error())
+		return Me{}, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return Me{}, errors.New("exchange error: " + err.Error())
+		return Me{}, exchangeError(err)
 	}
 
 	return resp.Data.Me, nil
@@ -118,16 +357,15 @@ func (c *Client) UserID() (string, error) {
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return "", errors.New("failed to do request: " + err.Error())
+		return "", transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return "", errors.New("failed to json.Unmarshal resp: " +
-			err.Error())
+		return "", decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return "", errors.New("exchange error: " + err.Error())
+		return "", exchangeError(err)
 	}
 
 	return resp.Data.User.ID, nil
@@ -139,8 +377,8 @@ func (c *Client) UserID() (string, error) {
 // order to sell ETH for BTC.
 // https://www.investopedia.com/terms/b/bid-and-asked.asp
 type Ask struct {
-	Price  decimal.Decimal
-	Volume decimal.Decimal
+	Price  decimal.Decimal `json:"price"`
+	Volume decimal.Decimal `json:"volume"`
 }
 
 // Bid is an order to buy stock (right asset in market) with given
@@ -149,16 +387,20 @@ type Ask struct {
 // order to buy ETH using BTC.
 // https://www.investopedia.com/terms/b/bid-and-asked.asp
 type Bid struct {
-	Price  decimal.Decimal
-	Volume decimal.Decimal
+	Price  decimal.Decimal `json:"price"`
+	Volume decimal.Decimal `json:"volume"`
 }
 
 // Depth is limited lists of asks and bids in benefit order.
 type Depth struct {
 	// Top asks by increasing price
-	Asks []Ask
+	Asks []Ask `json:"asks"`
 	// Top bids by decreasing price.
-	Bids []Bid
+	Bids []Bid `json:"bids"`
+
+	// Anomalies describes failed sanity checks if market data
+	// validation is enabled.
+	Anomalies []string `json:"-"`
 }
 
 // depthRequestVariables is a query variables used in request
@@ -192,7 +434,8 @@ func (c *Client) Depth(market string, limit uint, interval float64) (Depth, erro
 		}
 	`
 
-	req.Variables = depthRequestVariables{market, limit, interval}
+	req.Variables = depthRequestVariables{c.assets.market(market), limit,
+		interval}
 
 	resp := struct {
 		responseBase
@@ -203,16 +446,19 @@ func (c *Client) Depth(market string, limit uint, interval float64) (Depth, erro
 
 	respJSON, err := c.do(false, req)
 	if err != nil {
-		return depth, errors.New("failed to do request: " + err.Error())
+		return depth, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return depth, errors.New("failed to json.Unmarshal resp: " +
-			err.Error())
+		return depth, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return depth, errors.New("exchange error: " + err.Error())
+		return depth, exchangeError(err)
+	}
+
+	if err := c.validator.depth(&resp.Data.Depth); err != nil {
+		return resp.Data.Depth, err
 	}
 
 	return resp.Data.Depth, nil
@@ -228,26 +474,38 @@ type depositRequestVariables struct {
 
 // Deposit represents an account deposit.
 type Deposit struct {
+	// Asset is the asset of the deposit.
+	Asset string `json:"asset"`
+
 	// PaymentID is system specific withdraw operation ID.
 	// In blockchain it is transaction ID, in lightning network
 	// it is payment hash.
-	PaymentID string
+	PaymentID string `json:"paymentID"`
 
 	// PaymentSystem is a payment system in which deposit payment was
 	// occurred,
-	PaymentType string
+	PaymentType string `json:"paymentType"`
 
 	// Change is an amount on which balance has been changed.
-	Change decimal.Decimal
+	Change decimal.Decimal `json:"change"`
 
 	// Time when deposit was registered.
-	Time float64
+	Time float64 `json:"time"`
 }
 
 // Deposits returns account deposits in given offset and limit
 // from account change history.
 func (c *Client) Deposits(asset string, offset,
-limit int64) ([]Deposit, error) {
+	limit int64) ([]Deposit, error) {
+
+	deposits, _, err := c.depositRecords(asset, offset, limit)
+	return deposits, err
+}
+
+// depositRecords returns account deposits in given offset and limit
+// along with pagination metadata reported by the server.
+func (c *Client) depositRecords(asset string, offset,
+	limit int64) ([]Deposit, pageMeta, error) {
 
 	var req request
 
@@ -257,6 +515,7 @@ $limit: Int!) {
   			balanceUpdateRecords(assets: $assets, offset: $offset,
 				recordTypes: deposit, limit: $limit) {
     			... on Deposit {
+      				asset
       				change
       				time
       				paymentID
@@ -267,13 +526,14 @@ $limit: Int!) {
 	`
 
 	req.Variables = depositRequestVariables{
-		Assets: []string{asset},
+		Assets: []string{c.assets.asset(asset)},
 		Offset: offset,
 		Limit:  limit,
 	}
 
 	resp := struct {
 		responseBase
+		pageMeta
 		Data struct {
 			Deposits []Deposit `json:"balanceUpdateRecords"`
 		}
@@ -281,19 +541,110 @@ $limit: Int!) {
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return nil, errors.New("failed to do request: " + err.Error())
+		return nil, pageMeta{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, pageMeta{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, pageMeta{}, exchangeError(err)
+	}
+
+	for i, d := range resp.Data.Deposits {
+		resp.Data.Deposits[i].Asset = c.assets.localAsset(d.Asset)
+	}
+
+	return resp.Data.Deposits, resp.pageMeta, nil
+}
+
+// WithdrawalRecord represents an account withdrawal record of balance
+// change history.
+type WithdrawalRecord struct {
+	// PaymentID is system specific withdraw operation ID.
+	// In blockchain it is transaction ID, in lightning network
+	// it is payment hash.
+	PaymentID string `json:"paymentID"`
+
+	// PaymentAddr is the address of the payment receiver in
+	// blockchain system. Meaningless in lightning network.
+	PaymentAddr string `json:"paymentAddr"`
+
+	// Change is an amount on which balance has been changed.
+	Change decimal.Decimal `json:"change"`
+
+	// Time when withdrawal was registered.
+	Time float64 `json:"time"`
+}
+
+// withdrawalsRequestVariables is a query variables used in request
+// in client Withdrawals method.
+type withdrawalsRequestVariables struct {
+	Assets []string `json:"assets"`
+	Offset int64    `json:"offset"`
+	Limit  int64    `json:"limit"`
+}
+
+// Withdrawals returns account withdrawals in given offset and limit
+// from account change history.
+func (c *Client) Withdrawals(asset string, offset,
+	limit int64) ([]WithdrawalRecord, error) {
+
+	withdrawals, _, err := c.withdrawalRecords(asset, offset, limit)
+	return withdrawals, err
+}
+
+// withdrawalRecords returns account withdrawals in given offset and
+// limit along with pagination metadata reported by the server.
+func (c *Client) withdrawalRecords(asset string, offset,
+	limit int64) ([]WithdrawalRecord, pageMeta, error) {
+
+	var req request
+
+	req.Query = `
+		query GetWithdrawals($assets: [Asset!]!, $offset: Int!,
+$limit: Int!) {
+			balanceUpdateRecords(assets: $assets, offset: $offset,
+				recordTypes: withdrawal, limit: $limit) {
+				... on Withdrawal {
+					change
+					time
+					paymentID
+					paymentAddr
+				}
+			}
+		}
+	`
+
+	req.Variables = withdrawalsRequestVariables{
+		Assets: []string{c.assets.asset(asset)},
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	resp := struct {
+		responseBase
+		pageMeta
+		Data struct {
+			Withdrawals []WithdrawalRecord `json:"balanceUpdateRecords"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return nil, pageMeta{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return nil, errors.New("failed to json.Unmarshal resp: " +
-			err.Error())
+		return nil, pageMeta{}, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.New("exchange error: " + err.Error())
+		return nil, pageMeta{}, exchangeError(err)
 	}
 
-	return resp.Data.Deposits, nil
+	return resp.Data.Withdrawals, resp.pageMeta, nil
 }
 
 // Order is an exchange order to buy or sell stock. Market contains
@@ -301,32 +652,39 @@ $limit: Int!) {
 // Market{BTC,LTC} means that BTC is a money and LTC - stock.
 type Order struct {
 	// ID is a exchange specific order ID
-	ID int64
+	ID int64 `json:"id"`
 
 	// Status is order status: pending, finished or canceled
-	Status string
+	Status string `json:"status"`
 
 	// Either amount of money or stock depending on direction
 	// of order: buy or sell. Now only buy direction used so this is
 	// always should be stock amount.
-	Amount decimal.Decimal
+	Amount decimal.Decimal `json:"amount"`
 
 	// Price of 1 stock in money currency.
-	Price decimal.Decimal
+	Price decimal.Decimal `json:"price"`
 
 	// DealMoney is the amount of money which were involved in the
 	// order.
-	DealMoney decimal.Decimal
+	DealMoney decimal.Decimal `json:"dealMoney"`
 
 	// DealStock is the amount of stock which were involved in the
 	// order.
-	DealStock decimal.Decimal
+	DealStock decimal.Decimal `json:"dealStock"`
 
 	// Left is the amount of funds left in the market without being
 	// handled.
-	Left decimal.Decimal
+	Left decimal.Decimal `json:"left"`
 }
 
+// Order statuses reported by exchange.
+const (
+	OrderPending  = "pending"
+	OrderFinished = "finished"
+	OrderCanceled = "canceled"
+)
+
 // orderRequestVariables is a query variables used in request
 // in client Order method.
 type orderRequestVariables struct {
@@ -336,6 +694,10 @@ type orderRequestVariables struct {
 // Order returns order with specified id
 func (c *Client) Order(id int64) (Order, error) {
 
+	if order, ok := c.orders.get(id); ok {
+		return order, nil
+	}
+
 	var req request
 
 	req.Query = `
@@ -347,6 +709,7 @@ func (c *Client) Order(id int64) (Order, error) {
 				dealMoney
 				amount
 				price
+				left
   			}
 		}
 	`
@@ -362,32 +725,161 @@ func (c *Client) Order(id int64) (Order, error) {
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return Order{}, errors.New("failed to do request: " + err.
-			Error())
+		return Order{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return Order{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return Order{}, exchangeError(err)
+	}
+
+	c.orders.put(resp.Data.Order)
+	c.orderStates.observe(resp.Data.Order)
+
+	return resp.Data.Order, nil
+}
+
+// cancelOrderRequestVariables is a query variables used in request
+// in client CancelOrder method.
+type cancelOrderRequestVariables struct {
+	ID int64 `json:"id"`
+}
+
+// CancelOrder cancels pending order with specified id and returns the
+// order in its final state.
+func (c *Client) CancelOrder(id int64) (Order, error) {
+
+	var req request
+
+	req.Query = `
+		mutation CancelOrder($id: Int!) {
+			cancelOrder(id: $id) {
+				id
+				status
+				amount
+				price
+				dealStock
+				dealMoney
+				left
+			}
+		}
+	`
+
+	req.Variables = cancelOrderRequestVariables{id}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Order Order `json:"cancelOrder"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+
+	// Order may be canceled even if the response is lost, so it is
+	// invalidated once the mutation is sent whatever its result is.
+	// Dry run doesn't send it, so the order is kept.
+	if c.dryRun == nil {
+		c.orders.invalidate(id)
+	}
+
+	if err != nil {
+		return Order{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return Order{}, errors.New("failed to json.Unmarshal resp: " +
-			err.Error())
+		return Order{}, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return Order{}, errors.New("exchange error: " + err.Error())
+		return Order{}, exchangeError(err)
+	}
+
+	// Synthetic result of dry run doesn't tell the real order state.
+	if c.dryRun != nil {
+		return resp.Data.Order, nil
 	}
 
+	c.orders.put(resp.Data.Order)
+	c.orderStates.observe(resp.Data.Order)
+
 	return resp.Data.Order, nil
 }
 
 // createOrderRequestVariables is a query variables used in request
 // in client CreateOrder method.
 type createOrderRequestVariables struct {
-	Market string          `json:"market"`
-	Amount decimal.Decimal `json:"amount"`
-	Side   string          `json:"side"`
+	Market      string          `json:"market"`
+	Amount      decimal.Decimal `json:"amount"`
+	Side        OrderSide       `json:"side"`
+	TimeInForce TimeInForce     `json:"timeInForce,omitempty"`
 }
 
-func (c *Client) createOrder(market string, amount decimal.Decimal, side string) (Order, error) {
+// OrderSide is the side of the market order.
+type OrderSide string
+
+// Order sides.
+const (
+	// OrderAsk sells the right asset of the market for the left one.
+	OrderAsk OrderSide = "ask"
+
+	// OrderBid buys the right asset of the market using the left one.
+	OrderBid OrderSide = "bid"
+)
+
+func (c *Client) createOrder(market string, amount decimal.Decimal, side OrderSide) (Order, error) {
+	return c.PlaceOrder(OrderIntent{
+		Market: market,
+		Side:   side,
+		Amount: amount,
+	})
+}
+
+// PlaceOrder validates the intent, runs pre-trade checks and places the
+// order. Only market orders are supported by the exchange for now. Time
+// in force is passed to the server if it supports it, see
+// WithNativeTimeInForce, otherwise immediate-or-cancel is emulated by
+// canceling the remainder of the placed order and fill-or-kill is
+// rejected with ErrTimeInForceUnsupported.
+func (c *Client) PlaceOrder(intent OrderIntent) (Order, error) {
+	if err := intent.Validate(); err != nil {
+		return Order{}, errors.New("invalid order: " + err.Error())
+	}
+
+	if !intent.IsMarket() {
+		return Order{}, errors.New("limit orders are not supported")
+	}
+
+	intent, err := c.validateOrder(intent)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if !c.nativeTimeInForce && intent.TimeInForce == FillOrKill {
+		return Order{}, ErrTimeInForceUnsupported
+	}
+
+	if err := c.checkOrder(intent); err != nil {
+		return Order{}, err
+	}
+
+	order, err := c.createMarketOrder(intent)
+	if err != nil {
+		return order, err
+	}
+
+	if !c.nativeTimeInForce && intent.TimeInForce == ImmediateOrCancel {
+		return c.cancelRemainder(order)
+	}
 
+	return order, nil
+}
+
+// createMarketOrder sends the market order creation mutation.
+func (c *Client) createMarketOrder(intent OrderIntent) (Order, error) {
 	var req request
 
 	req.Query = `
@@ -404,12 +896,12 @@ func (c *Client) createOrder(market string, amount decimal.Decimal, side string)
 		}
 	`
 
-	req.Variables = createOrderRequestVariables{
-		Market: market,
-		Amount: amount,
-		Side:   side,
+	if c.nativeTimeInForce {
+		req.Query = createMarketOrderTIFQuery
 	}
 
+	req.Variables = c.orderVariables(intent)
+
 	resp := struct {
 		responseBase
 		Data struct {
@@ -419,17 +911,19 @@ func (c *Client) createOrder(market string, amount decimal.Decimal, side string)
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return Order{}, errors.New("failed to do request: " + err.
-			Error())
+		return Order{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return Order{}, errors.New("failed to json.Unmarshal resp: " +
-			err.Error())
+		return Order{}, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return Order{}, errors.New("exchange error: " + err.Error())
+		return Order{}, exchangeError(err)
+	}
+
+	if c.dryRun == nil {
+		c.orderStates.observe(resp.Data.Order)
 	}
 
 	return resp.Data.Order, nil
@@ -446,7 +940,7 @@ func (c *Client) CreateOrder(market string,
 // market BTCETH this method creates an order to sell ETH for BTC.
 func (c *Client) CreateOrderAsk(market string,
 	amount decimal.Decimal) (Order, error) {
-	return c.createOrder(market, amount, "ask")
+	return c.createOrder(market, amount, OrderAsk)
 }
 
 // CreateOrderBid creates bid order on market. Bid order means that
@@ -454,23 +948,34 @@ func (c *Client) CreateOrderAsk(market string,
 // E.g. in market BTCETH this method creates an order to buy ETH using BTC.
 func (c *Client) CreateOrderBid(market string,
 	amount decimal.Decimal) (Order, error) {
-	return c.createOrder(market, amount, "bid")
+	return c.createOrder(market, amount, OrderBid)
 
 }
 
+// CreateMarketOrder creates order of given side on market, it is
+// CreateOrderAsk or CreateOrderBid chosen by the side.
+func (c *Client) CreateMarketOrder(market string, side OrderSide,
+	amount decimal.Decimal) (Order, error) {
+
+	if side != OrderAsk && side != OrderBid {
+		return Order{}, errors.New("unknown order side: " + string(side))
+	}
+	return c.createOrder(market, amount, side)
+}
+
 // Withdrawal represents an account withdraw.
 type Withdrawal struct {
 	// PaymentID is system specific withdraw operation ID.
 	// In blockchain it is transaction ID, in lightning network
 	// it is payment hash.
-	PaymentID string
+	PaymentID string `json:"paymentID"`
 
 	// PaymentAddr is the address of the payment receiver in
 	// blockchain system. Meaningless in lightning network.
-	PaymentAddr string
+	PaymentAddr string `json:"paymentAddr"`
 
 	// Change is an amount on which balance has been changed.
-	Change decimal.Decimal
+	Change decimal.Decimal `json:"change"`
 }
 
 // withdrawRequestVariables is a query variables used in request
@@ -486,6 +991,15 @@ type withdrawRequestVariables struct {
 func (c *Client) Withdraw(asset string, amount decimal.Decimal,
 	address string) (Withdrawal, error) {
 
+	amount, err := c.validateWithdrawal(asset, amount)
+	if err != nil {
+		return Withdrawal{}, err
+	}
+
+	if err := c.checkWithdrawal(asset, amount); err != nil {
+		return Withdrawal{}, err
+	}
+
 	var req request
 
 	req.Query = `
@@ -505,7 +1019,7 @@ $address: String!) {
 	`
 
 	req.Variables = withdrawRequestVariables{
-		Asset:   asset,
+		Asset:   c.assets.asset(asset),
 		Amount:  amount,
 		Address: address,
 	}
@@ -519,18 +1033,23 @@ $address: String!) {
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return Withdrawal{},
-			errors.New("failed to do request: " + err.Error())
+		return Withdrawal{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return Withdrawal{},
-			errors.New("failed to json.Unmarshal resp: " + err.Error())
+		return Withdrawal{}, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
 		return Withdrawal{},
-			errors.New("exchange error: " + err.Error())
+			exchangeError(err)
+	}
+
+	// Synthetic withdrawal of dry run neither uses up the limits nor
+	// is logged.
+	if c.dryRun == nil {
+		c.trackWithdrawal(asset, amount)
+		c.withdrawalLog.record(resp.Data.Withdrawal)
 	}
 
 	return resp.Data.Withdrawal, nil
@@ -558,7 +1077,7 @@ func (c *Client) LightningNodeReachable(asset string,
 	`
 
 	req.Variables = reachableRequestVariables{
-		Asset:          asset,
+		Asset:          c.assets.asset(asset),
 		IdentityPubKey: identityPubKey,
 	}
 
@@ -571,18 +1090,16 @@ func (c *Client) LightningNodeReachable(asset string,
 
 	respJSON, err := c.do(false, req)
 	if err != nil {
-		return false,
-			errors.New("failed to do request: " + err.Error())
+		return false, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return false,
-			errors.New("failed to json.Unmarshal resp: " + err.Error())
+		return false, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
 		return false,
-			errors.New("exchange error: " + err.Error())
+			exchangeError(err)
 	}
 
 	return resp.Data.Reachable, nil
@@ -591,60 +1108,63 @@ func (c *Client) LightningNodeReachable(asset string,
 type Info struct {
 	// Network is a type of blockchain network which is configures on the
 	// server.
-	Network string
+	Network string `json:"network"`
 
 	// Time is the time on the server.
-	Time string
+	Time string `json:"time"`
 
 	// Lightning is the information about server lightning network node.
-	Lightning *LightningNodeInfo
+	Lightning *LightningNodeInfo `json:"lightning"`
+
+	// Source is the source which the info was received from.
+	Source DataSource `json:"-"`
 }
 
 // LightningNodeInfo is a lightning network node info.
 type LightningNodeInfo struct {
 	// Host is a lightning network daemon public host which is used for
 	// incoming peer connection.
-	Host string
+	Host string `json:"host"`
 
 	// Port is a lightning network daemon public port which is used for
 	// incoming peer connection.
-	Port string
+	Port string `json:"port"`
 
 	// MinAmount is a minimal amount payment supported by the lightning node.
-	MinAmount decimal.Decimal
+	MinAmount decimal.Decimal `json:"minAmount"`
 
 	// MaxAmount is a maximum amount payment supported by lightning node.
-	MaxAmount decimal.Decimal
+	MaxAmount decimal.Decimal `json:"maxAmount"`
 
 	// IdentityPubkey the identity pubkey of the zigzag lightning network node,
 	// which identifies it in the network.
-	IdentityPubkey string
+	IdentityPubkey string `json:"identityPubkey"`
 
 	// Alias is a public name, by which node could be found in the lightning network
 	// explorers.
-	Alias string
+	Alias string `json:"alias"`
 
 	// NumPendingChannels number of pending channels.
-	NumPendingChannels uint32
+	NumPendingChannels uint32 `json:"numPendingChannels"`
 
 	// NumActiveChannels is a number of active channels.
-	NumActiveChannels uint32
+	NumActiveChannels uint32 `json:"numActiveChannels"`
 
 	// NumPeers is number of peers which are connected to the node.
-	NumPeers uint32
+	NumPeers uint32 `json:"numPeers"`
 
 	// BlockHeight is the node's current view of the height of the best block.
-	BlockHeight uint32
+	BlockHeight uint32 `json:"blockHeight"`
 
 	// BlockHash is the node's current view of the hash of the best block.
-	BlockHash string
+	BlockHash string `json:"blockHash"`
 
 	// SyncedToChain denotes whether the lightning wallet is synced to the
 	// chain.
-	SyncedToChain bool
+	SyncedToChain bool `json:"syncedToChain"`
 
 	// Asset is a type of currency which lightning network is operating with.
-	Asset string
+	Asset string `json:"asset"`
 }
 
 // Info return the general information about service state,
@@ -685,18 +1205,28 @@ func (c *Client) Info() (*Info, error) {
 
 	respJSON, err := c.do(false, req)
 	if err != nil {
-		return &Info{},
-			errors.New("failed to do request: " + err.Error())
+		if c.fallback != nil {
+			info, ferr := c.fallback.info(c.context())
+			if ferr == nil {
+				return info, nil
+			}
+			err = errors.New(err.Error() + ", fallback: " + ferr.Error())
+		}
+		return &Info{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return &Info{},
-			errors.New("failed to json.Unmarshal resp: " + err.Error())
+		return &Info{}, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
 		return &Info{},
-			errors.New("exchange error: " + err.Error())
+			exchangeError(err)
+	}
+
+	if resp.Data.Info.Lightning != nil {
+		resp.Data.Info.Lightning.Asset = c.assets.localAsset(
+			resp.Data.Info.Lightning.Asset)
 	}
 
 	return &resp.Data.Info, nil
@@ -724,7 +1254,7 @@ $amount: String!) {
 	`
 
 	req.Variables = lightningCreateRequestVariables{
-		Asset:  asset,
+		Asset:  c.assets.asset(asset),
 		Amount: amount,
 	}
 
@@ -737,16 +1267,15 @@ $amount: String!) {
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return "", errors.New("failed to do request: " + err.Error())
+		return "", transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return "", errors.New("failed to json.Unmarshal resp: " +
-			err.Error())
+		return "", decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return "", errors.New("exchange error: " + err.Error())
+		return "", exchangeError(err)
 	}
 
 	return resp.Data.Invoice, nil
@@ -759,10 +1288,30 @@ type lightningWithdrawRequestError struct {
 	Invoice string `json:"invoice"`
 }
 
+// LightningWithdrawal represents an account withdraw with lightning
+// network.
+type LightningWithdrawal struct {
+	Withdrawal
+
+	// Preimage is the hex encoded payment preimage, which is the proof
+	// of the payment as its hash is the payment hash.
+	Preimage string `json:"preimage"`
+
+	// Fee is the routing fee paid for the payment.
+	Fee decimal.Decimal `json:"fee"`
+
+	// Hops is the number of hops of the payment route.
+	Hops uint32 `json:"hops"`
+}
+
 // LightningWithdraw withdraws funds from exchange with lightning network
 // using specified invoice.
 func (c *Client) LightningWithdraw(asset string,
-	invoice string) (Withdrawal, error) {
+	invoice string) (LightningWithdrawal, error) {
+
+	if err := c.checkWithdrawal(asset, decimal.Zero); err != nil {
+		return LightningWithdrawal{}, err
+	}
 
 	var req request
 
@@ -773,37 +1322,45 @@ func (c *Client) LightningWithdraw(asset string,
     			invoice: $invoice) {
     				...on Withdrawal {
       					paymentID
+      					paymentAddr
+						change
+						preimage
+						fee
+						hops
     				}
   			}
 		}
 	`
 
 	req.Variables = lightningWithdrawRequestError{
-		Asset:   asset,
+		Asset:   c.assets.asset(asset),
 		Invoice: invoice,
 	}
 
 	resp := struct {
 		responseBase
 		Data struct {
-			Withdrawal Withdrawal `json:"withdrawWithLightning"`
+			Withdrawal LightningWithdrawal `json:"withdrawWithLightning"`
 		}
 	}{}
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return Withdrawal{},
-			errors.New("failed to do request: " + err.Error())
+		return LightningWithdrawal{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return Withdrawal{},
-			errors.New("failed to json.Unmarshal resp: " + err.Error())
+		return LightningWithdrawal{}, decodeError(err)
 	}
 
 	if err := resp.Error(); err != nil {
-		return Withdrawal{},
-			errors.New("exchange error: " + err.Error())
+		return LightningWithdrawal{},
+			exchangeError(err)
+	}
+
+	if c.dryRun == nil {
+		c.trackWithdrawal(asset, resp.Data.Withdrawal.Change)
+		c.withdrawalLog.record(resp.Data.Withdrawal.Withdrawal)
 	}
 
 	return resp.Data.Withdrawal, nil
@@ -818,64 +1375,60 @@ type accountsRequest struct {
 type Transaction struct {
 	// ConfirmationsLeft is the number of confirmations which has to
 	// happen, before funds will be enrolled.
-	ConfirmationsLeft int
+	ConfirmationsLeft int `json:"confirmationsLeft"`
 
 	// Confirmations is the number of confirmations already done.
-	Confirmations int
+	Confirmations int `json:"confirmations"`
 
 	// Address is the address on which funds are send.
-	Address string
+	Address string `json:"address"`
 
 	// Amount is amount of funds are send
-	Amount decimal.Decimal
+	Amount decimal.Decimal `json:"amount"`
 
 	// TxID is the transaction ID of operation in blockchain.
-	TxID string
+	TxID string `json:"txid"`
 }
 
 type PendingInfo struct {
 	// Amount is the funds which are awaiting to be confirmed in the
 	// blockchain and after that to be enrolled in the account.
-	Amount decimal.Decimal
+	Amount decimal.Decimal `json:"amount"`
 
 	// Transactions is the pending transactions which are waiting to be
 	// approved.
-	Transactions []Transaction
+	Transactions []Transaction `json:"transactions"`
 }
 
 // Account struct describes the current balance of the exchange user by
 // every asset he owns
 type Account struct {
 	// A name of asset. Currently: BTC, BCH, ETH, LTC, DASH
-	Asset string
+	Asset string `json:"asset"`
 
 	// Address on which funds has to be sent to be deposited on the
 	// account. If returned null than address has to be created first.
-	Address string
+	Address string `json:"address"`
 
 	// Available is the funds which can be used in trading.
-	Available decimal.Decimal
+	Available decimal.Decimal `json:"available"`
 
 	// Estimation is the estimated number of dollars which corresponds
 	// to this asset.
-	Estimation decimal.Decimal
+	Estimation decimal.Decimal `json:"estimation"`
 
 	// Freezed is the funds which currently occupied in trades.
-	Freezed decimal.Decimal
+	Freezed decimal.Decimal `json:"freezed"`
 
 	// Pending is the information which related to the blockchain, for
 	// example: how much funds are waiting to be approved, before to be
 	// enrolled in the account.
-	Pending PendingInfo
+	Pending PendingInfo `json:"pending"`
 }
 
-// Accounts shows balances for the assets owned by loggedin user
-// using specified invoice.
-func (c *Client) Accounts(assets []string) ([]Account, error) {
-
-	var req request
-
-	req.Query = `
+// accountsQuery is the query of accounts with their pending
+// transactions.
+const accountsQuery = `
 		query Accounts($assets: [Asset!]!) {
   			accounts( assets: $assets) {
 				asset
@@ -897,10 +1450,27 @@ func (c *Client) Accounts(assets []string) ([]Account, error) {
 		}
 	`
 
-	req.Variables = accountsRequest{
-		Assets: assets,
+// Accounts shows balances for the assets owned by loggedin user
+// using specified invoice. If the query exceeds the complexity budget,
+// pending transactions are fetched separately, see
+// WithComplexityBudget.
+func (c *Client) Accounts(assets []string) ([]Account, error) {
+	req := request{
+		Query: accountsQuery,
+		Variables: accountsRequest{
+			Assets: c.assets.assets(assets),
+		},
 	}
 
+	if c.complexity.exceeds(req) {
+		return c.splitAccounts(assets)
+	}
+
+	return c.accounts(req)
+}
+
+// accounts sends the accounts query.
+func (c *Client) accounts(req request) ([]Account, error) {
 	resp := struct {
 		responseBase
 		Data struct {
@@ -910,18 +1480,21 @@ func (c *Client) Accounts(assets []string) ([]Account, error) {
 
 	respJSON, err := c.do(true, req)
 	if err != nil {
-		return []Account{},
-			errors.New("failed to do request: " + err.Error())
+		return []Account{}, transportError(err)
 	}
 
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return []Account{},
-			errors.New("failed to json.Unmarshal resp: " + err.Error())
+		return []Account{}, decodeError(err)
+	}
+
+	for i := range resp.Data.Accounts {
+		resp.Data.Accounts[i].Asset = c.assets.localAsset(
+			resp.Data.Accounts[i].Asset)
 	}
 
 	if err := resp.Error(); err != nil {
 		return resp.Data.Accounts,
-			errors.New("exchange error: " + err.Error())
+			exchangeError(err)
 	}
 
 	return resp.Data.Accounts, nil
@@ -933,9 +1506,7 @@ func (c *Client) IssueApiToken() (string, error) {
 
 	var req request
 
-	req.Query = `
-		query { issueApiToken }
-	`
+	req.Query = issueApiTokenQuery
 
 	resp := struct {
 		responseBase
@@ -957,7 +1528,7 @@ func (c *Client) IssueApiToken() (string, error) {
 
 	if err := resp.Error(); err != nil {
 		return resp.Data.IssueApiToken,
-			errors.New("exchange error: " + err.Error())
+			exchangeError(err)
 	}
 
 	return resp.Data.IssueApiToken, nil
@@ -973,49 +1544,56 @@ type MarketsRequest struct {
 // MarketStatus represent the information about market the market by the given period of time.
 type MarketStatus struct {
 	// Market is a pair of assets to be exchanged with each other
-	Market string
+	Market string `json:"market"`
 
 	// Stock is a right pair of a market. It is an asset to be sold in case of ask
 	// order and to be bought if a bid order
-	Stock string
+	Stock string `json:"stock"`
 
 	// Money is a left pair of a market. It is an asset to be bought in case of ask
 	// order and to be sold if a bid order
-	Money string
+	Money string `json:"money"`
 
 	// The opening price is the price at which a stok first trades upon the opening
 	// of an exchange on a given period
-	Open decimal.Decimal
+	Open decimal.Decimal `json:"open"`
 
 	// The closing price is the final price at which a stok is traded on a given period
-	Close decimal.Decimal
+	Close decimal.Decimal `json:"close"`
 
 	// The high price is the highest price at which a stok was traded within a given period
-	High decimal.Decimal
+	High decimal.Decimal `json:"high"`
 
 	// The last price is the price at which a most recent order was executed upon the given period
-	Last decimal.Decimal
+	Last decimal.Decimal `json:"last"`
 
 	// The low price is the lowest price at which a stok was traded within a given period
-	Low decimal.Decimal
+	Low decimal.Decimal `json:"low"`
 
 	// Volume is the amount of stock traded during a given period of time. The volume is estimated in market money.
-	Volume decimal.Decimal
+	Volume decimal.Decimal `json:"volume"`
 
 	// ChangeLast is the differnce between Open and Last values measured in percents
-	ChangeLast decimal.Decimal
+	ChangeLast decimal.Decimal `json:"changeLast"`
 
 	// ChangeHigh is the differnce between Open and Last values measured in percents
-	ChangeHigh decimal.Decimal
+	ChangeHigh decimal.Decimal `json:"changeHigh"`
 
 	// ChangeLow is the differnce between Open and Last values measured in percents
-	ChangeLow decimal.Decimal
+	ChangeLow decimal.Decimal `json:"changeLow"`
 
 	// BestAsk is the lowest price the stock may be bought right now
-	BestAsk decimal.Decimal
+	BestAsk decimal.Decimal `json:"bestAsk"`
 
 	// BestBid is the highes price the stock may be sold right now
-	BestBid decimal.Decimal
+	BestBid decimal.Decimal `json:"bestBid"`
+
+	// Source is the source which the status was received from.
+	Source DataSource `json:"-"`
+
+	// Anomalies describes failed sanity checks if market data
+	// validation is enabled.
+	Anomalies []string `json:"-"`
 }
 
 // Markets reporst the statuses (see MarketStatus) of the markets for the given period
@@ -1045,14 +1623,22 @@ func (c *Client) Markets(markets []string, period int32) ([]MarketStatus, error)
 	`
 
 	req.Variables = MarketsRequest{
-		markets,
+		c.assets.markets(markets),
 		period,
 	}
 
 	respJSON, err := c.do(false, req)
 	if err != nil {
-		return []MarketStatus{},
-			errors.New("failed to do request: " + err.Error())
+		if c.fallback != nil {
+			statuses, ferr := c.fallback.markets(c.context(),
+				c.assets.markets(markets), period)
+			if ferr == nil {
+				c.assets.localMarketStatuses(statuses)
+				return statuses, c.validator.marketStatuses(statuses)
+			}
+			err = errors.New(err.Error() + ", fallback: " + ferr.Error())
+		}
+		return []MarketStatus{}, transportError(err)
 	}
 
 	resp := struct {
@@ -1062,13 +1648,18 @@ func (c *Client) Markets(markets []string, period int32) ([]MarketStatus, error)
 		}
 	}{}
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return []MarketStatus{},
-			errors.New("failed to json.Unmarshal resp: " + err.Error())
+		return []MarketStatus{}, decodeError(err)
 	}
 
+	c.assets.localMarketStatuses(resp.Data.Markets)
+
 	if err := resp.Error(); err != nil {
 		return resp.Data.Markets,
-			errors.New("exchange error: " + err.Error())
+			exchangeError(err)
+	}
+
+	if err := c.validator.marketStatuses(resp.Data.Markets); err != nil {
+		return resp.Data.Markets, err
 	}
 
 	return resp.Data.Markets, nil
@@ -1085,24 +1676,43 @@ type DealsRequest struct {
 // MarketDeal is a structure to hold result of the Deal query
 type MarketDeal struct {
 	// ID of a deal
-	ID int32
+	ID int32 `json:"id"`
 
 	// Market the deal was closed on
-	Market string
+	Market string `json:"market"`
 
 	// A time of a deal
-	Time float32
+	Time float32 `json:"time"`
 
 	// Total amount of money spent to close the deal
-	Amount decimal.Decimal
+	Amount decimal.Decimal `json:"amount"`
 
 	// A price of stocks used to close the deal
-	Price decimal.Decimal
+	Price decimal.Decimal `json:"price"`
 
-	// Type is may be "ask" or "bid"
-	Type string
+	// Type is the side of the taker order which closed the deal.
+	Type DealType `json:"type"`
 }
 
+// DealType is the side of the order which closed the deal.
+type DealType string
+
+// Deal types.
+const (
+	DealAsk DealType = "ask"
+	DealBid DealType = "bid"
+)
+
+// DealRole tells whether the order was resting in the book (maker) or
+// matched the resting order (taker) in the deal.
+type DealRole string
+
+// Deal roles.
+const (
+	DealMaker DealRole = "maker"
+	DealTaker DealRole = "taker"
+)
+
 // Deals returns the result of orders matching with other users's orders. When users opposite orders have the same ask and bid prices their orderders considired to be appropriate for matching , the result of this matching is called deal.
 func (c *Client) Deals(markets []string, limit int32) ([]MarketDeal, error) {
 	var req request
@@ -1121,14 +1731,13 @@ func (c *Client) Deals(markets []string, limit int32) ([]MarketDeal, error) {
 	`
 
 	req.Variables = DealsRequest{
-		markets,
+		c.assets.markets(markets),
 		limit,
 	}
 
 	respJSON, err := c.do(false, req)
 	if err != nil {
-		return []MarketDeal{},
-			errors.New("failed to do request: " + err.Error())
+		return []MarketDeal{}, transportError(err)
 	}
 
 	resp := struct {
@@ -1138,14 +1747,241 @@ func (c *Client) Deals(markets []string, limit int32) ([]MarketDeal, error) {
 		}
 	}{}
 	if err := json.Unmarshal(respJSON, &resp); err != nil {
-		return []MarketDeal{},
-			errors.New("failed to json.Unmarshal resp: " + err.Error())
+		return []MarketDeal{}, decodeError(err)
+	}
+
+	for i := range resp.Data.Deals {
+		resp.Data.Deals[i].Market = c.assets.localMarket(
+			resp.Data.Deals[i].Market)
 	}
 
 	if err := resp.Error(); err != nil {
 		return resp.Data.Deals,
-			errors.New("exchange error: " + err.Error())
+			exchangeError(err)
 	}
 
 	return resp.Data.Deals, nil
 }
+
+// MyDeal is the deal in which the user order took part.
+type MyDeal struct {
+	// ID of a deal
+	ID int64 `json:"id"`
+
+	// OrderID is the ID of the user order which took part in the deal.
+	OrderID int64 `json:"orderID"`
+
+	// Market the deal was closed on
+	Market string `json:"market"`
+
+	// A time of a deal
+	Time float64 `json:"time"`
+
+	// Amount of stock traded in the deal
+	Amount decimal.Decimal `json:"amount"`
+
+	// A price of stocks used to close the deal
+	Price decimal.Decimal `json:"price"`
+
+	// Type is the side of the user order.
+	Type DealType `json:"type"`
+
+	// Role tells whether the user order was maker or taker.
+	Role DealRole `json:"role"`
+
+	// Fee is the fee charged for the user side of the deal.
+	Fee decimal.Decimal `json:"fee"`
+
+	// FeeAsset is the asset the fee is charged in.
+	FeeAsset string `json:"feeAsset"`
+}
+
+// myDealsRequestVariables is a query variables used in request
+// in client MyDeals method.
+type myDealsRequestVariables struct {
+	Market string `json:"market"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+// MyDeals returns deals of the user orders on the market in given
+// offset and limit, newest first.
+func (c *Client) MyDeals(market string, offset, limit int64) ([]MyDeal,
+	error) {
+
+	var req request
+
+	req.Query = `
+		query MyDeals($market: Market!, $offset: Int, $limit: Int) {
+			myDeals(market: $market, offset: $offset, limit: $limit) {
+				id
+				orderID
+				market
+				time
+				amount
+				price
+				type
+				role
+				fee
+				feeAsset
+			}
+		}
+	`
+
+	req.Variables = myDealsRequestVariables{
+		Market: c.assets.market(market),
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Deals []MyDeal `json:"myDeals"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	for i := range resp.Data.Deals {
+		d := &resp.Data.Deals[i]
+		d.Market = c.assets.localMarket(d.Market)
+		d.FeeAsset = c.assets.localAsset(d.FeeAsset)
+	}
+
+	return resp.Data.Deals, nil
+}
+
+// notificationsRequestVariables is a query variables used in request
+// in client Notifications method.
+type notificationsRequestVariables struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+// Notification is an exchange message addressed to the user, e.g.
+// maintenance notice, deposit credited or withdrawal completed.
+type Notification struct {
+	// ID is exchange specific notification ID.
+	ID string `json:"id"`
+
+	// Type is a kind of notification, e.g. "maintenance", "deposit" or
+	// "withdrawal".
+	Type string `json:"type"`
+
+	// Title is a short summary of the notification.
+	Title string `json:"title"`
+
+	// Message is a text of the notification.
+	Message string `json:"message"`
+
+	// Time when notification was created.
+	Time float64 `json:"time"`
+
+	// Read is true if notification has been marked as read.
+	Read bool `json:"read"`
+}
+
+// Notifications returns user notifications inbox in given offset and
+// limit, newest first.
+func (c *Client) Notifications(offset, limit int64) ([]Notification, error) {
+
+	var req request
+
+	req.Query = `
+		query Notifications($offset: Int!, $limit: Int!) {
+			notifications(offset: $offset, limit: $limit) {
+				id
+				type
+				title
+				message
+				time
+				read
+			}
+		}
+	`
+
+	req.Variables = notificationsRequestVariables{
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Notifications []Notification `json:"notifications"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	return resp.Data.Notifications, nil
+}
+
+// markNotificationReadRequestVariables is a query variables used in
+// request in client MarkNotificationRead method.
+type markNotificationReadRequestVariables struct {
+	ID string `json:"id"`
+}
+
+// MarkNotificationRead marks notification with specified id as read.
+func (c *Client) MarkNotificationRead(id string) error {
+
+	var req request
+
+	req.Query = `
+		mutation MarkNotificationRead($id: String!) {
+			markNotificationRead(id: $id)
+		}
+	`
+
+	req.Variables = markNotificationReadRequestVariables{id}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Marked bool `json:"markNotificationRead"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return exchangeError(err)
+	}
+
+	if !resp.Data.Marked {
+		return errors.New("notification " + id + " is not marked as read")
+	}
+
+	return nil
+}
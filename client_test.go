@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -36,6 +37,15 @@ func TestNewExchange(t *testing.T) {
 	}
 }
 
+// supportedMarketsJSON is the response of supported markets query.
+const supportedMarketsJSON = `{"data": {"supportedMarkets": [
+	{"market": "BTCETH", "stock": "ETH", "money": "BTC",
+		"pricePrecision": 8, "amountPrecision": 4, "minAmount": "0.01"},
+	{"market": "BTCBCH", "stock": "BCH", "money": "BTC"},
+	{"market": "BTCDASH", "stock": "DASH", "money": "BTC"},
+	{"market": "BTCLTC", "stock": "LTC", "money": "BTC"}
+]}}`
+
 func TestClient_Markets(t *testing.T) {
 	want := []string{
 		"BTCETH",
@@ -43,10 +53,24 @@ func TestClient_Markets(t *testing.T) {
 		"BTCDASH",
 		"BTCLTC",
 	}
-	got := (&Client{}).SupportedMarkets()
+	backend := &mockCore{respJSON: supportedMarketsJSON}
+	markets, err := (&Client{core: backend}).SupportedMarkets(
+		context.Background())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	var got []string
+	for _, m := range markets {
+		got = append(got, m.Market)
+	}
 	if !reflect.DeepEqual(want, got) {
 		t.Errorf("want `%v` markets but got `%v`", want, got)
 	}
+	if markets[0].Stock != "ETH" || markets[0].PricePrecision != 8 ||
+		markets[0].AmountPrecision != 4 ||
+		!markets[0].MinAmount.Equal(dec(0.01)) {
+		t.Errorf("want BTCETH metadata but got `%v`", markets[0])
+	}
 }
 
 func TestClient_UserID(t *testing.T) {
@@ -564,6 +588,36 @@ func TestClient_CreateOrder(t *testing.T) {
 	})
 }
 
+func TestClient_CreateMarketOrder(t *testing.T) {
+	for _, side := range []OrderSide{OrderAsk, OrderBid} {
+		backend := &mockCore{
+			respJSON: `{ "data": { "createMarketOrder": { "id": 1 } } }`,
+		}
+		client := &Client{core: backend}
+		if _, err := client.CreateMarketOrder("BTCETH", side,
+			dec(0.5)); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		got := backend.request.Variables.(createOrderRequestVariables)
+		if got.Side != side {
+			t.Errorf("want side `%s` but got `%s`", side, got.Side)
+		}
+	}
+
+	t.Run("unknown side", func(t *testing.T) {
+		backend := &mockCore{}
+		client := &Client{core: backend}
+		_, err := client.CreateMarketOrder("BTCETH", "buy", dec(0.5))
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if backend.request.Query != "" {
+			t.Error("want no request with unknown side")
+		}
+	})
+}
+
 func TestClient_Withdraw(t *testing.T) {
 	wantAsset := "ETH"
 	wantAmount := dec(10)
@@ -982,13 +1036,23 @@ func TestClient_LightningWithdraw(t *testing.T) {
 		checkRequest(t, backend.request)
 	})
 	t.Run("when valid response without errors", func(t *testing.T) {
-		wantWithdrawal := Withdrawal{
-			PaymentID: "some-id",
+		wantWithdrawal := LightningWithdrawal{
+			Withdrawal: Withdrawal{
+				PaymentID: "some-id",
+				Change:    dec(0.01),
+			},
+			Preimage: "0001020304050607080900010203040506070809000102030405060708090102",
+			Fee:      decimal.RequireFromString("0.00000002"),
+			Hops:     3,
 		}
 		backend := &mockCore{
 			respJSON: `
 				{ "data": { "withdrawWithLightning": {
-					"paymentID": "some-id"
+					"paymentID": "some-id",
+					"change": "0.01",
+					"preimage": "0001020304050607080900010203040506070809000102030405060708090102",
+					"fee": "0.00000002",
+					"hops": 3
 				} } }
 			`,
 		}
@@ -1145,6 +1209,418 @@ func TestClient_Accounts_succeed(t *testing.T) {
 	}
 }
 
+func TestClient_Notifications(t *testing.T) {
+	wantOffset := int64(10)
+	wantLimit := int64(20)
+	checkRequest := func(t *testing.T, got request) {
+		wantVariables := notificationsRequestVariables{
+			Offset: wantOffset,
+			Limit:  wantLimit,
+		}
+		if !reflect.DeepEqual(wantVariables, got.Variables) {
+			t.Errorf("want variables `%#v` but got `%#v`",
+				wantVariables, got.Variables)
+		}
+	}
+	t.Run("when core error", func(t *testing.T) {
+		backend := &mockCore{
+			error: errors.New("fail"),
+		}
+		client := &Client{core: backend}
+		_, err := client.Notifications(wantOffset, wantLimit)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to do request") {
+			t.Fatalf("want do request error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when invalid response json", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": 123, "data": "qwerty" }
+			`,
+		}
+		client := &Client{core: backend}
+		_, err := client.Notifications(wantOffset, wantLimit)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to json.Unmarshal") {
+			t.Fatalf("want json.Unmarshal error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when exchange error", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": [{ "message": "some error" }] }
+			`,
+		}
+		client := &Client{core: backend}
+		_, err := client.Notifications(wantOffset, wantLimit)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "exchange error") {
+			t.Fatalf("want exchange error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when valid response without errors", func(t *testing.T) {
+		wantNotifications := []Notification{{
+			ID:      "some-id",
+			Type:    "deposit",
+			Title:   "Deposit credited",
+			Message: "0.1 BTC credited",
+			Time:    123,
+			Read:    true,
+		}}
+		backend := &mockCore{
+			respJSON: `
+				{ "data": { "notifications": [{
+					"id": "some-id",
+					"type": "deposit",
+					"title": "Deposit credited",
+					"message": "0.1 BTC credited",
+					"time": 123,
+					"read": true
+				}] } }
+			`,
+		}
+		client := &Client{core: backend}
+		gotNotifications, err := client.Notifications(wantOffset, wantLimit)
+		if err != nil {
+			t.Fatalf("want no error but got `%s", err.Error())
+		}
+		if !reflect.DeepEqual(wantNotifications, gotNotifications) {
+			t.Errorf("want notifications `%#v` but got `%#v`",
+				wantNotifications, gotNotifications)
+		}
+		checkRequest(t, backend.request)
+	})
+}
+
+func TestClient_MarkNotificationRead(t *testing.T) {
+	wantID := "some-id"
+	checkRequest := func(t *testing.T, got request) {
+		wantVariables := markNotificationReadRequestVariables{wantID}
+		if !reflect.DeepEqual(wantVariables, got.Variables) {
+			t.Errorf("want variables `%#v` but got `%#v`",
+				wantVariables, got.Variables)
+		}
+	}
+	t.Run("when core error", func(t *testing.T) {
+		backend := &mockCore{
+			error: errors.New("fail"),
+		}
+		client := &Client{core: backend}
+		err := client.MarkNotificationRead(wantID)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to do request") {
+			t.Fatalf("want do request error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when exchange error", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": [{ "message": "some error" }] }
+			`,
+		}
+		client := &Client{core: backend}
+		err := client.MarkNotificationRead(wantID)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "exchange error") {
+			t.Fatalf("want exchange error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when not marked", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "data": { "markNotificationRead": false } }
+			`,
+		}
+		client := &Client{core: backend}
+		if err := client.MarkNotificationRead(wantID); err == nil {
+			t.Fatal("want error but got no error")
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when valid response without errors", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "data": { "markNotificationRead": true } }
+			`,
+		}
+		client := &Client{core: backend}
+		if err := client.MarkNotificationRead(wantID); err != nil {
+			t.Fatalf("want no error but got `%s", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+}
+
+func TestClient_CancelOrder(t *testing.T) {
+	wantID := int64(123)
+	checkRequest := func(t *testing.T, got request) {
+		wantVariables := cancelOrderRequestVariables{wantID}
+		if !reflect.DeepEqual(wantVariables, got.Variables) {
+			t.Errorf("want variables `%#v` but got `%#v`",
+				wantVariables, got.Variables)
+		}
+	}
+	t.Run("when core error", func(t *testing.T) {
+		backend := &mockCore{
+			error: errors.New("fail"),
+		}
+		client := &Client{core: backend}
+		_, err := client.CancelOrder(wantID)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to do request") {
+			t.Fatalf("want do request error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when invalid response json", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": 123, "data": "qwerty" }
+			`,
+		}
+		client := &Client{core: backend}
+		_, err := client.CancelOrder(wantID)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to json.Unmarshal") {
+			t.Fatalf("want json.Unmarshal error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when exchange error", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": [{ "message": "some error" }] }
+			`,
+		}
+		client := &Client{core: backend}
+		_, err := client.CancelOrder(wantID)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "exchange error") {
+			t.Fatalf("want exchange error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when valid response without errors", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "data": { "cancelOrder": {
+					"id": 123,
+					"status": "canceled",
+					"amount": "0.1",
+					"price": "0.2",
+					"dealMoney": "0",
+					"dealStock": "0",
+					"left": "0.1"
+				} } }
+			`,
+		}
+		client := &Client{core: backend}
+		order, err := client.CancelOrder(wantID)
+		if err != nil {
+			t.Fatalf("want no error but got `%s`", err.Error())
+		}
+		if order.ID != wantID || order.Status != OrderCanceled {
+			t.Errorf("want canceled order %v but got `%#v`", wantID,
+				order)
+		}
+		checkRequest(t, backend.request)
+	})
+}
+
+func TestClient_Withdrawals(t *testing.T) {
+	checkRequest := func(t *testing.T, got request) {
+		wantVariables := withdrawalsRequestVariables{
+			Assets: []string{"BTC"},
+			Offset: 1,
+			Limit:  2,
+		}
+		if !reflect.DeepEqual(wantVariables, got.Variables) {
+			t.Errorf("want variables `%#v` but got `%#v`",
+				wantVariables, got.Variables)
+		}
+	}
+	t.Run("when core error", func(t *testing.T) {
+		backend := &mockCore{
+			error: errors.New("fail"),
+		}
+		client := &Client{core: backend}
+		_, err := client.Withdrawals("BTC", 1, 2)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to do request") {
+			t.Fatalf("want do request error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when invalid response json", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": 123, "data": "qwerty" }
+			`,
+		}
+		client := &Client{core: backend}
+		_, err := client.Withdrawals("BTC", 1, 2)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to json.Unmarshal") {
+			t.Fatalf("want json.Unmarshal error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when exchange error", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": [{ "message": "some error" }] }
+			`,
+		}
+		client := &Client{core: backend}
+		_, err := client.Withdrawals("BTC", 1, 2)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "exchange error") {
+			t.Fatalf("want exchange error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when valid response without errors", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "data": { "balanceUpdateRecords": [{
+					"paymentID": "tx1",
+					"paymentAddr": "addr1",
+					"change": "-0.1",
+					"time": 1.5
+				}] } }
+			`,
+		}
+		client := &Client{core: backend}
+		withdrawals, err := client.Withdrawals("BTC", 1, 2)
+		if err != nil {
+			t.Fatalf("want no error but got `%s`", err.Error())
+		}
+		want := []WithdrawalRecord{{
+			PaymentID:   "tx1",
+			PaymentAddr: "addr1",
+			Change:      dec(-0.1),
+			Time:        1.5,
+		}}
+		if diff := pretty.Diff(want, withdrawals); len(diff) != 0 {
+			t.Errorf("want withdrawals `%#v` but got `%#v`, diff: %v",
+				want, withdrawals, diff)
+		}
+		checkRequest(t, backend.request)
+	})
+}
+
+func TestClient_MyDeals(t *testing.T) {
+	checkRequest := func(t *testing.T, got request) {
+		wantVariables := myDealsRequestVariables{
+			Market: "BTCETH",
+			Offset: 1,
+			Limit:  2,
+		}
+		if !reflect.DeepEqual(wantVariables, got.Variables) {
+			t.Errorf("want variables `%#v` but got `%#v`",
+				wantVariables, got.Variables)
+		}
+	}
+	t.Run("when core error", func(t *testing.T) {
+		backend := &mockCore{
+			error: errors.New("fail"),
+		}
+		client := &Client{core: backend}
+		_, err := client.MyDeals("BTCETH", 1, 2)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to do request") {
+			t.Fatalf("want do request error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when exchange error", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "errors": [{ "message": "some error" }] }
+			`,
+		}
+		client := &Client{core: backend}
+		_, err := client.MyDeals("BTCETH", 1, 2)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "exchange error") {
+			t.Fatalf("want exchange error but got `%s`", err.Error())
+		}
+		checkRequest(t, backend.request)
+	})
+	t.Run("when valid response without errors", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `
+				{ "data": { "myDeals": [{
+					"id": 10,
+					"orderID": 7,
+					"market": "BTCETH",
+					"time": 1.5,
+					"amount": "2",
+					"price": "0.03",
+					"type": "bid",
+					"role": "taker",
+					"fee": "0.004",
+					"feeAsset": "ETH"
+				}] } }
+			`,
+		}
+		client := &Client{core: backend}
+		deals, err := client.MyDeals("BTCETH", 1, 2)
+		if err != nil {
+			t.Fatalf("want no error but got `%s`", err.Error())
+		}
+		want := []MyDeal{{
+			ID:       10,
+			OrderID:  7,
+			Market:   "BTCETH",
+			Time:     1.5,
+			Amount:   dec(2),
+			Price:    dec(0.03),
+			Type:     DealBid,
+			Role:     DealTaker,
+			Fee:      dec(0.004),
+			FeeAsset: "ETH",
+		}}
+		if diff := pretty.Diff(want, deals); len(diff) != 0 {
+			t.Errorf("want deals `%#v` but got `%#v`, diff: %v",
+				want, deals, diff)
+		}
+		checkRequest(t, backend.request)
+	})
+}
+
 // mockCore is client core client mock implementation for testing
 // purpose
 type mockCore struct {
@@ -1160,7 +1636,9 @@ type mockCore struct {
 
 // do implements core. Stores request and returns predefined respJSON
 // and error.
-func (c *mockCore) do(needAuth bool, r request) ([]byte, error) {
+func (c *mockCore) do(ctx context.Context, needAuth bool,
+	r request) ([]byte, error) {
+
 	c.request = r
 	return []byte(c.respJSON), c.error
 }
@@ -0,0 +1,246 @@
+// Package fixtures provides builders of the exchange client domain
+// values for downstream unit tests, so they don't hand-craft JSON or
+// structs with decimal pitfalls. Amounts are given as strings and panic
+// if they can not be parsed, which is a programming error in tests.
+package fixtures
+
+import (
+	"github.com/bitlum/exchange-graphql-client"
+	"github.com/shopspring/decimal"
+)
+
+// Order statuses.
+const (
+	Pending  = client.OrderPending
+	Finished = client.OrderFinished
+	Canceled = client.OrderCanceled
+)
+
+// OrderBuilder builds client.Order.
+type OrderBuilder struct {
+	order client.Order
+}
+
+// NewOrder returns builder of pending order with ID 1 and zero amounts.
+func NewOrder() *OrderBuilder {
+	return &OrderBuilder{
+		order: client.Order{
+			ID:     1,
+			Status: Pending,
+		},
+	}
+}
+
+// WithID sets order ID.
+func (b *OrderBuilder) WithID(id int64) *OrderBuilder {
+	b.order.ID = id
+	return b
+}
+
+// WithStatus sets order status.
+func (b *OrderBuilder) WithStatus(status string) *OrderBuilder {
+	b.order.Status = status
+	return b
+}
+
+// WithAmount sets order amount.
+func (b *OrderBuilder) WithAmount(amount string) *OrderBuilder {
+	b.order.Amount = decimal.RequireFromString(amount)
+	return b
+}
+
+// WithPrice sets order price.
+func (b *OrderBuilder) WithPrice(price string) *OrderBuilder {
+	b.order.Price = decimal.RequireFromString(price)
+	return b
+}
+
+// WithDeal sets amounts of money and stock involved in the order.
+func (b *OrderBuilder) WithDeal(money, stock string) *OrderBuilder {
+	b.order.DealMoney = decimal.RequireFromString(money)
+	b.order.DealStock = decimal.RequireFromString(stock)
+	return b
+}
+
+// WithLeft sets amount left in the market without being handled.
+func (b *OrderBuilder) WithLeft(left string) *OrderBuilder {
+	b.order.Left = decimal.RequireFromString(left)
+	return b
+}
+
+// Build returns the order.
+func (b *OrderBuilder) Build() client.Order {
+	return b.order
+}
+
+// Level is a price level of the depth given as strings.
+type Level struct {
+	Price  string
+	Volume string
+}
+
+// DepthBuilder builds client.Depth.
+type DepthBuilder struct {
+	depth client.Depth
+}
+
+// NewDepth returns builder of empty depth.
+func NewDepth() *DepthBuilder {
+	return &DepthBuilder{}
+}
+
+// WithAsks appends asks to the depth.
+func (b *DepthBuilder) WithAsks(levels ...Level) *DepthBuilder {
+	for _, l := range levels {
+		b.depth.Asks = append(b.depth.Asks, client.Ask{
+			Price:  decimal.RequireFromString(l.Price),
+			Volume: decimal.RequireFromString(l.Volume),
+		})
+	}
+	return b
+}
+
+// WithBids appends bids to the depth.
+func (b *DepthBuilder) WithBids(levels ...Level) *DepthBuilder {
+	for _, l := range levels {
+		b.depth.Bids = append(b.depth.Bids, client.Bid{
+			Price:  decimal.RequireFromString(l.Price),
+			Volume: decimal.RequireFromString(l.Volume),
+		})
+	}
+	return b
+}
+
+// Build returns the depth.
+func (b *DepthBuilder) Build() client.Depth {
+	return b.depth
+}
+
+// DepositBuilder builds client.Deposit.
+type DepositBuilder struct {
+	deposit client.Deposit
+}
+
+// NewDeposit returns builder of blockchain deposit with zero change.
+func NewDeposit() *DepositBuilder {
+	return &DepositBuilder{
+		deposit: client.Deposit{
+			PaymentID:   "payment-id",
+			PaymentType: "blockchain",
+		},
+	}
+}
+
+// WithPaymentID sets deposit payment ID.
+func (b *DepositBuilder) WithPaymentID(id string) *DepositBuilder {
+	b.deposit.PaymentID = id
+	return b
+}
+
+// WithPaymentType sets deposit payment type, e.g. lightning.
+func (b *DepositBuilder) WithPaymentType(t string) *DepositBuilder {
+	b.deposit.PaymentType = t
+	return b
+}
+
+// WithChange sets amount on which balance has been changed.
+func (b *DepositBuilder) WithChange(change string) *DepositBuilder {
+	b.deposit.Change = decimal.RequireFromString(change)
+	return b
+}
+
+// WithTime sets time of the deposit.
+func (b *DepositBuilder) WithTime(t float64) *DepositBuilder {
+	b.deposit.Time = t
+	return b
+}
+
+// Build returns the deposit.
+func (b *DepositBuilder) Build() client.Deposit {
+	return b.deposit
+}
+
+// WithdrawalBuilder builds client.Withdrawal.
+type WithdrawalBuilder struct {
+	withdrawal client.Withdrawal
+}
+
+// NewWithdrawal returns builder of withdrawal with zero change.
+func NewWithdrawal() *WithdrawalBuilder {
+	return &WithdrawalBuilder{
+		withdrawal: client.Withdrawal{
+			PaymentID: "payment-id",
+		},
+	}
+}
+
+// WithPaymentID sets withdrawal payment ID.
+func (b *WithdrawalBuilder) WithPaymentID(id string) *WithdrawalBuilder {
+	b.withdrawal.PaymentID = id
+	return b
+}
+
+// WithPaymentAddr sets withdrawal receiver address.
+func (b *WithdrawalBuilder) WithPaymentAddr(addr string) *WithdrawalBuilder {
+	b.withdrawal.PaymentAddr = addr
+	return b
+}
+
+// WithChange sets amount on which balance has been changed.
+func (b *WithdrawalBuilder) WithChange(change string) *WithdrawalBuilder {
+	b.withdrawal.Change = decimal.RequireFromString(change)
+	return b
+}
+
+// Build returns the withdrawal.
+func (b *WithdrawalBuilder) Build() client.Withdrawal {
+	return b.withdrawal
+}
+
+// AccountBuilder builds client.Account.
+type AccountBuilder struct {
+	account client.Account
+}
+
+// NewAccount returns builder of the asset account with zero balances.
+func NewAccount(asset string) *AccountBuilder {
+	return &AccountBuilder{
+		account: client.Account{
+			Asset: asset,
+		},
+	}
+}
+
+// WithAddress sets deposit address of the account.
+func (b *AccountBuilder) WithAddress(addr string) *AccountBuilder {
+	b.account.Address = addr
+	return b
+}
+
+// WithAvailable sets funds available for trading.
+func (b *AccountBuilder) WithAvailable(available string) *AccountBuilder {
+	b.account.Available = decimal.RequireFromString(available)
+	return b
+}
+
+// WithFreezed sets funds occupied in trades.
+func (b *AccountBuilder) WithFreezed(freezed string) *AccountBuilder {
+	b.account.Freezed = decimal.RequireFromString(freezed)
+	return b
+}
+
+// WithPending sets funds awaiting blockchain confirmations.
+func (b *AccountBuilder) WithPending(amount string,
+	txs ...client.Transaction) *AccountBuilder {
+
+	b.account.Pending = client.PendingInfo{
+		Amount:       decimal.RequireFromString(amount),
+		Transactions: txs,
+	}
+	return b
+}
+
+// Build returns the account.
+func (b *AccountBuilder) Build() client.Account {
+	return b.account
+}
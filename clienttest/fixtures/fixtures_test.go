@@ -0,0 +1,57 @@
+package fixtures
+
+import (
+	"testing"
+)
+
+func TestNewOrder(t *testing.T) {
+	o := NewOrder().
+		WithID(123).
+		WithStatus(Finished).
+		WithAmount("0.5").
+		WithPrice("0.01").
+		WithDeal("0.005", "0.5").
+		WithLeft("0").
+		Build()
+
+	if o.ID != 123 || o.Status != Finished {
+		t.Errorf("want finished order 123 but got `%#v`", o)
+	}
+	if o.Amount.String() != "0.5" || o.DealMoney.String() != "0.005" {
+		t.Errorf("want amount 0.5 and deal money 0.005 but got %v and %v",
+			o.Amount, o.DealMoney)
+	}
+}
+
+func TestNewDepth(t *testing.T) {
+	d := NewDepth().
+		WithAsks(Level{"101", "1"}, Level{"102", "2"}).
+		WithBids(Level{"99", "3"}).
+		Build()
+
+	if len(d.Asks) != 2 || len(d.Bids) != 1 {
+		t.Fatalf("want 2 asks and 1 bid but got %v and %v",
+			len(d.Asks), len(d.Bids))
+	}
+	if d.Asks[1].Price.String() != "102" {
+		t.Errorf("want second ask price 102 but got %v", d.Asks[1].Price)
+	}
+}
+
+func TestNewAccount(t *testing.T) {
+	a := NewAccount("BTC").WithAvailable("1.5").WithPending("0.1").Build()
+	if a.Asset != "BTC" || a.Available.String() != "1.5" ||
+		a.Pending.Amount.String() != "0.1" {
+		t.Errorf("want BTC account with 1.5 available and 0.1 pending "+
+			"but got `%#v`", a)
+	}
+}
+
+func TestInvalidAmountPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic on invalid amount")
+		}
+	}()
+	NewOrder().WithAmount("1e")
+}
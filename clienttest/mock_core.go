@@ -0,0 +1,146 @@
+// Package clienttest provides test doubles of the exchange for unit
+// tests of code built on the exchange client: MockCore serves client
+// requests in-process, see client.WithDoer, and MockExchangeServer
+// serves them over HTTP.
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/bitlum/exchange-graphql-client"
+)
+
+// ErrNoResponse is returned by MockCore and MockExchangeServer if no
+// response is set for the request.
+var ErrNoResponse = errors.New("no response is set for the request")
+
+// response is the preset response to requests of the GraphQL field.
+type response struct {
+	body string
+	err  error
+}
+
+// responses is the preset responses keyed by GraphQL fields.
+type responses struct {
+	mtx      sync.Mutex
+	byField  map[string]response
+	fallback *response
+}
+
+// set sets the response to requests of the field, empty field means the
+// response to requests of unknown fields.
+func (r *responses) set(field string, resp response) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if field == "" {
+		r.fallback = &resp
+		return
+	}
+	if r.byField == nil {
+		r.byField = make(map[string]response)
+	}
+	r.byField[field] = resp
+}
+
+// match returns the response to the query, ErrNoResponse if none is
+// set. If the query selects several fields with responses, the longest
+// field name wins, so e.g. "accounts" and "accountsHistory" don't
+// clash.
+func (r *responses) match(query string) response {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	fields := make([]string, 0, len(r.byField))
+	for field := range r.byField {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return len(fields[i]) > len(fields[j])
+	})
+
+	for _, field := range fields {
+		if selects(query, field) {
+			return r.byField[field]
+		}
+	}
+	if r.fallback != nil {
+		return *r.fallback
+	}
+	return response{err: ErrNoResponse}
+}
+
+// selects returns true if the query selects the field.
+func selects(query, field string) bool {
+	re := regexp.MustCompile(`(^|[^_0-9A-Za-z])` + regexp.QuoteMeta(field) +
+		`\s*[({]`)
+	return re.MatchString(query)
+}
+
+// MockCore is client.Doer which responds to requests with responses
+// preset by the GraphQL field the request selects, e.g. "order" or
+// "createMarketOrder", and records the requests. It is safe for
+// concurrent use.
+//
+//	core := clienttest.NewMockCore().
+//		Respond("order", `{"data": {"order": {"id": 1}}}`)
+//	c, _ := client.NewClient("", "", "", client.WithDoer(core))
+type MockCore struct {
+	responses responses
+
+	mtx      sync.Mutex
+	requests []client.Request
+}
+
+// NewMockCore creates new mock core without responses.
+func NewMockCore() *MockCore {
+	return &MockCore{}
+}
+
+// Respond sets the JSON response body to requests of the field, empty
+// field means requests of fields without responses.
+func (m *MockCore) Respond(field, body string) *MockCore {
+	m.responses.set(field, response{body: body})
+	return m
+}
+
+// Fail makes requests of the field fail with the error, empty field
+// means requests of fields without responses.
+func (m *MockCore) Fail(field string, err error) *MockCore {
+	m.responses.set(field, response{err: err})
+	return m
+}
+
+// Do implements client.Doer.
+func (m *MockCore) Do(ctx context.Context, r client.Request) ([]byte,
+	error) {
+
+	m.mtx.Lock()
+	m.requests = append(m.requests, r)
+	m.mtx.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp := m.responses.match(r.Query)
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	return []byte(resp.body), nil
+}
+
+// Requests returns the requests received so far in the order of
+// arrival.
+func (m *MockCore) Requests() []client.Request {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	requests := make([]client.Request, len(m.requests))
+	copy(requests, m.requests)
+	return requests
+}
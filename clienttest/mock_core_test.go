@@ -0,0 +1,61 @@
+package clienttest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bitlum/exchange-graphql-client"
+)
+
+func TestMockCore(t *testing.T) {
+	core := NewMockCore().
+		Respond("order", `{"data": {"order": {"id": 1,
+			"status": "finished"}}}`).
+		Fail("cancelOrder", errors.New("connection refused"))
+
+	c, err := client.NewClient("", "", "", client.WithDoer(core))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	order, err := c.Order(1)
+	if err != nil || order.ID != 1 || order.Status != client.OrderFinished {
+		t.Errorf("want finished order 1 but got %v, `%v`", order, err)
+	}
+
+	if _, err := c.CancelOrder(1); err == nil {
+		t.Error("want cancel error")
+	}
+
+	if _, err := c.Me(); err == nil {
+		t.Error("want error of request without response")
+	}
+
+	requests := core.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("want 3 requests but got %v", len(requests))
+	}
+	if !requests[0].NeedAuth {
+		t.Error("want order request to be authorized")
+	}
+}
+
+func TestSelects(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+		want  bool
+	}{
+		{`query { order(id: 1) { id } }`, "order", true},
+		{`mutation { cancelOrder(id: 1) { id } }`, "order", false},
+		{`mutation { cancelOrder(id: 1) { id } }`, "cancelOrder", true},
+		{`query { me { id } }`, "me", true},
+		{`query { me { id } }`, "id", false},
+	}
+	for _, test := range tests {
+		if got := selects(test.query, test.field); got != test.want {
+			t.Errorf("want %v selects %v %v but got %v", test.query,
+				test.field, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package clienttest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// ServerRequest is the request received by MockExchangeServer.
+type ServerRequest struct {
+	// Header is the HTTP header of the request.
+	Header http.Header
+
+	// Query is the GraphQL query.
+	Query string
+
+	// Variables is the raw JSON of the query variables.
+	Variables json.RawMessage
+}
+
+// MockExchangeServer is the HTTP server which responds to GraphQL
+// requests with responses preset by the GraphQL field the request
+// selects, like MockCore, and records the requests. Requests without
+// responses fail with 500 status. It lets tests cover the HTTP layer of
+// the client, e.g. authorization headers, retries and timeouts.
+type MockExchangeServer struct {
+	server    *httptest.Server
+	responses responses
+
+	mtx      sync.Mutex
+	requests []ServerRequest
+}
+
+// NewMockExchangeServer starts new server without responses, it should
+// be closed with Close.
+func NewMockExchangeServer() *MockExchangeServer {
+	s := &MockExchangeServer{}
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+// URL returns the URL of the server to create the client with.
+func (s *MockExchangeServer) URL() string {
+	return s.server.URL
+}
+
+// Close stops the server.
+func (s *MockExchangeServer) Close() {
+	s.server.Close()
+}
+
+// Respond sets the JSON response body to requests of the field, empty
+// field means requests of fields without responses.
+func (s *MockExchangeServer) Respond(field, body string) *MockExchangeServer {
+	s.responses.set(field, response{body: body})
+	return s
+}
+
+// Requests returns the requests received so far in the order of
+// arrival.
+func (s *MockExchangeServer) Requests() []ServerRequest {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	requests := make([]ServerRequest, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// ServeHTTP implements http.Handler.
+func (s *MockExchangeServer) ServeHTTP(w http.ResponseWriter,
+	r *http.Request) {
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Query     string          `json:"query"`
+		Variables json.RawMessage `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mtx.Lock()
+	s.requests = append(s.requests, ServerRequest{
+		Header:    r.Header,
+		Query:     req.Query,
+		Variables: req.Variables,
+	})
+	s.mtx.Unlock()
+
+	resp := s.responses.match(req.Query)
+	if resp.err != nil {
+		http.Error(w, resp.err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(resp.body))
+}
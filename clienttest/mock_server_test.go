@@ -0,0 +1,37 @@
+package clienttest
+
+import (
+	"testing"
+
+	"github.com/bitlum/exchange-graphql-client"
+)
+
+func TestMockExchangeServer(t *testing.T) {
+	server := NewMockExchangeServer().
+		Respond("depth", `{"data": {"depth": {
+			"asks": [{"price": "2", "volume": "1"}]}}}`)
+	defer server.Close()
+
+	c, err := client.NewClient(server.URL(), "", "")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	depth, err := c.Depth("BTCETH", 10, 0)
+	if err != nil || len(depth.Asks) != 1 {
+		t.Errorf("want depth of one ask but got %v, `%v`", depth, err)
+	}
+
+	if _, err := c.Deals([]string{"BTCETH"}, 10); err == nil {
+		t.Error("want error of request without response")
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("want 2 requests but got %v", len(requests))
+	}
+	if string(requests[0].Variables) == "" ||
+		requests[0].Header.Get(client.ClientVersionHeader) == "" {
+		t.Errorf("want request recorded but got %v", requests[0])
+	}
+}
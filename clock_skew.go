@@ -0,0 +1,159 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/macaroon.v2"
+)
+
+// TimeCaveatFunc adds time caveat with the given time to the macaroon.
+// The macaroon should not be modified, modified copy is returned.
+type TimeCaveatFunc func(m *macaroon.Macaroon,
+	t time.Time) (*macaroon.Macaroon, error)
+
+// UnixTimeCaveat is the default TimeCaveatFunc which adds "time <unix
+// nanoseconds>" first party caveat, the format of auth.AddCurrentTime
+// the server checks caveats against.
+func UnixTimeCaveat(m *macaroon.Macaroon,
+	t time.Time) (*macaroon.Macaroon, error) {
+
+	m = m.Clone()
+	if err := m.AddFirstPartyCaveat([]byte(
+		fmt.Sprintf("time %d", t.UnixNano()))); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ClockSkewConfig is the configuration of the client clock skew
+// compensation used when constructing macaroon time caveats.
+type ClockSkewConfig struct {
+	// Offset is the initial server clock offset relative to local
+	// clock, i.e. server time minus local time.
+	Offset time.Duration
+
+	// Learn enables learning of the offset from the Date header of
+	// the server responses, the initial offset is used until the first
+	// response is received.
+	Learn bool
+
+	// Tolerance is subtracted from the caveat time, so the caveat is
+	// not considered to be in the future by the server because of
+	// offset inaccuracy, e.g. second resolution of the Date header.
+	Tolerance time.Duration
+
+	// WarnThreshold is the absolute offset above which OnDrift is
+	// invoked, zero disables warnings.
+	WarnThreshold time.Duration
+
+	// OnDrift is invoked with the learned offset if it exceeds
+	// WarnThreshold.
+	OnDrift func(offset time.Duration)
+
+	// TimeCaveat adds the time caveat to the macaroon, nil means
+	// UnixTimeCaveat. It should match the format of time caveats
+	// checked by the exchange.
+	TimeCaveat TimeCaveatFunc
+}
+
+// WithClockSkew makes the client construct macaroon time caveats using
+// local time corrected by the configured or learned server clock
+// offset, so requests are not rejected if local clock drifts. Learned
+// offset is reported as clock_skew_seconds metric.
+func WithClockSkew(cfg ClockSkewConfig) Option {
+	return func(o *options) {
+		o.clockSkew = &cfg
+	}
+}
+
+// skewClock is the clock corrected by the server clock offset.
+type skewClock struct {
+	cfg     ClockSkewConfig
+	metrics Metrics
+	now     func() time.Time
+
+	mtx    sync.Mutex
+	offset time.Duration
+}
+
+// newSkewClock creates new clock from configuration.
+func newSkewClock(cfg ClockSkewConfig, metrics Metrics) *skewClock {
+	if cfg.TimeCaveat == nil {
+		cfg.TimeCaveat = UnixTimeCaveat
+	}
+	return &skewClock{
+		cfg:     cfg,
+		metrics: metrics,
+		now:     time.Now,
+		offset:  cfg.Offset,
+	}
+}
+
+// Offset returns current server clock offset.
+func (c *skewClock) Offset() time.Duration {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.offset
+}
+
+// addTimeCaveat adds time caveat with corrected current time to the
+// macaroon, returning the time of the caveat along with the macaroon.
+func (c *skewClock) addTimeCaveat(m *macaroon.Macaroon) (*macaroon.Macaroon,
+	time.Time, error) {
+
+	t := c.now().Add(c.Offset()).Add(-c.cfg.Tolerance)
+	m, err := c.cfg.TimeCaveat(m, t)
+	return m, t, err
+}
+
+// observe learns the offset from the response Date header. As the
+// header has second resolution, offset is learned only if it differs
+// from the current one by more than a second, which also filters out
+// network latency.
+func (c *skewClock) observe(resp *http.Response, sent time.Time) {
+	if !c.cfg.Learn {
+		return
+	}
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	received := c.now()
+	local := sent.Add(received.Sub(sent) / 2)
+	observed := date.Sub(local)
+
+	c.mtx.Lock()
+	diff := observed - c.offset
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > time.Second {
+		c.offset = observed
+	}
+	offset := c.offset
+	c.mtx.Unlock()
+
+	c.metrics.Set("clock_skew_seconds", nil, offset.Seconds())
+
+	if offset < 0 {
+		offset = -offset
+	}
+	if c.cfg.WarnThreshold > 0 && offset > c.cfg.WarnThreshold &&
+		c.cfg.OnDrift != nil {
+		c.cfg.OnDrift(offset)
+	}
+}
+
+// ClockSkew returns the server clock offset used for macaroon time
+// caveats, zero if clock skew compensation is not enabled.
+func (c *Client) ClockSkew() time.Duration {
+	if c.clock == nil {
+		return 0
+	}
+	return c.clock.Offset()
+}
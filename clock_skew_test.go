@@ -0,0 +1,90 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"gopkg.in/macaroon.v2"
+)
+
+func TestSkewClock_observe(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	response := func(serverTime time.Time) *http.Response {
+		return &http.Response{Header: http.Header{
+			"Date": {serverTime.Format(http.TimeFormat)},
+		}}
+	}
+
+	var drift time.Duration
+	metrics := &recordingMetrics{}
+	clock := newSkewClock(ClockSkewConfig{
+		Learn:         true,
+		WarnThreshold: time.Minute,
+		OnDrift:       func(offset time.Duration) { drift = offset },
+	}, metrics)
+	clock.now = func() time.Time { return now }
+
+	clock.observe(response(now.Add(30*time.Second)), now)
+	if clock.Offset() != 30*time.Second {
+		t.Errorf("want 30s offset but got %v", clock.Offset())
+	}
+	if drift != 0 {
+		t.Errorf("want no drift warning but got %v", drift)
+	}
+	if metrics.count("clock_skew_seconds") != 1 {
+		t.Errorf("want clock skew metric to be reported")
+	}
+
+	// Sub-second difference is within Date header resolution.
+	clock.observe(response(now.Add(30*time.Second+500*time.Millisecond)),
+		now)
+	if clock.Offset() != 30*time.Second {
+		t.Errorf("want 30s offset but got %v", clock.Offset())
+	}
+
+	clock.observe(response(now.Add(-2*time.Minute)), now)
+	if clock.Offset() != -2*time.Minute {
+		t.Errorf("want -2m offset but got %v", clock.Offset())
+	}
+	if drift != 2*time.Minute {
+		t.Errorf("want 2m drift warning but got %v", drift)
+	}
+}
+
+func TestSkewClock_addTimeCaveat(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := newSkewClock(ClockSkewConfig{
+		Offset:    time.Minute,
+		Tolerance: 5 * time.Second,
+	}, nopMetrics{})
+	clock.now = func() time.Time { return now }
+
+	m, err := macaroon.New([]byte("root key"), []byte("id"), "",
+		macaroon.LatestVersion)
+	if err != nil {
+		t.Fatalf("failed to create macaroon: %v", err)
+	}
+
+	got, at, err := clock.addTimeCaveat(m)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(m.Caveats()) != 0 {
+		t.Errorf("want original macaroon to be intact")
+	}
+
+	caveats := got.Caveats()
+	if len(caveats) != 1 {
+		t.Fatalf("want 1 caveat but got %v", len(caveats))
+	}
+	want := "time " + strconv.FormatInt(
+		now.Add(55*time.Second).UnixNano(), 10)
+	if string(caveats[0].Id) != want {
+		t.Errorf("want caveat `%s` but got `%s`", want, caveats[0].Id)
+	}
+	if !at.Equal(now.Add(55 * time.Second)) {
+		t.Errorf("want caveat time returned but got %v", at)
+	}
+}
@@ -0,0 +1,73 @@
+// Command exchange-proxy exposes the exchange client API over JSON-RPC
+// 1.0 so non-Go services can reuse single authenticated client (nonce
+// handling, rate limiting) instead of reimplementing macaroon auth.
+//
+// Credentials are taken from EXCHANGE_MACAROON or EXCHANGE_JWT
+// environment variables to keep them out of the process list.
+//
+// Requests are required to carry the bearer token taken from
+// EXCHANGE_PROXY_TOKEN environment variable if it is set. Calls which
+// create orders and withdraw funds are rejected unless -mutations flag
+// is given, which requires the token.
+//
+// Usage:
+//
+//	EXCHANGE_MACAROON=0201... EXCHANGE_PROXY_TOKEN=secret exchange-proxy \
+//		-url https://exchange.bitlum.io/graphql -listen 127.0.0.1:9090
+//
+// Example request:
+//
+//	curl -H 'Authorization: Bearer secret' \
+//		-d '{"method":"Exchange.Markets","params":[{"markets":["BTCETH"]}],"id":1}' \
+//		http://127.0.0.1:9090/rpc
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"net/rpc"
+	"os"
+
+	"github.com/bitlum/exchange-graphql-client"
+)
+
+func main() {
+	var (
+		url    = flag.String("url", "", "exchange GraphQL endpoint URL")
+		listen = flag.String("listen", "127.0.0.1:9090",
+			"address to listen JSON-RPC requests on")
+		rps = flag.Float64("rps", 10,
+			"maximum number of exchange requests per second, "+
+				"zero disables the limit")
+		mutations = flag.Bool("mutations", false,
+			"enable calls which create orders and withdraw funds")
+	)
+	flag.Parse()
+
+	if *url == "" {
+		log.Fatal("exchange URL is not specified")
+	}
+
+	token := os.Getenv("EXCHANGE_PROXY_TOKEN")
+	if *mutations && token == "" {
+		log.Fatal("mutations require EXCHANGE_PROXY_TOKEN to be set")
+	}
+
+	c, err := client.NewClient(*url, os.Getenv("EXCHANGE_MACAROON"),
+		os.Getenv("EXCHANGE_JWT"))
+	if err != nil {
+		log.Fatalf("failed to create exchange client: %v", err)
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("Exchange",
+		newService(c, newLimiter(*rps), *mutations)); err != nil {
+		log.Fatalf("failed to register service: %v", err)
+	}
+
+	http.Handle("/rpc", rpcHandler(server, token))
+
+	log.Printf("listening JSON-RPC requests on http://%s/rpc", *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
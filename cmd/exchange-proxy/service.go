@@ -0,0 +1,257 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/bitlum/exchange-graphql-client"
+	"github.com/shopspring/decimal"
+)
+
+// service is the JSON-RPC service which proxies calls to the exchange
+// client. All calls go through the limiter to keep proxy clients
+// within exchange request limits.
+type service struct {
+	client  *client.Client
+	limiter *limiter
+
+	// mutations enables calls which create orders and withdraw funds.
+	mutations bool
+}
+
+// newService creates new service which proxies calls to the client,
+// calls which create orders and withdraw funds are rejected unless
+// mutations are enabled.
+func newService(c *client.Client, l *limiter, mutations bool) *service {
+	return &service{
+		client:    c,
+		limiter:   l,
+		mutations: mutations,
+	}
+}
+
+// MarketsArgs is arguments of Exchange.Markets call.
+type MarketsArgs struct {
+	Markets []string `json:"markets"`
+	Period  int32    `json:"period"`
+}
+
+// Markets proxies client Markets call.
+func (s *service) Markets(args MarketsArgs,
+	reply *[]client.MarketStatus) error {
+
+	s.limiter.wait()
+	res, err := s.client.Markets(args.Markets, args.Period)
+	*reply = res
+	return err
+}
+
+// DepthArgs is arguments of Exchange.Depth call.
+type DepthArgs struct {
+	Market   string  `json:"market"`
+	Limit    uint    `json:"limit"`
+	Interval float64 `json:"interval"`
+}
+
+// Depth proxies client Depth call.
+func (s *service) Depth(args DepthArgs, reply *client.Depth) error {
+	s.limiter.wait()
+	res, err := s.client.Depth(args.Market, args.Limit, args.Interval)
+	*reply = res
+	return err
+}
+
+// DealsArgs is arguments of Exchange.Deals call.
+type DealsArgs struct {
+	Markets []string `json:"markets"`
+	Limit   int32    `json:"limit"`
+}
+
+// Deals proxies client Deals call.
+func (s *service) Deals(args DealsArgs, reply *[]client.MarketDeal) error {
+	s.limiter.wait()
+	res, err := s.client.Deals(args.Markets, args.Limit)
+	*reply = res
+	return err
+}
+
+// InfoArgs is arguments of Exchange.Info call.
+type InfoArgs struct{}
+
+// Info proxies client Info call.
+func (s *service) Info(_ InfoArgs, reply *client.Info) error {
+	s.limiter.wait()
+	res, err := s.client.Info()
+	if res != nil {
+		*reply = *res
+	}
+	return err
+}
+
+// AccountsArgs is arguments of Exchange.Accounts call.
+type AccountsArgs struct {
+	Assets []string `json:"assets"`
+}
+
+// Accounts proxies client Accounts call.
+func (s *service) Accounts(args AccountsArgs, reply *[]client.Account) error {
+	s.limiter.wait()
+	res, err := s.client.Accounts(args.Assets)
+	*reply = res
+	return err
+}
+
+// DepositsArgs is arguments of Exchange.Deposits call.
+type DepositsArgs struct {
+	Asset  string `json:"asset"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+// Deposits proxies client Deposits call.
+func (s *service) Deposits(args DepositsArgs, reply *[]client.Deposit) error {
+	s.limiter.wait()
+	res, err := s.client.Deposits(args.Asset, args.Offset, args.Limit)
+	*reply = res
+	return err
+}
+
+// OrderArgs is arguments of Exchange.Order call.
+type OrderArgs struct {
+	ID int64 `json:"id"`
+}
+
+// Order proxies client Order call.
+func (s *service) Order(args OrderArgs, reply *client.Order) error {
+	s.limiter.wait()
+	res, err := s.client.Order(args.ID)
+	*reply = res
+	return err
+}
+
+// CreateOrderArgs is arguments of Exchange.CreateOrder call.
+type CreateOrderArgs struct {
+	Market string          `json:"market"`
+	Side   string          `json:"side"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// CreateOrder proxies client CreateOrderAsk or CreateOrderBid call
+// depending on side, which is either "ask" or "bid".
+func (s *service) CreateOrder(args CreateOrderArgs,
+	reply *client.Order) error {
+
+	if !s.mutations {
+		return errMutationsDisabled
+	}
+
+	var create func(string, decimal.Decimal) (client.Order, error)
+	switch args.Side {
+	case "ask":
+		create = s.client.CreateOrderAsk
+	case "bid":
+		create = s.client.CreateOrderBid
+	default:
+		return errUnknownSide
+	}
+
+	s.limiter.wait()
+	res, err := create(args.Market, args.Amount)
+	*reply = res
+	return err
+}
+
+// WithdrawArgs is arguments of Exchange.Withdraw call.
+type WithdrawArgs struct {
+	Asset   string          `json:"asset"`
+	Amount  decimal.Decimal `json:"amount"`
+	Address string          `json:"address"`
+}
+
+// Withdraw proxies client Withdraw call.
+func (s *service) Withdraw(args WithdrawArgs,
+	reply *client.Withdrawal) error {
+
+	if !s.mutations {
+		return errMutationsDisabled
+	}
+
+	s.limiter.wait()
+	res, err := s.client.Withdraw(args.Asset, args.Amount, args.Address)
+	*reply = res
+	return err
+}
+
+// errUnknownSide is returned if order side is neither ask nor bid.
+var errUnknownSide = errors.New("side should be either ask or bid")
+
+// errMutationsDisabled is returned on calls which create orders or
+// withdraw funds if mutations are not enabled.
+var errMutationsDisabled = errors.New("mutations are disabled")
+
+// limiter limits rate of the exchange requests.
+type limiter struct {
+	ticks <-chan time.Time
+}
+
+// newLimiter creates new limiter which allows rps requests per second,
+// zero rps disables the limit.
+func newLimiter(rps float64) *limiter {
+	if rps <= 0 {
+		return &limiter{}
+	}
+	return &limiter{
+		ticks: time.NewTicker(time.Duration(float64(time.Second) /
+			rps)).C,
+	}
+}
+
+// wait blocks until the next request is allowed.
+func (l *limiter) wait() {
+	if l.ticks != nil {
+		<-l.ticks
+	}
+}
+
+// rpcHandler returns http handler which serves single JSON-RPC request
+// per http POST request. Non-empty token is required as the bearer
+// token of requests.
+func rpcHandler(server *rpc.Server, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "method not allowed",
+				http.StatusMethodNotAllowed)
+			return
+		}
+
+		if token != "" && subtle.ConstantTimeCompare(
+			[]byte(r.Header.Get("Authorization")),
+			[]byte("Bearer "+token)) != 1 {
+
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		conn := &httpConn{in: r.Body, out: w}
+		if err := server.ServeRequest(jsonrpc.NewServerCodec(conn)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+}
+
+// httpConn adapts http request body and response writer to
+// io.ReadWriteCloser required by JSON-RPC codec.
+type httpConn struct {
+	in  io.Reader
+	out io.Writer
+}
+
+func (c *httpConn) Read(p []byte) (int, error)  { return c.in.Read(p) }
+func (c *httpConn) Write(p []byte) (int, error) { return c.out.Write(p) }
+func (c *httpConn) Close() error                { return nil }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"strings"
+	"testing"
+
+	"github.com/bitlum/exchange-graphql-client"
+)
+
+func TestRPCHandler(t *testing.T) {
+	exchange := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{ "data": { "markets": [
+				{ "market": "BTCETH", "last": "0.1" }
+			] } }`))
+		}))
+	defer exchange.Close()
+
+	c, err := client.NewClient(exchange.URL, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("Exchange",
+		newService(c, newLimiter(0), true)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	proxy := httptest.NewServer(rpcHandler(server, "secret"))
+	defer proxy.Close()
+
+	post := func(token, body string) (*http.Response, error) {
+		req, err := http.NewRequest("POST", proxy.URL,
+			strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return http.DefaultClient.Do(req)
+	}
+
+	t.Run("when valid call", func(t *testing.T) {
+		resp, err := post("secret", `{"method": "Exchange.Markets",
+				"params": [{"markets": ["BTCETH"]}], "id": 1}`)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		defer resp.Body.Close()
+
+		var reply struct {
+			ID     int
+			Result []client.MarketStatus
+			Error  interface{}
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+			t.Fatalf("failed to decode reply: %v", err)
+		}
+		if reply.Error != nil {
+			t.Fatalf("want no error but got `%v`", reply.Error)
+		}
+		if len(reply.Result) != 1 || reply.Result[0].Market != "BTCETH" {
+			t.Errorf("want BTCETH market status but got `%#v`",
+				reply.Result)
+		}
+	})
+	t.Run("when unknown order side", func(t *testing.T) {
+		resp, err := post("secret", `{"method": "Exchange.CreateOrder",
+				"params": [{"market": "BTCETH", "side": "up",
+				"amount": "1"}], "id": 2}`)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		defer resp.Body.Close()
+
+		var reply struct {
+			Error string
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+			t.Fatalf("failed to decode reply: %v", err)
+		}
+		if reply.Error != errUnknownSide.Error() {
+			t.Errorf("want `%s` error but got `%s`", errUnknownSide,
+				reply.Error)
+		}
+	})
+	t.Run("when token is wrong", func(t *testing.T) {
+		for _, token := range []string{"", "other"} {
+			resp, err := post(token, `{"method": "Exchange.Markets",
+				"params": [{"markets": ["BTCETH"]}], "id": 3}`)
+			if err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("want 401 status of token `%s` but got %v",
+					token, resp.StatusCode)
+			}
+		}
+	})
+	t.Run("when GET request", func(t *testing.T) {
+		resp, err := http.Get(proxy.URL)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("want 405 status but got %v", resp.StatusCode)
+		}
+	})
+}
+
+func TestService_mutationsDisabled(t *testing.T) {
+	s := newService(nil, newLimiter(0), false)
+
+	err := s.CreateOrder(CreateOrderArgs{Market: "BTCETH", Side: "ask"},
+		&client.Order{})
+	if err != errMutationsDisabled {
+		t.Errorf("want `%v` error but got `%v`", errMutationsDisabled, err)
+	}
+
+	err = s.Withdraw(WithdrawArgs{Asset: "BTC"}, &client.Withdrawal{})
+	if err != errMutationsDisabled {
+		t.Errorf("want `%v` error but got `%v`", errMutationsDisabled, err)
+	}
+}
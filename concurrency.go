@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// WithMaxConcurrent limits the number of authenticated and public
+// requests which are in flight at once, as the exchange rejects too
+// many concurrent authenticated calls of the user. Requests exceeding
+// the limit wait for a slot or until their context is done, the wait
+// time is reported as concurrency_wait_seconds metric labeled by kind,
+// either "auth" or "public". Non-positive limit disables the limit of
+// the kind.
+func WithMaxConcurrent(auth, public int) Option {
+	return func(o *options) {
+		o.maxConcurrentAuth = auth
+		o.maxConcurrentPublic = public
+	}
+}
+
+// concurrencyLimiter limits the number of requests in flight. Nil
+// limiter doesn't limit.
+type concurrencyLimiter struct {
+	// auth and public are the semaphores of authenticated and public
+	// requests, nil if requests of the kind are not limited.
+	auth   chan struct{}
+	public chan struct{}
+
+	metrics Metrics
+}
+
+// newConcurrencyLimiter creates new limiter, nil is returned if neither
+// kind of requests is limited.
+func newConcurrencyLimiter(auth, public int,
+	metrics Metrics) *concurrencyLimiter {
+
+	if auth <= 0 && public <= 0 {
+		return nil
+	}
+
+	l := &concurrencyLimiter{metrics: metrics}
+	if auth > 0 {
+		l.auth = make(chan struct{}, auth)
+	}
+	if public > 0 {
+		l.public = make(chan struct{}, public)
+	}
+	return l
+}
+
+// acquire blocks until the request may be sent and returns the function
+// which releases its slot. It returns the context error if the context
+// is done earlier.
+func (l *concurrencyLimiter) acquire(ctx context.Context,
+	needAuth bool) (func(), error) {
+
+	if l == nil {
+		return func() {}, nil
+	}
+
+	sem, kind := l.public, "public"
+	if needAuth {
+		sem, kind = l.auth, "auth"
+	}
+	if sem == nil {
+		return func() {}, nil
+	}
+
+	started := time.Now()
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	l.metrics.Observe("concurrency_wait_seconds", Labels{"kind": kind},
+		time.Since(started).Seconds())
+
+	return func() { <-sem }, nil
+}
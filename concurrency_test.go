@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter_acquire(t *testing.T) {
+	metrics := &recordingMetrics{}
+	l := newConcurrencyLimiter(1, 0, metrics)
+
+	release, err := l.acquire(context.Background(), true)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.acquire(ctx, true); err != context.DeadlineExceeded {
+		t.Errorf("want deadline exceeded but got `%v`", err)
+	}
+
+	// Public requests are not limited.
+	for i := 0; i < 3; i++ {
+		if _, err := l.acquire(ctx, false); err != nil {
+			t.Fatalf("want public request not to be limited but got `%v`",
+				err)
+		}
+	}
+
+	release()
+	if _, err := l.acquire(context.Background(), true); err != nil {
+		t.Errorf("want released slot acquired but got `%v`", err)
+	}
+
+	if n := metrics.count("concurrency_wait_seconds"); n != 2 {
+		t.Errorf("want 2 waits observed but got %v", n)
+	}
+
+	if newConcurrencyLimiter(0, -1, metrics) != nil {
+		t.Error("want nil limiter if nothing is limited")
+	}
+	var nilLimiter *concurrencyLimiter
+	if _, err := nilLimiter.acquire(ctx, true); err != nil {
+		t.Errorf("want nil limiter not to limit but got `%v`", err)
+	}
+}
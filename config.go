@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the bootstrap configuration of the client loaded from file,
+// so operational settings don't live in code of services using the
+// client. See LoadConfig for the file format.
+type Config struct {
+	// URL is the exchange GraphQL endpoint.
+	URL string `json:"url"`
+
+	// Credentials is the reference to the client credentials, which
+	// are never stored in the config file itself.
+	Credentials CredentialsConfig `json:"credentials"`
+
+	// Timeouts is the set of request timeouts.
+	Timeouts TimeoutsConfig `json:"timeouts"`
+
+	// RateLimit is the client side limit of requests.
+	RateLimit RateLimitConfig `json:"rateLimit"`
+
+	// Markets is the markets of interest of the service, e.g. markets
+	// to quote or watch. It is not used by the client itself.
+	Markets []string `json:"markets"`
+
+	// GuardRails is the set of client side risk limits.
+	GuardRails GuardRailsConfig `json:"guardRails"`
+}
+
+// CredentialsConfig references the client credentials either in
+// environment variables or in files. At most one of macaroon and JWT
+// may be given, no credentials means public requests only.
+type CredentialsConfig struct {
+	// MacaroonEnv is the environment variable with hex encoded
+	// macaroon.
+	MacaroonEnv string `json:"macaroonEnv"`
+
+	// MacaroonFile is the file with hex encoded macaroon.
+	MacaroonFile string `json:"macaroonFile"`
+
+	// JWTEnv is the environment variable with JWT.
+	JWTEnv string `json:"jwtEnv"`
+
+	// JWTFile is the file with JWT.
+	JWTFile string `json:"jwtFile"`
+}
+
+// TimeoutsConfig is the configuration of request timeouts, see
+// Timeouts. Durations are given as strings, e.g. "5s".
+type TimeoutsConfig struct {
+	Dial           Duration `json:"dial"`
+	TLSHandshake   Duration `json:"tlsHandshake"`
+	ResponseHeader Duration `json:"responseHeader"`
+	Total          Duration `json:"total"`
+}
+
+// RateLimitConfig is the configuration of the client side rate limit.
+type RateLimitConfig struct {
+	// RPS is the sustained number of requests per second, zero
+	// disables the limit.
+	RPS float64 `json:"rps"`
+
+	// Burst is the number of requests which may be sent at once.
+	Burst int `json:"burst"`
+}
+
+// GuardRailsConfig is the set of client side risk limits.
+type GuardRailsConfig struct {
+	// WithdrawalLimits is per asset daily withdrawal limits, see
+	// WithWithdrawalLimits.
+	WithdrawalLimits map[string]decimal.Decimal `json:"withdrawalLimits"`
+
+	// EnforceWithdrawalLimits rejects withdrawals exceeding limits.
+	EnforceWithdrawalLimits bool `json:"enforceWithdrawalLimits"`
+
+	// MaxOrderAmount is per market maximum amount of single order as
+	// given to CreateOrder, orders exceeding it are rejected by
+	// pre-trade check.
+	MaxOrderAmount map[string]decimal.Decimal `json:"maxOrderAmount"`
+
+	// MarketDataValidation enables market data sanity checks, see
+	// WithMarketDataValidation.
+	MarketDataValidation *MarketDataValidation `json:"marketDataValidation"`
+}
+
+// Duration is time.Duration which is given in config files as string,
+// e.g. "1m30s".
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("duration should be a string, e.g. \"5s\"")
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// LoadConfig loads and validates the config file, either YAML (.yaml or
+// .yml) or JSON (.json) one. Keys are named as json tags of Config
+// fields in both formats.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+	case ".yaml", ".yml":
+		// YAML is converted to JSON, so both formats share field
+		// names and decoding of decimals and durations.
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return cfg, errors.New("failed to yaml.Unmarshal config: " +
+				err.Error())
+		}
+
+		data, err = json.Marshal(yamlToJSON(doc))
+		if err != nil {
+			return cfg, errors.New("failed to json.Marshal config: " +
+				err.Error())
+		}
+	default:
+		return cfg, errors.New("unknown config format: " + path)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return cfg, errors.New("failed to decode config: " + err.Error())
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, errors.New("invalid config: " + err.Error())
+	}
+
+	return cfg, nil
+}
+
+// yamlToJSON converts YAML document to one which may be marshalled to
+// JSON, as YAML maps may have non-string keys.
+func yamlToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = yamlToJSON(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = yamlToJSON(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Validate checks the config consistency.
+func (c Config) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+		u.Host == "" {
+		return errors.New("url should be absolute http(s) URL")
+	}
+
+	creds := c.Credentials
+	if (creds.MacaroonEnv != "" || creds.MacaroonFile != "") &&
+		(creds.JWTEnv != "" || creds.JWTFile != "") {
+		return errors.New("either macaroon or JWT should be given")
+	}
+	if creds.MacaroonEnv != "" && creds.MacaroonFile != "" ||
+		creds.JWTEnv != "" && creds.JWTFile != "" {
+		return errors.New("credential should be given either in " +
+			"environment variable or in file")
+	}
+
+	t := c.Timeouts
+	if t.Dial < 0 || t.TLSHandshake < 0 || t.ResponseHeader < 0 ||
+		t.Total < 0 {
+		return errors.New("timeouts should not be negative")
+	}
+
+	if c.RateLimit.RPS < 0 || c.RateLimit.Burst < 0 {
+		return errors.New("rate limit should not be negative")
+	}
+
+	for _, m := range c.Markets {
+		if m == "" {
+			return errors.New("market should not be empty")
+		}
+	}
+
+	for asset, limit := range c.GuardRails.WithdrawalLimits {
+		if limit.Sign() <= 0 {
+			return errors.New("withdrawal limit of " + asset +
+				" should be positive")
+		}
+	}
+
+	for market, max := range c.GuardRails.MaxOrderAmount {
+		if max.Sign() <= 0 {
+			return errors.New("max order amount of " + market +
+				" should be positive")
+		}
+	}
+
+	return nil
+}
+
+// Options returns the client options of the config.
+func (c Config) Options() []Option {
+	opts := []Option{
+		WithTimeouts(Timeouts{
+			Dial:           time.Duration(c.Timeouts.Dial),
+			TLSHandshake:   time.Duration(c.Timeouts.TLSHandshake),
+			ResponseHeader: time.Duration(c.Timeouts.ResponseHeader),
+			Total:          time.Duration(c.Timeouts.Total),
+		}),
+	}
+
+	if c.RateLimit.RPS > 0 {
+		opts = append(opts, WithRateLimit(c.RateLimit.RPS,
+			c.RateLimit.Burst))
+	}
+
+	g := c.GuardRails
+	if len(g.WithdrawalLimits) > 0 {
+		opts = append(opts, WithWithdrawalLimits(g.WithdrawalLimits,
+			g.EnforceWithdrawalLimits))
+	}
+	if len(g.MaxOrderAmount) > 0 {
+		opts = append(opts, WithPreTradeCheck(
+			maxOrderAmountCheck(g.MaxOrderAmount)))
+	}
+	if g.MarketDataValidation != nil {
+		opts = append(opts, WithMarketDataValidation(
+			*g.MarketDataValidation))
+	}
+
+	return opts
+}
+
+// maxOrderAmountCheck returns pre-trade check rejecting orders which
+// amount exceeds per market maximum.
+func maxOrderAmountCheck(max map[string]decimal.Decimal) PreTradeCheck {
+	return PreTradeCheckFunc(func(_ context.Context,
+		intent OrderIntent) error {
+
+		if limit, ok := max[intent.Market]; ok &&
+			intent.Amount.GreaterThan(limit) {
+			return errors.New("order amount " + intent.Amount.String() +
+				" exceeds maximum " + limit.String() + " of " +
+				intent.Market)
+		}
+		return nil
+	})
+}
+
+// credentials reads macaroon and JWT referenced by the config.
+func (c CredentialsConfig) credentials() (string, string, error) {
+	read := func(env, file string) (string, error) {
+		if env != "" {
+			v := os.Getenv(env)
+			if v == "" {
+				return "", errors.New("environment variable " + env +
+					" is not set")
+			}
+			return v, nil
+		}
+		if file != "" {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(data)), nil
+		}
+		return "", nil
+	}
+
+	macaroon, err := read(c.MacaroonEnv, c.MacaroonFile)
+	if err != nil {
+		return "", "", errors.New("failed to read macaroon: " +
+			err.Error())
+	}
+
+	jwt, err := read(c.JWTEnv, c.JWTFile)
+	if err != nil {
+		return "", "", errors.New("failed to read JWT: " + err.Error())
+	}
+
+	return macaroon, jwt, nil
+}
+
+// FromConfigFile creates new client configured by the config file, see
+// LoadConfig. Given options are applied after the config ones, so they
+// take precedence.
+func FromConfigFile(path string, opts ...Option) (*Client, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	macaroon, jwt, err := cfg.Credentials.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(cfg.URL, macaroon, jwt,
+		append(cfg.Options(), opts...)...)
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// writeConfig writes config file with given name into temporary
+// directory and returns its path and function removing it.
+func writeConfig(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+const testYAMLConfig = `
+url: https://exchange.test/graphql
+credentials:
+  jwtEnv: TEST_EXCHANGE_JWT
+timeouts:
+  dial: 5s
+  total: 1m
+rateLimit:
+  rps: 2.5
+  burst: 5
+markets: [BTCETH, BTCDASH]
+guardRails:
+  withdrawalLimits:
+    BTC: 0.5
+  enforceWithdrawalLimits: true
+  maxOrderAmount:
+    BTCETH: "1"
+  marketDataValidation:
+    maxChangePercent: 100
+    reject: true
+`
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		path, remove := writeConfig(t, "client.yaml", testYAMLConfig)
+		defer remove()
+
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		if cfg.URL != "https://exchange.test/graphql" {
+			t.Errorf("want url but got `%s`", cfg.URL)
+		}
+		if cfg.Credentials.JWTEnv != "TEST_EXCHANGE_JWT" {
+			t.Errorf("want jwt env but got `%s`", cfg.Credentials.JWTEnv)
+		}
+		if time.Duration(cfg.Timeouts.Dial) != 5*time.Second ||
+			time.Duration(cfg.Timeouts.Total) != time.Minute {
+			t.Errorf("want timeouts 5s and 1m but got `%v`", cfg.Timeouts)
+		}
+		if cfg.RateLimit.RPS != 2.5 || cfg.RateLimit.Burst != 5 {
+			t.Errorf("want rate limit 2.5 rps but got `%v`", cfg.RateLimit)
+		}
+		if len(cfg.Markets) != 2 || cfg.Markets[1] != "BTCDASH" {
+			t.Errorf("want 2 markets but got `%v`", cfg.Markets)
+		}
+
+		g := cfg.GuardRails
+		if !g.WithdrawalLimits["BTC"].Equal(dec(0.5)) ||
+			!g.EnforceWithdrawalLimits {
+			t.Errorf("want BTC withdrawal limit but got `%v`",
+				g.WithdrawalLimits)
+		}
+		if !g.MaxOrderAmount["BTCETH"].Equal(dec(1)) {
+			t.Errorf("want BTCETH max order amount but got `%v`",
+				g.MaxOrderAmount)
+		}
+		if g.MarketDataValidation == nil ||
+			!g.MarketDataValidation.MaxChangePercent.Equal(dec(100)) ||
+			!g.MarketDataValidation.Reject {
+			t.Errorf("want market data validation but got `%v`",
+				g.MarketDataValidation)
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		path, remove := writeConfig(t, "client.json", `{
+			"url": "http://localhost:3000",
+			"timeouts": {"total": "30s"}
+		}`)
+		defer remove()
+
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if time.Duration(cfg.Timeouts.Total) != 30*time.Second {
+			t.Errorf("want total timeout 30s but got `%v`", cfg.Timeouts)
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		content string
+	}{
+		{"unknown field", `{"url": "http://e.test", "ur": "x"}`},
+		{"relative url", `{"url": "/graphql"}`},
+		{"both credentials", `{"url": "http://e.test", "credentials": {
+			"macaroonEnv": "A", "jwtEnv": "B"}}`},
+		{"negative timeout", `{"url": "http://e.test", "timeouts": {
+			"total": "-1s"}}`},
+		{"numeric duration", `{"url": "http://e.test", "timeouts": {
+			"total": 5}}`},
+		{"zero withdrawal limit", `{"url": "http://e.test",
+			"guardRails": {"withdrawalLimits": {"BTC": 0}}}`},
+	}
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			path, remove := writeConfig(t, "client.json", test.content)
+			defer remove()
+
+			if _, err := LoadConfig(path); err == nil {
+				t.Error("want error but got no error")
+			}
+		})
+	}
+}
+
+func TestFromConfigFile(t *testing.T) {
+	path, remove := writeConfig(t, "client.yml", testYAMLConfig)
+	defer remove()
+
+	t.Run("when credential is not set", func(t *testing.T) {
+		os.Unsetenv("TEST_EXCHANGE_JWT")
+		if _, err := FromConfigFile(path); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+	t.Run("when credential is set", func(t *testing.T) {
+		os.Setenv("TEST_EXCHANGE_JWT", "token")
+		defer os.Unsetenv("TEST_EXCHANGE_JWT")
+
+		client, err := FromConfigFile(path)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		core := client.core.(*graphQLCore)
+		if core.jwt != "token" || core.timeout != time.Minute {
+			t.Errorf("want JWT and total timeout configured but got "+
+				"`%s` and `%v`", core.jwt, core.timeout)
+		}
+		if client.withdrawals == nil || !client.enforceWithdrawalLimits {
+			t.Error("want withdrawal limits enforced")
+		}
+		if client.validator == nil {
+			t.Error("want market data validation enabled")
+		}
+		if l := core.rateLimit; l == nil || l.rps != 2.5 || l.burst != 5 {
+			t.Errorf("want rate limit 2.5 rps with burst 5 but got `%v`", l)
+		}
+
+		err = client.checkOrder(OrderIntent{Market: "BTCETH",
+			Side: "bid", Amount: dec(2)})
+		if err == nil {
+			t.Error("want order exceeding max amount rejected")
+		}
+		err = client.checkOrder(OrderIntent{Market: "BTCETH",
+			Side: "bid", Amount: dec(1)})
+		if err != nil {
+			t.Errorf("want order within max amount accepted but got `%v`",
+				err)
+		}
+	})
+}
+
+func TestMaxOrderAmountCheck(t *testing.T) {
+	check := maxOrderAmountCheck(map[string]decimal.Decimal{
+		"BTCETH": dec(1),
+	})
+	err := check.CheckOrder(context.Background(), OrderIntent{
+		Market: "BTCDASH", Amount: dec(100)})
+	if err != nil {
+		t.Errorf("want market without limit accepted but got `%v`", err)
+	}
+}
@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// conversionHub is the asset through which assets without direct market
+// are converted.
+const conversionHub = "BTC"
+
+// conversionDepthLimit is the number of depth levels used to estimate
+// conversion slippage.
+const conversionDepthLimit = 100
+
+// ErrSlippageExceeded is returned by ConvertAndTrade if estimated or
+// executed price of conversion leg is worse than best price by more
+// than allowed slippage.
+var ErrSlippageExceeded = errors.New("slippage exceeded")
+
+// ConversionStage is the stage of conversion leg reported in progress
+// events.
+type ConversionStage string
+
+const (
+	// ConversionQuoted is reported once leg price is estimated from the
+	// depth, before the order is created.
+	ConversionQuoted ConversionStage = "quoted"
+
+	// ConversionExecuted is reported once leg order is executed.
+	ConversionExecuted ConversionStage = "executed"
+)
+
+// ConversionConfig is the configuration of ConvertAndTrade.
+type ConversionConfig struct {
+	// MaxSlippage is the maximum allowed difference between average and
+	// best price of every leg as a fraction of the best price, e.g.
+	// 0.01 is 1%. Zero disables slippage limits.
+	MaxSlippage decimal.Decimal
+
+	// OnProgress is invoked on every stage of every leg, nil disables
+	// progress events.
+	OnProgress func(e ConversionEvent)
+}
+
+// ConversionEvent is the progress event of conversion.
+type ConversionEvent struct {
+	// Stage is the stage of the leg.
+	Stage ConversionStage
+
+	// Index is the index of the leg starting from zero.
+	Index int
+
+	// Legs is the total number of conversion legs.
+	Legs int
+
+	// Leg is the leg state at the stage, Order and Received are set
+	// once the leg is executed.
+	Leg ConversionLeg
+}
+
+// ConversionLeg is the single market order of the conversion.
+type ConversionLeg struct {
+	// Market is the market of the order.
+	Market string
+
+	// Side is the side of the order, either "ask" or "bid".
+	Side string
+
+	// From is the asset spent by the leg.
+	From string
+
+	// To is the asset received by the leg.
+	To string
+
+	// Amount is the amount of From asset spent.
+	Amount decimal.Decimal
+
+	// BestPrice is the best price of the depth when the leg is quoted.
+	BestPrice decimal.Decimal
+
+	// EstimatedPrice is the average price estimated from the depth.
+	EstimatedPrice decimal.Decimal
+
+	// Order is the executed order.
+	Order Order
+
+	// Received is the amount of To asset received.
+	Received decimal.Decimal
+}
+
+// Conversion is the consolidated result of the conversion.
+type Conversion struct {
+	// From is the asset spent.
+	From string
+
+	// To is the asset received.
+	To string
+
+	// Amount is the amount of From asset spent.
+	Amount decimal.Decimal
+
+	// Received is the amount of To asset received by the last executed
+	// leg, zero if conversion is not completed.
+	Received decimal.Decimal
+
+	// Legs is the executed legs of the conversion.
+	Legs []ConversionLeg
+}
+
+// Rate returns the amount of To asset received per one From asset, zero
+// if nothing is received.
+func (c Conversion) Rate() decimal.Decimal {
+	if c.Amount.Sign() == 0 {
+		return decimal.Zero
+	}
+	return c.Received.Div(c.Amount)
+}
+
+// ConvertAndTrade converts amount of from asset into to asset using
+// market orders, either in the direct market or in two hops through
+// BTC. Every leg is quoted from the depth first and is not executed if
+// estimated slippage exceeds the limit. If a leg fails the conversion
+// stops and the error is returned along with executed legs, so the
+// caller knows which intermediate asset it holds.
+func (c *Client) ConvertAndTrade(ctx context.Context, from, to string,
+	amount decimal.Decimal, cfg ConversionConfig) (Conversion, error) {
+
+	res := Conversion{
+		From:   from,
+		To:     to,
+		Amount: amount,
+	}
+
+	if amount.Sign() <= 0 {
+		return res, errors.New("amount should be positive")
+	}
+
+	legs, err := c.conversionRoute(ctx, from, to)
+	if err != nil {
+		return res, err
+	}
+
+	client := c.WithContext(ctx)
+
+	progress := func(stage ConversionStage, i int, leg ConversionLeg) {
+		if cfg.OnProgress != nil {
+			cfg.OnProgress(ConversionEvent{
+				Stage: stage,
+				Index: i,
+				Legs:  len(legs),
+				Leg:   leg,
+			})
+		}
+	}
+
+	for i, leg := range legs {
+		if i == 0 {
+			leg.Amount = amount
+		} else {
+			leg.Amount = res.Legs[i-1].Received
+		}
+
+		depth, err := client.Depth(leg.Market, conversionDepthLimit, 0)
+		if err != nil {
+			return res, errors.New("failed to get depth of " +
+				leg.Market + ": " + err.Error())
+		}
+
+		leg.BestPrice, leg.EstimatedPrice, err = estimateMarketOrder(depth,
+			leg.Side, leg.Amount)
+		if err != nil {
+			return res, errors.New("failed to quote " + leg.Market +
+				": " + err.Error())
+		}
+		if slippageExceeded(leg.Side, leg.BestPrice, leg.EstimatedPrice,
+			cfg.MaxSlippage) {
+			return res, ErrSlippageExceeded
+		}
+		progress(ConversionQuoted, i, leg)
+
+		if leg.Side == "bid" {
+			leg.Order, err = client.CreateOrderBid(leg.Market, leg.Amount)
+			leg.Received = leg.Order.DealStock
+		} else {
+			leg.Order, err = client.CreateOrderAsk(leg.Market, leg.Amount)
+			leg.Received = leg.Order.DealMoney
+		}
+		if err != nil {
+			return res, errors.New("failed to create order in " +
+				leg.Market + ": " + err.Error())
+		}
+
+		res.Legs = append(res.Legs, leg)
+		progress(ConversionExecuted, i, leg)
+
+		// Next leg is not executed if this one slipped more than
+		// estimated, e.g. the depth moved after quoting.
+		if i < len(legs)-1 && leg.Order.DealStock.Sign() > 0 &&
+			slippageExceeded(leg.Side, leg.BestPrice,
+				leg.Order.DealMoney.Div(leg.Order.DealStock),
+				cfg.MaxSlippage) {
+			return res, ErrSlippageExceeded
+		}
+	}
+
+	res.Received = res.Legs[len(res.Legs)-1].Received
+	return res, nil
+}
+
+// conversionRoute returns legs converting from asset into to asset,
+// either direct or through the hub.
+func (c *Client) conversionRoute(ctx context.Context, from,
+	to string) ([]ConversionLeg, error) {
+
+	if from == to {
+		return nil, errors.New("assets should differ")
+	}
+
+	markets, err := c.SupportedMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	direct := func(from, to string) (ConversionLeg, bool) {
+		for _, info := range markets {
+			switch m := info.Market; m {
+			case from + to:
+				// Money is spent to buy stock.
+				return ConversionLeg{Market: m, Side: "bid", From: from,
+					To: to}, true
+			case to + from:
+				// Stock is sold for money.
+				return ConversionLeg{Market: m, Side: "ask", From: from,
+					To: to}, true
+			}
+		}
+		return ConversionLeg{}, false
+	}
+
+	if leg, ok := direct(from, to); ok {
+		return []ConversionLeg{leg}, nil
+	}
+
+	first, ok := direct(from, conversionHub)
+	if !ok {
+		return nil, errors.New("no market to convert " + from)
+	}
+	second, ok := direct(conversionHub, to)
+	if !ok {
+		return nil, errors.New("no market to convert to " + to)
+	}
+	return []ConversionLeg{first, second}, nil
+}
+
+// estimateMarketOrder walks the depth to estimate the average price of
+// market order of the amount, which is money for bid and stock for ask.
+// It returns the best price and the estimated average one.
+func estimateMarketOrder(depth Depth, side string,
+	amount decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+
+	var levels []Ask
+	if side == "bid" {
+		levels = depth.Asks
+	} else {
+		for _, b := range depth.Bids {
+			levels = append(levels, Ask{Price: b.Price, Volume: b.Volume})
+		}
+	}
+	if len(levels) == 0 {
+		return decimal.Zero, decimal.Zero, errors.New("empty depth")
+	}
+
+	var money, stock decimal.Decimal
+	left := amount
+	for _, l := range levels {
+		if left.Sign() <= 0 {
+			break
+		}
+
+		if side == "bid" {
+			// Amount is money, level volume is stock.
+			cost := l.Volume.Mul(l.Price)
+			if cost.GreaterThanOrEqual(left) {
+				stock = stock.Add(left.Div(l.Price))
+				money = money.Add(left)
+				left = decimal.Zero
+				continue
+			}
+			stock = stock.Add(l.Volume)
+			money = money.Add(cost)
+			left = left.Sub(cost)
+		} else {
+			volume := decimal.Min(l.Volume, left)
+			stock = stock.Add(volume)
+			money = money.Add(volume.Mul(l.Price))
+			left = left.Sub(volume)
+		}
+	}
+
+	if left.Sign() > 0 || stock.Sign() == 0 {
+		return decimal.Zero, decimal.Zero, errors.New(
+			"not enough liquidity")
+	}
+
+	return levels[0].Price, money.Div(stock), nil
+}
+
+// slippageExceeded returns true if price is worse than best by more
+// than max fraction of best, zero max disables the check.
+func slippageExceeded(side string, best, price,
+	max decimal.Decimal) bool {
+
+	if max.Sign() <= 0 || best.Sign() <= 0 {
+		return false
+	}
+
+	slippage := price.Sub(best).Div(best)
+	if side != "bid" {
+		slippage = slippage.Neg()
+	}
+	return slippage.GreaterThan(max)
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// conversionCore fakes exchange with the same depth in every market,
+// market orders are executed at the given prices.
+func conversionCore(depth, bidOrder, askOrder string,
+	orders *[]createOrderRequestVariables) CoreFunc {
+
+	return func(query string, variables interface{}) ([]byte, error) {
+		switch {
+		case strings.Contains(query, "supportedMarkets"):
+			return []byte(supportedMarketsJSON), nil
+		case strings.Contains(query, "depth"):
+			return []byte(`{"data": {"depth": ` + depth + `}}`), nil
+		case strings.Contains(query, "createMarketOrder"):
+			v := variables.(createOrderRequestVariables)
+			*orders = append(*orders, v)
+			order := askOrder
+			if v.Side == "bid" {
+				order = bidOrder
+			}
+			return []byte(`{"data": {"createMarketOrder": ` + order +
+				`}}`), nil
+		}
+		return []byte(`{}`), nil
+	}
+}
+
+func TestClient_ConvertAndTrade(t *testing.T) {
+	const depth = `{
+		"asks": [{"price": "0.1", "volume": "10"},
+			{"price": "0.2", "volume": "10"}],
+		"bids": [{"price": "0.09", "volume": "10"},
+			{"price": "0.05", "volume": "10"}]
+	}`
+
+	t.Run("direct market", func(t *testing.T) {
+		var orders []createOrderRequestVariables
+		client := &Client{core: conversionCore(depth,
+			`{"status": "finished", "dealMoney": "0.5", "dealStock": "5"}`,
+			``, &orders)}
+
+		var events []ConversionEvent
+		res, err := client.ConvertAndTrade(context.Background(), "BTC",
+			"ETH", dec(0.5), ConversionConfig{
+				MaxSlippage: dec(0.01),
+				OnProgress: func(e ConversionEvent) {
+					events = append(events, e)
+				},
+			})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		if len(orders) != 1 || orders[0].Market != "BTCETH" ||
+			orders[0].Side != "bid" || !orders[0].Amount.Equal(dec(0.5)) {
+			t.Errorf("want bid of 0.5 in BTCETH but got `%v`", orders)
+		}
+		if !res.Received.Equal(dec(5)) || !res.Rate().Equal(dec(10)) {
+			t.Errorf("want 5 ETH received but got `%v`", res.Received)
+		}
+		if len(events) != 2 || events[0].Stage != ConversionQuoted ||
+			events[1].Stage != ConversionExecuted {
+			t.Errorf("want quoted and executed events but got `%v`",
+				events)
+		}
+	})
+	t.Run("two hops through BTC", func(t *testing.T) {
+		var orders []createOrderRequestVariables
+		client := &Client{core: conversionCore(depth,
+			`{"status": "finished", "dealMoney": "0.45", "dealStock": "4.5"}`,
+			`{"status": "finished", "dealMoney": "0.45", "dealStock": "5"}`,
+			&orders)}
+
+		res, err := client.ConvertAndTrade(context.Background(), "ETH",
+			"DASH", dec(5), ConversionConfig{})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		if len(orders) != 2 ||
+			orders[0].Market != "BTCETH" || orders[0].Side != "ask" ||
+			!orders[0].Amount.Equal(dec(5)) ||
+			orders[1].Market != "BTCDASH" || orders[1].Side != "bid" ||
+			!orders[1].Amount.Equal(dec(0.45)) {
+			t.Errorf("want ask of 5 ETH and bid of 0.45 BTC but got `%v`",
+				orders)
+		}
+		if len(res.Legs) != 2 || !res.Received.Equal(dec(4.5)) {
+			t.Errorf("want 4.5 DASH received in 2 legs but got `%v`", res)
+		}
+	})
+	t.Run("when estimated slippage exceeded", func(t *testing.T) {
+		var orders []createOrderRequestVariables
+		client := &Client{core: conversionCore(depth, `{}`, `{}`, &orders)}
+
+		_, err := client.ConvertAndTrade(context.Background(), "BTC",
+			"ETH", dec(2), ConversionConfig{MaxSlippage: dec(0.1)})
+		if err != ErrSlippageExceeded {
+			t.Fatalf("want ErrSlippageExceeded but got `%v`", err)
+		}
+		if len(orders) != 0 {
+			t.Errorf("want no orders but got `%v`", orders)
+		}
+	})
+	t.Run("when no market", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: supportedMarketsJSON}}
+		_, err := client.ConvertAndTrade(context.Background(), "BTC",
+			"XRP", dec(1), ConversionConfig{})
+		if err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+}
+
+func TestEstimateMarketOrder(t *testing.T) {
+	depth := Depth{
+		Asks: []Ask{{Price: dec(1), Volume: dec(1)},
+			{Price: dec(2), Volume: dec(1)}},
+		Bids: []Bid{{Price: dec(1), Volume: dec(1)},
+			{Price: dec(0.5), Volume: dec(1)}},
+	}
+
+	tests := []struct {
+		side   string
+		amount decimal.Decimal
+		want   decimal.Decimal
+	}{
+		// 3 money buys 1 stock at 1 and 1 stock at 2.
+		{"bid", dec(3), dec(1.5)},
+		{"bid", dec(0.5), dec(1)},
+		// 2 stock is sold for 1 and 0.5 money.
+		{"ask", dec(2), dec(0.75)},
+	}
+	for _, test := range tests {
+		_, got, err := estimateMarketOrder(depth, test.side, test.amount)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("want %s price of %s to be %s but got %s", test.side,
+				test.amount, test.want, got)
+		}
+	}
+
+	if _, _, err := estimateMarketOrder(depth, "bid", dec(4)); err == nil {
+		t.Error("want not enough liquidity error but got no error")
+	}
+}
@@ -2,11 +2,14 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bitlum/macaroon-application-auth"
@@ -19,88 +22,432 @@ import (
 // 1. graphQLCore: used for real requests to exchange GraphQL server.
 // 2. mockCore: used for testing purposes.
 type core interface {
-	do(needAuth bool, r request) ([]byte, error)
+	do(ctx context.Context, needAuth bool, r request) ([]byte, error)
 }
 
 // graphQLCore is client core implementation used to perform authorized
 // http requests to exchange GraphQL server.
 type graphQLCore struct {
-	url      string
+	// lastUsed is the unix time in nanoseconds of the last request,
+	// used to keep idle connections warm. Accessed atomically, so it is
+	// kept first to be 64-bit aligned.
+	lastUsed int64
+
+	url string
+
+	// mtx guards macaroon and jwt which may be replaced on reauth.
+	mtx      sync.RWMutex
 	macaroon *macaroon.Macaroon
+	jwt      string
+
+	// jwtExpiry is the expiry of the jwt obtained from the token
+	// source, zero if it is unknown. It is guarded by mtx.
+	jwtExpiry time.Time
+
+	// tokens obtains JWT tokens, nil means the given credentials are
+	// used. tokenMtx serializes token refreshes.
+	tokens   TokenSource
+	tokenMtx sync.Mutex
+
+	// issuer issues macaroon for the JWT token, nil means the given
+	// credentials are used.
+	issuer *macaroonIssuer
+
+	// authTrace keeps the values of the last authorization, nil
+	// disables tracing.
+	authTrace *authTrace
+
+	// signer signs requests if neither macaroon nor JWT is given.
+	signer Signer
+
+	// reauth is used to obtain fresh credentials if server rejects
+	// current ones, nil disables reauthorization.
+	reauth ReauthFunc
+
+	// har records request/response pairs, nil disables recording.
+	har *HARRecorder
+
+	// httpClient is used to make http requests, nil means the client
+	// is created per request from transport and timeout.
+	httpClient *http.Client
+
+	// transport is used to make http requests, nil means
+	// http.DefaultTransport.
+	transport http.RoundTripper
+
+	// timeout is the total timeout of the request, zero means no
+	// timeout.
+	timeout time.Duration
+
+	// clock is used for macaroon time caveats, nil means local clock.
+	clock *skewClock
+
+	// prober selects the endpoint of requests which do not need
+	// authorization, nil means url is used for all requests.
+	prober *LatencyProber
+
+	// deprecations collects deprecations reported by the server, nil
+	// disables collecting.
+	deprecations *deprecations
+
+	// retry is the configuration of retries of transient failures, nil
+	// disables retries.
+	retry *RetryConfig
+
+	// hedge sends duplicates of slow public requests, nil disables
+	// hedging.
+	hedge *hedger
 
-	jwt string
+	// msgpack makes the core accept msgpack encoded responses.
+	msgpack bool
+
+	// checksumHeader is the header of the response body checksum,
+	// empty disables checksum verification.
+	checksumHeader string
+
+	// strictDecimals is the bound of fraction digits of decimals in
+	// request variables, zero disables strict decimal mode.
+	strictDecimals int32
+
+	// rateLimit limits requests, nil disables the limit.
+	rateLimit *rateLimiter
+
+	// concurrency limits requests in flight, nil disables the limit.
+	concurrency *concurrencyLimiter
+
+	// throttle is the configuration of retries of throttled requests,
+	// nil disables them.
+	throttle *ThrottleConfig
+
+	// nonces generates macaroon nonces, nil means nonces are the
+	// current time in nanoseconds.
+	nonces *nonceGenerator
 }
 
 // do performs authorized GraphQL request to bitlum exchange service and
-// returns response body.
-func (c *graphQLCore) do(needAuth bool, r request) ([]byte, error) {
-	reqJSON, err := json.Marshal(r)
+// returns response body. Transient failures are retried if retries are
+// configured, see WithRetry. Authorized request which nonce collides
+// with one used by another process is retried once with the new nonce,
+// see WithNonces. If server rejects credentials and reauth function,
+// token source or macaroon issue is set, it obtains fresh credentials
+// and retries once.
+func (c *graphQLCore) do(ctx context.Context, needAuth bool,
+	r request) ([]byte, error) {
+
+	reqJSON, err := marshalRequest(r, c.strictDecimals)
 	if err != nil {
 		return nil, errors.New("failed to json.Marshal request: " +
 			err.Error())
 	}
 
-	httpReq, err := http.NewRequest("POST", c.url,
+	repeatable := idempotent(ctx, r)
+
+	if needAuth && c.tokens != nil {
+		if err := c.ensureToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if needAuth && c.issuer != nil {
+		if err := c.ensureMacaroon(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mtx.RLock()
+	sentJWT, sentMacaroon := c.jwt, c.macaroon
+	c.mtx.RUnlock()
+
+	body, err := c.sendRetrying(ctx, needAuth, repeatable, reqJSON)
+	if needAuth && isNonceCollision(body, err) {
+		c.authTrace.rejected(body, err)
+		body, err = c.sendRetrying(ctx, needAuth, repeatable, reqJSON)
+	}
+	if !needAuth || !(isAuthRejected(err) || isTokenExpired(body, err)) {
+		return body, err
+	}
+	c.authTrace.rejected(body, err)
+
+	switch {
+	case c.tokens != nil:
+		err = c.refreshToken(ctx, sentJWT)
+	case c.issuer != nil && sentMacaroon != nil:
+		err = c.issueMacaroon(ctx, sentMacaroon)
+	case c.reauth != nil:
+		err = c.reauthorize(ctx)
+	default:
+		return body, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = c.sendRetrying(ctx, needAuth, repeatable, reqJSON)
+	if isAuthRejected(err) || isTokenExpired(body, err) {
+		c.authTrace.rejected(body, err)
+	}
+	if isAuthRejected(err) {
+		return nil, fmt.Errorf("%w: %v", ErrReauthFailed, err)
+	}
+	if isTokenExpired(body, err) {
+		return nil, fmt.Errorf("%w: fresh token is expired",
+			ErrReauthFailed)
+	}
+
+	return body, err
+}
+
+// send sends marshalled GraphQL request and returns response body.
+// Idempotent requests which do not need authorization are hedged if
+// hedging is enabled, see WithHedging.
+func (c *graphQLCore) send(ctx context.Context, needAuth,
+	idempotent bool, reqJSON []byte) ([]byte, error) {
+
+	url := c.url
+	if !needAuth && c.prober != nil {
+		url = c.prober.Fastest()
+	}
+
+	if !needAuth && idempotent && c.hedge != nil {
+		return c.hedge.do(ctx, url, c.url, func(ctx context.Context,
+			url string) ([]byte, error) {
+
+			return c.sendTo(ctx, url, needAuth, reqJSON)
+		})
+	}
+
+	return c.sendTo(ctx, url, needAuth, reqJSON)
+}
+
+// sendTo sends marshalled GraphQL request to the url and returns
+// response body.
+func (c *graphQLCore) sendTo(ctx context.Context, url string,
+	needAuth bool, reqJSON []byte) ([]byte, error) {
+
+	if err := c.rateLimit.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	release, err := c.concurrency.acquire(ctx, needAuth)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	atomic.StoreInt64(&c.lastUsed, time.Now().UnixNano())
+
+	stats := contextCallStats(ctx)
+	stats.attempt()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url,
 		bytes.NewBuffer(reqJSON))
 	if err != nil {
-		return nil, errors.New("failed to http.NewRequest: " +
+		return nil, errors.New("failed to http.NewRequestWithContext: " +
 			err.Error())
 	}
 
-	if needAuth {
-		if c.macaroon != nil {
-			// Adding nonce to protect client from replay-attack.
-			m, err := auth.AddNonce(c.macaroon, time.Now().UnixNano())
-			if err != nil {
-				return nil, errors.New(
-					"failed to add nonce to macaroon: " + err.Error())
-			}
+	httpReq.Header.Set(ClientVersionHeader, Version)
 
-			// Adding current time to protect client from replay-attack.
-			m, err = auth.AddCurrentTime(m)
-			if err != nil {
-				return nil, errors.New(
-					"failed to add current time to macaroon: " + err.Error())
-			}
+	if c.msgpack {
+		httpReq.Header.Set("Accept", msgpackContentType+
+			", application/json;q=0.9")
+	}
 
-			token, err := auth.EncodeMacaroon(m)
-			if err != nil {
-				return nil, errors.New(
-					"failed to encode macaroon: " + err.Error())
-			}
+	if needAuth {
+		if err := c.authorize(httpReq, reqJSON); err != nil {
+			return nil, err
+		}
+	}
 
-			httpReq.Header.Set("Content-Type", "application/json")
-			httpReq.Header.Set("Authorization", "Macaroon "+token)
-		} else if c.jwt != "" {
-			httpReq.Header.Set("Authorization", "Bearer "+c.jwt)
-		} else {
-			return nil, errors.New("unable to make operation which requires" +
-				" auth without auth tokens")
+	started := time.Now()
+
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Transport: c.transport,
+			Timeout:   c.timeout,
 		}
 	}
 
-	httpResp, err := (&http.Client{}).Do(httpReq)
+	httpResp, err := httpClient.Do(httpReq)
 	if err != nil {
-		return nil, errors.New("failed to do http request: " +
-			err.Error())
+		if c.har != nil {
+			c.har.record(started, httpReq, reqJSON, nil, nil, err)
+		}
+		return nil, fmt.Errorf("failed to do http request: %w", err)
 	}
 
 	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response status: %s",
-			httpResp.Status)
+	stats.respond(httpResp.Header.Get(RequestIDHeader))
+
+	if c.clock != nil {
+		c.clock.observe(httpResp, started)
 	}
 
 	body, err := ioutil.ReadAll(httpResp.Body)
+	err = verifyBody(httpResp, body, err, c.checksumHeader)
+
+	if c.har != nil {
+		c.har.record(started, httpReq, reqJSON, httpResp, body, err)
+	}
+
+	if err == nil && isMsgpack(httpResp) {
+		if body, err = msgpackToJSON(body); err != nil {
+			return nil, errors.New("failed to decode msgpack response: " +
+				err.Error())
+		}
+	}
+
+	c.deprecations.observe(httpResp, body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, newHTTPStatusError(httpResp, body)
+	}
+
+	if err == ErrTruncatedResponse {
+		return nil, err
+	}
 	if err != nil {
-		return nil, errors.New("failed to read response body: " +
-			err.Error())
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	return body, nil
 }
 
+// authorize sets authorization headers of the request using either
+// macaroon, JWT or signer.
+func (c *graphQLCore) authorize(httpReq *http.Request, reqJSON []byte) error {
+	c.mtx.RLock()
+	mac, jwt := c.macaroon, c.jwt
+	c.mtx.RUnlock()
+
+	if mac != nil {
+		// Adding nonce to protect client from replay-attack.
+		nonce := c.nonces.nonce()
+		m, err := auth.AddNonce(mac, nonce)
+		if err != nil {
+			return errors.New(
+				"failed to add nonce to macaroon: " + err.Error())
+		}
+
+		// Adding current time to protect client from replay-attack.
+		var caveatTime time.Time
+		if c.clock != nil {
+			m, caveatTime, err = c.clock.addTimeCaveat(m)
+		} else {
+			caveatTime = time.Now()
+			m, err = auth.AddCurrentTime(m)
+		}
+		if err != nil {
+			return errors.New(
+				"failed to add current time to macaroon: " + err.Error())
+		}
+		c.authTrace.caveats(nonce, caveatTime)
+
+		token, err := auth.EncodeMacaroon(m)
+		if err != nil {
+			return errors.New(
+				"failed to encode macaroon: " + err.Error())
+		}
+
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Macaroon "+token)
+	} else if jwt != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+jwt)
+	} else if c.signer != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+		if err := c.signer.Sign(httpReq.Header, reqJSON); err != nil {
+			return errors.New(
+				"failed to sign request: " + err.Error())
+		}
+	} else {
+		return errors.New("unable to make operation which requires" +
+			" auth without auth tokens")
+	}
+
+	return nil
+}
+
+// maxErrorDetailLength is the maximum length of the plain text error
+// detail taken from the response body.
+const maxErrorDetailLength = 512
+
+// HTTPStatusError is returned if exchange server responds with non 200
+// status code.
+type HTTPStatusError struct {
+	// StatusCode is the response status code, e.g. 400.
+	StatusCode int
+
+	// Status is the response status, e.g. "400 Bad Request".
+	Status string
+
+	// Body is the response body.
+	Body []byte
+
+	// Detail is the error detail extracted from the response body,
+	// either GraphQL-style errors, JSON error message or plain text.
+	Detail string
+
+	// RetryAfter is the delay after which the request may be repeated
+	// given in Retry-After header, zero if the header is absent.
+	RetryAfter time.Duration
+}
+
+// newHTTPStatusError creates new error of the response with given body.
+func newHTTPStatusError(resp *http.Response, body []byte) *HTTPStatusError {
+	return &HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       body,
+		Detail:     errorDetail(body),
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+func (e *HTTPStatusError) Error() string {
+	msg := fmt.Sprintf("unexpected response status: %s", e.Status)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// errorDetail extracts error detail from the error response body.
+func errorDetail(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return ""
+	}
+
+	var payload struct {
+		responseBase
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if err := payload.responseBase.Error(); err != nil {
+			return err.Error()
+		}
+		if payload.Error != "" {
+			return payload.Error
+		}
+		if payload.Message != "" {
+			return payload.Message
+		}
+	}
+
+	if body[0] == '{' || body[0] == '[' || body[0] == '<' {
+		// Unknown structured payload or HTML page, it is kept in Body.
+		return ""
+	}
+
+	detail := string(body)
+	if len(detail) > maxErrorDetailLength {
+		detail = detail[:maxErrorDetailLength] + "..."
+	}
+	return detail
+}
+
 // request is the GraphQL request.
 type request struct {
 	Query     string      `json:"query"`
@@ -114,8 +461,12 @@ type responseBase struct {
 }
 
 type responseError struct {
-	Message   string
-	Locations []responseErrorLocation
+	Message    string
+	Locations  []responseErrorLocation
+	Path       []interface{} `json:"path"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
 }
 
 type responseErrorLocation struct {
@@ -148,5 +499,17 @@ func (rb responseBase) Error() error {
 		msg = fmt.Sprintf("%d errors occurred, first one is: %s",
 			len(rb.Errors), msg)
 	}
-	return errors.New(msg)
+	return &responseErrors{msg: msg, errors: rb.Errors}
+}
+
+// responseErrors is the error of GraphQL response which keeps response
+// errors, so they are exposed by ExchangeError.
+type responseErrors struct {
+	msg    string
+	errors []responseError
+}
+
+// Error implements error.
+func (e *responseErrors) Error() string {
+	return e.msg
 }
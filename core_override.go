@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CoreFunc is the adapter of the function which receives the query with
+// its variables and returns raw JSON response to Doer, for fakes which
+// need neither the context nor the request authorization.
+type CoreFunc func(query string, variables interface{}) ([]byte, error)
+
+// Do implements Doer.
+func (f CoreFunc) Do(_ context.Context, r Request) ([]byte, error) {
+	return f(r.Query, r.Variables)
+}
+
+// do implements core.
+func (f CoreFunc) do(_ context.Context, _ bool, r request) ([]byte, error) {
+	return f(r.Query, r.Variables)
+}
+
+// coreOverrideKey is the context key of the core override.
+type coreOverrideKey struct{}
+
+// WithCoreOverride returns the copy of the context which makes client
+// calls made with it to be served by fake instead of the real client
+// core, like with WithDoer. It is intended for tests of code which
+// receives shared, already constructed *Client, e.g. to test error
+// paths of particular calls.
+func WithCoreOverride(ctx context.Context, fake Doer) context.Context {
+	return context.WithValue(ctx, coreOverrideKey{}, core(doerCore{fake}))
+}
+
+// coreOverride returns the core override stored in the context, if any.
+func coreOverride(ctx context.Context) (core, bool) {
+	c, ok := ctx.Value(coreOverrideKey{}).(core)
+	return c, ok
+}
+
+// WithContext returns shallow copy of the client which performs all
+// requests with given context, so every client method is context-aware:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//	defer cancel()
+//	depth, err := c.WithContext(ctx).Depth("BTCETH", 10, 0)
+//
+// The context deadline and cancel abort in-flight HTTP request, and the
+// context may carry core override, see WithCoreOverride.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// context returns the context of the client requests.
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// do performs request using the core override from the client context
+// if it is present or the client core otherwise. Mutations are recorded
+// in the journal if it is enabled and are not sent in dry run mode.
+// Requests with done context and mutations during maintenance windows
+// are not sent, so they never reach the journal.
+func (c *Client) do(needAuth bool, r request) ([]byte, error) {
+	if err := c.context().Err(); err != nil {
+		return nil, err
+	}
+
+	if !isMutation(r.Query) {
+		return c.send(needAuth, r)
+	}
+
+	if err := c.maintenance.check(); err != nil {
+		return nil, err
+	}
+
+	send := c.send
+	if c.dryRun != nil {
+		send = func(_ bool, r request) ([]byte, error) {
+			return c.dryRun.respond(r)
+		}
+	}
+
+	if c.journal == nil {
+		return send(needAuth, r)
+	}
+
+	seq, err := c.journal.begin(r)
+	if err != nil {
+		return nil, errors.New("failed to journal mutation: " +
+			err.Error())
+	}
+
+	resp, err := send(needAuth, r)
+
+	// Failure to mark the mutation done keeps it in flight, so it is
+	// reconciled later instead of hiding the mutation result.
+	c.journal.end(seq, err)
+
+	return resp, err
+}
+
+// send performs request using the core override from the client context
+// if it is present or the client core otherwise. The request is tracked
+// as in-flight until it is done, see InFlight. Requests are sent one by
+// one in submission order if they are serialized.
+func (c *Client) send(needAuth bool, r request) ([]byte, error) {
+	ctx, done := c.inFlight.track(c.context(), needAuth, r)
+	defer done()
+
+	var stats *callStats
+	if c.logger != nil || c.slowCalls != nil {
+		ctx, stats = withCallStats(ctx)
+	}
+
+	started := time.Now()
+
+	var (
+		resp []byte
+		err  error
+	)
+	if perr := c.serial.Do(ctx, func() {
+		if override, ok := coreOverride(ctx); ok {
+			resp, err = override.do(ctx, needAuth, r)
+		} else {
+			resp, err = c.core.do(ctx, needAuth, r)
+		}
+	}); perr != nil {
+		err = perr
+	}
+
+	category := operationCategory(contextOperationTags(ctx), needAuth, r)
+	c.health.observe(category, err)
+	c.logRequest(category, r, started, stats, err)
+
+	return resp, err
+}
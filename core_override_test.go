@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithCoreOverride(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{ "data": { "me": { "id": "real-id" } } }`,
+	}
+	client := &Client{core: backend}
+
+	t.Run("when override returns error", func(t *testing.T) {
+		ctx := WithCoreOverride(context.Background(),
+			CoreFunc(func(query string, variables interface{}) ([]byte,
+				error) {
+
+				return nil, errors.New("fail")
+			}))
+		_, err := client.WithContext(ctx).UserID()
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if !strings.Contains(err.Error(), "failed to do request") {
+			t.Fatalf("want do request error but got `%s`", err.Error())
+		}
+	})
+	t.Run("when override returns response", func(t *testing.T) {
+		var gotQuery string
+		ctx := WithCoreOverride(context.Background(),
+			CoreFunc(func(query string, variables interface{}) ([]byte,
+				error) {
+
+				gotQuery = query
+				return []byte(`{ "data": { "me": { "id": "fake-id" } } }`),
+					nil
+			}))
+		id, err := client.WithContext(ctx).UserID()
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if id != "fake-id" {
+			t.Errorf("want user ID `fake-id` but got `%s`", id)
+		}
+		if !strings.Contains(gotQuery, "me") {
+			t.Errorf("want me query but got `%s`", gotQuery)
+		}
+	})
+	t.Run("when override is doer", func(t *testing.T) {
+		var got Request
+		ctx := WithCoreOverride(context.Background(), DoerFunc(
+			func(_ context.Context, r Request) ([]byte, error) {
+				got = r
+				return []byte(`{ "data": { "me": { "id": "fake-id" } } }`),
+					nil
+			}))
+		if _, err := client.WithContext(ctx).UserID(); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if !got.NeedAuth {
+			t.Error("want authorized request passed to the doer")
+		}
+	})
+	t.Run("when no override", func(t *testing.T) {
+		id, err := client.WithContext(context.Background()).UserID()
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if id != "real-id" {
+			t.Errorf("want user ID `real-id` but got `%s`", id)
+		}
+	})
+}
+
+func TestClient_WithContext(t *testing.T) {
+	client := &Client{core: &mockCore{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := client.WithContext(ctx)
+	if got == client {
+		t.Fatal("want copy of the client")
+	}
+	if got.context() != ctx {
+		t.Error("want client context to be set")
+	}
+	if client.context() != context.Background() {
+		t.Error("want original client context to be untouched")
+	}
+}
+
+func TestClient_WithContextCancel(t *testing.T) {
+	t.Run("when context is done before request", func(t *testing.T) {
+		backend := &mockCore{respJSON: `{ "data": { "me": { "id": "id" } } }`}
+		client := &Client{core: backend}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.WithContext(ctx).UserID()
+		if err == nil || !strings.Contains(err.Error(),
+			context.Canceled.Error()) {
+			t.Fatalf("want context canceled error but got `%v`", err)
+		}
+		if backend.request.Query != "" {
+			t.Errorf("want request not sent but got `%s`",
+				backend.request.Query)
+		}
+	})
+	t.Run("when context is done during request", func(t *testing.T) {
+		release := make(chan struct{})
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+			<-release
+		}))
+		defer s.Close()
+		defer close(release)
+
+		client := &Client{core: &graphQLCore{url: s.URL}}
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			50*time.Millisecond)
+		defer cancel()
+
+		started := time.Now()
+		_, err := client.WithContext(ctx).Depth("BTCETH", 10, 0)
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("want request aborted by deadline but it took %v",
+				elapsed)
+		}
+	})
+}
@@ -1,11 +1,13 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/bitlum/macaroon-application-auth"
@@ -184,7 +186,7 @@ func Test_graphQLCore_do(t *testing.T) {
 			url:      s.url() + path,
 			macaroon: mac,
 		}
-		_, err := c.do(true, request{
+		_, err := c.do(context.Background(), true, request{
 			Query: "query",
 			Variables: struct {
 				Var1 string `json:"var1"`
@@ -208,7 +210,7 @@ func Test_graphQLCore_do(t *testing.T) {
 				Var1 string `json:"var1"`
 			}{"value"},
 		}
-		_, err := c.do(true, req)
+		_, err := c.do(context.Background(), true, req)
 		checkMethod(t, s)
 		checkURLPath(t, s)
 		checkHeaders(t, s)
@@ -217,6 +219,32 @@ func Test_graphQLCore_do(t *testing.T) {
 			t.Error("want error but got no error")
 		}
 	})
+	t.Run("when 400 status code with error body", func(t *testing.T) {
+		s := newMockBackendServer()
+		defer s.stop()
+		s.response.code = 400
+		s.response.body = `{ "errors": [{ "message": "unknown field" }] }`
+		c := &graphQLCore{
+			url:      s.url() + path,
+			macaroon: mac,
+		}
+		_, err := c.do(context.Background(), true, request{Query: "query"})
+		statusErr, ok := err.(*HTTPStatusError)
+		if !ok {
+			t.Fatalf("want *HTTPStatusError but got `%v`", err)
+		}
+		if statusErr.StatusCode != 400 {
+			t.Errorf("want 400 status code but got %v",
+				statusErr.StatusCode)
+		}
+		if statusErr.Detail != "unknown field" {
+			t.Errorf("want `unknown field` detail but got `%s`",
+				statusErr.Detail)
+		}
+		if !strings.Contains(err.Error(), "unknown field") {
+			t.Errorf("want detail in error message but got `%s`", err)
+		}
+	})
 	t.Run("when 200 status code", func(t *testing.T) {
 		s := newMockBackendServer()
 		defer s.stop()
@@ -234,7 +262,7 @@ func Test_graphQLCore_do(t *testing.T) {
 				Dec   decimal.Decimal `json:"dec"`
 			}{"value", "BTC", dec(10)},
 		}
-		_, err := c.do(true, req)
+		_, err := c.do(context.Background(), true, req)
 		checkMethod(t, s)
 		checkURLPath(t, s)
 		checkHeaders(t, s)
@@ -303,3 +331,84 @@ func (s *mockExchangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(s.response.code)
 	w.Write([]byte(s.response.body))
 }
+
+func TestErrorDetail(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{``, ``},
+		{`{ "errors": [{ "message": "bad query" }] }`, `bad query`},
+		{`{ "error": "bad request" }`, `bad request`},
+		{`{ "message": "invalid amount" }`, `invalid amount`},
+		{`{ "unknown": 1 }`, ``},
+		{`<html>Bad Gateway</html>`, ``},
+		{"  rate limit exceeded\n", `rate limit exceeded`},
+		{strings.Repeat("a", maxErrorDetailLength+1),
+			strings.Repeat("a", maxErrorDetailLength) + "..."},
+	}
+	for _, test := range tests {
+		if got := errorDetail([]byte(test.body)); got != test.want {
+			t.Errorf("want detail `%s` of `%s` but got `%s`", test.want,
+				test.body, got)
+		}
+	}
+}
+
+// countingTransport is http.RoundTripper which counts requests.
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response,
+	error) {
+
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNewClient_withHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{ "data": { "me": { "id": "1" } } }`))
+		}))
+	defer server.Close()
+
+	t.Run("http client", func(t *testing.T) {
+		transport := &countingTransport{}
+		hc := &http.Client{Transport: transport}
+
+		client, err := NewClient(server.URL, "", "token", WithHTTPClient(hc))
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if client.core.(*graphQLCore).httpClient != hc {
+			t.Fatal("want given http client to be reused")
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.Me(); err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+		}
+		if n := atomic.LoadInt32(&transport.requests); n != 2 {
+			t.Errorf("want 2 requests through http client but got %v", n)
+		}
+	})
+	t.Run("transport", func(t *testing.T) {
+		transport := &countingTransport{}
+
+		client, err := NewClient(server.URL, "", "token",
+			WithTransport(transport))
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		if _, err := client.Me(); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if n := atomic.LoadInt32(&transport.requests); n != 1 {
+			t.Errorf("want request through transport but got %v", n)
+		}
+	})
+}
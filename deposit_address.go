@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrNoDepositAddress is returned by CurrentDepositAddress when the
+// deposit address of the asset isn't created yet, RotateDepositAddress
+// creates it.
+var ErrNoDepositAddress = errors.New("deposit address isn't created")
+
+// depositAddressRequestVariables is a query variables used in requests
+// of deposit address.
+type depositAddressRequestVariables struct {
+	Asset string `json:"asset"`
+}
+
+// CurrentDepositAddress returns the address on which funds of the asset
+// have to be sent to be deposited on the account. Exchange rotates
+// deposit addresses, so unlike Account.Address which may be stale, the
+// address is requested every time.
+func (c *Client) CurrentDepositAddress(asset string) (string, error) {
+	var req request
+
+	req.Query = `
+		query CurrentDepositAddress($asset: Asset!) {
+			accounts(assets: [$asset]) {
+				asset
+				address
+			}
+		}
+	`
+
+	req.Variables = depositAddressRequestVariables{
+		Asset: c.assets.asset(asset),
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Accounts []struct {
+				Asset   string `json:"asset"`
+				Address string `json:"address"`
+			} `json:"accounts"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return "", transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return "", decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return "", exchangeError(err)
+	}
+
+	if len(resp.Data.Accounts) == 0 ||
+		resp.Data.Accounts[0].Address == "" {
+		return "", ErrNoDepositAddress
+	}
+
+	return resp.Data.Accounts[0].Address, nil
+}
+
+// RotateDepositAddress makes the exchange generate new deposit address
+// of the asset and returns it. Funds sent to previous addresses are
+// still deposited, but new payments should be shown the new address.
+func (c *Client) RotateDepositAddress(asset string) (string, error) {
+	var req request
+
+	req.Query = `
+		mutation GenerateDepositAddress($asset: Asset!) {
+			generateDepositAddress(asset: $asset)
+		}
+	`
+
+	req.Variables = depositAddressRequestVariables{
+		Asset: c.assets.asset(asset),
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Address string `json:"generateDepositAddress"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return "", transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return "", decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return "", exchangeError(err)
+	}
+
+	return resp.Data.Address, nil
+}
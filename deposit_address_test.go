@@ -0,0 +1,62 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClient_CurrentDepositAddress(t *testing.T) {
+	t.Run("when address exists", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `{ "data": { "accounts": [
+				{ "asset": "BTC", "address": "some-address" }
+			] } }`,
+		}
+		client := &Client{core: backend}
+
+		addr, err := client.CurrentDepositAddress("BTC")
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if addr != "some-address" {
+			t.Errorf("want some-address but got `%s`", addr)
+		}
+
+		wantVariables := depositAddressRequestVariables{Asset: "BTC"}
+		if !reflect.DeepEqual(wantVariables, backend.request.Variables) {
+			t.Errorf("want variables `%#v` but got `%#v`",
+				wantVariables, backend.request.Variables)
+		}
+	})
+	t.Run("when address isn't created", func(t *testing.T) {
+		backend := &mockCore{
+			respJSON: `{ "data": { "accounts": [
+				{ "asset": "BTC", "address": null }
+			] } }`,
+		}
+		client := &Client{core: backend}
+
+		_, err := client.CurrentDepositAddress("BTC")
+		if err != ErrNoDepositAddress {
+			t.Errorf("want ErrNoDepositAddress but got `%v`", err)
+		}
+	})
+}
+
+func TestClient_RotateDepositAddress(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{ "data": { "generateDepositAddress": "new-address" } }`,
+	}
+	client := &Client{core: backend}
+
+	addr, err := client.RotateDepositAddress("BTC")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if addr != "new-address" {
+		t.Errorf("want new-address but got `%s`", addr)
+	}
+	if !isMutation(backend.request.Query) {
+		t.Error("want rotation to be mutation")
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// depositsRequestVariables is a subscription variables used in request
+// in client SubscribeDeposits method.
+type depositsRequestVariables struct {
+	Assets []string `json:"assets"`
+}
+
+// SubscribeDeposits returns the channel of new deposits of the assets
+// as they are detected. Deposits are streamed over the exchange GraphQL
+// WebSocket endpoint; if dialing fails repeatedly the subscription
+// falls back to polling of the latest deposit records, which are
+// delivered in chronological order once each. The channel is closed
+// once the client context is done, see WithContext.
+func (c *Client) SubscribeDeposits(assets []string) (<-chan Deposit, error) {
+	if len(assets) == 0 {
+		return nil, errors.New("assets should be given")
+	}
+
+	ctx := c.context()
+	deposits := make(chan Deposit, c.subs.buffer())
+
+	var req request
+	req.Query = `
+		subscription Deposits($assets: [Asset!]!) {
+			deposits(assets: $assets) {
+				asset
+				change
+				time
+				paymentID
+				paymentType
+			}
+		}
+	`
+	req.Variables = depositsRequestVariables{
+		Assets: c.assets.assets(assets),
+	}
+
+	// seen is the deposits delivered at the cursor time, as records
+	// of the same time are polled again.
+	seen := make(map[string]int64)
+
+	sub := subscription{
+		name:    "deposits",
+		request: req,
+		decode: func(payload json.RawMessage) ([]interface{}, error) {
+			data := struct {
+				Deposit Deposit `json:"deposits"`
+			}{}
+			if err := json.Unmarshal(payload, &data); err != nil {
+				return nil, err
+			}
+			data.Deposit.Asset = c.assets.localAsset(data.Deposit.Asset)
+			return []interface{}{data.Deposit}, nil
+		},
+		// The cursor is the time of the latest delivered deposit in
+		// milliseconds.
+		poll: func(cursor int64) ([]interface{}, int64, error) {
+			updates, err := c.BalanceUpdates(assets,
+				[]RecordType{RecordDeposit}, 0, int64(c.subs.pollLimit()))
+			if err != nil {
+				return nil, cursor, err
+			}
+
+			var records []Deposit
+			for _, u := range updates {
+				if u.Deposit != nil {
+					records = append(records, *u.Deposit)
+				}
+			}
+			sort.SliceStable(records, func(i, j int) bool {
+				return records[i].Time < records[j].Time
+			})
+
+			var events []interface{}
+			next := cursor
+			for _, d := range records {
+				t := int64(d.Time * 1000)
+				key := d.Asset + ":" + d.PaymentID
+				if _, ok := seen[key]; ok || t < cursor {
+					continue
+				}
+				seen[key] = t
+				events = append(events, d)
+				if t > next {
+					next = t
+				}
+			}
+
+			for key, t := range seen {
+				if t < next {
+					delete(seen, key)
+				}
+			}
+			return events, next, nil
+		},
+	}
+
+	go func() {
+		defer close(deposits)
+		defer c.errs.recover("deposits")
+		c.subs.run(ctx, sub, func(event interface{}) bool {
+			select {
+			case deposits <- event.(Deposit):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return deposits, nil
+}
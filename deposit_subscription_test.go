@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_SubscribeDeposits(t *testing.T) {
+	var (
+		mtx   sync.Mutex
+		polls int
+	)
+	responses := []string{
+		`{ "data": { "balanceUpdateRecords": [
+			{ "__typename": "Deposit", "asset": "BTC", "time": 1,
+				"paymentID": "a" }] } }`,
+		`{ "data": { "balanceUpdateRecords": [
+			{ "__typename": "Deposit", "asset": "ETH", "time": 2,
+				"paymentID": "c" },
+			{ "__typename": "Deposit", "asset": "BTC", "time": 2,
+				"paymentID": "b" },
+			{ "__typename": "Deposit", "asset": "BTC", "time": 1,
+				"paymentID": "a" }] } }`,
+		`{ "data": { "balanceUpdateRecords": [
+			{ "__typename": "Deposit", "asset": "BTC", "time": 2,
+				"paymentID": "d" },
+			{ "__typename": "Deposit", "asset": "ETH", "time": 2,
+				"paymentID": "c" },
+			{ "__typename": "Deposit", "asset": "BTC", "time": 2,
+				"paymentID": "b" }] } }`,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := (&Client{
+		core: CoreFunc(func(string, interface{}) ([]byte, error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			resp := responses[len(responses)-1]
+			if polls < len(responses) {
+				resp = responses[polls]
+			}
+			polls++
+			return []byte(resp), nil
+		}),
+		subs: newSubscriptions(SubscriptionConfig{
+			PollInterval: time.Millisecond,
+		}, nil, nopMetrics{}),
+	}).WithContext(ctx)
+
+	if _, err := client.SubscribeDeposits(nil); err == nil {
+		t.Error("want error of no assets")
+	}
+
+	deposits, err := client.SubscribeDeposits([]string{"BTC", "ETH"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	for _, want := range []string{"ETH:c", "BTC:b", "BTC:d"} {
+		select {
+		case d := <-deposits:
+			if got := d.Asset + ":" + d.PaymentID; got != want {
+				t.Fatalf("want deposit %v but got %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("want deposit %v but got timeout", want)
+		}
+	}
+
+	select {
+	case d := <-deposits:
+		t.Errorf("want deposits delivered once but got %v", d)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	for range deposits {
+	}
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Version is the version of the client which is sent to the
+	// exchange in ClientVersionHeader, so server may negotiate API
+	// version and report deprecations relevant to the client.
+	Version = "1.0.0"
+
+	// ClientVersionHeader is the header with the client version.
+	ClientVersionHeader = "X-Client-Version"
+)
+
+// Deprecation is the warning about deprecated API reported by the
+// exchange server either in Warning, Deprecation and Sunset headers or
+// in "deprecations" GraphQL response extension.
+type Deprecation struct {
+	// Message describes deprecated API and its replacement.
+	Message string
+
+	// Field is the deprecated GraphQL field, empty if deprecation is
+	// reported in headers.
+	Field string
+
+	// Sunset is the time after which deprecated API may be removed,
+	// zero if unknown.
+	Sunset time.Time
+}
+
+func (d Deprecation) String() string {
+	msg := d.Message
+	if d.Field != "" {
+		msg = d.Field + ": " + msg
+	}
+	if !d.Sunset.IsZero() {
+		msg += ", sunset at " + d.Sunset.Format(time.RFC3339)
+	}
+	return msg
+}
+
+// WithDeprecationHandler sets the function invoked once per distinct
+// deprecation reported by the exchange server. By default deprecations
+// are logged with the standard logger.
+func WithDeprecationHandler(h func(d Deprecation)) Option {
+	return func(o *options) {
+		o.deprecationHandler = h
+	}
+}
+
+// logDeprecation is the default deprecation handler.
+func logDeprecation(d Deprecation) {
+	log.Printf("exchange API deprecation: %s", d)
+}
+
+// warningRe matches warning value of Warning header, e.g.
+// `299 - "Deprecated field"`, capturing the code and the text.
+var warningRe = regexp.MustCompile(`(\d{3}) \S+ "((?:[^"\\]|\\.)*)"`)
+
+// deprecations collects distinct deprecations reported by the server.
+type deprecations struct {
+	handler func(d Deprecation)
+
+	mtx  sync.Mutex
+	seen map[Deprecation]struct{}
+	list []Deprecation
+}
+
+// newDeprecations creates new collector invoking handler once per
+// distinct deprecation, nil handler means logDeprecation.
+func newDeprecations(handler func(d Deprecation)) *deprecations {
+	if handler == nil {
+		handler = logDeprecation
+	}
+	return &deprecations{
+		handler: handler,
+		seen:    make(map[Deprecation]struct{}),
+	}
+}
+
+// observe collects deprecations of the response with given body.
+func (d *deprecations) observe(resp *http.Response, body []byte) {
+	if d == nil {
+		return
+	}
+
+	for _, w := range resp.Header["Warning"] {
+		for _, m := range warningRe.FindAllStringSubmatch(w, -1) {
+			d.add(Deprecation{
+				Message: strings.Replace(m[2], `\"`, `"`, -1),
+			})
+		}
+	}
+
+	if v := resp.Header.Get("Deprecation"); v != "" && v != "false" {
+		dep := Deprecation{Message: "endpoint is deprecated"}
+		if sunset := resp.Header.Get("Sunset"); sunset != "" {
+			dep.Sunset, _ = http.ParseTime(sunset)
+		}
+		d.add(dep)
+	}
+
+	if !bytes.Contains(body, []byte(`"deprecations"`)) {
+		return
+	}
+	var payload struct {
+		Extensions struct {
+			Deprecations []struct {
+				Message string `json:"message"`
+				Field   string `json:"field"`
+			} `json:"deprecations"`
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return
+	}
+	for _, e := range payload.Extensions.Deprecations {
+		d.add(Deprecation{Message: e.Message, Field: e.Field})
+	}
+}
+
+// add stores deprecation and invokes handler if it is seen first time.
+func (d *deprecations) add(dep Deprecation) {
+	d.mtx.Lock()
+	if _, ok := d.seen[dep]; ok {
+		d.mtx.Unlock()
+		return
+	}
+	d.seen[dep] = struct{}{}
+	d.list = append(d.list, dep)
+	d.mtx.Unlock()
+
+	d.handler(dep)
+}
+
+// Deprecations returns distinct deprecations reported by the exchange
+// server during the client session in the order of appearance.
+func (c *Client) Deprecations() []Deprecation {
+	if c.deprecations == nil {
+		return nil
+	}
+
+	c.deprecations.mtx.Lock()
+	defer c.deprecations.mtx.Unlock()
+
+	res := make([]Deprecation, len(c.deprecations.list))
+	copy(res, c.deprecations.list)
+	return res
+}
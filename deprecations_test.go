@@ -0,0 +1,82 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Deprecations(t *testing.T) {
+	var gotVersion string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+
+		gotVersion = r.Header.Get(ClientVersionHeader)
+		w.Header().Add("Warning", `299 - "markets(period) is deprecated"`)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", "Sat, 01 Jan 2022 00:00:00 GMT")
+		w.Write([]byte(`{"data": {"markets": []}, "extensions": {
+			"deprecations": [{"field": "changeLow",
+			"message": "use low instead"}]}}`))
+	}))
+	defer s.Close()
+
+	var handled []Deprecation
+	client, err := NewClient(s.URL, "", "",
+		WithDeprecationHandler(func(d Deprecation) {
+			handled = append(handled, d)
+		}))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Markets([]string{"BTCETH"}, 0); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+	}
+
+	if gotVersion != Version {
+		t.Errorf("want client version `%s` but got `%s`", Version,
+			gotVersion)
+	}
+
+	want := []Deprecation{{
+		Message: "markets(period) is deprecated",
+	}, {
+		Message: "endpoint is deprecated",
+		Sunset:  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, {
+		Message: "use low instead",
+		Field:   "changeLow",
+	}}
+	got := client.Deprecations()
+	if len(got) != len(want) {
+		t.Fatalf("want deprecations `%v` but got `%v`", want, got)
+	}
+	for i := range want {
+		if got[i].String() != want[i].String() {
+			t.Errorf("want deprecation `%s` but got `%s`", want[i], got[i])
+		}
+	}
+	if !reflect.DeepEqual(got, handled) {
+		t.Errorf("want every deprecation handled once but got `%v`",
+			handled)
+	}
+}
+
+func TestDeprecations_WarningHeader(t *testing.T) {
+	d := newDeprecations(func(Deprecation) {})
+	resp := &http.Response{Header: http.Header{
+		"Warning": {`299 api.exchange "first \"quoted\"", ` +
+			`299 - "second" "Sat, 01 Jan 2022 00:00:00 GMT"`},
+	}}
+	d.observe(resp, nil)
+
+	if len(d.list) != 2 || d.list[0].Message != `first "quoted"` ||
+		d.list[1].Message != "second" {
+		t.Errorf("want two warnings but got `%v`", d.list)
+	}
+}
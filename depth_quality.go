@@ -0,0 +1,116 @@
+package client
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+var (
+	onePercent  = decimal.New(1, -2)
+	fivePercent = decimal.New(5, -2)
+	bpsInOne    = decimal.New(1, 4)
+	decimalTwo  = decimal.New(2, 0)
+	decimalOne  = decimal.New(1, 0)
+)
+
+// DepthQuality is a summary of the order book depth used to decide
+// whether the market is liquid enough to quote.
+type DepthQuality struct {
+	// AskLevels is the number of ask price levels.
+	AskLevels int
+
+	// BidLevels is the number of bid price levels.
+	BidLevels int
+
+	// TwoSided is true if depth has both asks and bids, otherwise
+	// the mid price based fields are zero.
+	TwoSided bool
+
+	// BestAsk is the lowest ask price.
+	BestAsk decimal.Decimal
+
+	// BestBid is the highest bid price.
+	BestBid decimal.Decimal
+
+	// Mid is the average of the best ask and best bid prices.
+	Mid decimal.Decimal
+
+	// SpreadBps is the difference between best ask and best bid
+	// prices in basis points of the mid price.
+	SpreadBps decimal.Decimal
+
+	// AskVolume1Pct is the cumulative volume of asks with price within
+	// 1% above the mid price.
+	AskVolume1Pct decimal.Decimal
+
+	// BidVolume1Pct is the cumulative volume of bids with price within
+	// 1% below the mid price.
+	BidVolume1Pct decimal.Decimal
+
+	// AskVolume5Pct is the cumulative volume of asks with price within
+	// 5% above the mid price.
+	AskVolume5Pct decimal.Decimal
+
+	// BidVolume5Pct is the cumulative volume of bids with price within
+	// 5% below the mid price.
+	BidVolume5Pct decimal.Decimal
+}
+
+// Quality returns the depth quality metrics: number of levels, spread
+// and cumulative volumes near the mid price.
+func (d Depth) Quality() DepthQuality {
+	q := DepthQuality{
+		AskLevels: len(d.Asks),
+		BidLevels: len(d.Bids),
+	}
+
+	if len(d.Asks) == 0 || len(d.Bids) == 0 {
+		return q
+	}
+
+	q.TwoSided = true
+
+	q.BestAsk = d.Asks[0].Price
+	for _, a := range d.Asks[1:] {
+		if a.Price.LessThan(q.BestAsk) {
+			q.BestAsk = a.Price
+		}
+	}
+
+	q.BestBid = d.Bids[0].Price
+	for _, b := range d.Bids[1:] {
+		if b.Price.GreaterThan(q.BestBid) {
+			q.BestBid = b.Price
+		}
+	}
+
+	q.Mid = q.BestAsk.Add(q.BestBid).Div(decimalTwo)
+	if q.Mid.Sign() <= 0 {
+		return q
+	}
+
+	q.SpreadBps = q.BestAsk.Sub(q.BestBid).Div(q.Mid).Mul(bpsInOne)
+
+	askLimit1 := q.Mid.Mul(decimalOne.Add(onePercent))
+	askLimit5 := q.Mid.Mul(decimalOne.Add(fivePercent))
+	for _, a := range d.Asks {
+		if a.Price.LessThanOrEqual(askLimit1) {
+			q.AskVolume1Pct = q.AskVolume1Pct.Add(a.Volume)
+		}
+		if a.Price.LessThanOrEqual(askLimit5) {
+			q.AskVolume5Pct = q.AskVolume5Pct.Add(a.Volume)
+		}
+	}
+
+	bidLimit1 := q.Mid.Mul(decimalOne.Sub(onePercent))
+	bidLimit5 := q.Mid.Mul(decimalOne.Sub(fivePercent))
+	for _, b := range d.Bids {
+		if b.Price.GreaterThanOrEqual(bidLimit1) {
+			q.BidVolume1Pct = q.BidVolume1Pct.Add(b.Volume)
+		}
+		if b.Price.GreaterThanOrEqual(bidLimit5) {
+			q.BidVolume5Pct = q.BidVolume5Pct.Add(b.Volume)
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestDepth_Quality(t *testing.T) {
+	t.Run("when one-sided depth", func(t *testing.T) {
+		q := Depth{Asks: []Ask{{Price: dec(1), Volume: dec(1)}}}.Quality()
+		if q.TwoSided {
+			t.Error("want one-sided quality")
+		}
+		if q.AskLevels != 1 || q.BidLevels != 0 {
+			t.Errorf("want 1 ask and 0 bid levels but got %v and %v",
+				q.AskLevels, q.BidLevels)
+		}
+	})
+	t.Run("when two-sided depth", func(t *testing.T) {
+		d := Depth{
+			Asks: []Ask{
+				{Price: dec(101), Volume: dec(1)},
+				{Price: dec(104), Volume: dec(2)},
+				{Price: dec(110), Volume: dec(4)},
+			},
+			Bids: []Bid{
+				{Price: dec(99), Volume: dec(3)},
+				{Price: dec(96), Volume: dec(5)},
+				{Price: dec(90), Volume: dec(7)},
+			},
+		}
+		q := d.Quality()
+		if !q.TwoSided {
+			t.Fatal("want two-sided quality")
+		}
+
+		checks := []struct {
+			name string
+			got  string
+			want string
+		}{
+			{"best ask", q.BestAsk.String(), "101"},
+			{"best bid", q.BestBid.String(), "99"},
+			{"mid", q.Mid.String(), "100"},
+			{"spread bps", q.SpreadBps.String(), "200"},
+			{"ask volume 1%", q.AskVolume1Pct.String(), "1"},
+			{"ask volume 5%", q.AskVolume5Pct.String(), "3"},
+			{"bid volume 1%", q.BidVolume1Pct.String(), "3"},
+			{"bid volume 5%", q.BidVolume5Pct.String(), "8"},
+		}
+		for _, c := range checks {
+			if c.got != c.want {
+				t.Errorf("want %s `%s` but got `%s`", c.name, c.want,
+					c.got)
+			}
+		}
+	})
+}
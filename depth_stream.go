@@ -0,0 +1,379 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrBookChanged is returned by PlaceIfBookUnchanged if the order book
+// advanced past the sequence number the order is based on.
+var ErrBookChanged = errors.New("order book changed")
+
+// depthUpdate is the update of the order book. Snapshot update replaces
+// the whole book, otherwise levels are replaced one by one and levels
+// of zero volume are removed. Seq is the sequence number of the update
+// assigned by the server, zero if it is unknown.
+type depthUpdate struct {
+	Seq      int64 `json:"seq"`
+	Snapshot bool  `json:"snapshot"`
+	Asks     []Ask `json:"asks"`
+	Bids     []Bid `json:"bids"`
+}
+
+// depthUpdatesRequestVariables is a subscription variables used in
+// request in client SubscribeDepth method.
+type depthUpdatesRequestVariables struct {
+	Market string `json:"market"`
+}
+
+// OrderBookStream is the order book of the market reconstructed locally
+// from streamed updates. It is safe for concurrent use.
+type OrderBookStream struct {
+	market string
+
+	// client places orders guarded by the book, see
+	// PlaceIfBookUnchanged.
+	client *Client
+
+	mtx sync.RWMutex
+
+	// seq is the sequence number of the last applied update.
+	seq int64
+
+	// seqOffset is added to the server sequence numbers, so the book
+	// sequence keeps growing after the server sequence is reset.
+	seqOffset int64
+
+	// asks and bids are the volumes of the book levels by their
+	// prices.
+	asks map[string]Ask
+	bids map[string]Bid
+
+	// updates is closed and replaced on every applied update.
+	updates chan struct{}
+
+	done chan struct{}
+}
+
+// newOrderBookStream creates new order book of the market.
+func newOrderBookStream(market string) *OrderBookStream {
+	return &OrderBookStream{
+		market:  market,
+		asks:    make(map[string]Ask),
+		bids:    make(map[string]Bid),
+		updates: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Market returns the market of the order book.
+func (s *OrderBookStream) Market() string {
+	return s.market
+}
+
+// BestAsk returns the ask of the lowest price, false is returned if
+// there are no asks.
+func (s *OrderBookStream) BestAsk() (Ask, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	var (
+		best  Ask
+		found bool
+	)
+	for _, a := range s.asks {
+		if !found || a.Price.LessThan(best.Price) {
+			best, found = a, true
+		}
+	}
+	return best, found
+}
+
+// BestBid returns the bid of the highest price, false is returned if
+// there are no bids.
+func (s *OrderBookStream) BestBid() (Bid, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	var (
+		best  Bid
+		found bool
+	)
+	for _, b := range s.bids {
+		if !found || b.Price.GreaterThan(best.Price) {
+			best, found = b, true
+		}
+	}
+	return best, found
+}
+
+// Snapshot returns the copy of the whole order book, asks by
+// increasing and bids by decreasing price.
+func (s *OrderBookStream) Snapshot() Depth {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	depth := Depth{
+		Asks: make([]Ask, 0, len(s.asks)),
+		Bids: make([]Bid, 0, len(s.bids)),
+	}
+	for _, a := range s.asks {
+		depth.Asks = append(depth.Asks, a)
+	}
+	for _, b := range s.bids {
+		depth.Bids = append(depth.Bids, b)
+	}
+
+	sort.Slice(depth.Asks, func(i, j int) bool {
+		return depth.Asks[i].Price.LessThan(depth.Asks[j].Price)
+	})
+	sort.Slice(depth.Bids, func(i, j int) bool {
+		return depth.Bids[i].Price.GreaterThan(depth.Bids[j].Price)
+	})
+	return depth
+}
+
+// Seq returns the sequence number of the book, i.e. of the last applied
+// update. It follows the server sequence number if updates carry it,
+// otherwise the book counts the updates which change it, e.g. polled
+// snapshots which differ from the book. Either way it grows whenever
+// the book changes and never goes down, see PlaceIfBookUnchanged.
+func (s *OrderBookStream) Seq() int64 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.seq
+}
+
+// PlaceIfBookUnchanged places the order only if the book has not
+// advanced past the sequence number seenSeq, which is the Seq the
+// decision to place the order is based on, otherwise ErrBookChanged is
+// returned. It reduces executions at stale prices, though the book may
+// still change while the order is in flight.
+func (s *OrderBookStream) PlaceIfBookUnchanged(intent OrderIntent,
+	seenSeq int64) (Order, error) {
+
+	if intent.Market != s.market {
+		return Order{}, errors.New("order market " + intent.Market +
+			" differs from book market " + s.market)
+	}
+	if s.client == nil {
+		return Order{}, errors.New("book is not subscribed")
+	}
+
+	if s.Seq() > seenSeq {
+		return Order{}, ErrBookChanged
+	}
+
+	return s.client.PlaceOrder(intent)
+}
+
+// Updated returns the channel which is closed once the next update is
+// applied to the book.
+func (s *OrderBookStream) Updated() <-chan struct{} {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.updates
+}
+
+// Done returns the channel which is closed once the stream is stopped,
+// i.e. the client context is done. The book is not updated afterwards.
+func (s *OrderBookStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// apply applies the update to the book. Updates of the server sequence
+// number not greater than the applied one are stale or duplicate and
+// are dropped, except snapshots, which mean the server sequence is
+// reset, e.g. after reconnect. Updates without the sequence number
+// advance the book sequence only if they change the book.
+func (s *OrderBookStream) apply(u depthUpdate) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	seq := u.Seq
+	if seq > 0 {
+		seq += s.seqOffset
+		if seq <= s.seq {
+			if !u.Snapshot {
+				return
+			}
+
+			// Reset server sequence is shifted, so the book sequence
+			// never goes down.
+			s.seqOffset += s.seq + 1 - seq
+			seq = s.seq + 1
+		}
+	}
+
+	asks, bids := s.asks, s.bids
+	if u.Snapshot {
+		asks = make(map[string]Ask, len(u.Asks))
+		bids = make(map[string]Bid, len(u.Bids))
+	}
+
+	var changed bool
+	for _, a := range u.Asks {
+		if setAsk(asks, a) {
+			changed = true
+		}
+	}
+	for _, b := range u.Bids {
+		if setBid(bids, b) {
+			changed = true
+		}
+	}
+	if u.Snapshot {
+		changed = !sameAsks(s.asks, asks) || !sameBids(s.bids, bids)
+	}
+	s.asks, s.bids = asks, bids
+
+	if seq == 0 {
+		if !changed {
+			return
+		}
+		seq = s.seq + 1
+	}
+	s.seq = seq
+
+	close(s.updates)
+	s.updates = make(chan struct{})
+}
+
+// setAsk replaces the level of the ask price, removing it if the ask
+// volume is zero, and returns true if the level changed.
+func setAsk(levels map[string]Ask, a Ask) bool {
+	key := depthLevelKey(a.Price)
+	old, ok := levels[key]
+	if a.Volume.Sign() <= 0 {
+		delete(levels, key)
+		return ok
+	}
+	levels[key] = a
+	return !ok || !old.Volume.Equal(a.Volume)
+}
+
+// setBid replaces the level of the bid price, see setAsk.
+func setBid(levels map[string]Bid, b Bid) bool {
+	key := depthLevelKey(b.Price)
+	old, ok := levels[key]
+	if b.Volume.Sign() <= 0 {
+		delete(levels, key)
+		return ok
+	}
+	levels[key] = b
+	return !ok || !old.Volume.Equal(b.Volume)
+}
+
+// sameAsks returns true if the ask levels have equal volumes.
+func sameAsks(a, b map[string]Ask) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, level := range a {
+		other, ok := b[key]
+		if !ok || !other.Volume.Equal(level.Volume) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameBids returns true if the bid levels have equal volumes.
+func sameBids(a, b map[string]Bid) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, level := range a {
+		other, ok := b[key]
+		if !ok || !other.Volume.Equal(level.Volume) {
+			return false
+		}
+	}
+	return true
+}
+
+// depthLevelKey returns the key of the book level of the price, equal
+// prices of different scale share the key.
+func depthLevelKey(price decimal.Decimal) string {
+	return price.String()
+}
+
+// SubscribeDepth returns the order book of the market which is kept up
+// to date with the depth updates subscription, or with polling of
+// Depth if streaming is unavailable. The book is requested once before
+// returning to establish the baseline, its error is returned. Updates
+// are applied until the client context is done, see WithContext.
+func (c *Client) SubscribeDepth(market string) (*OrderBookStream, error) {
+	ctx := c.context()
+
+	depth, err := c.Depth(market, uint(c.subs.pollLimit()), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	book := newOrderBookStream(market)
+	book.client = c
+	book.apply(depthUpdate{Snapshot: true, Asks: depth.Asks,
+		Bids: depth.Bids})
+
+	var req request
+	req.Query = `
+		subscription DepthUpdates($market: Market!) {
+			depthUpdates(market: $market) {
+				seq
+				snapshot
+				asks {
+					price
+					volume
+				}
+				bids {
+					price
+					volume
+				}
+			}
+		}
+	`
+	req.Variables = depthUpdatesRequestVariables{c.assets.market(market)}
+
+	sub := subscription{
+		name:    "depth",
+		request: req,
+		decode: func(payload json.RawMessage) ([]interface{}, error) {
+			data := struct {
+				DepthUpdates depthUpdate `json:"depthUpdates"`
+			}{}
+			if err := json.Unmarshal(payload, &data); err != nil {
+				return nil, err
+			}
+			return []interface{}{data.DepthUpdates}, nil
+		},
+		// Every poll yields the snapshot of the book, so the cursor
+		// only counts polls.
+		poll: func(cursor int64) ([]interface{}, int64, error) {
+			depth, err := c.Depth(market, uint(c.subs.pollLimit()), 0)
+			if err != nil {
+				return nil, cursor, err
+			}
+			return []interface{}{depthUpdate{
+				Snapshot: true,
+				Asks:     depth.Asks,
+				Bids:     depth.Bids,
+			}}, cursor + 1, nil
+		},
+	}
+
+	go func() {
+		defer close(book.done)
+		defer c.errs.recover("depth")
+		c.subs.run(ctx, sub, func(event interface{}) bool {
+			book.apply(event.(depthUpdate))
+			return ctx.Err() == nil
+		})
+	}()
+
+	return book, nil
+}
@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedDialer opens streams which yield given payloads and then
+// block until the context is done.
+type scriptedDialer struct {
+	payloads []string
+}
+
+func (d *scriptedDialer) dial(context.Context, request) (eventStream, error) {
+	return &scriptedStream{payloads: d.payloads}, nil
+}
+
+type scriptedStream struct {
+	payloads []string
+}
+
+func (s *scriptedStream) next(ctx context.Context) (json.RawMessage, error) {
+	if len(s.payloads) == 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	payload := s.payloads[0]
+	s.payloads = s.payloads[1:]
+	return json.RawMessage(payload), nil
+}
+
+func (s *scriptedStream) close() error {
+	return nil
+}
+
+func TestOrderBookStream_apply(t *testing.T) {
+	book := newOrderBookStream("BTCETH")
+
+	if _, ok := book.BestAsk(); ok {
+		t.Error("want no best ask of empty book")
+	}
+
+	updated := book.Updated()
+	book.apply(depthUpdate{
+		Snapshot: true,
+		Asks:     []Ask{{dec(3), dec(1)}, {dec(2), dec(1)}},
+		Bids:     []Bid{{dec(1), dec(1)}},
+	})
+	select {
+	case <-updated:
+	default:
+		t.Error("want update to be signalled")
+	}
+
+	book.apply(depthUpdate{
+		Asks: []Ask{{dec(2), dec(0)}, {dec(4), dec(5)}},
+		Bids: []Bid{{dec(1.5), dec(2)}, {dec(1), dec(3)}},
+	})
+
+	if ask, ok := book.BestAsk(); !ok || !ask.Price.Equal(dec(3)) {
+		t.Errorf("want best ask 3 but got `%v`", ask)
+	}
+	if bid, ok := book.BestBid(); !ok || !bid.Price.Equal(dec(1.5)) {
+		t.Errorf("want best bid 1.5 but got `%v`", bid)
+	}
+
+	depth := book.Snapshot()
+	if len(depth.Asks) != 2 || !depth.Asks[1].Price.Equal(dec(4)) {
+		t.Errorf("want asks by increasing price but got `%v`", depth.Asks)
+	}
+	if len(depth.Bids) != 2 || !depth.Bids[1].Volume.Equal(dec(3)) {
+		t.Errorf("want replaced bid level but got `%v`", depth.Bids)
+	}
+
+	book.apply(depthUpdate{Snapshot: true, Bids: []Bid{{dec(1), dec(1)}}})
+	if depth := book.Snapshot(); len(depth.Asks) != 0 ||
+		len(depth.Bids) != 1 {
+		t.Errorf("want book replaced with snapshot but got `%v`", depth)
+	}
+}
+
+func TestClient_SubscribeDepth(t *testing.T) {
+	t.Run("when streamed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		client := (&Client{
+			core: &mockCore{respJSON: `{"data": {"depth": {
+				"asks": [{"price": "2", "volume": "1"}],
+				"bids": [{"price": "1", "volume": "1"}]}}}`},
+			subs: newSubscriptions(SubscriptionConfig{},
+				&scriptedDialer{payloads: []string{
+					`{"depthUpdates": {"asks": [
+						{"price": "1.5", "volume": "1"}]}}`,
+				}}, nopMetrics{}),
+		}).WithContext(ctx)
+
+		book, err := client.SubscribeDepth("BTCETH")
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		waitBook(t, book, func() bool {
+			ask, _ := book.BestAsk()
+			return ask.Price.Equal(dec(1.5))
+		})
+		if bid, _ := book.BestBid(); !bid.Price.Equal(dec(1)) {
+			t.Errorf("want baseline bid to be kept but got `%v`", bid)
+		}
+
+		cancel()
+		<-book.Done()
+	})
+	t.Run("when polled", func(t *testing.T) {
+		var (
+			mtx   sync.Mutex
+			polls int
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		client := (&Client{
+			core: CoreFunc(func(string, interface{}) ([]byte, error) {
+				mtx.Lock()
+				defer mtx.Unlock()
+				polls++
+				if polls < 3 {
+					return []byte(`{"data": {"depth": {
+						"asks": [{"price": "2", "volume": "1"}]}}}`), nil
+				}
+				return []byte(`{"data": {"depth": {
+					"bids": [{"price": "1", "volume": "1"}]}}}`), nil
+			}),
+			subs: newSubscriptions(SubscriptionConfig{
+				PollInterval: time.Millisecond,
+			}, nil, nopMetrics{}),
+		}).WithContext(ctx)
+
+		book, err := client.SubscribeDepth("BTCETH")
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if ask, ok := book.BestAsk(); !ok || !ask.Price.Equal(dec(2)) {
+			t.Errorf("want baseline ask but got `%v`", ask)
+		}
+
+		waitBook(t, book, func() bool {
+			_, hasAsk := book.BestAsk()
+			_, hasBid := book.BestBid()
+			return !hasAsk && hasBid
+		})
+
+		cancel()
+		<-book.Done()
+	})
+	t.Run("when baseline fails", func(t *testing.T) {
+		client := &Client{core: &mockCore{error: errors.New("fail")}}
+		if _, err := client.SubscribeDepth("BTCETH"); err == nil {
+			t.Error("want error of baseline request")
+		}
+	})
+}
+
+// waitBook waits until the book satisfies the condition.
+func waitBook(t *testing.T, book *OrderBookStream, cond func() bool) {
+	t.Helper()
+
+	timeout := time.After(time.Second)
+	for {
+		updated := book.Updated()
+		if cond() {
+			return
+		}
+		select {
+		case <-updated:
+		case <-timeout:
+			t.Fatalf("want book to be updated but got `%v`",
+				book.Snapshot())
+		}
+	}
+}
+
+func TestOrderBookStream_PlaceIfBookUnchanged(t *testing.T) {
+	core := &mockCore{respJSON: `{"data": {"createMarketOrder": {"id": 1}}}`}
+	book := newOrderBookStream("BTCETH")
+	book.client = &Client{core: core}
+
+	book.apply(depthUpdate{Seq: 10, Snapshot: true})
+	seen := book.Seq()
+
+	intent := OrderIntent{Market: "BTCETH", Side: OrderBid, Amount: dec(1)}
+	if _, err := book.PlaceIfBookUnchanged(intent, seen); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	book.apply(depthUpdate{Bids: []Bid{{dec(1), dec(1)}}})
+	if seq := book.Seq(); seq != 11 {
+		t.Errorf("want local sequence 11 but got %v", seq)
+	}
+
+	core.request = request{}
+	_, err := book.PlaceIfBookUnchanged(intent, seen)
+	if err != ErrBookChanged {
+		t.Errorf("want book changed error but got `%v`", err)
+	}
+	if core.request.Query != "" {
+		t.Error("want order not to be placed")
+	}
+
+	intent.Market = "BTCLTC"
+	if _, err := book.PlaceIfBookUnchanged(intent, book.Seq()); err == nil {
+		t.Error("want error of order of other market")
+	}
+}
+
+func TestOrderBookStream_apply_outOfOrder(t *testing.T) {
+	book := newOrderBookStream("BTCETH")
+	book.apply(depthUpdate{Seq: 10, Snapshot: true,
+		Bids: []Bid{{dec(1), dec(1)}}})
+	book.apply(depthUpdate{Seq: 12, Bids: []Bid{{dec(1), dec(2)}}})
+
+	// Stale and duplicate updates are dropped.
+	updated := book.Updated()
+	book.apply(depthUpdate{Seq: 11, Bids: []Bid{{dec(1), dec(5)}}})
+	book.apply(depthUpdate{Seq: 12, Bids: []Bid{{dec(1), dec(5)}}})
+	if seq := book.Seq(); seq != 12 {
+		t.Errorf("want sequence 12 but got %v", seq)
+	}
+	if bid, _ := book.BestBid(); !bid.Volume.Equal(dec(2)) {
+		t.Errorf("want stale update dropped but got `%v`", bid)
+	}
+	select {
+	case <-updated:
+		t.Error("want dropped update not to be signalled")
+	default:
+	}
+
+	// Snapshot after the server sequence reset keeps the sequence
+	// growing, and so do the updates following it.
+	book.apply(depthUpdate{Seq: 1, Snapshot: true,
+		Bids: []Bid{{dec(1), dec(3)}}})
+	if seq := book.Seq(); seq != 13 {
+		t.Errorf("want sequence 13 after reset but got %v", seq)
+	}
+	book.apply(depthUpdate{Seq: 2, Bids: []Bid{{dec(1), dec(4)}}})
+	if seq := book.Seq(); seq != 14 {
+		t.Errorf("want sequence 14 but got %v", seq)
+	}
+	if bid, _ := book.BestBid(); !bid.Volume.Equal(dec(4)) {
+		t.Errorf("want update after reset applied but got `%v`", bid)
+	}
+}
+
+func TestOrderBookStream_apply_polled(t *testing.T) {
+	core := &mockCore{respJSON: `{"data": {"createMarketOrder": {"id": 1}}}`}
+	book := newOrderBookStream("BTCETH")
+	book.client = &Client{core: core}
+
+	snapshot := depthUpdate{
+		Snapshot: true,
+		Asks:     []Ask{{dec(2), dec(1)}},
+		Bids:     []Bid{{dec(1), dec(1)}},
+	}
+	book.apply(snapshot)
+	seen := book.Seq()
+
+	// Polls of the unchanged book don't advance the sequence.
+	for i := 0; i < 3; i++ {
+		book.apply(snapshot)
+	}
+	if seq := book.Seq(); seq != seen {
+		t.Errorf("want sequence %v but got %v", seen, seq)
+	}
+	intent := OrderIntent{Market: "BTCETH", Side: OrderBid, Amount: dec(1)}
+	if _, err := book.PlaceIfBookUnchanged(intent, seen); err != nil {
+		t.Errorf("want no error but got `%v`", err)
+	}
+
+	book.apply(depthUpdate{Snapshot: true, Asks: snapshot.Asks})
+	if seq := book.Seq(); seq != seen+1 {
+		t.Errorf("want sequence %v but got %v", seen+1, seq)
+	}
+	if _, err := book.PlaceIfBookUnchanged(intent, seen); err != ErrBookChanged {
+		t.Errorf("want book changed error but got `%v`", err)
+	}
+}
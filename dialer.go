@@ -0,0 +1,387 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// IPPreference is a preference of the IP address family used when the
+// exchange host resolves to both IPv4 and IPv6 addresses.
+type IPPreference int
+
+const (
+	// PreferAny keeps the order of addresses returned by the resolver.
+	PreferAny IPPreference = iota
+
+	// PreferIPv4 tries IPv4 addresses first and falls back to IPv6.
+	PreferIPv4
+
+	// PreferIPv6 tries IPv6 addresses first and falls back to IPv4.
+	PreferIPv6
+
+	// IPv4Only never uses IPv6 addresses.
+	IPv4Only
+
+	// IPv6Only never uses IPv4 addresses.
+	IPv6Only
+)
+
+// defaultFallbackDelay is the Happy Eyeballs delay used by the net
+// package, see RFC 6555.
+const defaultFallbackDelay = 300 * time.Millisecond
+
+// DialerConfig is a configuration of the dialer used to connect to the
+// exchange server.
+type DialerConfig struct {
+	// Timeout is the maximum amount of time a single connection attempt
+	// may take. Zero means no timeout.
+	Timeout time.Duration
+
+	// KeepAlive is the TCP keep-alive period. Zero means default of the
+	// net package, negative disables keep-alive.
+	KeepAlive time.Duration
+
+	// Preference is the preferred IP address family.
+	Preference IPPreference
+
+	// FallbackDelay is the Happy Eyeballs delay before the connection
+	// to the address of the secondary family is started in parallel
+	// with the primary one. Zero means 300ms, negative disables
+	// parallel fallback, addresses are tried one by one then.
+	FallbackDelay time.Duration
+
+	// DNSCacheTTL enables in-process DNS cache, resolved addresses are
+	// reused during this period regardless of TTL of DNS records.
+	// Zero disables the cache.
+	DNSCacheTTL time.Duration
+}
+
+// WithDialer sets the configuration of the dialer used to connect to
+// the exchange server.
+func WithDialer(cfg DialerConfig) Option {
+	return func(o *options) {
+		o.dialer = &cfg
+	}
+}
+
+// WithDNSCache enables in-process DNS cache which reuses resolved
+// addresses of the exchange host during ttl.
+func WithDNSCache(ttl time.Duration) Option {
+	return func(o *options) {
+		if o.dialer == nil {
+			o.dialer = &DialerConfig{}
+		}
+		o.dialer.DNSCacheTTL = ttl
+	}
+}
+
+// DialContextFunc connects to the address on the named network, it has
+// signature of net.Dialer.DialContext.
+type DialContextFunc func(ctx context.Context, network,
+	address string) (net.Conn, error)
+
+// WithDialContext makes the client connect to the exchange server with
+// the function instead of the net package dialer, e.g. to route
+// connections over Tor or VPN-bound interface, or to in-memory listener
+// in tests. It is used by both HTTP requests and WebSocket
+// subscriptions and takes precedence over WithDialer.
+func WithDialContext(fn DialContextFunc) Option {
+	return func(o *options) {
+		o.dialContext = fn
+	}
+}
+
+// dialer dials the exchange server using configured address family
+// preference, Happy Eyeballs fallback and DNS cache.
+type dialer struct {
+	cfg     DialerConfig
+	cache   *dnsCache
+	metrics Metrics
+
+	// lookup resolves host to its addresses.
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// newDialer creates new dialer with given configuration.
+func newDialer(cfg DialerConfig, metrics Metrics) *dialer {
+	d := &dialer{
+		cfg:     cfg,
+		metrics: metrics,
+		lookup:  net.DefaultResolver.LookupIPAddr,
+	}
+	if cfg.DNSCacheTTL > 0 {
+		d.cache = newDNSCache(cfg.DNSCacheTTL)
+	}
+	return d
+}
+
+// DialContext connects to the address on the named network. It has
+// signature of net.Dialer.DialContext to be used in http.Transport.
+func (d *dialer) DialContext(ctx context.Context, network,
+	address string) (net.Conn, error) {
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return d.netDialer().DialContext(ctx, network, address)
+	}
+
+	addrs, err := d.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	primaries, fallbacks := d.partition(addrs)
+	if len(primaries) == 0 {
+		return nil, errors.New("no suitable address found for host " +
+			host)
+	}
+
+	return d.dialParallel(ctx, network, join(primaries, port),
+		join(fallbacks, port))
+}
+
+// netDialer returns dialer which is used for single connection attempt.
+func (d *dialer) netDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   d.cfg.Timeout,
+		KeepAlive: d.cfg.KeepAlive,
+	}
+}
+
+// resolve returns addresses of the host using the cache if enabled.
+func (d *dialer) resolve(ctx context.Context, host string) ([]net.IPAddr,
+	error) {
+
+	if d.cache != nil {
+		if addrs, ok := d.cache.get(host); ok {
+			d.metrics.Add("dns_cache_hits_total", nil, 1)
+			return addrs, nil
+		}
+		d.metrics.Add("dns_cache_misses_total", nil, 1)
+	}
+
+	start := time.Now()
+	addrs, err := d.lookup(ctx, host)
+	d.metrics.Observe("dns_resolve_duration_seconds", nil,
+		time.Since(start).Seconds())
+	if err != nil {
+		d.metrics.Add("dns_resolve_errors_total", nil, 1)
+		return nil, err
+	}
+
+	if d.cache != nil {
+		d.cache.set(host, addrs)
+	}
+
+	return addrs, nil
+}
+
+// partition splits addresses into primary and fallback groups according
+// to the address family preference. If no preference is set the family
+// of the first address is primary one.
+func (d *dialer) partition(addrs []net.IPAddr) (primaries,
+	fallbacks []net.IPAddr) {
+
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+
+	var primaryIsV4 bool
+	switch d.cfg.Preference {
+	case PreferIPv4, IPv4Only:
+		primaryIsV4 = true
+	case PreferIPv6, IPv6Only:
+		primaryIsV4 = false
+	default:
+		primaryIsV4 = addrs[0].IP.To4() != nil
+	}
+
+	for _, a := range addrs {
+		if (a.IP.To4() != nil) == primaryIsV4 {
+			primaries = append(primaries, a)
+		} else {
+			fallbacks = append(fallbacks, a)
+		}
+	}
+
+	switch d.cfg.Preference {
+	case IPv4Only, IPv6Only:
+		return primaries, nil
+	case PreferIPv4, PreferIPv6:
+		if len(primaries) == 0 {
+			return fallbacks, nil
+		}
+	}
+
+	return primaries, fallbacks
+}
+
+// dialParallel races primary and fallback addresses in Happy Eyeballs
+// manner: fallback addresses are started after the fallback delay if
+// the primary ones are not connected yet.
+func (d *dialer) dialParallel(ctx context.Context, network string,
+	primaries, fallbacks []string) (net.Conn, error) {
+
+	delay := d.cfg.FallbackDelay
+	if delay == 0 {
+		delay = defaultFallbackDelay
+	}
+
+	if len(fallbacks) == 0 || delay < 0 {
+		return d.dialSerial(ctx, network, append(primaries,
+			fallbacks...))
+	}
+
+	type dialResult struct {
+		net.Conn
+		err     error
+		primary bool
+		done    bool
+	}
+
+	returned := make(chan struct{})
+	defer close(returned)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult)
+	start := func(primary bool, addrs []string) {
+		conn, err := d.dialSerial(ctx, network, addrs)
+		select {
+		case results <- dialResult{Conn: conn, err: err,
+			primary: primary, done: true}:
+		case <-returned:
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	}
+
+	var primary, fallback dialResult
+
+	go start(true, primaries)
+
+	fallbackTimer := time.NewTimer(delay)
+	defer fallbackTimer.Stop()
+
+	for {
+		select {
+		case <-fallbackTimer.C:
+			go start(false, fallbacks)
+
+		case res := <-results:
+			if res.err == nil {
+				return res.Conn, nil
+			}
+			if res.primary {
+				primary = res
+			} else {
+				fallback = res
+			}
+			if primary.done && fallback.done {
+				return nil, primary.err
+			}
+			if res.primary && fallbackTimer.Stop() {
+				// Primary failed before the fallback delay is
+				// elapsed, there is no reason to wait more.
+				go start(false, fallbacks)
+			}
+		}
+	}
+}
+
+// dialSerial connects to the addresses one by one until the first
+// successful connection.
+func (d *dialer) dialSerial(ctx context.Context, network string,
+	addrs []string) (net.Conn, error) {
+
+	var firstErr error
+	for _, addr := range addrs {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		conn, err := d.netDialer().DialContext(ctx, network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = errors.New("no addresses to dial")
+	}
+	return nil, firstErr
+}
+
+// join joins addresses with port.
+func join(addrs []net.IPAddr, port string) []string {
+	res := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		res = append(res, net.JoinHostPort(a.String(), port))
+	}
+	return res
+}
+
+// dnsCache is an in-process cache of resolved host addresses.
+type dnsCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mtx     sync.Mutex
+	entries map[string]dnsCacheEntry
+}
+
+// dnsCacheEntry is resolved host addresses with time of expiration.
+type dnsCacheEntry struct {
+	addrs   []net.IPAddr
+	expires time.Time
+}
+
+// newDNSCache creates new DNS cache which keeps entries during ttl.
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]dnsCacheEntry),
+	}
+}
+
+// get returns cached addresses of the host if they are not expired.
+func (c *dnsCache) get(host string) ([]net.IPAddr, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.entries[host]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, host)
+		return nil, false
+	}
+	return e.addrs, true
+}
+
+// set stores addresses of the host.
+func (c *dnsCache) set(host string, addrs []net.IPAddr) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.entries[host] = dnsCacheEntry{
+		addrs:   addrs,
+		expires: c.now().Add(c.ttl),
+	}
+}
@@ -0,0 +1,315 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDialer_partition(t *testing.T) {
+	v4 := net.IPAddr{IP: net.ParseIP("127.0.0.1")}
+	v6 := net.IPAddr{IP: net.ParseIP("::1")}
+
+	tests := []struct {
+		name          string
+		preference    IPPreference
+		addrs         []net.IPAddr
+		wantPrimaries []net.IPAddr
+		wantFallbacks []net.IPAddr
+	}{
+		{
+			name:          "any keeps resolver order",
+			preference:    PreferAny,
+			addrs:         []net.IPAddr{v6, v4},
+			wantPrimaries: []net.IPAddr{v6},
+			wantFallbacks: []net.IPAddr{v4},
+		},
+		{
+			name:          "prefer ipv4",
+			preference:    PreferIPv4,
+			addrs:         []net.IPAddr{v6, v4},
+			wantPrimaries: []net.IPAddr{v4},
+			wantFallbacks: []net.IPAddr{v6},
+		},
+		{
+			name:          "prefer ipv4 without ipv4 addresses",
+			preference:    PreferIPv4,
+			addrs:         []net.IPAddr{v6},
+			wantPrimaries: []net.IPAddr{v6},
+		},
+		{
+			name:          "ipv6 only",
+			preference:    IPv6Only,
+			addrs:         []net.IPAddr{v4, v6},
+			wantPrimaries: []net.IPAddr{v6},
+		},
+		{
+			name:       "ipv6 only without ipv6 addresses",
+			preference: IPv6Only,
+			addrs:      []net.IPAddr{v4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDialer(DialerConfig{Preference: tt.preference},
+				nopMetrics{})
+			gotPrimaries, gotFallbacks := d.partition(tt.addrs)
+			if !reflect.DeepEqual(gotPrimaries, tt.wantPrimaries) {
+				t.Errorf("want primaries `%v` but got `%v`",
+					tt.wantPrimaries, gotPrimaries)
+			}
+			if !reflect.DeepEqual(gotFallbacks, tt.wantFallbacks) {
+				t.Errorf("want fallbacks `%v` but got `%v`",
+					tt.wantFallbacks, gotFallbacks)
+			}
+		})
+	}
+}
+
+func TestDialer_DialContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	metrics := &recordingMetrics{}
+	d := newDialer(DialerConfig{
+		Timeout:     time.Second,
+		DNSCacheTTL: time.Minute,
+	}, metrics)
+
+	var lookups int
+	d.lookup = func(ctx context.Context, host string) ([]net.IPAddr,
+		error) {
+		lookups++
+		if host != "exchange.test" {
+			return nil, errors.New("unknown host")
+		}
+		return []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		conn, err := d.DialContext(context.Background(), "tcp",
+			net.JoinHostPort("exchange.test", port))
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		conn.Close()
+	}
+
+	if lookups != 1 {
+		t.Errorf("want 1 lookup but got %v", lookups)
+	}
+	if got := metrics.count("dns_resolve_duration_seconds"); got != 1 {
+		t.Errorf("want 1 resolve duration observation but got %v", got)
+	}
+	if got := metrics.count("dns_cache_hits_total"); got != 1 {
+		t.Errorf("want 1 cache hit but got %v", got)
+	}
+}
+
+func TestDialer_dialParallel_fallback(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	// Closed listener gives unreachable primary address.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closed.Close()
+
+	d := newDialer(DialerConfig{
+		FallbackDelay: time.Hour,
+	}, nopMetrics{})
+
+	conn, err := d.dialParallel(context.Background(), "tcp",
+		[]string{closed.Addr().String()}, []string{l.Addr().String()})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	defer conn.Close()
+
+	if conn.RemoteAddr().String() != l.Addr().String() {
+		t.Errorf("want connection to `%s` but got to `%s`",
+			l.Addr(), conn.RemoteAddr())
+	}
+}
+
+func TestDNSCache(t *testing.T) {
+	now := time.Now()
+	c := newDNSCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	if _, ok := c.get("host"); ok {
+		t.Fatal("want miss on empty cache")
+	}
+
+	want := []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}
+	c.set("host", want)
+
+	got, ok := c.get("host")
+	if !ok {
+		t.Fatal("want hit after set")
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want addresses `%v` but got `%v`", want, got)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("host"); ok {
+		t.Fatal("want miss after ttl is elapsed")
+	}
+}
+
+func TestNewClient_withDialer(t *testing.T) {
+	client, err := NewClient("http://test.url", "", "",
+		WithDialer(DialerConfig{Preference: PreferIPv4}),
+		WithDNSCache(time.Minute))
+	if err != nil {
+		t.Fatalf("want NewClient no error but got `%v`", err)
+	}
+	core := client.core.(*graphQLCore)
+	transport, ok := core.transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("want *http.Transport but got `%T`", core.transport)
+	}
+	if transport.DialContext == nil {
+		t.Fatal("want custom DialContext")
+	}
+}
+
+// memListener is in-memory net.Listener which connections are dialed
+// with its dial method.
+type memListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newMemListener() *memListener {
+	return &memListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *memListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, errors.New("listener is closed")
+	}
+}
+
+func (l *memListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *memListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: "mem", Net: "mem"}
+}
+
+func (l *memListener) dial(ctx context.Context, _, _ string) (net.Conn,
+	error) {
+
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-l.done:
+		return nil, errors.New("listener is closed")
+	}
+}
+
+func TestNewClient_withDialContext(t *testing.T) {
+	l := newMemListener()
+	defer l.Close()
+
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+
+		w.Write([]byte(`{"data": {"info": {"network": "simnet"}}}`))
+	}))
+
+	client, err := NewClient("http://exchange.invalid", "", "",
+		WithDialContext(l.dial),
+		WithDialer(DialerConfig{Preference: PreferIPv4}))
+	if err != nil {
+		t.Fatalf("want NewClient no error but got `%v`", err)
+	}
+
+	info, err := client.Info()
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if info.Network != "simnet" {
+		t.Errorf("want info served in memory but got `%v`", info)
+	}
+}
+
+// recordingMetrics is Metrics implementation which records metric
+// names for testing purposes.
+type recordingMetrics struct {
+	mtx   sync.Mutex
+	names []string
+}
+
+func (m *recordingMetrics) record(name string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.names = append(m.names, name)
+}
+
+func (m *recordingMetrics) count(name string) int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	var n int
+	for _, got := range m.names {
+		if got == name {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *recordingMetrics) Observe(name string, _ Labels, _ float64) {
+	m.record(name)
+}
+
+func (m *recordingMetrics) Add(name string, _ Labels, _ float64) {
+	m.record(name)
+}
+
+func (m *recordingMetrics) Set(name string, _ Labels, _ float64) {
+	m.record(name)
+}
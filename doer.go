@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+)
+
+// Request is the GraphQL request to the exchange.
+type Request struct {
+	// Query is the GraphQL query.
+	Query string
+
+	// Variables is the query variables, marshalled to JSON.
+	Variables interface{}
+
+	// NeedAuth is true if the request is authorized with the client
+	// credentials.
+	NeedAuth bool
+}
+
+// Doer sends GraphQL requests to the exchange and returns response
+// bodies. It is the layer the client methods are built on, so they may
+// be served by alternative transports or by test doubles, see WithDoer,
+// WithCoreOverride and package clienttest.
+type Doer interface {
+	Do(ctx context.Context, r Request) ([]byte, error)
+}
+
+// DoerFunc is the function implementing Doer.
+type DoerFunc func(ctx context.Context, r Request) ([]byte, error)
+
+// Do implements Doer.
+func (f DoerFunc) Do(ctx context.Context, r Request) ([]byte, error) {
+	return f(ctx, r)
+}
+
+// WithDoer makes the client send requests with the doer instead of
+// sending them over HTTP, e.g. to unit-test trading logic without a
+// live exchange. Options of the HTTP transport, retries and
+// authorization don't apply then, while client side features like
+// validation, journaling and caches do. Subscriptions fall back to
+// polling with the doer.
+func WithDoer(d Doer) Option {
+	return func(o *options) {
+		o.doer = d
+	}
+}
+
+// doerCore is the core which sends requests with the doer.
+type doerCore struct {
+	doer Doer
+}
+
+// do implements core.
+func (c doerCore) do(ctx context.Context, needAuth bool,
+	r request) ([]byte, error) {
+
+	return c.doer.Do(ctx, Request{
+		Query:     r.Query,
+		Variables: r.Variables,
+		NeedAuth:  needAuth,
+	})
+}
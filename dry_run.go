@@ -0,0 +1,148 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DryRunPrefix is the prefix of synthetic payment IDs and invoices
+// returned by mutations in dry run mode.
+const DryRunPrefix = "dry-run-"
+
+// WithDryRun makes every mutation go through validation, pre-trade
+// checks, withdrawal limits and journaling as usual, but stops before
+// sending it to the exchange and returns synthetic result instead.
+// Synthetic orders have negative IDs and synthetic payment IDs have
+// DryRunPrefix. Queries are sent as usual. It allows to shadow-test new
+// strategy build in production infrastructure.
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// dryRun synthesizes mutation results in dry run mode.
+type dryRun struct {
+	// seq is the sequence number of synthetic results, accessed
+	// atomically.
+	seq int64
+}
+
+// dryRunResult is the function which returns synthetic result of the
+// mutation field given mutation variables.
+type dryRunResult func(seq int64, vars map[string]interface{}) interface{}
+
+// dryRunResults is the synthetic results of known mutation fields.
+// Unknown mutations return null, which leaves results zero.
+var dryRunResults = map[string]dryRunResult{
+	"createMarketOrder": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return map[string]interface{}{
+			"id":        -seq,
+			"status":    OrderPending,
+			"amount":    vars["amount"],
+			"price":     "0",
+			"dealStock": "0",
+			"dealMoney": "0",
+			"left":      vars["amount"],
+		}
+	},
+	"cancelOrder": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return map[string]interface{}{
+			"id":     vars["id"],
+			"status": OrderCanceled,
+		}
+	},
+	"withdrawWithBlockchain": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return map[string]interface{}{
+			"paymentID":   DryRunPrefix + strconv.FormatInt(seq, 10),
+			"paymentAddr": vars["address"],
+			"change":      vars["amount"],
+		}
+	},
+	"withdrawWithLightning": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return map[string]interface{}{
+			"paymentID": DryRunPrefix + strconv.FormatInt(seq, 10),
+			"change":    "0",
+			"fee":       "0",
+		}
+	},
+	"generateLightningInvoice": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return DryRunPrefix + strconv.FormatInt(seq, 10)
+	},
+	"generateDepositAddress": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return DryRunPrefix + strconv.FormatInt(seq, 10)
+	},
+	"markNotificationRead": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return true
+	},
+	"requestTaxReport": func(seq int64,
+		vars map[string]interface{}) interface{} {
+
+		return map[string]interface{}{
+			"id":     DryRunPrefix + strconv.FormatInt(seq, 10),
+			"year":   vars["year"],
+			"status": ReportPending,
+		}
+	},
+}
+
+// respond returns synthetic response of the mutation request.
+func (d *dryRun) respond(r request) ([]byte, error) {
+	field := mutationField(r.Query)
+	seq := atomic.AddInt64(&d.seq, 1)
+
+	varsJSON, err := json.Marshal(r.Variables)
+	if err != nil {
+		return nil, errors.New("failed to json.Marshal variables: " +
+			err.Error())
+	}
+
+	var vars map[string]interface{}
+	if err := json.Unmarshal(varsJSON, &vars); err != nil {
+		return nil, errors.New("failed to json.Unmarshal variables: " +
+			err.Error())
+	}
+
+	var result interface{}
+	if synthesize, ok := dryRunResults[field]; ok {
+		result = synthesize(seq, vars)
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			field: result,
+		},
+	})
+}
+
+// mutationField returns the name of the first field selected by the
+// mutation, e.g. "createMarketOrder".
+func mutationField(query string) string {
+	i := strings.Index(query, "{")
+	if i < 0 {
+		return ""
+	}
+
+	field := strings.TrimLeft(query[i+1:], " \t\r\n")
+	if end := strings.IndexAny(field, " \t\r\n({"); end >= 0 {
+		field = field[:end]
+	}
+	return field
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestClient_DryRun(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{"data": {"order": {"id": 7, "status": "finished"}}}`,
+	}
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	journal, err := OpenJournal(filepath.Join(dir, "journal"))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	defer journal.Close()
+
+	var checked int
+	client := &Client{
+		core:    backend,
+		journal: journal,
+		dryRun:  &dryRun{},
+		preTradeChecks: []PreTradeCheck{PreTradeCheckFunc(
+			func(context.Context, OrderIntent) error {
+				checked++
+				return nil
+			})},
+	}
+
+	t.Run("mutations are not sent", func(t *testing.T) {
+		order, err := client.CreateOrderBid("BTCETH", dec(1.5))
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if order.ID >= 0 || order.Status != OrderPending ||
+			!order.Amount.Equal(dec(1.5)) || !order.Left.Equal(dec(1.5)) {
+			t.Errorf("want synthetic pending order but got `%v`", order)
+		}
+		if backend.request.Query != "" {
+			t.Errorf("want no request sent but got `%s`",
+				backend.request.Query)
+		}
+		if checked != 1 {
+			t.Errorf("want pre-trade check invoked but got %d checks",
+				checked)
+		}
+
+		w, err := client.Withdraw("BTC", dec(0.1), "addr")
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if !strings.HasPrefix(w.PaymentID, DryRunPrefix) ||
+			w.PaymentAddr != "addr" || !w.Change.Equal(dec(0.1)) {
+			t.Errorf("want synthetic withdrawal but got `%v`", w)
+		}
+
+		if err := client.MarkNotificationRead("1"); err != nil {
+			t.Errorf("want no error but got `%v`", err)
+		}
+
+		report, err := client.RequestTaxReport(2018)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if !strings.HasPrefix(report.ID, DryRunPrefix) ||
+			report.Year != 2018 || report.Status != ReportPending {
+			t.Errorf("want synthetic pending report but got `%v`", report)
+		}
+	})
+	t.Run("mutations are journaled", func(t *testing.T) {
+		if n := len(journal.InFlight()); n != 0 {
+			t.Errorf("want no mutations in flight but got %d", n)
+		}
+		if journal.seq != 4 {
+			t.Errorf("want 4 mutations journaled but got %d", journal.seq)
+		}
+	})
+	t.Run("queries are sent", func(t *testing.T) {
+		order, err := client.Order(7)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if order.ID != 7 {
+			t.Errorf("want order 7 but got `%v`", order)
+		}
+	})
+	t.Run("validation still applies", func(t *testing.T) {
+		client := &Client{
+			core:   backend,
+			dryRun: &dryRun{},
+			preTradeChecks: []PreTradeCheck{PreTradeCheckFunc(
+				func(context.Context, OrderIntent) error {
+					return errors.New("market is restricted")
+				})},
+		}
+		if _, err := client.CreateOrderBid("BTCETH", dec(1)); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+}
+
+func TestMutationField(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"mutation X($id: Int!) {\n\tcancelOrder(id: $id) { id } }",
+			"cancelOrder"},
+		{"mutation { markNotificationRead(id: \"1\") }",
+			"markNotificationRead"},
+		{"mutation", ""},
+	}
+	for _, test := range tests {
+		if got := mutationField(test.query); got != test.want {
+			t.Errorf("want field `%s` but got `%s`", test.want, got)
+		}
+	}
+}
+
+func TestClient_DryRun_sideEffects(t *testing.T) {
+	backend := &countingCore{
+		mockCore: mockCore{respJSON: `{"data": {"order": {"id": 1,
+			"status": "pending", "amount": "2", "left": "2"}}}`},
+	}
+	var anomalies []StateAnomaly
+	client := &Client{
+		core:   backend,
+		dryRun: &dryRun{},
+		orders: newOrderCache(0, nopMetrics{}),
+		orderStates: newOrderStates(func(a StateAnomaly) {
+			anomalies = append(anomalies, a)
+		}, nopMetrics{}),
+		withdrawals: NewWithdrawalTracker(map[string]decimal.Decimal{
+			"BTC": dec(1),
+		}),
+		withdrawalLog: newWithdrawalLog(withdrawalLogSize),
+	}
+
+	if _, err := client.Order(1); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if _, err := client.CancelOrder(1); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	order, err := client.Order(1)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if order.Status != OrderPending || !order.Amount.Equal(dec(2)) {
+		t.Errorf("want pending order from backend but got `%v`", order)
+	}
+	if backend.calls != 2 {
+		t.Errorf("want 2 requests but got %v", backend.calls)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("want no state anomalies but got `%v`", anomalies)
+	}
+
+	w, err := client.Withdraw("BTC", dec(0.5), "addr")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if withdrawn := client.withdrawals.Withdrawn("BTC"); withdrawn.Sign() != 0 {
+		t.Errorf("want limits not used up but got %v withdrawn", withdrawn)
+	}
+	if client.withdrawalLog.get(w.PaymentID) != nil {
+		t.Errorf("want synthetic withdrawal not logged")
+	}
+}
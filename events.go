@@ -0,0 +1,213 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Event types of the events subscription.
+const (
+	// EventTicker is the ticker update, its data is Ticker.
+	EventTicker = "ticker"
+
+	// EventDeal is the new deal, its data is MarketDeal.
+	EventDeal = "deal"
+)
+
+// Event is the event streamed by SubscribeEvents.
+type Event struct {
+	// Type is the type of the event, e.g. EventTicker.
+	Type string
+
+	// Version is the version of the event schema.
+	Version int
+
+	// Data is the decoded event, e.g. Ticker, or UnknownEvent if the
+	// type or version is not known to the client.
+	Data interface{}
+}
+
+// UnknownEvent is the data of the event which type or version is not
+// known to the client, e.g. because the server is newer. It keeps the
+// raw payload, so consumers may decode it themselves.
+type UnknownEvent struct {
+	// Type is the type of the event.
+	Type string
+
+	// Version is the version of the event schema.
+	Version int
+
+	// Raw is the raw JSON payload of the event.
+	Raw json.RawMessage
+}
+
+// eventEnvelope is the envelope of the streamed event.
+type eventEnvelope struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// eventDecoder decodes the payload of the known event type. Version is
+// the latest schema version it decodes, newer events are surfaced as
+// unknown ones.
+type eventDecoder struct {
+	version int
+	decode  func(c *Client, payload json.RawMessage) (interface{}, error)
+}
+
+// eventDecoders is the decoders of known event types.
+var eventDecoders = map[string]eventDecoder{
+	EventTicker: {
+		version: 1,
+		decode: func(c *Client, payload json.RawMessage) (interface{},
+			error) {
+
+			var t Ticker
+			if err := json.Unmarshal(payload, &t); err != nil {
+				return nil, err
+			}
+			t.Market = c.assets.localMarket(t.Market)
+			return t, nil
+		},
+	},
+	EventDeal: {
+		version: 1,
+		decode: func(c *Client, payload json.RawMessage) (interface{},
+			error) {
+
+			var d MarketDeal
+			if err := json.Unmarshal(payload, &d); err != nil {
+				return nil, err
+			}
+			d.Market = c.assets.localMarket(d.Market)
+			return d, nil
+		},
+	},
+}
+
+// eventsRequestVariables is a subscription variables used in request
+// in client SubscribeEvents method.
+type eventsRequestVariables struct {
+	Types []string `json:"types,omitempty"`
+}
+
+// SubscribeEvents returns the channel of events of the types, all types
+// are streamed if none is given. Events are wrapped in versioned
+// envelopes, so events of unknown type or newer version are delivered
+// as UnknownEvent instead of breaking the stream, their number is
+// counted in subscription_unknown_events_total metric. Events are
+// streamed over WebSocket only, the channel is closed once the client
+// context is done, see WithContext.
+func (c *Client) SubscribeEvents(types []string) (<-chan Event, error) {
+	if c.subs == nil || c.subs.dialer == nil {
+		return nil, errors.New("events require WebSocket streaming")
+	}
+
+	ctx := c.context()
+	events := make(chan Event, c.subs.buffer())
+
+	var req request
+	req.Query = `
+		subscription Events($types: [String!]) {
+			events(types: $types) {
+				type
+				version
+				payload
+			}
+		}
+	`
+	req.Variables = eventsRequestVariables{Types: types}
+
+	sub := subscription{
+		name:    "events",
+		request: req,
+		decode: func(payload json.RawMessage) ([]interface{}, error) {
+			return c.decodeEvents(payload)
+		},
+	}
+
+	go func() {
+		defer close(events)
+		defer c.errs.recover("events")
+		c.subs.run(ctx, sub, func(event interface{}) bool {
+			select {
+			case events <- event.(Event):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return events, nil
+}
+
+// decodeEvents decodes the events subscription payload which holds
+// either single envelope or a batch of them.
+func (c *Client) decodeEvents(payload json.RawMessage) ([]interface{},
+	error) {
+
+	data := struct {
+		Events json.RawMessage `json:"events"`
+	}{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	var envelopes []eventEnvelope
+	if len(data.Events) > 0 && data.Events[0] == '[' {
+		if err := json.Unmarshal(data.Events, &envelopes); err != nil {
+			return nil, err
+		}
+	} else {
+		var e eventEnvelope
+		if err := json.Unmarshal(data.Events, &e); err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, e)
+	}
+
+	// Malformed event is skipped alone, so it doesn't drop the rest of
+	// the batch.
+	events := make([]interface{}, 0, len(envelopes))
+	for _, e := range envelopes {
+		event, err := c.decodeEvent(e)
+		if err != nil {
+			c.subs.metrics.Add("subscription_decode_errors_total",
+				Labels{"subscription": "events"}, 1)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// decodeEvent decodes the envelope into the event. Envelope without
+// version is considered to be of the first one.
+func (c *Client) decodeEvent(e eventEnvelope) (Event, error) {
+	if e.Version == 0 {
+		e.Version = 1
+	}
+	event := Event{Type: e.Type, Version: e.Version}
+
+	decoder, ok := eventDecoders[e.Type]
+	if !ok || e.Version > decoder.version {
+		c.subs.metrics.Add("subscription_unknown_events_total",
+			Labels{"subscription": "events", "type": e.Type}, 1)
+		event.Data = UnknownEvent{
+			Type:    e.Type,
+			Version: e.Version,
+			Raw:     e.Payload,
+		}
+		return event, nil
+	}
+
+	data, err := decoder.decode(c, e.Payload)
+	if err != nil {
+		return event, errors.New("failed to decode " + e.Type +
+			" event: " + err.Error())
+	}
+	event.Data = data
+	return event, nil
+}
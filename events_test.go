@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestClient_decodeEvents(t *testing.T) {
+	metrics := &recordingMetrics{}
+	client := &Client{subs: newSubscriptions(SubscriptionConfig{}, nil,
+		metrics)}
+
+	events, err := client.decodeEvents(json.RawMessage(`{"events": [
+		{"type": "ticker", "version": 1,
+			"payload": {"market": "BTCETH", "last": "1"}},
+		{"type": "deal", "payload": {"id": 7, "market": "BTCETH"}},
+		{"type": "ticker", "version": 2, "payload": {"pair": "BTCETH"}},
+		{"type": "liquidation", "version": 1, "payload": {"id": 1}},
+		{"type": "deal", "version": 1, "payload": {"id": "malformed"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("want 4 events but got %v", len(events))
+	}
+
+	ticker, ok := events[0].(Event).Data.(Ticker)
+	if !ok || !ticker.Last.Equal(dec(1)) {
+		t.Errorf("want ticker but got `%v`", events[0])
+	}
+
+	deal, ok := events[1].(Event).Data.(MarketDeal)
+	if e := events[1].(Event); !ok || deal.ID != 7 || e.Version != 1 {
+		t.Errorf("want deal of version 1 but got `%v`", e)
+	}
+
+	for _, i := range []int{2, 3} {
+		e := events[i].(Event)
+		unknown, ok := e.Data.(UnknownEvent)
+		if !ok || unknown.Type != e.Type || len(unknown.Raw) == 0 {
+			t.Errorf("want unknown event with raw payload but got `%v`", e)
+		}
+	}
+
+	if n := metrics.count("subscription_unknown_events_total"); n != 2 {
+		t.Errorf("want 2 unknown events counted but got %v", n)
+	}
+	if n := metrics.count("subscription_decode_errors_total"); n != 1 {
+		t.Errorf("want 1 decode error counted but got %v", n)
+	}
+
+	events, err = client.decodeEvents(json.RawMessage(`{"events":
+		{"type": "ticker", "payload": {"market": "BTCETH"}}}`))
+	if err != nil || len(events) != 1 {
+		t.Errorf("want single event decoded but got `%v`, `%v`", events, err)
+	}
+}
+
+func TestClient_SubscribeEvents(t *testing.T) {
+	s, _ := mockWSServer(t, false, func(conn *websocket.Conn, n int32) {
+		conn.WriteJSON(wsMessage{ID: wsSubscriptionID, Type: wsData,
+			Payload: json.RawMessage(`{"data": {"events": [
+				{"type": "margin", "version": 3, "payload": {}},
+				{"type": "ticker", "version": 1,
+					"payload": {"market": "BTCETH", "last": "2"}}
+			]}}`)})
+
+		var stop wsMessage
+		conn.ReadJSON(&stop)
+	})
+	defer s.Close()
+
+	client, err := NewClient(s.URL, "", "token")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := client.WithContext(ctx).SubscribeEvents(nil)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if e := <-events; e.Type != "margin" {
+		t.Errorf("want unknown margin event but got `%v`", e)
+	} else if _, ok := e.Data.(UnknownEvent); !ok {
+		t.Errorf("want unknown event data but got `%v`", e.Data)
+	}
+
+	if e := <-events; e.Type != EventTicker {
+		t.Errorf("want ticker event after unknown one but got `%v`", e)
+	}
+
+	cancel()
+	for range events {
+	}
+
+	if _, err := (&Client{}).SubscribeEvents(nil); err == nil {
+		t.Error("want error without streaming")
+	}
+}
+
+func TestSubscriptions_run_streamOnly(t *testing.T) {
+	dialer := &failingDialer{}
+	s := newSubscriptions(SubscriptionConfig{
+		PollInterval:    time.Millisecond,
+		MaxDialAttempts: 1,
+	}, dialer, nopMetrics{})
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		50*time.Millisecond)
+	defer cancel()
+
+	s.run(ctx, subscription{
+		name:    "events",
+		request: request{Query: "subscription { events { type } }"},
+	}, func(interface{}) bool { return true })
+
+	if dialer.attempts < 2 {
+		t.Errorf("want stream-only subscription to keep dialing but got"+
+			" %v attempts", dialer.attempts)
+	}
+}
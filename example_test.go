@@ -0,0 +1,237 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/bitlum/exchange-graphql-client"
+	"github.com/shopspring/decimal"
+)
+
+// exampleResponses is the canned responses of the example exchange
+// server by the GraphQL field the request selects.
+var exampleResponses = []struct {
+	field    string
+	response string
+}{
+	{"createMarketOrder", `{"createMarketOrder": {"id": 42,
+		"status": "pending", "amount": "0.5", "price": "0",
+		"dealStock": "0", "dealMoney": "0", "left": "0.5"}}`},
+	{"cancelOrder", `{"cancelOrder": {"id": 42, "status": "canceled",
+		"amount": "0.5", "price": "0.03", "dealStock": "0.2",
+		"dealMoney": "0.006", "left": "0.3"}}`},
+	{"order(", `{"order": {"id": 42, "status": "pending",
+		"amount": "0.5", "price": "0.03", "dealStock": "0.2",
+		"dealMoney": "0.006", "left": "0.3"}}`},
+	{"balanceUpdateRecords", `{"balanceUpdateRecords": [
+		{"paymentID": "a1b2", "paymentType": "blockchain",
+			"change": "1.5", "time": 1546300800}]}`},
+	{"generateLightningInvoice", `{"generateLightningInvoice":
+		"lnbc10u1pwexample"}`},
+	{"withdrawWithBlockchain", `{"withdrawWithBlockchain": {
+		"paymentID": "c3d4", "paymentAddr": "2N3oefVeg6stiTb5Kh3ozCSkaqmx91FDbsm",
+		"change": "0.1"}}`},
+	{"depth(", `{"depth": {"asks": [{"price": "0.031", "volume": "2"}],
+		"bids": [{"price": "0.03", "volume": "1"}]}}`},
+}
+
+// newExampleServer starts the exchange server mock which serves the
+// examples, it should be closed once the example is done.
+func newExampleServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+
+		var req struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for _, resp := range exampleResponses {
+			if strings.Contains(req.Query, resp.field) {
+				fmt.Fprintf(w, `{"data": %s}`, resp.response)
+				return
+			}
+		}
+		fmt.Fprint(w, `{"errors": [{"message": "unknown query"}]}`)
+	}))
+}
+
+func Example() {
+	server := newExampleServer()
+	defer server.Close()
+
+	// Either hex encoded macaroon or JWT authorizes the client.
+	c, err := client.NewClient(server.URL, "", "jwt-token")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	depth, err := c.Depth("BTCETH", 10, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("best ask:", depth.Asks[0].Price)
+	fmt.Println("best bid:", depth.Bids[0].Price)
+	// Output:
+	// best ask: 0.031
+	// best bid: 0.03
+}
+
+// The order lifecycle: the order is created, its state is polled and the
+// rest of it is canceled.
+func Example_orderLifecycle() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, err := client.NewClient(server.URL, "", "jwt-token")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Spend 0.5 BTC to buy ETH in BTCETH market.
+	order, err := c.CreateOrderBid("BTCETH",
+		decimal.RequireFromString("0.5"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("created:", order.ID, order.Status)
+
+	order, err = c.Order(order.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("left:", order.Left)
+
+	order, err = c.CancelOrder(order.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("final:", order.Status, "bought", order.DealStock)
+	// Output:
+	// created: 42 pending
+	// left: 0.3
+	// final: canceled bought 0.2
+}
+
+// Deposit detection: deposits are requested page by page from the
+// account change history.
+func ExampleClient_Deposits() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, err := client.NewClient(server.URL, "", "jwt-token")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	deposits, err := c.Deposits("BTC", 0, 100)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, d := range deposits {
+		fmt.Println(d.PaymentID, d.PaymentType,
+			client.FormatAmount("BTC", d.Change))
+	}
+	// Output:
+	// a1b2 blockchain 1.50000000 BTC
+}
+
+// Lightning deposit: the invoice is generated and given to the payer.
+func ExampleClient_LightningCreateInvoice() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, err := client.NewClient(server.URL, "", "jwt-token")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	invoice, err := c.LightningCreateInvoice("BTC",
+		decimal.RequireFromString("0.00001"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(invoice)
+	// Output:
+	// lnbc10u1pwexample
+}
+
+// Withdrawal: funds are sent to the blockchain address.
+func ExampleClient_Withdraw() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, err := client.NewClient(server.URL, "", "jwt-token")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	amount, err := client.ParseUserAmount("BTC", "0.1 BTC")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	withdrawal, err := c.Withdraw("BTC", amount,
+		"2N3oefVeg6stiTb5Kh3ozCSkaqmx91FDbsm")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(withdrawal.PaymentID, withdrawal.Change)
+	// Output:
+	// c3d4 0.1
+}
+
+// Requests are bound to the context with WithContext, e.g. to limit
+// their duration.
+func ExampleClient_WithContext() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, err := client.NewClient(server.URL, "", "jwt-token")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	top, err := c.WithContext(ctx).TopOfBook("BTCETH")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(top.BestAsk, top.BestBid)
+	// Output:
+	// 0.031 0.03
+}
+
+func ExampleFormatAmount() {
+	fmt.Println(client.FormatAmount("BTC",
+		decimal.RequireFromString("0.000123456")))
+	// Output:
+	// 0.00012345 BTC
+}
+
+func ExampleParseUserAmount() {
+	amount, err := client.ParseUserAmount("BTC", "₿0.5")
+	fmt.Println(amount, err)
+
+	_, err = client.ParseUserAmount("BTC", "0.000000001")
+	fmt.Println(err)
+	// Output:
+	// 0.5 <nil>
+	// amount has more than 8 decimal places of BTC
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrInsufficientFunds is returned if account balance is not enough
+	// to create an order or withdraw funds.
+	ErrInsufficientFunds = errors.New("exchange error: insufficient funds")
+
+	// ErrAmountTooSmall is returned if order or withdrawal amount is
+	// less than minimal amount allowed by the exchange.
+	ErrAmountTooSmall = errors.New("exchange error: amount too small")
+
+	// ErrMarketNotFound is returned if market is not supported by the
+	// exchange.
+	ErrMarketNotFound = errors.New("exchange error: market not found")
+
+	// ErrUnauthorized is returned if the exchange rejects credentials
+	// or the operation is not permitted by them.
+	ErrUnauthorized = errors.New("exchange error: unauthorized")
+)
+
+// ExchangeError is the error reported by the exchange in GraphQL
+// response. Known errors match sentinel errors with errors.Is, e.g.
+// errors.Is(err, ErrInsufficientFunds).
+type ExchangeError struct {
+	// Code is the error code given in error extensions, empty if the
+	// server doesn't report it.
+	Code string
+
+	// Message is the message of the first error.
+	Message string
+
+	// Locations is the query locations of the first error.
+	Locations []ErrorLocation
+
+	// Count is the number of errors in the response.
+	Count int
+
+	// detail is the error description including locations.
+	detail string
+
+	// kind is the sentinel error of the known error, nil otherwise.
+	kind error
+}
+
+// ErrorLocation is the location in GraphQL query the error refers to.
+type ErrorLocation struct {
+	Line   int
+	Column int
+}
+
+// Error implements error.
+func (e *ExchangeError) Error() string {
+	return "exchange error: " + e.detail
+}
+
+// Unwrap returns the sentinel error of the known error.
+func (e *ExchangeError) Unwrap() error {
+	return e.kind
+}
+
+// TransportError is returned if the request can not be performed, e.g.
+// server is unreachable or responds with non 200 status code, in which
+// case it wraps *HTTPStatusError.
+type TransportError struct {
+	Err error
+}
+
+// transportError wraps request error.
+func transportError(err error) error {
+	return &TransportError{Err: err}
+}
+
+// Error implements error.
+func (e *TransportError) Error() string {
+	return "failed to do request: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned if the exchange response can not be decoded.
+type DecodeError struct {
+	Err error
+}
+
+// decodeError wraps response decoding error.
+func decodeError(err error) error {
+	return &DecodeError{Err: err}
+}
+
+// Error implements error.
+func (e *DecodeError) Error() string {
+	return "failed to json.Unmarshal resp: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// exchangeErrors is the translation table of known exchange error
+// messages to sentinel errors. Messages are matched as lower case
+// substrings of the error, so location suffixes don't matter. Keep
+// wordings in sync with the server, tests are pinned to them.
+var exchangeErrors = []struct {
+	message string
+	err     error
+}{
+	// Matching engine errors.
+	{"balance not enough", ErrInsufficientFunds},
+	{"amount too small", ErrAmountTooSmall},
+	{"market not found", ErrMarketNotFound},
+
+	// GraphQL API errors.
+	{"insufficient funds", ErrInsufficientFunds},
+	{"not enough funds", ErrInsufficientFunds},
+	{"less than minimal amount", ErrAmountTooSmall},
+	{`expected type "market"`, ErrMarketNotFound},
+	{"expected type market", ErrMarketNotFound},
+}
+
+// exchangeErrorCodes is the translation table of GraphQL error codes
+// to sentinel errors.
+var exchangeErrorCodes = map[string]error{
+	"UNAUTHENTICATED":    ErrUnauthorized,
+	"FORBIDDEN":          ErrUnauthorized,
+	"INSUFFICIENT_FUNDS": ErrInsufficientFunds,
+}
+
+// exchangeError converts error of GraphQL response to *ExchangeError,
+// which matches the sentinel error if its code or message is known.
+func exchangeError(err error) error {
+	e := &ExchangeError{
+		Message: err.Error(),
+		Count:   1,
+		detail:  err.Error(),
+	}
+
+	if re, ok := err.(*responseErrors); ok && len(re.errors) > 0 {
+		first := re.errors[0]
+		e.Code = first.Extensions.Code
+		e.Message = first.Message
+		e.Count = len(re.errors)
+		for _, l := range first.Locations {
+			e.Locations = append(e.Locations, ErrorLocation(l))
+		}
+	}
+
+	if kind, ok := exchangeErrorCodes[e.Code]; ok {
+		e.kind = kind
+		return e
+	}
+
+	msg := strings.ToLower(e.detail)
+	for _, known := range exchangeErrors {
+		if strings.Contains(msg, known.message) {
+			e.kind = known.err
+			break
+		}
+	}
+	return e
+}
+
+// Is makes HTTPStatusError of rejected credentials match
+// ErrUnauthorized.
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrUnauthorized &&
+		(e.StatusCode == http.StatusUnauthorized ||
+			e.StatusCode == http.StatusForbidden)
+}
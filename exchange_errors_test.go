@@ -0,0 +1,149 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExchangeError(t *testing.T) {
+	tests := []struct {
+		message string
+		want    error
+	}{{
+		message: "balance not enough",
+		want:    ErrInsufficientFunds,
+	}, {
+		message: "Balance not enough, location: 2:3",
+		want:    ErrInsufficientFunds,
+	}, {
+		message: "2 errors occurred, first one is: insufficient funds",
+		want:    ErrInsufficientFunds,
+	}, {
+		message: "Not enough funds to withdraw",
+		want:    ErrInsufficientFunds,
+	}, {
+		message: "amount too small",
+		want:    ErrAmountTooSmall,
+	}, {
+		message: "Amount is less than minimal amount 0.0001",
+		want:    ErrAmountTooSmall,
+	}, {
+		message: "market not found",
+		want:    ErrMarketNotFound,
+	}, {
+		message: `Variable "$market" got invalid value "FOOBAR"; ` +
+			`Expected type Market.`,
+		want: ErrMarketNotFound,
+	}, {
+		message: `Variable "$market" got invalid value "FOOBAR".` + "\n" +
+			`Expected type "Market", found "FOOBAR".`,
+		want: ErrMarketNotFound,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.message, func(t *testing.T) {
+			err := exchangeError(errors.New(test.message))
+			if !errors.Is(err, test.want) {
+				t.Errorf("want `%v` but got `%v`", test.want, err)
+			}
+		})
+	}
+
+	t.Run("unknown error", func(t *testing.T) {
+		err := exchangeError(errors.New("internal error"))
+		if err.Error() != "exchange error: internal error" {
+			t.Errorf("want wrapped error but got `%v`", err)
+		}
+		if errors.Unwrap(err) != nil {
+			t.Errorf("want no sentinel error but got `%v`",
+				errors.Unwrap(err))
+		}
+	})
+}
+
+func TestClient_CreateOrder_InsufficientFunds(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{"errors": [{"message": "balance not enough"}]}`,
+	}
+	client := &Client{core: backend}
+	_, err := client.CreateOrderBid("BTCETH", dec(1))
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("want ErrInsufficientFunds but got `%v`", err)
+	}
+}
+
+func TestExchangeError_Fields(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{"errors": [{
+			"message": "not authenticated",
+			"locations": [{"line": 2, "column": 3}],
+			"extensions": {"code": "UNAUTHENTICATED"}
+		}, {"message": "second"}]}`,
+	}
+	client := &Client{core: backend}
+
+	_, err := client.Order(1)
+
+	var exchangeErr *ExchangeError
+	if !errors.As(err, &exchangeErr) {
+		t.Fatalf("want *ExchangeError but got `%T`", err)
+	}
+	if exchangeErr.Code != "UNAUTHENTICATED" ||
+		exchangeErr.Message != "not authenticated" ||
+		exchangeErr.Count != 2 {
+		t.Errorf("want code, message and count of errors but got `%#v`",
+			exchangeErr)
+	}
+	if len(exchangeErr.Locations) != 1 ||
+		exchangeErr.Locations[0] != (ErrorLocation{Line: 2, Column: 3}) {
+		t.Errorf("want error location but got `%v`",
+			exchangeErr.Locations)
+	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("want ErrUnauthorized but got `%v`", err)
+	}
+	if errors.Is(err, ErrInsufficientFunds) {
+		t.Error("want error not to match ErrInsufficientFunds")
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	statusErr := &HTTPStatusError{StatusCode: 401, Status: "401 Unauthorized"}
+	client := &Client{core: &mockCore{error: statusErr}}
+
+	_, err := client.Order(1)
+
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("want *TransportError but got `%T`", err)
+	}
+
+	var gotStatusErr *HTTPStatusError
+	if !errors.As(err, &gotStatusErr) || gotStatusErr.StatusCode != 401 {
+		t.Errorf("want wrapped *HTTPStatusError but got `%v`", err)
+	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("want ErrUnauthorized but got `%v`", err)
+	}
+
+	client = &Client{core: &mockCore{error: &HTTPStatusError{
+		StatusCode: 500}}}
+	if _, err := client.Order(1); errors.Is(err, ErrUnauthorized) {
+		t.Error("want server error not to match ErrUnauthorized")
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	client := &Client{core: &mockCore{respJSON: `{"data": 1`}}
+
+	_, err := client.Order(1)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("want *DecodeError but got `%T`", err)
+	}
+	if !strings.Contains(err.Error(), "failed to json.Unmarshal") {
+		t.Errorf("want json.Unmarshal error but got `%v`", err)
+	}
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// StrategyExecution is the execution totals of a single strategy.
+type StrategyExecution struct {
+	// Strategy is the name of the strategy.
+	Strategy string `json:"strategy"`
+
+	// Orders is the number of observed orders.
+	Orders int `json:"orders"`
+
+	// FilledStock is the total amount of stock which has been dealt.
+	FilledStock decimal.Decimal `json:"filledStock"`
+
+	// SpentMoney is the total amount of money which has been dealt.
+	SpentMoney decimal.Decimal `json:"spentMoney"`
+
+	// AvgPrice is the volume weighted average price of the deals, zero
+	// if nothing has been filled.
+	AvgPrice decimal.Decimal `json:"avgPrice"`
+
+	// Fee is the fee estimate calculated as spent money multiplied by
+	// the report fee rate.
+	Fee decimal.Decimal `json:"fee"`
+}
+
+// ExecutionReport accumulates partial fills of the orders into per
+// strategy execution totals. It consumes order states, e.g. polled with
+// Client.Order, and may be exported as JSON or CSV at the end of a
+// session. It is safe for concurrent use.
+type ExecutionReport struct {
+	feeRate decimal.Decimal
+
+	mtx sync.Mutex
+
+	// orders is the most filled observed state of the orders by
+	// strategy and order ID.
+	orders map[string]map[int64]Order
+}
+
+// NewExecutionReport creates new empty report which estimates fees with
+// given fee rate, e.g. 0.002 for 0.2%.
+func NewExecutionReport(feeRate decimal.Decimal) *ExecutionReport {
+	return &ExecutionReport{
+		feeRate: feeRate,
+		orders:  make(map[string]map[int64]Order),
+	}
+}
+
+// Observe registers order state of the strategy. As order deal amounts
+// are cumulative, the state replaces previously observed state of the
+// same order unless the previous one is more filled, so the states may
+// be observed repeatedly and out of order.
+func (r *ExecutionReport) Observe(strategy string, order Order) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	orders, ok := r.orders[strategy]
+	if !ok {
+		orders = make(map[int64]Order)
+		r.orders[strategy] = orders
+	}
+
+	if prev, ok := orders[order.ID]; ok &&
+		prev.DealStock.GreaterThan(order.DealStock) {
+		return
+	}
+
+	orders[order.ID] = order
+}
+
+// Totals returns execution totals of the strategies sorted by strategy
+// name.
+func (r *ExecutionReport) Totals() []StrategyExecution {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	totals := make([]StrategyExecution, 0, len(r.orders))
+	for strategy, orders := range r.orders {
+		e := StrategyExecution{
+			Strategy: strategy,
+			Orders:   len(orders),
+		}
+		for _, o := range orders {
+			e.FilledStock = e.FilledStock.Add(o.DealStock.Abs())
+			e.SpentMoney = e.SpentMoney.Add(o.DealMoney.Abs())
+		}
+		if e.FilledStock.Sign() != 0 {
+			e.AvgPrice = e.SpentMoney.Div(e.FilledStock)
+		}
+		e.Fee = e.SpentMoney.Mul(r.feeRate)
+		totals = append(totals, e)
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Strategy < totals[j].Strategy
+	})
+
+	return totals
+}
+
+// WriteJSON writes strategies totals to w as JSON array.
+func (r *ExecutionReport) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r.Totals())
+}
+
+// WriteCSV writes strategies totals to w as CSV with header.
+func (r *ExecutionReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"strategy", "orders", "filled_stock",
+		"spent_money", "avg_price", "fee"}); err != nil {
+		return err
+	}
+
+	for _, e := range r.Totals() {
+		if err := cw.Write([]string{
+			e.Strategy,
+			strconv.Itoa(e.Orders),
+			e.FilledStock.String(),
+			e.SpentMoney.String(),
+			e.AvgPrice.String(),
+			e.Fee.String(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestExecutionReport(t *testing.T) {
+	r := NewExecutionReport(dec(0.002))
+
+	// Partial fill followed by full fill and stale partial fill state.
+	r.Observe("maker", Order{ID: 1, DealStock: dec(1), DealMoney: dec(10)})
+	r.Observe("maker", Order{ID: 1, DealStock: dec(2), DealMoney: dec(20)})
+	r.Observe("maker", Order{ID: 1, DealStock: dec(1), DealMoney: dec(10)})
+	r.Observe("maker", Order{ID: 2, DealStock: dec(2), DealMoney: dec(30)})
+	r.Observe("arb", Order{ID: 3})
+
+	totals := r.Totals()
+	if len(totals) != 2 {
+		t.Fatalf("want 2 strategies but got %v", len(totals))
+	}
+
+	arb := totals[0]
+	if arb.Strategy != "arb" || arb.Orders != 1 {
+		t.Errorf("want arb strategy with 1 order but got `%#v`", arb)
+	}
+	if !arb.AvgPrice.Equal(dec(0)) {
+		t.Errorf("want zero avg price but got %v", arb.AvgPrice)
+	}
+
+	maker := totals[1]
+	if maker.Strategy != "maker" || maker.Orders != 2 {
+		t.Errorf("want maker strategy with 2 orders but got `%#v`", maker)
+	}
+	if !maker.FilledStock.Equal(dec(4)) {
+		t.Errorf("want filled stock 4 but got %v", maker.FilledStock)
+	}
+	if !maker.SpentMoney.Equal(dec(50)) {
+		t.Errorf("want spent money 50 but got %v", maker.SpentMoney)
+	}
+	if !maker.AvgPrice.Equal(dec(12.5)) {
+		t.Errorf("want avg price 12.5 but got %v", maker.AvgPrice)
+	}
+	if !maker.Fee.Equal(dec(0.1)) {
+		t.Errorf("want fee 0.1 but got %v", maker.Fee)
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.WriteJSON(&buf); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		var got []StrategyExecution
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("failed to unmarshal report: %v", err)
+		}
+		if len(got) != 2 || !got[1].SpentMoney.Equal(dec(50)) {
+			t.Errorf("want report totals but got `%#v`", got)
+		}
+	})
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.WriteCSV(&buf); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		want := "strategy,orders,filled_stock,spent_money,avg_price,fee\n" +
+			"arb,1,0,0,0,0\n" +
+			"maker,2,4,50,12.5,0.1\n"
+		if buf.String() != want {
+			t.Errorf("want CSV\n%s\nbut got\n%s", want, buf.String())
+		}
+	})
+}
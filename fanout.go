@@ -0,0 +1,319 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// SlowConsumerPolicy defines what happens to events of the shared
+// subscription when the consumer doesn't keep up with them and its
+// buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerBlock makes the subscription wait until the consumer
+	// takes the event, which stalls all other consumers too. Use it only
+	// if no event may be lost.
+	SlowConsumerBlock SlowConsumerPolicy = iota
+
+	// SlowConsumerDrop drops new events until the consumer frees the
+	// buffer.
+	SlowConsumerDrop
+
+	// SlowConsumerLatest drops the oldest buffered events in favour of
+	// new ones, so the consumer always gets the latest events.
+	SlowConsumerLatest
+)
+
+// SubscriberConfig is the configuration of the shared subscription
+// consumer.
+type SubscriberConfig struct {
+	// Buffer is the number of events buffered for the consumer, zero
+	// means 1.
+	Buffer int
+
+	// Policy is applied when the buffer is full.
+	Policy SlowConsumerPolicy
+}
+
+// fanOut delivers events of one subscription to multiple consumers,
+// each with its own buffer, so slow consumer doesn't stall others
+// unless it has blocking policy.
+type fanOut struct {
+	name    string
+	metrics Metrics
+
+	mtx         sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+
+	// replay keeps recent events for consumers attaching late, nil
+	// disables replay.
+	replay *replayBuffer
+}
+
+// subscriber is the consumer of the fan-out.
+type subscriber struct {
+	policy SlowConsumerPolicy
+	queue  chan interface{}
+
+	// done is closed once the consumer is gone.
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// stop marks the consumer as gone.
+func (s *subscriber) stop() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// newFanOut creates new fan-out of the named subscription.
+func newFanOut(name string, metrics Metrics) *fanOut {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	return &fanOut{
+		name:        name,
+		metrics:     metrics,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// subscribe adds the consumer. Its events are passed to deliver, which
+// should return false if the consumer is gone. Once the fan-out is
+// closed and buffered events are delivered, or the consumer is gone,
+// closeOut is called. Returned function removes the consumer.
+func (f *fanOut) subscribe(cfg SubscriberConfig,
+	deliver func(event interface{}, done <-chan struct{}) bool,
+	closeOut func()) func() {
+
+	return f.subscribeSince(cfg, time.Time{}, deliver, closeOut)
+}
+
+// subscribeSince adds the consumer like subscribe, first passing it the
+// replayed events published at or after the time, zero time disables
+// replay. Replayed and live events are taken under the same lock, so
+// the consumer gets neither gaps nor duplicates between them.
+func (f *fanOut) subscribeSince(cfg SubscriberConfig, since time.Time,
+	deliver func(event interface{}, done <-chan struct{}) bool,
+	closeOut func()) func() {
+
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1
+	}
+	s := &subscriber{
+		policy: cfg.Policy,
+		queue:  make(chan interface{}, cfg.Buffer),
+		done:   make(chan struct{}),
+	}
+
+	unsubscribe := func() {
+		s.stop()
+		f.mtx.Lock()
+		delete(f.subscribers, s)
+		f.mtx.Unlock()
+	}
+
+	f.mtx.Lock()
+	var replay []interface{}
+	if !since.IsZero() {
+		replay = f.replay.since(since)
+	}
+	if f.closed {
+		f.mtx.Unlock()
+		if len(replay) == 0 {
+			closeOut()
+			return func() {}
+		}
+		close(s.queue)
+	} else {
+		f.subscribers[s] = struct{}{}
+		f.mtx.Unlock()
+	}
+
+	go func() {
+		defer closeOut()
+		defer unsubscribe()
+
+		for _, e := range replay {
+			if !deliver(e, s.done) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case e, ok := <-s.queue:
+				if !ok || !deliver(e, s.done) {
+					return
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	return unsubscribe
+}
+
+// publish passes the event to all consumers according to their
+// policies.
+func (f *fanOut) publish(event interface{}) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	f.replay.add(time.Now(), event)
+
+	for s := range f.subscribers {
+		switch s.policy {
+		case SlowConsumerDrop:
+			select {
+			case s.queue <- event:
+			default:
+				f.dropped()
+			}
+
+		case SlowConsumerLatest:
+			for sent := false; !sent; {
+				select {
+				case s.queue <- event:
+					sent = true
+				default:
+					select {
+					case <-s.queue:
+						f.dropped()
+					default:
+					}
+				}
+			}
+
+		default:
+			select {
+			case s.queue <- event:
+			case <-s.done:
+			}
+		}
+	}
+}
+
+// dropped counts the event dropped for slow consumer.
+func (f *fanOut) dropped() {
+	f.metrics.Add("subscription_dropped_events_total",
+		Labels{"subscription": f.name}, 1)
+}
+
+// close closes the fan-out, consumers get buffered events and then
+// their channels are closed.
+func (f *fanOut) close() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.closed {
+		return
+	}
+	f.closed = true
+	for s := range f.subscribers {
+		close(s.queue)
+	}
+}
+
+// DealsFeed is the deals subscription shared by multiple consumers.
+type DealsFeed struct {
+	fan *fanOut
+}
+
+// DealsFeed subscribes to new deals on the markets once and shares them
+// between consumers attached with Subscribe. The feed is closed once
+// the client context is done, see WithContext. Recent deals are kept
+// for replay if SubscriptionConfig.ReplayBuffer is set.
+func (c *Client) DealsFeed(markets []string) (*DealsFeed, error) {
+	deals, err := c.SubscribeDeals(markets)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics Metrics = nopMetrics{}
+	if c.subs != nil {
+		metrics = c.subs.metrics
+	}
+	f := &DealsFeed{fan: newFanOut("deals", metrics)}
+	f.fan.replay = newReplayBuffer(c.subs.replayBuffer())
+
+	go func() {
+		defer f.fan.close()
+		defer c.errs.recover("deals feed")
+		for d := range deals {
+			f.fan.publish(d)
+		}
+	}()
+
+	return f, nil
+}
+
+// Subscribe attaches new consumer to the feed. The channel is closed
+// once the feed is closed or the returned cancel function is called.
+func (f *DealsFeed) Subscribe(cfg SubscriberConfig) (<-chan MarketDeal,
+	func()) {
+
+	deals := make(chan MarketDeal)
+	cancel := f.fan.subscribe(cfg,
+		func(event interface{}, done <-chan struct{}) bool {
+			select {
+			case deals <- event.(MarketDeal):
+				return true
+			case <-done:
+				return false
+			}
+		},
+		func() {
+			close(deals)
+		},
+	)
+	return deals, cancel
+}
+
+// SubscribeSince attaches new consumer to the feed like Subscribe, first
+// delivering the kept deals received at or after the time, so the
+// consumer attaching late, e.g. after strategy restart, doesn't miss
+// them. Replayed deals are followed by live ones without gap or
+// duplicates. Deals received before the oldest kept one are lost, see
+// SubscriptionConfig.ReplayBuffer.
+func (f *DealsFeed) SubscribeSince(cfg SubscriberConfig,
+	since time.Time) (<-chan MarketDeal, func()) {
+
+	deals := make(chan MarketDeal)
+	cancel := f.fan.subscribeSince(cfg, since,
+		func(event interface{}, done <-chan struct{}) bool {
+			select {
+			case deals <- event.(MarketDeal):
+				return true
+			case <-done:
+				return false
+			}
+		},
+		func() {
+			close(deals)
+		},
+	)
+	return deals, cancel
+}
+
+// ReplaySince returns the kept deals received at or after the time in
+// the order of receiving.
+func (f *DealsFeed) ReplaySince(since time.Time) []MarketDeal {
+	f.fan.mtx.Lock()
+	events := f.fan.replay.since(since)
+	f.fan.mtx.Unlock()
+
+	deals := make([]MarketDeal, len(events))
+	for i, e := range events {
+		deals[i] = e.(MarketDeal)
+	}
+	return deals
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collect subscribes to the fan-out and returns function which waits
+// for the consumer channel to be closed and returns received events.
+// Events are not consumed until gate is closed.
+func collect(f *fanOut, cfg SubscriberConfig,
+	gate <-chan struct{}) func() []int {
+
+	events := make(chan interface{})
+	f.subscribe(cfg, func(event interface{}, done <-chan struct{}) bool {
+		select {
+		case events <- event:
+			return true
+		case <-done:
+			return false
+		}
+	}, func() {
+		close(events)
+	})
+
+	var (
+		wg  sync.WaitGroup
+		res []int
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-gate
+		for e := range events {
+			res = append(res, e.(int))
+		}
+	}()
+
+	return func() []int {
+		wg.Wait()
+		return res
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	metrics := &recordingMetrics{}
+	f := newFanOut("test", metrics)
+
+	open := make(chan struct{})
+	close(open)
+	gate := make(chan struct{})
+
+	fast := collect(f, SubscriberConfig{Policy: SlowConsumerBlock}, open)
+	drop := collect(f, SubscriberConfig{Policy: SlowConsumerDrop}, gate)
+	latest := collect(f, SubscriberConfig{Policy: SlowConsumerLatest}, gate)
+
+	const n = 10
+	for i := 1; i <= n; i++ {
+		f.publish(i)
+	}
+	f.close()
+	close(gate)
+
+	if got := fast(); len(got) != n {
+		t.Errorf("want all %d events for fast consumer but got `%v`",
+			n, got)
+	}
+
+	// Consumer holds at most one event in flight besides its buffer.
+	dropped := drop()
+	if len(dropped) == 0 || len(dropped) > 2 {
+		t.Fatalf("want 1 or 2 events for dropping consumer but got `%v`",
+			dropped)
+	}
+	if dropped[0] != 1 || len(dropped) == 2 && dropped[1] <= 1 {
+		t.Errorf("want first event and then one of later events for "+
+			"dropping consumer but got `%v`", dropped)
+	}
+
+	latests := latest()
+	if len(latests) == 0 || len(latests) > 2 {
+		t.Fatalf("want 1 or 2 events for latest only consumer but got "+
+			"`%v`", latests)
+	}
+	if latests[len(latests)-1] != n {
+		t.Errorf("want last event for latest only consumer but got `%v`",
+			latests)
+	}
+
+	want := 2*n - len(dropped) - len(latests)
+	if got := metrics.count("subscription_dropped_events_total"); got != want {
+		t.Errorf("want %d dropped events but got %d", want, got)
+	}
+}
+
+func TestFanOut_Unsubscribe(t *testing.T) {
+	f := newFanOut("test", nil)
+
+	events := make(chan interface{})
+	closed := make(chan struct{})
+	unsubscribe := f.subscribe(SubscriberConfig{},
+		func(event interface{}, done <-chan struct{}) bool {
+			select {
+			case events <- event:
+				return true
+			case <-done:
+				return false
+			}
+		}, func() {
+			close(closed)
+		})
+
+	// Blocking consumer which is gone must not stall the publisher.
+	f.publish(1)
+	unsubscribe()
+	f.publish(2)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("want consumer to be closed but it isn't")
+	}
+}
+
+func TestFanOut_subscribeSince(t *testing.T) {
+	f := newFanOut("test", nil)
+	f.replay = newReplayBuffer(3)
+
+	since := time.Now()
+	for i := 1; i <= 5; i++ {
+		f.publish(i)
+	}
+
+	open := make(chan struct{})
+	close(open)
+
+	var late []int
+	events := make(chan interface{})
+	f.subscribeSince(SubscriberConfig{Buffer: 10}, since,
+		func(event interface{}, done <-chan struct{}) bool {
+			select {
+			case events <- event:
+				return true
+			case <-done:
+				return false
+			}
+		}, func() {
+			close(events)
+		})
+
+	f.publish(6)
+	f.close()
+	for e := range events {
+		late = append(late, e.(int))
+	}
+	if want := []int{3, 4, 5, 6}; !reflect.DeepEqual(late, want) {
+		t.Errorf("want replayed and live events %v but got %v", want, late)
+	}
+
+	// Consumer attaching without replay to the closed fan-out gets
+	// nothing.
+	closed := collect(f, SubscriberConfig{}, open)()
+	if len(closed) != 0 {
+		t.Errorf("want no events without replay but got %v", closed)
+	}
+}
+
+func TestDealsFeed_ReplaySince(t *testing.T) {
+	feed := &DealsFeed{fan: newFanOut("deals", nil)}
+	feed.fan.replay = newReplayBuffer(2)
+
+	feed.fan.publish(MarketDeal{ID: 1})
+	since := time.Now()
+	feed.fan.publish(MarketDeal{ID: 2})
+	feed.fan.publish(MarketDeal{ID: 3})
+
+	deals := feed.ReplaySince(since)
+	if len(deals) != 2 || deals[0].ID != 2 || deals[1].ID != 3 {
+		t.Errorf("want deals 2 and 3 but got %v", deals)
+	}
+
+	feed.fan.close()
+	replayed, _ := feed.SubscribeSince(SubscriberConfig{}, since)
+	var ids []int32
+	for d := range replayed {
+		ids = append(ids, d.ID)
+	}
+	if len(ids) != 2 {
+		t.Errorf("want deals replayed after close but got %v", ids)
+	}
+}
+
+func TestClient_DealsFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	backend := &mockCore{respJSON: `{"data": {"deals": []}}`}
+	client := (&Client{core: backend}).WithContext(ctx)
+
+	feed, err := client.DealsFeed([]string{"BTCETH"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	deals, _ := feed.Subscribe(SubscriberConfig{})
+	select {
+	case _, ok := <-deals:
+		if ok {
+			t.Error("want channel closed but got deal")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want channel closed once context is done")
+	}
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxFixedScale is the maximum scale of fixed-point numbers, so that
+// 10^scale fits into int64.
+const maxFixedScale = 18
+
+// TopOfBook is the best ask and bid of the market.
+type TopOfBook struct {
+	BestAsk       decimal.Decimal
+	BestAskVolume decimal.Decimal
+	BestBid       decimal.Decimal
+	BestBidVolume decimal.Decimal
+}
+
+// TopOfBookFixed is the best ask and bid of the market as fixed-point
+// numbers, which value is the number multiplied by 10^Scale. E.g. with
+// scale 8 price 0.025 is 2500000.
+type TopOfBookFixed struct {
+	Scale         int32
+	BestAsk       int64
+	BestAskVolume int64
+	BestBid       int64
+	BestBidVolume int64
+}
+
+// Decimal converts the fixed-point number of the top of book to
+// decimal.
+func (t TopOfBookFixed) Decimal(v int64) decimal.Decimal {
+	return decimal.New(v, -t.Scale)
+}
+
+// topOfBookQuery is the query of the best ask and bid.
+const topOfBookQuery = `
+	query GetTopOfBook($market: Market!, $limit: Int, $interval: Float) {
+		depth(market: $market, limit: $limit, interval: $interval) {
+			asks {
+				price
+				volume
+			}
+			bids {
+				price
+				volume
+			}
+		}
+	}
+`
+
+// topOfBookRaw is the top of book response with numbers left undecoded.
+type topOfBookRaw struct {
+	responseBase
+	Data struct {
+		Depth struct {
+			Asks []struct {
+				Price  json.RawMessage `json:"price"`
+				Volume json.RawMessage `json:"volume"`
+			} `json:"asks"`
+			Bids []struct {
+				Price  json.RawMessage `json:"price"`
+				Volume json.RawMessage `json:"volume"`
+			} `json:"bids"`
+		} `json:"depth"`
+	} `json:"data"`
+}
+
+// topOfBook requests the best ask and bid of the market leaving numbers
+// undecoded.
+func (c *Client) topOfBook(market string) (topOfBookRaw, error) {
+	var resp topOfBookRaw
+
+	req := request{
+		Query: topOfBookQuery,
+		Variables: depthRequestVariables{
+			Market: c.assets.market(market),
+			Limit:  1,
+		},
+	}
+
+	respJSON, err := c.do(false, req)
+	if err != nil {
+		return resp, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return resp, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return resp, exchangeError(err)
+	}
+
+	return resp, nil
+}
+
+// TopOfBook returns the best ask and bid of the market, zero if the
+// side of the book is empty.
+func (c *Client) TopOfBook(market string) (TopOfBook, error) {
+	var top TopOfBook
+
+	resp, err := c.topOfBook(market)
+	if err != nil {
+		return top, err
+	}
+
+	depth := resp.Data.Depth
+	if len(depth.Asks) > 0 {
+		top.BestAsk, err = decimalFromJSON(depth.Asks[0].Price)
+		if err == nil {
+			top.BestAskVolume, err = decimalFromJSON(depth.Asks[0].Volume)
+		}
+	}
+	if err == nil && len(depth.Bids) > 0 {
+		top.BestBid, err = decimalFromJSON(depth.Bids[0].Price)
+		if err == nil {
+			top.BestBidVolume, err = decimalFromJSON(depth.Bids[0].Volume)
+		}
+	}
+	if err != nil {
+		return TopOfBook{}, decodeError(err)
+	}
+
+	return top, nil
+}
+
+// TopOfBookFixed returns the best ask and bid of the market as
+// fixed-point numbers with given scale, zero if the side of the book is
+// empty. Numbers are parsed without arbitrary precision arithmetic, so
+// it fits high-frequency polling by consumers which don't need
+// arbitrary precision. Digits beyond the scale are truncated, numbers
+// which don't fit into int64 are rejected.
+func (c *Client) TopOfBookFixed(market string,
+	scale int32) (TopOfBookFixed, error) {
+
+	top := TopOfBookFixed{Scale: scale}
+
+	if scale < 0 || scale > maxFixedScale {
+		return top, errors.New("scale should be within [0, 18]")
+	}
+
+	resp, err := c.topOfBook(market)
+	if err != nil {
+		return top, err
+	}
+
+	depth := resp.Data.Depth
+	if len(depth.Asks) > 0 {
+		top.BestAsk, err = parseFixed(depth.Asks[0].Price, scale)
+		if err == nil {
+			top.BestAskVolume, err = parseFixed(depth.Asks[0].Volume, scale)
+		}
+	}
+	if err == nil && len(depth.Bids) > 0 {
+		top.BestBid, err = parseFixed(depth.Bids[0].Price, scale)
+		if err == nil {
+			top.BestBidVolume, err = parseFixed(depth.Bids[0].Volume, scale)
+		}
+	}
+	if err != nil {
+		return TopOfBookFixed{Scale: scale}, decodeError(err)
+	}
+
+	return top, nil
+}
+
+// decimalFromJSON decodes decimal given as JSON string or number.
+func decimalFromJSON(b []byte) (decimal.Decimal, error) {
+	var d decimal.Decimal
+	err := d.UnmarshalJSON(b)
+	return d, err
+}
+
+// parseFixed parses decimal number given as JSON string or number, e.g.
+// "0.025", into fixed-point number with given scale without
+// allocations. Digits beyond the scale are truncated.
+func parseFixed(b []byte, scale int32) (int64, error) {
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		b = b[1 : len(b)-1]
+	}
+	if len(b) == 0 {
+		return 0, errors.New("empty number")
+	}
+
+	negative := b[0] == '-'
+	if negative || b[0] == '+' {
+		b = b[1:]
+	}
+
+	var (
+		v        int64
+		digits   bool
+		point    bool
+		decimals int32
+	)
+	for _, ch := range b {
+		switch {
+		case ch == '.' && !point:
+			point = true
+		case ch >= '0' && ch <= '9':
+			digits = true
+			if point {
+				if decimals == scale {
+					// Digits beyond the scale are truncated.
+					continue
+				}
+				decimals++
+			}
+			if v > (math.MaxInt64-int64(ch-'0'))/10 {
+				return 0, errors.New("number overflows fixed-point " +
+					"int64: " + string(b))
+			}
+			v = v*10 + int64(ch-'0')
+		default:
+			return 0, errors.New("invalid number: " + string(b))
+		}
+	}
+	if !digits {
+		return 0, errors.New("invalid number: " + string(b))
+	}
+
+	for ; decimals < scale; decimals++ {
+		if v > math.MaxInt64/10 {
+			return 0, errors.New("number overflows fixed-point int64: " +
+				string(b))
+		}
+		v *= 10
+	}
+
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFixed(t *testing.T) {
+	tests := []struct {
+		in    string
+		scale int32
+		want  int64
+	}{
+		{`"0.025"`, 8, 2500000},
+		{`"1"`, 8, 100000000},
+		{`"-1.5"`, 2, -150},
+		{`0.123456789`, 8, 12345678},
+		{`"12."`, 1, 120},
+		{`".5"`, 1, 5},
+		{`"92233720368.54775807"`, 8, 9223372036854775807},
+	}
+	for _, test := range tests {
+		got, err := parseFixed([]byte(test.in), test.scale)
+		if err != nil {
+			t.Errorf("want no error for `%s` but got `%v`", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("want %d for `%s` but got %d", test.want, test.in, got)
+		}
+	}
+
+	invalid := []string{`""`, `"-"`, `"1.2.3"`, `"1e-8"`, `null`,
+		`"92233720368.54775808"`, `"9223372036854775808"`}
+	for _, in := range invalid {
+		if _, err := parseFixed([]byte(in), 8); err == nil {
+			t.Errorf("want error for `%s` but got no error", in)
+		}
+	}
+}
+
+func TestClient_TopOfBook(t *testing.T) {
+	backend := &mockCore{respJSON: `{"data": {"depth": {
+		"asks": [{"price": "0.03", "volume": "2"}],
+		"bids": [{"price": "0.025", "volume": "1.5"}]
+	}}}`}
+	client := &Client{core: backend}
+
+	top, err := client.TopOfBook("BTCETH")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !top.BestAsk.Equal(dec(0.03)) || !top.BestBidVolume.Equal(dec(1.5)) {
+		t.Errorf("want best ask 0.03 and bid volume 1.5 but got `%v`", top)
+	}
+
+	vars := backend.request.Variables.(depthRequestVariables)
+	if vars.Limit != 1 || vars.Market != "BTCETH" {
+		t.Errorf("want top of BTCETH requested but got `%v`", vars)
+	}
+
+	fixed, err := client.TopOfBookFixed("BTCETH", 8)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	want := TopOfBookFixed{
+		Scale:         8,
+		BestAsk:       3000000,
+		BestAskVolume: 200000000,
+		BestBid:       2500000,
+		BestBidVolume: 150000000,
+	}
+	if fixed != want {
+		t.Errorf("want `%v` but got `%v`", want, fixed)
+	}
+	if !fixed.Decimal(fixed.BestBid).Equal(dec(0.025)) {
+		t.Errorf("want best bid 0.025 but got %s",
+			fixed.Decimal(fixed.BestBid))
+	}
+
+	t.Run("when book is empty", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: `{"data": {"depth": {
+			"asks": [], "bids": []}}}`}}
+		fixed, err := client.TopOfBookFixed("BTCETH", 8)
+		if err != nil || fixed != (TopOfBookFixed{Scale: 8}) {
+			t.Errorf("want zero top of book but got `%v` and `%v`",
+				fixed, err)
+		}
+	})
+	t.Run("when number is invalid", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: `{"data": {"depth": {
+			"asks": [{"price": "abc", "volume": "1"}]}}}`}}
+		_, err := client.TopOfBookFixed("BTCETH", 8)
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("want decode error but got `%v`", err)
+		}
+	})
+	t.Run("when scale is invalid", func(t *testing.T) {
+		if _, err := client.TopOfBookFixed("BTCETH", 19); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+}
+
+func BenchmarkParseFixed(b *testing.B) {
+	in := []byte(`"0.02512345"`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseFixed(in, 8)
+	}
+}
+
+func BenchmarkDecimalFromJSON(b *testing.B) {
+	in := []byte(`"0.02512345"`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		decimalFromJSON(in)
+	}
+}
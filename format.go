@@ -0,0 +1,115 @@
+package client
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultAssetPrecision is the precision of assets without metadata.
+const defaultAssetPrecision = 8
+
+// AssetInfo is the display metadata of the asset.
+type AssetInfo struct {
+	// Code is the asset code, e.g. "BTC".
+	Code string
+
+	// Symbol is the currency sign of the asset, e.g. "₿", empty if
+	// the asset has none.
+	Symbol string
+
+	// Precision is the number of decimal places of the asset amounts
+	// accounted by the exchange, e.g. 8 for BTC which is accounted in
+	// satoshis.
+	Precision int32
+}
+
+var (
+	// assetInfosMtx guards assetInfos.
+	assetInfosMtx sync.RWMutex
+
+	// assetInfos is the metadata of the assets by their codes.
+	assetInfos = map[string]AssetInfo{
+		"BTC":  {Code: "BTC", Symbol: "₿", Precision: 8},
+		"BCH":  {Code: "BCH", Precision: 8},
+		"DASH": {Code: "DASH", Precision: 8},
+		"LTC":  {Code: "LTC", Symbol: "Ł", Precision: 8},
+		"ETH":  {Code: "ETH", Symbol: "Ξ", Precision: 8},
+	}
+)
+
+// RegisterAssetInfo sets the display metadata of the asset, e.g. of the
+// local asset code configured with WithAssetCodes.
+func RegisterAssetInfo(info AssetInfo) {
+	assetInfosMtx.Lock()
+	assetInfos[strings.ToUpper(info.Code)] = info
+	assetInfosMtx.Unlock()
+}
+
+// LookupAssetInfo returns the display metadata of the asset. Unknown
+// assets have 8 decimal places and no symbol.
+func LookupAssetInfo(asset string) AssetInfo {
+	assetInfosMtx.RLock()
+	info, ok := assetInfos[strings.ToUpper(asset)]
+	assetInfosMtx.RUnlock()
+
+	if !ok {
+		info = AssetInfo{Code: asset, Precision: defaultAssetPrecision}
+	}
+	return info
+}
+
+// FormatAmount formats the amount of the asset for display with exactly
+// asset precision decimal places followed by the asset code, e.g.
+// "0.00012000 BTC". Amount is truncated toward zero, so the displayed
+// balance is never more than the available one.
+func FormatAmount(asset string, amount decimal.Decimal) string {
+	info := LookupAssetInfo(asset)
+	return amount.Truncate(info.Precision).StringFixed(info.Precision) +
+		" " + info.Code
+}
+
+// ParseUserAmount parses the amount of the asset entered by user, e.g.
+// "0.5", "0.5 BTC" or "₿0.5". Amount should be positive and should not
+// have more decimal places than asset precision, as such amount can not
+// be accounted by the exchange and silent rounding would change it.
+func ParseUserAmount(asset string, s string) (decimal.Decimal, error) {
+	info := LookupAssetInfo(asset)
+
+	s = strings.TrimSpace(s)
+	if info.Symbol != "" {
+		s = strings.TrimSpace(strings.TrimPrefix(s, info.Symbol))
+	}
+	if n := len(s) - len(info.Code); n >= 0 &&
+		strings.EqualFold(s[n:], info.Code) {
+		s = strings.TrimSpace(s[:n])
+	}
+
+	if s == "" {
+		return decimal.Zero, errors.New("amount is empty")
+	}
+	if strings.ContainsAny(s, "eE") {
+		return decimal.Zero, errors.New("amount should not be in " +
+			"exponent notation")
+	}
+
+	amount, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, errors.New("invalid amount: " + s)
+	}
+
+	if amount.Sign() <= 0 {
+		return decimal.Zero, errors.New("amount should be positive")
+	}
+
+	if !amount.Truncate(info.Precision).Equal(amount) {
+		return decimal.Zero, errors.New("amount has more than " +
+			strconv.Itoa(int(info.Precision)) +
+			" decimal places of " + info.Code)
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		asset  string
+		amount string
+		want   string
+	}{
+		{"BTC", "0.00012", "0.00012000 BTC"},
+		{"btc", "1", "1.00000000 BTC"},
+		{"BTC", "0.123456789", "0.12345678 BTC"},
+		{"BTC", "-0.123456789", "-0.12345678 BTC"},
+		{"XYZ", "2.5", "2.50000000 XYZ"},
+	}
+	for _, test := range tests {
+		got := FormatAmount(test.asset, decimal.RequireFromString(test.amount))
+		if got != test.want {
+			t.Errorf("want `%s` but got `%s`", test.want, got)
+		}
+	}
+}
+
+func TestParseUserAmount(t *testing.T) {
+	valid := map[string]string{
+		"0.5":          "0.5",
+		" 0.5 BTC ":    "0.5",
+		"0.5btc":       "0.5",
+		"₿0.00000001":  "0.00000001",
+		"1.2300000000": "1.23",
+	}
+	for s, want := range valid {
+		got, err := ParseUserAmount("BTC", s)
+		if err != nil {
+			t.Errorf("want no error for `%s` but got `%v`", s, err)
+			continue
+		}
+		if !got.Equal(decimal.RequireFromString(want)) {
+			t.Errorf("want %s for `%s` but got %s", want, s, got)
+		}
+	}
+
+	invalid := []string{"", "BTC", "abc", "-1", "0", "1e-3",
+		"0.000000001", "0.5 ETH"}
+	for _, s := range invalid {
+		if _, err := ParseUserAmount("BTC", s); err == nil {
+			t.Errorf("want error for `%s` but got no error", s)
+		}
+	}
+}
+
+func TestRegisterAssetInfo(t *testing.T) {
+	RegisterAssetInfo(AssetInfo{Code: "USDT", Symbol: "₮", Precision: 2})
+	defer func() {
+		assetInfosMtx.Lock()
+		delete(assetInfos, "USDT")
+		assetInfosMtx.Unlock()
+	}()
+
+	if got := FormatAmount("USDT", dec(1.239)); got != "1.23 USDT" {
+		t.Errorf("want `1.23 USDT` but got `%s`", got)
+	}
+	if _, err := ParseUserAmount("USDT", "₮1.234"); err == nil {
+		t.Error("want error of amount exceeding precision")
+	}
+}
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// harRedacted is the value which replaces sensitive header values.
+const harRedacted = "[REDACTED]"
+
+// harSensitiveHeaders is the set of headers which values are never
+// written to HAR files.
+var harSensitiveHeaders = map[string]struct{}{
+	"Authorization": {},
+	"Cookie":        {},
+	"Set-Cookie":    {},
+	SignatureHeader: {},
+}
+
+// WithHARRecorder makes the client record every request/response pair
+// into r, which may be saved as HAR file for exchange support team.
+// Credentials are redacted from recorded headers, and the values of
+// the request and response bodies fields which may hold credentials,
+// addresses or invoices are redacted like in WithLogger. Bodies which
+// are not JSON are not recorded.
+func WithHARRecorder(r *HARRecorder) Option {
+	return func(o *options) {
+		o.har = r
+	}
+}
+
+// HARRecorder records sanitized request/response pairs of the client
+// session in HTTP Archive (HAR) 1.2 format.
+type HARRecorder struct {
+	mtx     sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder creates new empty recorder.
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// Len returns number of recorded entries.
+func (r *HARRecorder) Len() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.entries)
+}
+
+// WriteTo writes recorded session to w as HAR JSON document.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mtx.Lock()
+	entries := make([]harEntry, len(r.entries))
+	copy(entries, r.entries)
+	r.mtx.Unlock()
+
+	doc := harDocument{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{
+				Name:    "exchange-graphql-client",
+				Version: "1.0",
+			},
+			Entries: entries,
+		},
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Save writes recorded session to the file at path.
+func (r *HARRecorder) Save(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// record adds request/response pair to the session. Response may be nil
+// if request failed, in this case err describes the failure.
+func (r *HARRecorder) record(started time.Time, req *http.Request,
+	reqBody []byte, resp *http.Response, respBody []byte, err error) {
+
+	e := harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            durationMs(time.Since(started)),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNameValue{},
+			PostData: &harPostData{
+				MimeType: req.Header.Get("Content-Type"),
+				Text:     harBody(reqBody),
+			},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache: struct{}{},
+	}
+	e.Timings.Wait = e.Time
+
+	if resp != nil {
+		e.Response.Status = resp.StatusCode
+		e.Response.StatusText = http.StatusText(resp.StatusCode)
+		e.Response.HTTPVersion = resp.Proto
+		e.Response.Headers = harHeaders(resp.Header)
+		e.Response.Content = harContent{
+			Size:     len(respBody),
+			MimeType: resp.Header.Get("Content-Type"),
+			Text:     harBody(respBody),
+		}
+		e.Response.BodySize = len(respBody)
+	}
+
+	if err != nil {
+		e.Comment = err.Error()
+	}
+
+	r.mtx.Lock()
+	r.entries = append(r.entries, e)
+	r.mtx.Unlock()
+}
+
+// harHeaders converts http headers to HAR headers redacting sensitive
+// values.
+func harHeaders(h http.Header) []harNameValue {
+	res := []harNameValue{}
+	for name, values := range h {
+		for _, value := range values {
+			if _, ok := harSensitiveHeaders[http.CanonicalHeaderKey(
+				name)]; ok {
+				value = harRedacted
+			}
+			res = append(res, harNameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// harBody returns the JSON body with sensitive field values redacted,
+// the body is kept as is if it has none. Bodies which are not JSON, e.g.
+// MessagePack ones, are replaced with harRedacted as their fields can't
+// be redacted.
+func harBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return string(body)
+	}
+
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil || d.More() {
+		return harRedacted
+	}
+
+	if !redactValue(v) {
+		return string(body)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return harRedacted
+	}
+	return string(data)
+}
+
+// durationMs returns duration in milliseconds as required by HAR.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
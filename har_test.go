@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_graphQLCore_do_har(t *testing.T) {
+	s := newMockBackendServer()
+	defer s.stop()
+	s.response.code = 200
+	s.response.body = `{ "data": {} }`
+
+	har := NewHARRecorder()
+	c := &graphQLCore{
+		url: s.url(),
+		jwt: "secret-token",
+		har: har,
+	}
+	if _, err := c.do(context.Background(), true,
+		request{Query: "query"}); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	s.stop()
+	if _, err := c.do(context.Background(), true,
+		request{Query: "query"}); err == nil {
+		t.Fatal("want error but got no error")
+	}
+
+	if har.Len() != 2 {
+		t.Fatalf("want 2 entries but got %v", har.Len())
+	}
+
+	var buf bytes.Buffer
+	if _, err := har.WriteTo(&buf); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Error("want credentials to be redacted")
+	}
+
+	var doc harDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to json.Unmarshal HAR: %v", err)
+	}
+	if doc.Log.Version != "1.2" {
+		t.Errorf("want HAR version 1.2 but got `%s`", doc.Log.Version)
+	}
+
+	ok := doc.Log.Entries[0]
+	if ok.Response.Status != 200 {
+		t.Errorf("want status 200 but got %v", ok.Response.Status)
+	}
+	if ok.Response.Content.Text != s.response.body {
+		t.Errorf("want response body `%s` but got `%s`",
+			s.response.body, ok.Response.Content.Text)
+	}
+	if ok.Request.PostData == nil ||
+		!strings.Contains(ok.Request.PostData.Text, "query") {
+		t.Error("want request body to be recorded")
+	}
+
+	failed := doc.Log.Entries[1]
+	if failed.Response.Status != 0 || failed.Comment == "" {
+		t.Errorf("want failed entry with comment but got `%#v`", failed)
+	}
+}
+
+func Test_graphQLCore_do_harBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			if strings.Contains(string(body), "issueApiToken") {
+				w.Write([]byte(`{"data": {"issueApiToken": "0201secret"}}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"withdrawWithBlockchain": {
+				"paymentID": "1", "paymentAddr": "secret-address",
+				"change": "0.1"}}}`))
+		}))
+	defer server.Close()
+
+	har := NewHARRecorder()
+	c := &graphQLCore{url: server.URL, jwt: "jwt", har: har}
+
+	if _, err := c.do(context.Background(), true,
+		request{Query: issueApiTokenQuery}); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if _, err := c.do(context.Background(), true, request{
+		Query: "mutation Withdraw { withdrawWithBlockchain }",
+		Variables: withdrawRequestVariables{
+			Asset:   "BTC",
+			Amount:  dec(0.1),
+			Address: "secret-address",
+		},
+	}); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := har.WriteTo(&buf); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	for _, secret := range []string{"0201secret", "secret-address"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("want `%s` to be redacted", secret)
+		}
+	}
+	if !strings.Contains(buf.String(), "paymentID") {
+		t.Error("want non-sensitive fields to be recorded")
+	}
+}
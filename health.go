@@ -0,0 +1,144 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Health is the snapshot of the client state for service health
+// endpoints.
+type Health struct {
+	// LastSuccess is the time of the last successful request by
+	// operation category, categories without successful requests are
+	// absent.
+	LastSuccess map[OperationCategory]time.Time `json:"lastSuccess"`
+
+	// LastError is the text of the last request error, empty if no
+	// request failed.
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorAt is the time of the last request error.
+	LastErrorAt time.Time `json:"lastErrorAt,omitempty"`
+
+	// TokenExpiry is the expiration time of the JWT the client is
+	// authorized with, zero if it is unknown or doesn't expire.
+	TokenExpiry time.Time `json:"tokenExpiry,omitempty"`
+
+	// StreamingSubscriptions is the number of subscriptions served with
+	// WebSocket streams.
+	StreamingSubscriptions int `json:"streamingSubscriptions"`
+
+	// PollingSubscriptions is the number of subscriptions served with
+	// long polling, e.g. because WebSocket is not reachable.
+	PollingSubscriptions int `json:"pollingSubscriptions"`
+
+	// ClockOffset is the learned offset of the server clock, zero if
+	// clock skew compensation is disabled.
+	ClockOffset time.Duration `json:"clockOffset"`
+
+	// InFlight is the number of outstanding requests.
+	InFlight int `json:"inFlight"`
+}
+
+// healthTracker tracks outcomes of the client requests. Nil tracker
+// tracks nothing.
+type healthTracker struct {
+	now func() time.Time
+
+	mtx         sync.Mutex
+	lastSuccess map[OperationCategory]time.Time
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// newHealthTracker creates new health tracker.
+func newHealthTracker() *healthTracker {
+	return &healthTracker{
+		now:         time.Now,
+		lastSuccess: make(map[OperationCategory]time.Time),
+	}
+}
+
+// observe records the outcome of the request of the category.
+func (h *healthTracker) observe(category OperationCategory, err error) {
+	if h == nil {
+		return
+	}
+
+	now := h.now()
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if err != nil {
+		h.lastError = err.Error()
+		h.lastErrorAt = now
+		return
+	}
+	h.lastSuccess[category] = now
+}
+
+// Health returns the snapshot of the client state, which services may
+// embed into their own health endpoints.
+func (c *Client) Health() Health {
+	h := Health{
+		LastSuccess: make(map[OperationCategory]time.Time),
+		InFlight:    len(c.InFlight()),
+	}
+
+	if c.health != nil {
+		c.health.mtx.Lock()
+		for category, t := range c.health.lastSuccess {
+			h.LastSuccess[category] = t
+		}
+		h.LastError = c.health.lastError
+		h.LastErrorAt = c.health.lastErrorAt
+		c.health.mtx.Unlock()
+	}
+
+	if core, ok := c.core.(*graphQLCore); ok {
+		core.mtx.RLock()
+		h.TokenExpiry = jwtExpiry(core.jwt)
+		core.mtx.RUnlock()
+	}
+
+	if c.subs != nil {
+		h.StreamingSubscriptions = int(atomic.LoadInt32(&c.subs.streams))
+		h.PollingSubscriptions = int(atomic.LoadInt32(&c.subs.polls))
+	}
+
+	if c.clock != nil {
+		h.ClockOffset = c.clock.Offset()
+	}
+
+	return h
+}
+
+// jwtExpiry returns the expiration time of the JWT taken from its exp
+// claim without verifying the token, zero if it can't be determined.
+func jwtExpiry(jwt string) time.Time {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(
+		strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil ||
+		claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
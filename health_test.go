@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClient_Health(t *testing.T) {
+	claims := base64.RawURLEncoding.EncodeToString(
+		[]byte(`{"sub": "1", "exp": 1546300800}`))
+
+	fail := false
+	core := CoreFunc(func(string, interface{}) ([]byte, error) {
+		if fail {
+			return nil, errors.New("exchange is down")
+		}
+		return []byte(`{ "data": {} }`), nil
+	})
+
+	client := &Client{
+		core:   core,
+		health: newHealthTracker(),
+		subs: newSubscriptions(SubscriptionConfig{
+			PollInterval: time.Millisecond,
+		}, nil, nopMetrics{}),
+	}
+
+	client.Markets([]string{"BTCETH"}, 1)
+	fail = true
+	client.Accounts([]string{"BTC"})
+
+	h := client.Health()
+	if _, ok := h.LastSuccess[CategoryMarketData]; !ok {
+		t.Error("want market data success recorded")
+	}
+	if _, ok := h.LastSuccess[CategoryAccount]; ok {
+		t.Error("want no account success recorded")
+	}
+	if h.LastError == "" || h.LastErrorAt.IsZero() {
+		t.Error("want last error recorded")
+	}
+
+	t.Run("subscriptions", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		deals, _ := client.WithContext(ctx).SubscribeDeals(
+			[]string{"BTCETH"})
+
+		waitFor(t, func() bool {
+			return client.Health().PollingSubscriptions == 1
+		})
+
+		cancel()
+		for range deals {
+		}
+		if n := client.Health().PollingSubscriptions; n != 0 {
+			t.Errorf("want no polling subscriptions but got %v", n)
+		}
+	})
+	t.Run("token expiry", func(t *testing.T) {
+		client := &Client{core: &graphQLCore{
+			jwt: "eyJhbGciOiJIUzI1NiJ9." + claims + ".signature",
+		}}
+
+		want := time.Unix(1546300800, 0)
+		if got := client.Health().TokenExpiry; !got.Equal(want) {
+			t.Errorf("want token expiry %v but got %v", want, got)
+		}
+	})
+}
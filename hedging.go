@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHedgePercentile is the default percentile of latencies
+	// used as hedging threshold.
+	defaultHedgePercentile = 0.95
+
+	// defaultHedgeWindow is the default number of latency samples the
+	// threshold is computed from.
+	defaultHedgeWindow = 100
+
+	// defaultHedgeDelay is the default hedging threshold used until
+	// enough latency samples are collected.
+	defaultHedgeDelay = 100 * time.Millisecond
+
+	// minHedgeSamples is the number of latency samples required to
+	// compute the threshold from them.
+	minHedgeSamples = 10
+)
+
+// HedgeConfig is the configuration of request hedging.
+type HedgeConfig struct {
+	// Endpoint is the secondary endpoint duplicate requests are sent to.
+	// If it is the endpoint of the original request, duplicate is sent
+	// to the client URL.
+	Endpoint string
+
+	// Percentile is the percentile of recent latencies after which the
+	// duplicate request is sent, zero means 0.95.
+	Percentile float64
+
+	// Window is the number of recent latencies the percentile is
+	// computed from, zero means 100.
+	Window int
+
+	// Delay is the threshold used until enough latencies are collected,
+	// zero means 100ms. It is also the lower bound of the threshold.
+	Delay time.Duration
+
+	// Metrics receives requests_hedged_total and hedge_wins_total
+	// counters, nil discards them.
+	Metrics Metrics
+}
+
+// WithHedging makes the client hedge idempotent requests which do not
+// need authorization, i.e. public market data: if the request isn't
+// answered within the percentile of recent latencies, its duplicate is
+// sent to the secondary endpoint, the fastest successful response is
+// taken and the other request is canceled. It trades extra load for
+// lower tail latency.
+func WithHedging(cfg HedgeConfig) Option {
+	return func(o *options) {
+		o.hedge = newHedger(cfg)
+	}
+}
+
+// hedger sends duplicate requests to cut the tail latency.
+type hedger struct {
+	cfg HedgeConfig
+
+	mtx       sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+// newHedger creates hedger with defaults applied.
+func newHedger(cfg HedgeConfig) *hedger {
+	if cfg.Percentile <= 0 || cfg.Percentile > 1 {
+		cfg.Percentile = defaultHedgePercentile
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultHedgeWindow
+	}
+	if cfg.Delay <= 0 {
+		cfg.Delay = defaultHedgeDelay
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = nopMetrics{}
+	}
+	return &hedger{cfg: cfg}
+}
+
+// observe records the latency of successful request.
+func (h *hedger) observe(d time.Duration) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if len(h.latencies) < h.cfg.Window {
+		h.latencies = append(h.latencies, d)
+		return
+	}
+	h.latencies[h.next] = d
+	h.next = (h.next + 1) % h.cfg.Window
+}
+
+// threshold returns the time after which duplicate request is sent.
+func (h *hedger) threshold() time.Duration {
+	h.mtx.Lock()
+	latencies := append([]time.Duration(nil), h.latencies...)
+	h.mtx.Unlock()
+
+	if len(latencies) < minHedgeSamples {
+		return h.cfg.Delay
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	d := latencies[int(float64(len(latencies)-1)*h.cfg.Percentile)]
+	if d < h.cfg.Delay {
+		d = h.cfg.Delay
+	}
+	return d
+}
+
+// hedgeResult is the result of one of hedged requests.
+type hedgeResult struct {
+	body    []byte
+	err     error
+	latency time.Duration
+	hedge   bool
+}
+
+// do sends the request to the primary endpoint and its duplicate to the
+// secondary one if the primary doesn't answer within the threshold. The
+// first successful response is returned, the other request is canceled.
+func (h *hedger) do(ctx context.Context, primary, fallback string,
+	send func(ctx context.Context, url string) ([]byte, error)) ([]byte,
+	error) {
+
+	secondary := h.cfg.Endpoint
+	if secondary == primary {
+		secondary = fallback
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	start := func(url string, hedge bool) {
+		go func() {
+			started := time.Now()
+			body, err := send(ctx, url)
+			results <- hedgeResult{body, err, time.Since(started), hedge}
+		}()
+	}
+
+	start(primary, false)
+	pending := 1
+
+	timer := time.NewTimer(h.threshold())
+	defer timer.Stop()
+
+	var err error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if secondary == primary {
+				continue
+			}
+			h.cfg.Metrics.Add("requests_hedged_total", nil, 1)
+			start(secondary, true)
+			pending++
+		case r := <-results:
+			pending--
+			if r.err != nil {
+				err = r.err
+				continue
+			}
+			h.observe(r.latency)
+			if r.hedge {
+				h.cfg.Metrics.Add("hedge_wins_total", nil, 1)
+			}
+			return r.body, nil
+		}
+	}
+
+	return nil, err
+}
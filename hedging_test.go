@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedger_threshold(t *testing.T) {
+	h := newHedger(HedgeConfig{Delay: 5 * time.Millisecond, Window: 20})
+
+	if got := h.threshold(); got != 5*time.Millisecond {
+		t.Errorf("want initial delay threshold but got %v", got)
+	}
+
+	for i := 1; i <= 40; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+
+	// Window keeps latencies 21ms..40ms, 95th percentile of them.
+	if got := h.threshold(); got != 39*time.Millisecond {
+		t.Errorf("want 39ms threshold but got %v", got)
+	}
+}
+
+func TestGraphQLCore_hedging(t *testing.T) {
+	var primaryCanceled int32
+	primary := httptest.NewServer(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+
+		// Body is read, so server notices canceled request.
+		ioutil.ReadAll(r.Body)
+
+		select {
+		case <-time.After(2 * time.Second):
+			w.Write([]byte(`{"data": {"from": "primary"}}`))
+		case <-r.Context().Done():
+			atomic.StoreInt32(&primaryCanceled, 1)
+		}
+	}))
+	defer primary.Close()
+
+	var secondaryCalls int32
+	secondary := httptest.NewServer(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+
+		atomic.AddInt32(&secondaryCalls, 1)
+		w.Write([]byte(`{"data": {"from": "secondary"}}`))
+	}))
+	defer secondary.Close()
+
+	metrics := &recordingMetrics{}
+	c := &graphQLCore{
+		url: primary.URL,
+		hedge: newHedger(HedgeConfig{
+			Endpoint: secondary.URL,
+			Delay:    20 * time.Millisecond,
+			Metrics:  metrics,
+		}),
+	}
+
+	t.Run("slow public query is hedged", func(t *testing.T) {
+		started := time.Now()
+		body, err := c.do(context.Background(), false,
+			request{Query: "query Q { from }"})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if string(body) != `{"data": {"from": "secondary"}}` {
+			t.Errorf("want secondary response but got `%s`", body)
+		}
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("want hedged response fast but it took %v", elapsed)
+		}
+		if metrics.count("requests_hedged_total") != 1 ||
+			metrics.count("hedge_wins_total") != 1 {
+			t.Error("want hedged request and its win counted")
+		}
+
+		waitFor(t, func() bool {
+			return atomic.LoadInt32(&primaryCanceled) == 1
+		})
+	})
+	t.Run("mutation is not hedged", func(t *testing.T) {
+		atomic.StoreInt32(&secondaryCalls, 0)
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			100*time.Millisecond)
+		defer cancel()
+
+		c.do(ctx, false, request{Query: "mutation M { from }"})
+		if n := atomic.LoadInt32(&secondaryCalls); n != 0 {
+			t.Errorf("want no duplicate of mutation but got %d", n)
+		}
+	})
+	t.Run("authorized query is not hedged", func(t *testing.T) {
+		atomic.StoreInt32(&secondaryCalls, 0)
+
+		c := &graphQLCore{url: primary.URL, jwt: "token", hedge: c.hedge}
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			100*time.Millisecond)
+		defer cancel()
+
+		c.do(ctx, true, request{Query: "query Q { from }"})
+		if n := atomic.LoadInt32(&secondaryCalls); n != 0 {
+			t.Errorf("want no duplicate of authorized query but got %d", n)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OperationCategory is the category of client operations which may be
+// canceled together, see CancelAll.
+type OperationCategory string
+
+// Operation categories assigned to requests by default, see
+// WithOperationCategory to assign custom one.
+const (
+	// CategoryMarketData is public queries, e.g. Depth or Markets.
+	CategoryMarketData OperationCategory = "market-data"
+
+	// CategoryAccount is authorized queries, e.g. Accounts or Order.
+	CategoryAccount OperationCategory = "account"
+
+	// CategoryTrading is mutations, e.g. CreateOrder or Withdraw.
+	CategoryTrading OperationCategory = "trading"
+)
+
+// Priority is the informational priority of the operation reported by
+// InFlight.
+type Priority int
+
+// Operation priorities, PriorityNormal is the default one.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// operationKey is the context key of the operation category and
+// priority.
+type operationKey struct{}
+
+// operationTags is the operation category and priority given in the
+// context.
+type operationTags struct {
+	category OperationCategory
+	priority Priority
+}
+
+// WithOperationCategory returns the copy of the context which makes
+// client requests made with it to be of given category instead of the
+// default one.
+func WithOperationCategory(ctx context.Context,
+	category OperationCategory) context.Context {
+
+	tags := contextOperationTags(ctx)
+	tags.category = category
+	return context.WithValue(ctx, operationKey{}, tags)
+}
+
+// WithPriority returns the copy of the context which makes client
+// requests made with it to have given priority.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	tags := contextOperationTags(ctx)
+	tags.priority = priority
+	return context.WithValue(ctx, operationKey{}, tags)
+}
+
+// contextOperationTags returns the operation tags stored in the
+// context, zero if there are none.
+func contextOperationTags(ctx context.Context) operationTags {
+	tags, _ := ctx.Value(operationKey{}).(operationTags)
+	return tags
+}
+
+// operationCategory returns the category of the request, either given
+// with WithOperationCategory or derived from the request.
+func operationCategory(tags operationTags, needAuth bool,
+	r request) OperationCategory {
+
+	if tags.category != "" {
+		return tags.category
+	}
+
+	switch {
+	case isMutation(r.Query):
+		return CategoryTrading
+	case needAuth:
+		return CategoryAccount
+	default:
+		return CategoryMarketData
+	}
+}
+
+// Operation is the outstanding client request.
+type Operation struct {
+	// Name is the GraphQL operation name, e.g. "GetOrder".
+	Name string
+
+	// Category is the operation category.
+	Category OperationCategory
+
+	// Priority is the operation priority.
+	Priority Priority
+
+	// StartedAt is the time the request was sent.
+	StartedAt time.Time
+}
+
+// inFlight is the inventory of outstanding operations.
+type inFlight struct {
+	mtx sync.Mutex
+	seq uint64
+	ops map[uint64]inFlightOperation
+}
+
+// inFlightOperation is the outstanding operation along with the
+// function canceling it.
+type inFlightOperation struct {
+	Operation
+	cancel context.CancelFunc
+}
+
+// newInFlight creates empty inventory.
+func newInFlight() *inFlight {
+	return &inFlight{
+		ops: make(map[uint64]inFlightOperation),
+	}
+}
+
+// track registers the request in the inventory and returns the context
+// to perform it with and the function to be called once it is done.
+func (f *inFlight) track(ctx context.Context, needAuth bool,
+	r request) (context.Context, func()) {
+
+	if f == nil {
+		return ctx, func() {}
+	}
+
+	tags := contextOperationTags(ctx)
+	category := operationCategory(tags, needAuth, r)
+
+	// Anonymous operations are named after the selected field.
+	name := operationName(r.Query)
+	if name == "" {
+		name = mutationField(r.Query)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	f.mtx.Lock()
+	f.seq++
+	id := f.seq
+	f.ops[id] = inFlightOperation{
+		Operation: Operation{
+			Name:      name,
+			Category:  category,
+			Priority:  tags.priority,
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+	f.mtx.Unlock()
+
+	return ctx, func() {
+		f.mtx.Lock()
+		delete(f.ops, id)
+		f.mtx.Unlock()
+		cancel()
+	}
+}
+
+// list returns outstanding operations from the oldest one.
+func (f *inFlight) list() []Operation {
+	if f == nil {
+		return nil
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	// Sequence numbers order operations even if clock resolution is
+	// coarse.
+	ids := make([]uint64, 0, len(f.ops))
+	for id := range f.ops {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	ops := make([]Operation, len(ids))
+	for i, id := range ids {
+		ops[i] = f.ops[id].Operation
+	}
+	return ops
+}
+
+// cancel cancels outstanding operations of the category, all of them
+// if category is empty, and returns the number of canceled operations.
+func (f *inFlight) cancel(category OperationCategory) int {
+	if f == nil {
+		return 0
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	var n int
+	for _, op := range f.ops {
+		if category == "" || op.Category == category {
+			op.cancel()
+			n++
+		}
+	}
+	return n
+}
+
+// InFlight returns the client requests which are currently outstanding,
+// from the oldest one. It helps to find stuck calls.
+func (c *Client) InFlight() []Operation {
+	return c.inFlight.list()
+}
+
+// CancelAll aborts outstanding requests of the category, all of them if
+// category is empty, e.g. to drop background market data requests
+// during shutdown. Aborted requests return context canceled error. It
+// returns the number of aborted requests.
+func (c *Client) CancelAll(category OperationCategory) int {
+	return c.inFlight.cancel(category)
+}
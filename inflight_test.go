@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingCore is the core which blocks requests until their context is
+// done.
+type blockingCore struct {
+	started chan string
+}
+
+// do implements core.
+func (c *blockingCore) do(ctx context.Context, _ bool,
+	r request) ([]byte, error) {
+
+	c.started <- r.Query
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestClient_InFlight(t *testing.T) {
+	backend := &blockingCore{started: make(chan string, 3)}
+	client := &Client{core: backend, inFlight: newInFlight()}
+
+	var (
+		wg   sync.WaitGroup
+		mtx  sync.Mutex
+		errs = map[string]error{}
+	)
+	run := func(name string, call func(c *Client) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := call(client)
+			mtx.Lock()
+			errs[name] = err
+			mtx.Unlock()
+		}()
+		<-backend.started
+	}
+
+	run("depth", func(c *Client) error {
+		_, err := c.Depth("BTCETH", 10, 0)
+		return err
+	})
+	run("order", func(c *Client) error {
+		ctx := WithPriority(context.Background(), PriorityHigh)
+		_, err := c.WithContext(ctx).Order(1)
+		return err
+	})
+	run("background", func(c *Client) error {
+		ctx := WithOperationCategory(context.Background(), "background")
+		_, err := c.WithContext(ctx).Markets([]string{"BTCETH"}, 3600)
+		return err
+	})
+
+	ops := client.InFlight()
+	if len(ops) != 3 {
+		t.Fatalf("want 3 operations but got %d", len(ops))
+	}
+	if ops[0].Category != CategoryMarketData {
+		t.Errorf("want market data category but got `%s`", ops[0].Category)
+	}
+	if ops[1].Category != CategoryAccount || ops[1].Priority != PriorityHigh ||
+		ops[1].Name != "GetOrder" {
+		t.Errorf("want high priority GetOrder account operation but got "+
+			"`%v`", ops[1])
+	}
+	if ops[2].Category != "background" {
+		t.Errorf("want custom category but got `%s`", ops[2].Category)
+	}
+	if ops[0].StartedAt.After(ops[1].StartedAt) {
+		t.Error("want operations ordered by start time")
+	}
+
+	if n := client.CancelAll(CategoryMarketData); n != 1 {
+		t.Fatalf("want 1 market data operation canceled but got %d", n)
+	}
+	waitFor(t, func() bool { return len(client.InFlight()) == 2 })
+
+	mtx.Lock()
+	err := errs["depth"]
+	mtx.Unlock()
+	if err == nil || !strings.Contains(err.Error(), "canceled") {
+		t.Errorf("want canceled error but got `%v`", err)
+	}
+
+	if n := client.CancelAll(""); n != 2 {
+		t.Fatalf("want 2 operations canceled but got %d", n)
+	}
+	wg.Wait()
+
+	if ops := client.InFlight(); len(ops) != 0 {
+		t.Errorf("want no operations but got `%v`", ops)
+	}
+}
+
+// waitFor waits until the condition is true or fails the test.
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition is not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
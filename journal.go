@@ -0,0 +1,314 @@
+package client
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Journal entry kinds.
+const (
+	// JournalIntent is the entry recorded before mutation is sent.
+	JournalIntent = "intent"
+
+	// JournalDone is the entry recorded after mutation response is
+	// received or sending failed.
+	JournalDone = "done"
+)
+
+// JournalEntry is a single record of the journal.
+type JournalEntry struct {
+	// Seq is the sequence number of the mutation, done entry has the
+	// same sequence number as its intent.
+	Seq uint64 `json:"seq"`
+
+	// Kind is either intent or done.
+	Kind string `json:"kind"`
+
+	// Time is the time of the record.
+	Time time.Time `json:"time"`
+
+	// Operation is the GraphQL operation name of the mutation.
+	Operation string `json:"operation,omitempty"`
+
+	// Variables is the JSON encoded mutation variables.
+	Variables json.RawMessage `json:"variables,omitempty"`
+
+	// Error is the error of the mutation, empty if it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// Journal is an append-only write-ahead journal of mutation intents.
+// Every mutation is recorded before it is sent and marked done after
+// response, each record is synced to disk, so after a crash InFlight
+// reports exactly which mutations need reconciliation.
+type Journal struct {
+	mtx      sync.Mutex
+	file     *os.File
+	seq      uint64
+	inFlight map[uint64]JournalEntry
+	now      func() time.Time
+
+	// aead encrypts records, nil means records are stored in plain
+	// text, see OpenEncryptedJournal.
+	aead cipher.AEAD
+
+	// records is the number of records in the file, it binds encrypted
+	// records to their positions.
+	records uint64
+}
+
+// WithJournal makes the client record mutations into the journal.
+func WithJournal(j *Journal) Option {
+	return func(o *options) {
+		o.journal = j
+	}
+}
+
+// OpenJournal opens journal at path creating it if needed. Existing
+// records are loaded to continue the sequence and to restore in-flight
+// mutations of the previous session. Partially written last record,
+// e.g. after crash, is cut off, while corrupted records before it are
+// an error.
+func OpenJournal(path string) (*Journal, error) {
+	return openJournal(path, nil)
+}
+
+// openJournal opens journal at path which records are encrypted with
+// aead unless it is nil.
+func openJournal(path string, aead cipher.AEAD) (*Journal, error) {
+	entries, size, err := readJournal(path, aead)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Partially written record is cut off, so records appended later
+	// are neither glued to it nor shifted from their positions.
+	if err == nil {
+		if err := os.Truncate(path, size); err != nil {
+			return nil, errors.New("failed to truncate partial record: " +
+				err.Error())
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{
+		file:     f,
+		inFlight: make(map[uint64]JournalEntry),
+		now:      time.Now,
+		aead:     aead,
+		records:  uint64(len(entries)),
+	}
+
+	for _, e := range entries {
+		if e.Seq > j.seq {
+			j.seq = e.Seq
+		}
+		switch e.Kind {
+		case JournalIntent:
+			j.inFlight[e.Seq] = e
+		case JournalDone:
+			delete(j.inFlight, e.Seq)
+		}
+	}
+
+	return j, nil
+}
+
+// readJournal reads journal entries from the file at path decrypting
+// them with aead unless it is nil. It returns the entries and the size
+// of the file part they occupy. The last record which lacks the line
+// end is partially written, e.g. because of crash, and is skipped.
+// Any other record which fails the integrity check or can not be
+// decoded is an error.
+func readJournal(path string, aead cipher.AEAD) ([]JournalEntry, int64,
+	error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		entries []JournalEntry
+		size    int64
+	)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		// Records are written along with the line end, so the record
+		// without it was not completely written.
+		if size+int64(len(line)) == info.Size() {
+			break
+		}
+
+		data := line
+		if aead != nil {
+			var err error
+			data, err = openJournalRecord(aead, uint64(len(entries)), line)
+			if err != nil {
+				return nil, 0, errors.New("journal integrity check " +
+					"failed at record " + strconv.Itoa(len(entries)+1))
+			}
+		}
+
+		var e JournalEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, 0, errors.New("failed to decode journal record " +
+				strconv.Itoa(len(entries)+1) + ": " + err.Error())
+		}
+		entries = append(entries, e)
+		size += int64(len(line)) + 1
+	}
+
+	return entries, size, scanner.Err()
+}
+
+// InFlight returns mutations which were recorded as intents but were
+// not marked done, ordered by sequence number.
+func (j *Journal) InFlight() []JournalEntry {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	entries := make([]JournalEntry, 0, len(j.inFlight))
+	for _, e := range j.inFlight {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, k int) bool {
+		return entries[i].Seq < entries[k].Seq
+	})
+	return entries
+}
+
+// Resolve marks in-flight mutation with given sequence number as done,
+// e.g. after it has been reconciled with the exchange.
+func (j *Journal) Resolve(seq uint64) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if _, ok := j.inFlight[seq]; !ok {
+		return errors.New("mutation is not in flight")
+	}
+
+	return j.write(JournalEntry{Seq: seq, Kind: JournalDone})
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	return j.file.Close()
+}
+
+// begin records mutation intent and returns its sequence number.
+func (j *Journal) begin(r request) (uint64, error) {
+	vars, err := json.Marshal(r.Variables)
+	if err != nil {
+		return 0, errors.New("failed to json.Marshal variables: " +
+			err.Error())
+	}
+
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	e := JournalEntry{
+		Seq:       j.seq + 1,
+		Kind:      JournalIntent,
+		Operation: operationName(r.Query),
+		Variables: vars,
+	}
+	if err := j.write(e); err != nil {
+		return 0, err
+	}
+
+	j.seq++
+	return e.Seq, nil
+}
+
+// end marks mutation with given sequence number as done.
+func (j *Journal) end(seq uint64, mutationErr error) error {
+	e := JournalEntry{Seq: seq, Kind: JournalDone}
+	if mutationErr != nil {
+		e.Error = mutationErr.Error()
+	}
+
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	return j.write(e)
+}
+
+// write appends the entry to the journal file and syncs it to disk. It
+// should be called with mutex held.
+func (j *Journal) write(e JournalEntry) error {
+	e.Time = j.now()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.New("failed to json.Marshal entry: " + err.Error())
+	}
+
+	if j.aead != nil {
+		data, err = sealJournalRecord(j.aead, j.records, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return errors.New("failed to write journal: " + err.Error())
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return errors.New("failed to sync journal: " + err.Error())
+	}
+	j.records++
+
+	switch e.Kind {
+	case JournalIntent:
+		j.inFlight[e.Seq] = e
+	case JournalDone:
+		delete(j.inFlight, e.Seq)
+	}
+
+	return nil
+}
+
+// isMutation returns true if the GraphQL query is a mutation.
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
+// operationName returns the name of the GraphQL operation, empty if the
+// operation is anonymous.
+func operationName(query string) string {
+	query = strings.TrimSpace(query)
+	i := strings.IndexAny(query, " \t\n")
+	if i < 0 {
+		return ""
+	}
+
+	name := strings.TrimLeft(query[i:], " \t\n")
+	if end := strings.IndexAny(name, " \t\n({"); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// KeyFunc returns the key which encrypts the journal at rest, 16, 24 or
+// 32 bytes long for AES-128, AES-192 or AES-256 respectively. It lets
+// the key be obtained from the environment or from KMS, see KeyFromEnv.
+type KeyFunc func() ([]byte, error)
+
+// KeyFromEnv returns KeyFunc which reads base64 encoded key from the
+// environment variable.
+func KeyFromEnv(name string) KeyFunc {
+	return func() ([]byte, error) {
+		encoded, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, errors.New("environment variable " + name +
+				" is not set")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("failed to decode key from " + name +
+				": " + err.Error())
+		}
+		return key, nil
+	}
+}
+
+// OpenEncryptedJournal opens journal at path like OpenJournal, but
+// records are encrypted with AES-GCM using the key, so the journal,
+// which holds mutation variables like addresses and amounts, may be
+// stored on shared disks. Every record is authenticated along with its
+// position, so altered, reordered or removed records fail the
+// integrity check on load, except the records removed from the end of
+// the journal: the number of records is not authenticated, so the
+// journal truncated at a record boundary loads as if the removed
+// mutations were never recorded. Only partially written last record is
+// tolerated, it is cut off.
+func OpenEncryptedJournal(path string, key KeyFunc) (*Journal, error) {
+	k, err := key()
+	if err != nil {
+		return nil, errors.New("failed to get journal key: " + err.Error())
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, errors.New("failed to create cipher: " + err.Error())
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.New("failed to create GCM: " + err.Error())
+	}
+
+	return openJournal(path, aead)
+}
+
+// sealJournalRecord encrypts the record at the position and returns it
+// base64 encoded along with the random nonce.
+func sealJournalRecord(aead cipher.AEAD, position uint64,
+	data []byte) ([]byte, error) {
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.New("failed to generate nonce: " + err.Error())
+	}
+
+	sealed := aead.Seal(nonce, nonce, data, journalRecordAD(position))
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(encoded, sealed)
+	return encoded, nil
+}
+
+// openJournalRecord decrypts the record sealed by sealJournalRecord and
+// checks it is at the position.
+func openJournalRecord(aead cipher.AEAD, position uint64,
+	record []byte) ([]byte, error) {
+
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(record)))
+	n, err := base64.StdEncoding.Decode(sealed, record)
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[:n]
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("record is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, journalRecordAD(position))
+}
+
+// journalRecordAD returns additional data which binds the record to its
+// position.
+func journalRecordAD(position uint64) []byte {
+	ad := make([]byte, 8)
+	binary.BigEndian.PutUint64(ad, position)
+	return ad
+}
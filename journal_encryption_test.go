@@ -0,0 +1,99 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptedJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal")
+
+	key := func() ([]byte, error) {
+		return bytes.Repeat([]byte{7}, 32), nil
+	}
+
+	j, err := OpenEncryptedJournal(path, key)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := j.begin(request{
+			Query:     "mutation Withdraw($address: String!) { withdraw }",
+			Variables: withdrawRequestVariables{Address: "secret-address"},
+		}); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+	}
+	j.end(1, nil)
+	j.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if bytes.Contains(data, []byte("secret-address")) {
+		t.Fatal("want address to be encrypted")
+	}
+
+	// Simulate crash in the middle of the record.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.Write([]byte("AAAA"))
+	f.Close()
+
+	j, err = OpenEncryptedJournal(path, key)
+	if err != nil {
+		t.Fatalf("want partial record to be tolerated but got `%v`", err)
+	}
+	if n := len(j.InFlight()); n != 2 {
+		t.Errorf("want 2 in-flight mutations but got %v", n)
+	}
+	seq, err := j.begin(request{Query: "mutation { withdraw }"})
+	if err != nil || seq != 4 {
+		t.Errorf("want seq 4 but got %v, `%v`", seq, err)
+	}
+	j.Close()
+
+	wrongKey := func() ([]byte, error) {
+		return bytes.Repeat([]byte{8}, 32), nil
+	}
+	if j, err := OpenEncryptedJournal(path, wrongKey); err == nil {
+		t.Errorf("want integrity error with wrong key but got %v records",
+			j.records)
+	}
+
+	// Swapped records fail the integrity check.
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	lines[0], lines[1] = lines[1], lines[0]
+	ioutil.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'),
+		0600)
+	if _, err := OpenEncryptedJournal(path, key); err == nil {
+		t.Error("want integrity error of reordered records")
+	}
+}
+
+func TestKeyFromEnv(t *testing.T) {
+	const name = "EXCHANGE_CLIENT_TEST_JOURNAL_KEY"
+
+	os.Unsetenv(name)
+	if _, err := KeyFromEnv(name)(); err == nil {
+		t.Error("want error of unset variable")
+	}
+
+	want := bytes.Repeat([]byte{1}, 16)
+	os.Setenv(name, base64.StdEncoding.EncodeToString(want))
+	defer os.Unsetenv(name)
+
+	key, err := KeyFromEnv(name)()
+	if err != nil || !bytes.Equal(key, want) {
+		t.Errorf("want key decoded but got `%x`, `%v`", key, err)
+	}
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal")
+
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	client := &Client{
+		core:    &mockCore{respJSON: `{ "data": {} }`},
+		journal: j,
+	}
+
+	// Queries are not journaled.
+	client.Info()
+
+	client.CreateOrderBid("BTCETH", dec(1))
+	if len(j.InFlight()) != 0 {
+		t.Fatalf("want no in-flight mutations but got %v",
+			len(j.InFlight()))
+	}
+
+	// Simulate crash after intent is recorded.
+	seq, err := j.begin(request{
+		Query:     "mutation Withdraw($asset: Asset!) { withdraw }",
+		Variables: withdrawRequestVariables{Asset: "BTC"},
+	})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if seq != 2 {
+		t.Errorf("want seq 2 but got %v", seq)
+	}
+	j.Close()
+
+	// Partially written record.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.Write([]byte(`{"seq":3,"ki`))
+	f.Close()
+
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	defer j.Close()
+
+	inFlight := j.InFlight()
+	if len(inFlight) != 1 {
+		t.Fatalf("want 1 in-flight mutation but got %v", len(inFlight))
+	}
+	if inFlight[0].Seq != 2 || inFlight[0].Operation != "Withdraw" {
+		t.Errorf("want in-flight Withdraw #2 but got `%#v`", inFlight[0])
+	}
+
+	if err := j.Resolve(2); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(j.InFlight()) != 0 {
+		t.Errorf("want no in-flight mutations after resolve")
+	}
+	j.Close()
+
+	// Record appended after the partial one is not lost.
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(j.InFlight()) != 0 {
+		t.Errorf("want resolution to survive reopen but got %v",
+			j.InFlight())
+	}
+	j.Close()
+
+	// Corrupted record which is followed by others is an error.
+	data, _ := ioutil.ReadFile(path)
+	corrupted := append([]byte("{corrupted}\n"), data...)
+	ioutil.WriteFile(path, corrupted, 0600)
+	if _, err := OpenJournal(path); err == nil {
+		t.Error("want error of corrupted record")
+	}
+}
+
+func TestClient_do_journalFailedMutation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	j, err := OpenJournal(filepath.Join(dir, "journal"))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	defer j.Close()
+
+	client := &Client{
+		core:    &mockCore{error: errors.New("fail")},
+		journal: j,
+	}
+	if _, err := client.CreateOrderBid("BTCETH", dec(1)); err == nil {
+		t.Fatal("want error but got no error")
+	}
+
+	entries, _, err := readJournal(filepath.Join(dir, "journal"), nil)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(entries) != 2 || entries[1].Kind != JournalDone ||
+		entries[1].Error != "fail" {
+		t.Errorf("want intent and failed done entries but got `%#v`",
+			entries)
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := map[string]string{
+		"mutation CreateMarketOrder($market: Market!) {}": "CreateMarketOrder",
+		"\n\tmutation Withdraw(":                          "Withdraw",
+		"mutation { x }":                                  "",
+		"mutation":                                        "",
+	}
+	for query, want := range tests {
+		if got := operationName(query); got != want {
+			t.Errorf("want `%s` operation name of `%s` but got `%s`",
+				want, query, got)
+		}
+	}
+}
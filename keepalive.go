@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// KeepAliveConfig is the configuration of keepalive pings which keep
+// idle connections from being dropped by NATs and load balancers.
+type KeepAliveConfig struct {
+	// HTTPInterval is the idle time after which lightweight request is
+	// sent to keep pooled HTTP connections warm, zero disables HTTP
+	// pings. See Client.RunKeepAlive.
+	HTTPInterval time.Duration
+
+	// StreamInterval is the interval between pings of subscription
+	// streams which support them, e.g. WebSocket, zero disables stream
+	// pings. Stream which fails to answer ping is reconnected.
+	StreamInterval time.Duration
+}
+
+// WithKeepAlive sets the configuration of keepalive pings, which
+// usually depends on the environment, e.g. NAT idle timeouts.
+func WithKeepAlive(cfg KeepAliveConfig) Option {
+	return func(o *options) {
+		o.keepAlive = cfg
+	}
+}
+
+// keepAliveRequest is the lightest GraphQL request, which doesn't touch
+// exchange data.
+var keepAliveRequest = request{Query: "{ __typename }"}
+
+// pinger is implemented by event streams which support keepalive pings.
+type pinger interface {
+	// ping sends ping and waits for the answer.
+	ping(ctx context.Context) error
+}
+
+// RunKeepAlive sends lightweight request to the exchange server each
+// time the client is idle for the HTTP keepalive interval, so pooled
+// connections are not dropped and the next call doesn't pay for new
+// connection. It blocks until ctx is done, so it is supposed to be run
+// in its own goroutine. Ping errors are ignored as the next request
+// just dials new connection.
+func (c *Client) RunKeepAlive(ctx context.Context) error {
+	if c.keepAlive.HTTPInterval <= 0 {
+		return errors.New("http keepalive interval is not configured")
+	}
+
+	core, ok := c.core.(*graphQLCore)
+	if !ok {
+		return errors.New("keepalive is supported by GraphQL core only")
+	}
+
+	reqJSON, err := json.Marshal(keepAliveRequest)
+	if err != nil {
+		return errors.New("failed to json.Marshal request: " +
+			err.Error())
+	}
+
+	interval := c.keepAlive.HTTPInterval
+	for {
+		idle := time.Since(time.Unix(0, atomic.LoadInt64(&core.lastUsed)))
+		if idle >= interval {
+			core.sendTo(ctx, core.url, false, reqJSON)
+			idle = 0
+		}
+
+		if !sleep(ctx, interval-idle) {
+			return ctx.Err()
+		}
+	}
+}
+
+// keepStreamAlive pings the stream every interval until ctx is done. If
+// ping fails the stream is closed, so it gets reconnected.
+func keepStreamAlive(ctx context.Context, stream eventStream,
+	interval time.Duration) {
+
+	p, ok := stream.(pinger)
+	if !ok || interval <= 0 {
+		return
+	}
+
+	for sleep(ctx, interval) {
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := p.ping(pingCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			stream.close()
+			return
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_RunKeepAlive(t *testing.T) {
+	t.Run("pings idle connections", func(t *testing.T) {
+		var (
+			mtx   sync.Mutex
+			pings int
+		)
+		s := httptest.NewServer(http.HandlerFunc(func(
+			w http.ResponseWriter, r *http.Request) {
+
+			body, _ := ioutil.ReadAll(r.Body)
+			if strings.Contains(string(body), "__typename") {
+				mtx.Lock()
+				pings++
+				mtx.Unlock()
+			}
+			w.Write([]byte(`{"data": {"__typename": "Query"}}`))
+		}))
+		defer s.Close()
+
+		client, err := NewClient(s.URL, "", "", WithKeepAlive(
+			KeepAliveConfig{HTTPInterval: 10 * time.Millisecond}))
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			55*time.Millisecond)
+		defer cancel()
+		if err := client.RunKeepAlive(ctx); err != context.DeadlineExceeded {
+			t.Errorf("want context.DeadlineExceeded but got `%v`", err)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		if pings < 2 {
+			t.Errorf("want at least 2 pings but got %d", pings)
+		}
+	})
+	t.Run("when interval is not configured", func(t *testing.T) {
+		client, err := NewClient("http://exchange.test", "", "")
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if err := client.RunKeepAlive(context.Background()); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+	t.Run("when core is not GraphQL one", func(t *testing.T) {
+		client := &Client{
+			core:      &mockCore{},
+			keepAlive: KeepAliveConfig{HTTPInterval: time.Second},
+		}
+		if err := client.RunKeepAlive(context.Background()); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+}
+
+// pingingStream is event stream which answers pings with err.
+type pingingStream struct {
+	mtx    sync.Mutex
+	err    error
+	pings  int
+	closed chan struct{}
+}
+
+func newPingingStream(err error) *pingingStream {
+	return &pingingStream{err: err, closed: make(chan struct{})}
+}
+
+func (s *pingingStream) next(ctx context.Context) (json.RawMessage,
+	error) {
+
+	select {
+	case <-s.closed:
+		return nil, errors.New("stream is closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *pingingStream) close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	select {
+	case <-s.closed:
+	default:
+		close(s.closed)
+	}
+	return nil
+}
+
+func (s *pingingStream) ping(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pings++
+	return s.err
+}
+
+func TestKeepStreamAlive(t *testing.T) {
+	t.Run("pings stream", func(t *testing.T) {
+		stream := newPingingStream(nil)
+		ctx, cancel := context.WithTimeout(context.Background(),
+			35*time.Millisecond)
+		defer cancel()
+
+		keepStreamAlive(ctx, stream, 10*time.Millisecond)
+
+		stream.mtx.Lock()
+		defer stream.mtx.Unlock()
+		if stream.pings < 2 {
+			t.Errorf("want at least 2 pings but got %d", stream.pings)
+		}
+	})
+	t.Run("closes stream if ping fails", func(t *testing.T) {
+		stream := newPingingStream(errors.New("timeout"))
+		keepStreamAlive(context.Background(), stream, time.Millisecond)
+
+		select {
+		case <-stream.closed:
+		default:
+			t.Error("want stream closed but it isn't")
+		}
+	})
+}
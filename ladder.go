@@ -0,0 +1,229 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// LadderSpacing is the spacing of ladder levels from the mid price.
+type LadderSpacing string
+
+// Ladder spacings.
+const (
+	// LadderArithmetic places level i at mid ± i*Step.
+	LadderArithmetic LadderSpacing = "arithmetic"
+
+	// LadderGeometric places level i at mid * (1 ± Step)^i.
+	LadderGeometric LadderSpacing = "geometric"
+)
+
+// LadderConfig is the configuration of the symmetric quote ladder.
+type LadderConfig struct {
+	// Market is the market of the quotes.
+	Market string
+
+	// Mid is the price the ladder is built around.
+	Mid decimal.Decimal
+
+	// Levels is the number of levels on each side.
+	Levels int
+
+	// Spacing is the spacing of levels, LadderArithmetic by default.
+	Spacing LadderSpacing
+
+	// Step is the distance between levels, absolute price for
+	// arithmetic spacing and fraction of the price for geometric one.
+	Step decimal.Decimal
+
+	// Tick is the price tick, bids are rounded down and asks up to its
+	// multiple. Zero disables rounding.
+	Tick decimal.Decimal
+
+	// Size is the amount of every level before the skew.
+	Size decimal.Decimal
+
+	// Inventory is the current inventory normalized to [-1, 1], where
+	// positive means long.
+	Inventory decimal.Decimal
+
+	// Skew is the share of the size shifted by the full inventory in
+	// [0, 1]: long inventory enlarges asks and shrinks bids by
+	// Size*Skew*Inventory, so the ladder leans to flatten the position.
+	Skew decimal.Decimal
+}
+
+// OpenQuote is the quote which is already on the book.
+type OpenQuote struct {
+	OrderIntent
+
+	// ID is the ID of the open order.
+	ID int64
+}
+
+// LadderDiff is the minimal set of changes which turns open quotes into
+// the desired ones.
+type LadderDiff struct {
+	// Cancel is the open quotes which are not desired.
+	Cancel []OpenQuote
+
+	// Place is the desired quotes which are not open.
+	Place []OrderIntent
+}
+
+// BuildLadder builds the symmetric ladder of limit order intents, asks
+// first from the nearest to the mid, then bids in the same order.
+// Levels whose size is skewed down to zero are omitted.
+func BuildLadder(cfg LadderConfig) ([]OrderIntent, error) {
+	one := decimal.New(1, 0)
+
+	switch {
+	case cfg.Market == "":
+		return nil, errors.New("market is not specified")
+	case cfg.Mid.Sign() <= 0:
+		return nil, errors.New("mid price should be positive")
+	case cfg.Levels <= 0:
+		return nil, errors.New("number of levels should be positive")
+	case cfg.Step.Sign() <= 0:
+		return nil, errors.New("step should be positive")
+	case cfg.Tick.Sign() < 0:
+		return nil, errors.New("tick should not be negative")
+	case cfg.Size.Sign() <= 0:
+		return nil, errors.New("size should be positive")
+	case cfg.Inventory.Abs().GreaterThan(one):
+		return nil, errors.New("inventory should be in [-1, 1]")
+	case cfg.Skew.Sign() < 0 || cfg.Skew.GreaterThan(one):
+		return nil, errors.New("skew should be in [0, 1]")
+	}
+
+	spacing := cfg.Spacing
+	if spacing == "" {
+		spacing = LadderArithmetic
+	}
+
+	var askPrice, bidPrice func(level int64) decimal.Decimal
+	switch spacing {
+	case LadderArithmetic:
+		askPrice = func(level int64) decimal.Decimal {
+			return cfg.Mid.Add(cfg.Step.Mul(decimal.New(level, 0)))
+		}
+		bidPrice = func(level int64) decimal.Decimal {
+			return cfg.Mid.Sub(cfg.Step.Mul(decimal.New(level, 0)))
+		}
+	case LadderGeometric:
+		if cfg.Step.GreaterThanOrEqual(one) {
+			return nil, errors.New("geometric step should be less than 1")
+		}
+		askPrice = func(level int64) decimal.Decimal {
+			return cfg.Mid.Mul(pow(one.Add(cfg.Step), level))
+		}
+		bidPrice = func(level int64) decimal.Decimal {
+			return cfg.Mid.Mul(pow(one.Sub(cfg.Step), level))
+		}
+	default:
+		return nil, errors.New("unknown ladder spacing: " + string(spacing))
+	}
+
+	shift := cfg.Size.Mul(cfg.Skew).Mul(cfg.Inventory)
+	askSize := cfg.Size.Add(shift)
+	bidSize := cfg.Size.Sub(shift)
+
+	quotes := make([]OrderIntent, 0, 2*cfg.Levels)
+
+	if askSize.Sign() > 0 {
+		for i := 1; i <= cfg.Levels; i++ {
+			price := roundToTick(askPrice(int64(i)), cfg.Tick, true)
+			quotes = append(quotes, newQuote(cfg.Market, OrderAsk,
+				askSize, price))
+		}
+	}
+
+	if bidSize.Sign() > 0 {
+		for i := 1; i <= cfg.Levels; i++ {
+			price := roundToTick(bidPrice(int64(i)), cfg.Tick, false)
+			if price.Sign() <= 0 {
+				return nil, errors.New("bid price of level is not positive," +
+					" step is too large")
+			}
+			quotes = append(quotes, newQuote(cfg.Market, OrderBid,
+				bidSize, price))
+		}
+	}
+
+	return quotes, nil
+}
+
+// DiffLadder computes the minimal changes which turn open quotes into
+// desired ones. Quotes are equal if they have the same market, side,
+// price and amount, equal open quotes are kept untouched and everything
+// else is either canceled or placed. Quotes keep their input order.
+func DiffLadder(desired []OrderIntent, open []OpenQuote) LadderDiff {
+	var diff LadderDiff
+
+	kept := make([]bool, len(open))
+
+	for _, q := range desired {
+		matched := false
+		for i, o := range open {
+			if !kept[i] && sameQuote(q, o.OrderIntent) {
+				kept[i] = true
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			diff.Place = append(diff.Place, q)
+		}
+	}
+
+	for i, o := range open {
+		if !kept[i] {
+			diff.Cancel = append(diff.Cancel, o)
+		}
+	}
+
+	return diff
+}
+
+// sameQuote returns whether intents describe the same quote.
+func sameQuote(a, b OrderIntent) bool {
+	return a.Market == b.Market && a.Side == b.Side &&
+		a.Price.Equal(b.Price) && a.Amount.Equal(b.Amount)
+}
+
+// newQuote creates the limit order intent of the ladder level.
+func newQuote(market string, side OrderSide, amount,
+	price decimal.Decimal) OrderIntent {
+
+	return OrderIntent{
+		Market: market,
+		Side:   side,
+		Amount: amount,
+		Price:  price,
+	}
+}
+
+// pow returns d raised to the non-negative integer power.
+func pow(d decimal.Decimal, n int64) decimal.Decimal {
+	res := decimal.New(1, 0)
+	for ; n > 0; n-- {
+		res = res.Mul(d)
+	}
+	return res
+}
+
+// roundToTick rounds the price to the multiple of the tick, up or down.
+// Zero tick leaves the price as is.
+func roundToTick(price, tick decimal.Decimal, up bool) decimal.Decimal {
+	if tick.Sign() == 0 {
+		return price
+	}
+
+	ticks := price.Div(tick)
+	if up {
+		ticks = ticks.Ceil()
+	} else {
+		ticks = ticks.Floor()
+	}
+	return ticks.Mul(tick)
+}
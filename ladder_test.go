@@ -0,0 +1,201 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestBuildLadder(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        LadderConfig
+		wantAsks   []string
+		wantBids   []string
+		wantAskAmt string
+		wantBidAmt string
+	}{
+		{
+			name: "arithmetic",
+			cfg: LadderConfig{
+				Market: "BTCETH",
+				Mid:    decimal.RequireFromString("100"),
+				Levels: 3,
+				Step:   decimal.RequireFromString("0.5"),
+				Size:   decimal.RequireFromString("1"),
+			},
+			wantAsks:   []string{"100.5", "101", "101.5"},
+			wantBids:   []string{"99.5", "99", "98.5"},
+			wantAskAmt: "1",
+			wantBidAmt: "1",
+		},
+		{
+			name: "geometric with tick",
+			cfg: LadderConfig{
+				Market:  "BTCETH",
+				Mid:     decimal.RequireFromString("100"),
+				Levels:  2,
+				Spacing: LadderGeometric,
+				Step:    decimal.RequireFromString("0.01"),
+				Tick:    decimal.RequireFromString("0.1"),
+				Size:    decimal.RequireFromString("1"),
+			},
+			// 101, 102.01 -> 102.1; 99, 98.01 -> 98.
+			wantAsks:   []string{"101", "102.1"},
+			wantBids:   []string{"99", "98"},
+			wantAskAmt: "1",
+			wantBidAmt: "1",
+		},
+		{
+			name: "long inventory skew",
+			cfg: LadderConfig{
+				Market:    "BTCETH",
+				Mid:       decimal.RequireFromString("100"),
+				Levels:    1,
+				Step:      decimal.RequireFromString("1"),
+				Size:      decimal.RequireFromString("2"),
+				Inventory: decimal.RequireFromString("0.5"),
+				Skew:      decimal.RequireFromString("0.5"),
+			},
+			wantAsks:   []string{"101"},
+			wantBids:   []string{"99"},
+			wantAskAmt: "2.5",
+			wantBidAmt: "1.5",
+		},
+		{
+			name: "full skew drops bids",
+			cfg: LadderConfig{
+				Market:    "BTCETH",
+				Mid:       decimal.RequireFromString("100"),
+				Levels:    1,
+				Step:      decimal.RequireFromString("1"),
+				Size:      decimal.RequireFromString("1"),
+				Inventory: decimal.RequireFromString("1"),
+				Skew:      decimal.RequireFromString("1"),
+			},
+			wantAsks:   []string{"101"},
+			wantAskAmt: "2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes, err := BuildLadder(tt.cfg)
+			if err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+
+			var asks, bids []OrderIntent
+			for _, q := range quotes {
+				if q.Market != tt.cfg.Market {
+					t.Errorf("want market %v but got %v",
+						tt.cfg.Market, q.Market)
+				}
+				switch q.Side {
+				case OrderAsk:
+					asks = append(asks, q)
+				case OrderBid:
+					bids = append(bids, q)
+				default:
+					t.Fatalf("unexpected side %v", q.Side)
+				}
+			}
+
+			check := func(side string, got []OrderIntent, wantPrices []string,
+				wantAmount string) {
+
+				if len(got) != len(wantPrices) {
+					t.Fatalf("want %v %v levels but got %v",
+						len(wantPrices), side, len(got))
+				}
+				for i, q := range got {
+					wantPrice := decimal.RequireFromString(wantPrices[i])
+					if !q.Price.Equal(wantPrice) {
+						t.Errorf("want %v level %v price %v but got %v",
+							side, i+1, wantPrice, q.Price)
+					}
+					wantAmt := decimal.RequireFromString(wantAmount)
+					if !q.Amount.Equal(wantAmt) {
+						t.Errorf("want %v level %v amount %v but got %v",
+							side, i+1, wantAmt, q.Amount)
+					}
+				}
+			}
+			check("ask", asks, tt.wantAsks, tt.wantAskAmt)
+			check("bid", bids, tt.wantBids, tt.wantBidAmt)
+		})
+	}
+}
+
+func TestBuildLadder_Invalid(t *testing.T) {
+	valid := LadderConfig{
+		Market: "BTCETH",
+		Mid:    dec(100),
+		Levels: 1,
+		Step:   dec(1),
+		Size:   dec(1),
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cfg *LadderConfig)
+	}{
+		{"no market", func(cfg *LadderConfig) { cfg.Market = "" }},
+		{"zero mid", func(cfg *LadderConfig) { cfg.Mid = dec(0) }},
+		{"zero levels", func(cfg *LadderConfig) { cfg.Levels = 0 }},
+		{"zero step", func(cfg *LadderConfig) { cfg.Step = dec(0) }},
+		{"zero size", func(cfg *LadderConfig) { cfg.Size = dec(0) }},
+		{"inventory", func(cfg *LadderConfig) { cfg.Inventory = dec(2) }},
+		{"skew", func(cfg *LadderConfig) { cfg.Skew = dec(-1) }},
+		{"spacing", func(cfg *LadderConfig) { cfg.Spacing = "cubic" }},
+		{"geometric step", func(cfg *LadderConfig) {
+			cfg.Spacing = LadderGeometric
+		}},
+		{"negative bid", func(cfg *LadderConfig) { cfg.Levels = 100 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if _, err := BuildLadder(cfg); err == nil {
+				t.Error("want error but got nil")
+			}
+		})
+	}
+}
+
+func TestDiffLadder(t *testing.T) {
+	quote := func(side OrderSide, price, amount float64) OrderIntent {
+		return newQuote("BTCETH", side, dec(amount), dec(price))
+	}
+
+	desired := []OrderIntent{
+		quote(OrderAsk, 101, 1),
+		quote(OrderAsk, 102, 1),
+		quote(OrderBid, 99, 1),
+		quote(OrderBid, 99, 1),
+	}
+	open := []OpenQuote{
+		{ID: 1, OrderIntent: quote(OrderAsk, 101, 1)},
+		{ID: 2, OrderIntent: quote(OrderAsk, 103, 1)},
+		{ID: 3, OrderIntent: quote(OrderBid, 99, 1)},
+		{ID: 4, OrderIntent: quote(OrderBid, 98, 2)},
+	}
+
+	diff := DiffLadder(desired, open)
+
+	if len(diff.Cancel) != 2 || diff.Cancel[0].ID != 2 ||
+		diff.Cancel[1].ID != 4 {
+		t.Errorf("want orders 2 and 4 canceled but got %v", diff.Cancel)
+	}
+
+	if len(diff.Place) != 2 || !sameQuote(diff.Place[0], desired[1]) ||
+		!sameQuote(diff.Place[1], desired[3]) {
+		t.Errorf("want ask at 102 and bid at 99 placed but got %v",
+			diff.Place)
+	}
+
+	diff = DiffLadder(desired, nil)
+	if len(diff.Place) != len(desired) || len(diff.Cancel) != 0 {
+		t.Errorf("want all quotes placed but got %v", diff)
+	}
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultProbeInterval is the default interval between probes.
+	defaultProbeInterval = 30 * time.Second
+
+	// defaultProbeTimeout is the default timeout of a single probe.
+	defaultProbeTimeout = 5 * time.Second
+
+	// defaultProbeHysteresis is the default relative improvement of RTT
+	// required to switch to another endpoint.
+	defaultProbeHysteresis = 0.2
+)
+
+// probeQuery is the lightweight GraphQL query used to measure RTT.
+const probeQuery = `{"query":"{ __typename }"}`
+
+// ProberConfig is the configuration of the latency prober.
+type ProberConfig struct {
+	// Interval is the interval between probe rounds, zero means 30
+	// seconds.
+	Interval time.Duration
+
+	// Timeout is the timeout of a single probe, zero means 5 seconds.
+	Timeout time.Duration
+
+	// Hysteresis is the relative RTT improvement required to switch
+	// from the current endpoint to a faster one, e.g. 0.2 means the
+	// other endpoint should be at least 20% faster. Zero means 0.2,
+	// negative disables hysteresis.
+	Hysteresis float64
+
+	// Transport is used to make probe requests, nil means
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Metrics receives endpoint_rtt_seconds gauges and
+	// endpoint_switches_total counter, nil discards them.
+	Metrics Metrics
+}
+
+// LatencyProber periodically measures RTT to the exchange endpoints,
+// e.g. EU and US ones, and selects the fastest one.
+type LatencyProber struct {
+	endpoints []string
+	cfg       ProberConfig
+
+	mtx     sync.RWMutex
+	rtt     map[string]time.Duration
+	fastest string
+}
+
+// NewLatencyProber creates new prober of the endpoints, the first one
+// is selected until the first probe round completes.
+func NewLatencyProber(endpoints []string, cfg ProberConfig) (*LatencyProber,
+	error) {
+
+	if len(endpoints) == 0 {
+		return nil, errors.New("no endpoints to probe")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultProbeInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultProbeTimeout
+	}
+	if cfg.Hysteresis == 0 {
+		cfg.Hysteresis = defaultProbeHysteresis
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = nopMetrics{}
+	}
+
+	return &LatencyProber{
+		endpoints: append([]string(nil), endpoints...),
+		cfg:       cfg,
+		rtt:       make(map[string]time.Duration),
+		fastest:   endpoints[0],
+	}, nil
+}
+
+// WithLatencyRouting makes the client send requests which do not need
+// authorization, i.e. public market data, to the fastest endpoint
+// selected by the prober. Authorized requests are always sent to the
+// client URL. The prober should be run separately, see Run.
+func WithLatencyRouting(p *LatencyProber) Option {
+	return func(o *options) {
+		o.prober = p
+	}
+}
+
+// Run probes endpoints every interval until the context is done.
+func (p *LatencyProber) Run(ctx context.Context) {
+	for {
+		p.Probe(ctx)
+		if !sleep(ctx, p.cfg.Interval) {
+			return
+		}
+	}
+}
+
+// Probe measures RTT of all endpoints concurrently and updates the
+// fastest one.
+func (p *LatencyProber) Probe(ctx context.Context) {
+	rtt := make(map[string]time.Duration, len(p.endpoints))
+
+	var (
+		wg  sync.WaitGroup
+		mtx sync.Mutex
+	)
+	for _, endpoint := range p.endpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			d, err := p.probe(ctx, endpoint)
+			if err != nil {
+				return
+			}
+			mtx.Lock()
+			rtt[endpoint] = d
+			mtx.Unlock()
+		}(endpoint)
+	}
+	wg.Wait()
+
+	for endpoint, d := range rtt {
+		p.cfg.Metrics.Set("endpoint_rtt_seconds",
+			Labels{"endpoint": endpoint}, d.Seconds())
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.rtt = rtt
+
+	var best string
+	for _, endpoint := range p.endpoints {
+		d, ok := rtt[endpoint]
+		if ok && (best == "" || d < rtt[best]) {
+			best = endpoint
+		}
+	}
+	if best == "" || best == p.fastest {
+		return
+	}
+
+	// Switch only if the current endpoint is unavailable or the best
+	// one is significantly faster, to not flap between endpoints with
+	// similar latency.
+	current, ok := rtt[p.fastest]
+	if ok && float64(rtt[best]) > float64(current)*(1-p.cfg.Hysteresis) {
+		return
+	}
+
+	p.fastest = best
+	p.cfg.Metrics.Add("endpoint_switches_total",
+		Labels{"endpoint": best}, 1)
+}
+
+// probe measures RTT of the endpoint.
+func (p *LatencyProber) probe(ctx context.Context,
+	endpoint string) (time.Duration, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint,
+		bytes.NewBufferString(probeQuery))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	started := time.Now()
+	resp, err := (&http.Client{Transport: p.cfg.Transport}).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, newHTTPStatusError(resp, nil)
+	}
+
+	return time.Since(started), nil
+}
+
+// Fastest returns the currently selected fastest endpoint.
+func (p *LatencyProber) Fastest() string {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.fastest
+}
+
+// RTT returns the last measured RTT of the endpoint and false if the
+// endpoint was not reachable in the last probe round.
+func (p *LatencyProber) RTT(endpoint string) (time.Duration, bool) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	d, ok := p.rtt[endpoint]
+	return d, ok
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLatencyProber_Probe(t *testing.T) {
+	newServer := func(delay *time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(*delay)
+				w.Write([]byte(`{ "data": { "__typename": "Query" } }`))
+			}))
+	}
+
+	euDelay, usDelay := 50*time.Millisecond, time.Duration(0)
+	eu, us := newServer(&euDelay), newServer(&usDelay)
+	defer eu.Close()
+	defer us.Close()
+
+	metrics := &recordingMetrics{}
+	p, err := NewLatencyProber([]string{eu.URL, us.URL}, ProberConfig{
+		Hysteresis: 0.5,
+		Metrics:    metrics,
+	})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if p.Fastest() != eu.URL {
+		t.Fatalf("want first endpoint before probing")
+	}
+
+	p.Probe(context.Background())
+	if p.Fastest() != us.URL {
+		t.Errorf("want US endpoint to be the fastest")
+	}
+	if _, ok := p.RTT(eu.URL); !ok {
+		t.Errorf("want EU endpoint RTT to be measured")
+	}
+	if metrics.count("endpoint_switches_total") != 1 {
+		t.Errorf("want endpoint switch to be reported")
+	}
+
+	// Slightly faster endpoint is not switched to due to hysteresis.
+	euDelay, usDelay = 40*time.Millisecond, 50*time.Millisecond
+	p.Probe(context.Background())
+	if p.Fastest() != us.URL {
+		t.Errorf("want US endpoint to be kept")
+	}
+
+	// Unavailable endpoint is switched from.
+	us.Close()
+	p.Probe(context.Background())
+	if p.Fastest() != eu.URL {
+		t.Errorf("want EU endpoint when US one is unavailable")
+	}
+}
+
+func Test_graphQLCore_latencyRouting(t *testing.T) {
+	var public, private int
+	fast := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { public++ }))
+	defer fast.Close()
+	main := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { private++ }))
+	defer main.Close()
+
+	p, _ := NewLatencyProber([]string{fast.URL}, ProberConfig{})
+	c := &graphQLCore{url: main.URL, jwt: "token", prober: p}
+
+	c.do(context.Background(), false, request{})
+	c.do(context.Background(), true, request{})
+
+	if public != 1 || private != 1 {
+		t.Errorf("want 1 public and 1 private request but got %v and %v",
+			public, private)
+	}
+}
@@ -0,0 +1,406 @@
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultInvoiceExpiry is the invoice expiry used if the invoice does
+// not specify one, see BOLT #11.
+const defaultInvoiceExpiry = time.Hour
+
+// Invoice is the lightning network invoice.
+type Invoice struct {
+	// PaymentRequest is the BOLT #11 encoded invoice.
+	PaymentRequest string
+
+	// PaymentHash is the hex encoded payment hash, used to track the
+	// payment.
+	PaymentHash string
+
+	// Timestamp is the invoice creation time.
+	Timestamp time.Time
+
+	// Expiry is the period after creation in which invoice may be
+	// paid.
+	Expiry time.Duration
+
+	// Amount is the invoice amount in asset units, zero if the invoice
+	// does not specify the amount.
+	Amount decimal.Decimal
+
+	// Description is the invoice description.
+	Description string
+}
+
+// ExpiresAt returns the time after which the invoice can not be paid.
+func (i Invoice) ExpiresAt() time.Time {
+	return i.Timestamp.Add(i.Expiry)
+}
+
+// LightningInvoice creates lightning network invoice to pay for deposit
+// funds in exchange, like LightningCreateInvoice, and returns it
+// decoded, so the payment hash and expiry may be tracked.
+func (c *Client) LightningInvoice(asset string,
+	amount decimal.Decimal) (Invoice, error) {
+
+	paymentRequest, err := c.LightningCreateInvoice(asset, amount)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	invoice, err := decodeBOLT11(paymentRequest)
+	if err != nil {
+		return Invoice{}, errors.New("failed to decode invoice: " +
+			err.Error())
+	}
+
+	return invoice, nil
+}
+
+// DecodedInvoice is the lightning network invoice along with its
+// destination.
+type DecodedInvoice struct {
+	Invoice
+
+	// Destination is the hex encoded public key of the payee node,
+	// empty if it is unknown.
+	Destination string
+}
+
+// invoiceCurrencies is the BOLT #11 currency prefixes of the assets
+// networks.
+var invoiceCurrencies = map[string][]string{
+	"BTC": {"bc", "tb", "bcrt", "sb"},
+	"LTC": {"ltc", "tltc", "rltc"},
+}
+
+// decodeInvoiceRequestVariables is a query variables used in request in
+// client DecodeLightningInvoice method.
+type decodeInvoiceRequestVariables struct {
+	Asset   string `json:"asset"`
+	Invoice string `json:"invoice"`
+}
+
+// DecodeLightningInvoice decodes the invoice of the asset, so it may be
+// verified before it is paid with LightningWithdraw. The invoice is
+// decoded by the exchange if it supports it, otherwise it is decoded
+// locally, in which case the destination is known only if the invoice
+// specifies it explicitly and the invoice network is checked to be the
+// asset one.
+func (c *Client) DecodeLightningInvoice(asset,
+	invoice string) (DecodedInvoice, error) {
+
+	decoded, err := c.serverDecodeInvoice(asset, invoice)
+	var exchangeErr *ExchangeError
+	if err == nil || !errors.As(err, &exchangeErr) {
+		return decoded, err
+	}
+
+	// Server rejected the decode query, most likely it doesn't support
+	// it.
+	decoded, err = decodeBOLT11Invoice(invoice)
+	if err != nil {
+		return DecodedInvoice{}, errors.New("failed to decode invoice: " +
+			err.Error())
+	}
+
+	if currencies, ok := invoiceCurrencies[asset]; ok {
+		currency := bolt11Currency(invoice)
+		for _, c := range currencies {
+			if c == currency {
+				return decoded, nil
+			}
+		}
+		return DecodedInvoice{}, errors.New("invoice of network " +
+			currency + " is not an invoice of " + asset)
+	}
+
+	return decoded, nil
+}
+
+// serverDecodeInvoice requests the exchange to decode the invoice.
+func (c *Client) serverDecodeInvoice(asset,
+	invoice string) (DecodedInvoice, error) {
+
+	var req request
+
+	req.Query = `
+		query DecodeLightningInvoice($asset: Asset!, $invoice: String!) {
+			decodeLightningInvoice(asset: $asset, invoice: $invoice) {
+				paymentHash
+				amount
+				timestamp
+				expiry
+				description
+				destination
+			}
+		}
+	`
+
+	req.Variables = decodeInvoiceRequestVariables{
+		Asset:   c.assets.asset(asset),
+		Invoice: invoice,
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Invoice struct {
+				PaymentHash string          `json:"paymentHash"`
+				Amount      decimal.Decimal `json:"amount"`
+				Timestamp   float64         `json:"timestamp"`
+				Expiry      float64         `json:"expiry"`
+				Description string          `json:"description"`
+				Destination string          `json:"destination"`
+			} `json:"decodeLightningInvoice"`
+		}
+	}{}
+
+	respJSON, err := c.do(false, req)
+	if err != nil {
+		return DecodedInvoice{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return DecodedInvoice{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return DecodedInvoice{}, exchangeError(err)
+	}
+
+	i := resp.Data.Invoice
+	return DecodedInvoice{
+		Invoice: Invoice{
+			PaymentRequest: invoice,
+			PaymentHash:    i.PaymentHash,
+			Timestamp:      unixTime(i.Timestamp),
+			Expiry:         time.Duration(i.Expiry * float64(time.Second)),
+			Amount:         i.Amount,
+			Description:    i.Description,
+		},
+		Destination: i.Destination,
+	}, nil
+}
+
+// bech32Charset is the bech32 alphabet, the index of the character is
+// its 5-bit value.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// BOLT #11 tagged field types.
+const (
+	bolt11PaymentHash = 1
+	bolt11Description = 13
+	bolt11Payee       = 19
+	bolt11Expiry      = 6
+)
+
+// bolt11SignatureWords is the length of the invoice signature in 5-bit
+// words.
+const bolt11SignatureWords = 104
+
+// bolt11Multipliers maps amount multipliers to their values.
+var bolt11Multipliers = map[byte]decimal.Decimal{
+	'm': decimal.New(1, -3),
+	'u': decimal.New(1, -6),
+	'n': decimal.New(1, -9),
+	'p': decimal.New(1, -12),
+}
+
+// decodeBOLT11 decodes BOLT #11 lightning invoice. The signature is not
+// verified as it is the exchange which issues the invoice.
+func decodeBOLT11(paymentRequest string) (Invoice, error) {
+	invoice, err := decodeBOLT11Invoice(paymentRequest)
+	return invoice.Invoice, err
+}
+
+// decodeBOLT11Invoice decodes BOLT #11 lightning invoice along with its
+// destination if the invoice specifies it explicitly with the payee
+// field. The signature is neither verified nor used to recover the
+// destination.
+func decodeBOLT11Invoice(paymentRequest string) (DecodedInvoice, error) {
+	s := strings.ToLower(paymentRequest)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 0 || len(s)-sep-1 < 6 {
+		return DecodedInvoice{}, errors.New("invalid bech32 string")
+	}
+	hrp := s[:sep]
+
+	words := make([]byte, 0, len(s)-sep-1)
+	for _, ch := range s[sep+1:] {
+		w := strings.IndexRune(bech32Charset, ch)
+		if w < 0 {
+			return DecodedInvoice{}, errors.New("invalid bech32 character")
+		}
+		words = append(words, byte(w))
+	}
+
+	if !bech32VerifyChecksum(hrp, words) {
+		return DecodedInvoice{}, errors.New("invalid bech32 checksum")
+	}
+	words = words[:len(words)-6]
+
+	if len(words) < 7+bolt11SignatureWords {
+		return DecodedInvoice{}, errors.New("invoice is too short")
+	}
+
+	amount, err := bolt11Amount(hrp)
+	if err != nil {
+		return DecodedInvoice{}, err
+	}
+
+	invoice := DecodedInvoice{Invoice: Invoice{
+		PaymentRequest: paymentRequest,
+		Timestamp:      time.Unix(int64(wordsToUint(words[:7])), 0),
+		Expiry:         defaultInvoiceExpiry,
+		Amount:         amount,
+	}}
+
+	fields := words[7 : len(words)-bolt11SignatureWords]
+	for len(fields) > 0 {
+		if len(fields) < 3 {
+			return DecodedInvoice{}, errors.New("invalid tagged field")
+		}
+
+		typ := fields[0]
+		length := int(fields[1])<<5 | int(fields[2])
+		if len(fields) < 3+length {
+			return DecodedInvoice{}, errors.New("invalid tagged field length")
+		}
+		data := fields[3 : 3+length]
+		fields = fields[3+length:]
+
+		switch typ {
+		case bolt11PaymentHash:
+			if length != 52 {
+				// Fields of unexpected length should be skipped.
+				continue
+			}
+			invoice.PaymentHash = hex.EncodeToString(wordsToBytes(data))
+		case bolt11Expiry:
+			invoice.Expiry = time.Duration(wordsToUint(data)) *
+				time.Second
+		case bolt11Description:
+			invoice.Description = string(wordsToBytes(data))
+		case bolt11Payee:
+			if length != 53 {
+				continue
+			}
+			invoice.Destination = hex.EncodeToString(wordsToBytes(data))
+		}
+	}
+
+	if invoice.PaymentHash == "" {
+		return DecodedInvoice{}, errors.New("no payment hash")
+	}
+
+	return invoice, nil
+}
+
+// bolt11Currency returns the currency prefix of the invoice, e.g. "bc"
+// for bitcoin mainnet.
+func bolt11Currency(paymentRequest string) string {
+	hrp := strings.ToLower(paymentRequest)
+	if sep := strings.LastIndexByte(hrp, '1'); sep >= 0 {
+		hrp = hrp[:sep]
+	}
+	hrp = strings.TrimPrefix(hrp, "ln")
+	if i := strings.IndexAny(hrp, "0123456789"); i >= 0 {
+		hrp = hrp[:i]
+	}
+	return hrp
+}
+
+// bolt11Amount parses the amount from the invoice human readable part,
+// which is "ln" followed by currency prefix and optional amount.
+func bolt11Amount(hrp string) (decimal.Decimal, error) {
+	if !strings.HasPrefix(hrp, "ln") {
+		return decimal.Zero, errors.New("invalid invoice prefix")
+	}
+
+	i := strings.IndexAny(hrp, "0123456789")
+	if i < 0 {
+		return decimal.Zero, nil
+	}
+	amount := hrp[i:]
+
+	multiplier := decimal.New(1, 0)
+	if m, ok := bolt11Multipliers[amount[len(amount)-1]]; ok {
+		multiplier = m
+		amount = amount[:len(amount)-1]
+	}
+
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return decimal.Zero, errors.New("invalid invoice amount")
+	}
+
+	return value.Mul(multiplier), nil
+}
+
+// wordsToUint converts big-endian 5-bit words to unsigned integer.
+func wordsToUint(words []byte) uint64 {
+	var v uint64
+	for _, w := range words {
+		v = v<<5 | uint64(w)
+	}
+	return v
+}
+
+// wordsToBytes converts 5-bit words to bytes dropping incomplete
+// trailing bits.
+func wordsToBytes(words []byte) []byte {
+	var (
+		res  []byte
+		acc  uint
+		bits uint
+	)
+	for _, w := range words {
+		acc = acc<<5 | uint(w)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			res = append(res, byte(acc>>bits))
+		}
+	}
+	return res
+}
+
+// bech32VerifyChecksum verifies bech32 checksum of the data with human
+// readable part.
+func bech32VerifyChecksum(hrp string, data []byte) bool {
+	values := make([]byte, 0, len(hrp)*2+1+len(data))
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	values = append(values, data...)
+	return bech32Polymod(values) == 1
+}
+
+// bech32Polymod computes bech32 checksum polymod.
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd,
+		0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testInvoice is BOLT #11 example invoice: "Please send $3 for a cup of
+// coffee to the same peer, within one minute".
+const testInvoice = "lnbc2500u1pvjluezpp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfq" +
+	"qqsyqcyq5rqwzqfqypqdq5xysxxatsyp3k7enxv4jsxqzpuaztrnwngzn3kdzw5hydlzf" +
+	"03qdgm2hdq27cqv3agm2awhz5se903vruatfhq77w3ls4evs3ch9zw97j25emudupq63n" +
+	"yw24cg27h2rspfj9srp"
+
+func TestDecodeBOLT11(t *testing.T) {
+	invoice, err := decodeBOLT11(testInvoice)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	wantHash := "0001020304050607080900010203040506070809000102030405060708090102"
+	if invoice.PaymentHash != wantHash {
+		t.Errorf("want payment hash `%s` but got `%s`", wantHash,
+			invoice.PaymentHash)
+	}
+	if !invoice.Amount.Equal(dec(0.0025)) {
+		t.Errorf("want amount 0.0025 but got %v", invoice.Amount)
+	}
+	if invoice.Expiry != time.Minute {
+		t.Errorf("want expiry 1m but got %v", invoice.Expiry)
+	}
+	if invoice.Timestamp.Unix() != 1496314658 {
+		t.Errorf("want timestamp 1496314658 but got %v",
+			invoice.Timestamp.Unix())
+	}
+	if invoice.Description != "1 cup coffee" {
+		t.Errorf("want description `1 cup coffee` but got `%s`",
+			invoice.Description)
+	}
+
+	corrupted := strings.Replace(testInvoice, "2500u", "2600u", 1)
+	if _, err := decodeBOLT11(corrupted); err == nil {
+		t.Error("want checksum error but got no error")
+	}
+}
+
+func TestClient_LightningInvoice(t *testing.T) {
+	client := &Client{core: &mockCore{respJSON: `{ "data": {
+		"generateLightningInvoice": "` + testInvoice + `"
+	} }`}}
+
+	invoice, err := client.LightningInvoice("BTC", dec(0.0025))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if invoice.PaymentRequest != testInvoice {
+		t.Errorf("want payment request to be kept")
+	}
+	if want := time.Unix(1496314718, 0); !invoice.ExpiresAt().Equal(want) {
+		t.Errorf("want expiration at %v but got %v", want,
+			invoice.ExpiresAt())
+	}
+}
+
+func TestClient_DecodeLightningInvoice(t *testing.T) {
+	t.Run("when decoded by exchange", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: `{"data": {
+			"decodeLightningInvoice": {
+				"paymentHash": "00ff",
+				"amount": "0.0025",
+				"timestamp": 1496314658,
+				"expiry": 60,
+				"destination": "03e7156ae33b0a208d0744199163177e909e80176e55d97a2f221ede0f934dd9ad"
+			}
+		}}`}}
+
+		invoice, err := client.DecodeLightningInvoice("BTC", testInvoice)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if invoice.PaymentHash != "00ff" || invoice.Destination == "" {
+			t.Errorf("want invoice decoded by exchange but got `%v`",
+				invoice)
+		}
+		if want := time.Unix(1496314718, 0); !invoice.ExpiresAt().Equal(want) {
+			t.Errorf("want expiration at %v but got %v", want,
+				invoice.ExpiresAt())
+		}
+	})
+	t.Run("when decoded locally", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: `{"errors": [
+			{"message": "unknown field decodeLightningInvoice"}]}`}}
+
+		invoice, err := client.DecodeLightningInvoice("BTC", testInvoice)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if !invoice.Amount.Equal(dec(0.0025)) ||
+			invoice.Description != "1 cup coffee" {
+			t.Errorf("want invoice decoded locally but got `%v`", invoice)
+		}
+
+		if _, err := client.DecodeLightningInvoice("LTC",
+			testInvoice); err == nil {
+			t.Error("want error of bitcoin invoice of LTC")
+		}
+	})
+	t.Run("when transport fails", func(t *testing.T) {
+		client := &Client{core: &mockCore{error: errors.New("fail")}}
+		if _, err := client.DecodeLightningInvoice("BTC",
+			testInvoice); err == nil {
+			t.Error("want transport error")
+		}
+	})
+}
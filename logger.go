@@ -0,0 +1,178 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// redacted replaces values of sensitive request variables in logs.
+const redacted = "[REDACTED]"
+
+// sensitiveVariables is the substrings of variable names which values
+// are redacted in logs, compared case-insensitively.
+var sensitiveVariables = []string{
+	"macaroon", "jwt", "token", "password", "secret", "address", "addr",
+	"invoice", "paymentrequest",
+}
+
+// Request statuses of log entries.
+const (
+	LogStatusOK    = "ok"
+	LogStatusError = "error"
+)
+
+// LogEntry is the log record of the request sent by the client.
+type LogEntry struct {
+	// Operation is the GraphQL operation name, e.g. "GetOrder".
+	Operation string
+
+	// Category is the operation category.
+	Category OperationCategory
+
+	// Duration is the time the request took.
+	Duration time.Duration
+
+	// Variables is the request variables with credentials, addresses
+	// and invoices redacted.
+	Variables map[string]interface{}
+
+	// Status is either LogStatusOK or LogStatusError.
+	Status string
+
+	// Err is the request error, nil if the request succeeded.
+	Err error
+
+	// Market is the market variable of the request, if any.
+	Market string
+
+	// Attempts is the number of times the request was sent, including
+	// retries and hedged duplicates.
+	Attempts int
+
+	// RequestID is the ID the server assigned to the request, empty if
+	// the server didn't report it.
+	RequestID string
+
+	// Slow is true if the request exceeded the slow call threshold,
+	// see WithSlowCallLogging.
+	Slow bool
+}
+
+// Logger receives log entries of the client requests.
+type Logger interface {
+	LogRequest(e LogEntry)
+}
+
+// LoggerFunc is the function which implements Logger.
+type LoggerFunc func(e LogEntry)
+
+// LogRequest implements Logger.
+func (f LoggerFunc) LogRequest(e LogEntry) {
+	f(e)
+}
+
+// WithLogger makes the client log every request it sends: operation
+// name, duration, variables and result status. Values of variables
+// which may hold credentials, payment addresses or invoices are
+// redacted.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// logRequest passes the request to the slow call logger if it is
+// enabled and logs it if the client has logger.
+func (c *Client) logRequest(category OperationCategory, r request,
+	started time.Time, stats *callStats, err error) {
+
+	if c.logger == nil && c.slowCalls == nil {
+		return
+	}
+
+	name := operationName(r.Query)
+	if name == "" {
+		name = mutationField(r.Query)
+	}
+
+	e := LogEntry{
+		Operation: name,
+		Category:  category,
+		Duration:  time.Since(started),
+		Variables: redactVariables(r.Variables),
+		Status:    LogStatusOK,
+		Err:       err,
+	}
+	if err != nil {
+		e.Status = LogStatusError
+	}
+	if market, ok := e.Variables["market"].(string); ok {
+		e.Market = market
+	}
+	stats.fill(&e)
+
+	c.slowCalls.observe(&e)
+	if c.logger != nil {
+		c.logger.LogRequest(e)
+	}
+}
+
+// redactVariables returns generic copy of the request variables with
+// sensitive values redacted.
+func redactVariables(vars interface{}) map[string]interface{} {
+	if vars == nil {
+		return nil
+	}
+
+	varsJSON, err := json.Marshal(vars)
+	if err != nil {
+		return nil
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(varsJSON, &m); err != nil {
+		return nil
+	}
+
+	redactValue(m)
+	return m
+}
+
+// redactValue redacts sensitive values of maps nested in the value,
+// returns true if any value was redacted.
+func redactValue(v interface{}) bool {
+	var changed bool
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if isSensitiveVariable(k) {
+				v[k] = redacted
+				changed = true
+				continue
+			}
+			if redactValue(e) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if redactValue(e) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// isSensitiveVariable returns true if value of the variable should be
+// redacted.
+func isSensitiveVariable(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveVariables {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
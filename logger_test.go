@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClient_logRequest(t *testing.T) {
+	var entries []LogEntry
+	client := &Client{
+		core: &mockCore{error: errors.New("fail")},
+		logger: LoggerFunc(func(e LogEntry) {
+			entries = append(entries, e)
+		}),
+	}
+
+	client.Withdraw("BTC", dec(1), "some-address")
+
+	if len(entries) != 1 {
+		t.Fatalf("want 1 log entry but got %v", len(entries))
+	}
+	e := entries[0]
+	if e.Operation != "Withdraw" || e.Category != CategoryTrading {
+		t.Errorf("want Withdraw trading operation but got `%s` `%s`",
+			e.Operation, e.Category)
+	}
+	if e.Status != LogStatusError || e.Err == nil {
+		t.Errorf("want error status but got `%s`", e.Status)
+	}
+	if e.Variables["address"] != redacted {
+		t.Errorf("want address redacted but got `%v`", e.Variables["address"])
+	}
+	if e.Variables["asset"] != "BTC" {
+		t.Errorf("want asset logged but got `%v`", e.Variables["asset"])
+	}
+}
+
+func TestClient_logRequest_lightningWithdraw(t *testing.T) {
+	var entries []LogEntry
+	client := &Client{
+		core: &mockCore{error: errors.New("fail")},
+		logger: LoggerFunc(func(e LogEntry) {
+			entries = append(entries, e)
+		}),
+	}
+
+	client.LightningWithdraw("BTC", "lnbc1invoice")
+
+	if len(entries) != 1 {
+		t.Fatalf("want 1 log entry but got %v", len(entries))
+	}
+	e := entries[0]
+	if e.Variables["invoice"] != redacted {
+		t.Errorf("want invoice redacted but got `%v`", e.Variables["invoice"])
+	}
+	if e.Variables["asset"] != "BTC" {
+		t.Errorf("want asset logged but got `%v`", e.Variables["asset"])
+	}
+}
+
+func TestRedactVariables(t *testing.T) {
+	got := redactVariables(map[string]interface{}{
+		"market": "BTCETH",
+		"auth": map[string]interface{}{
+			"apiToken": "secret-token",
+			"Macaroon": "0201",
+		},
+		"payments": []interface{}{
+			map[string]interface{}{"paymentAddr": "addr", "amount": "1"},
+		},
+		"paymentRequest": "lnbc1invoice",
+	})
+
+	auth := got["auth"].(map[string]interface{})
+	payment := got["payments"].([]interface{})[0].(map[string]interface{})
+	switch {
+	case got["market"] != "BTCETH":
+		t.Errorf("want market kept but got `%v`", got["market"])
+	case auth["apiToken"] != redacted || auth["Macaroon"] != redacted:
+		t.Errorf("want credentials redacted but got `%v`", auth)
+	case payment["paymentAddr"] != redacted || payment["amount"] != "1":
+		t.Errorf("want only address redacted but got `%v`", payment)
+	case got["paymentRequest"] != redacted:
+		t.Errorf("want payment request redacted but got `%v`",
+			got["paymentRequest"])
+	}
+}
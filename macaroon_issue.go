@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/bitlum/macaroon-application-auth"
+	"gopkg.in/macaroon.v2"
+)
+
+// issueApiTokenQuery is the query of IssueApiToken.
+const issueApiTokenQuery = `
+	query { issueApiToken }
+`
+
+// WithIssuedMacaroon makes the client created with JWT token exchange it
+// for the macaroon with IssueApiToken before the first authorized
+// request and authorize all subsequent requests with the macaroon. If
+// the server rejects the macaroon, it is issued once again. Non-empty
+// path is the file the macaroon is cached in, readable by the owner
+// only, so it is issued once across restarts.
+func WithIssuedMacaroon(path string) Option {
+	return func(o *options) {
+		o.issuedMacaroon = &macaroonIssuer{path: path}
+	}
+}
+
+// macaroonIssuer issues macaroons for the JWT token.
+type macaroonIssuer struct {
+	// path is the file the macaroon is cached in, empty disables
+	// caching.
+	path string
+
+	// mtx serializes issues.
+	mtx sync.Mutex
+}
+
+// load returns the macaroon cached in the file, nil if there is none.
+func (i *macaroonIssuer) load() (*macaroon.Macaroon, error) {
+	if i.path == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(i.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read cached macaroon: " +
+			err.Error())
+	}
+
+	m, err := auth.DecodeMacaroon(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.New("failed to decode cached macaroon: " +
+			err.Error())
+	}
+	return m, nil
+}
+
+// store caches the hex encoded macaroon in the file.
+func (i *macaroonIssuer) store(token string) error {
+	if i.path == "" {
+		return nil
+	}
+
+	if err := ioutil.WriteFile(i.path, []byte(token), 0600); err != nil {
+		return errors.New("failed to cache macaroon: " + err.Error())
+	}
+	return nil
+}
+
+// ensureMacaroon issues the macaroon if the core has none.
+func (c *graphQLCore) ensureMacaroon(ctx context.Context) error {
+	c.mtx.RLock()
+	m := c.macaroon
+	c.mtx.RUnlock()
+
+	if m != nil {
+		return nil
+	}
+	return c.issueMacaroon(ctx, nil)
+}
+
+// issueMacaroon replaces the stale macaroon with the one issued for the
+// JWT token. Issues are serialized, and the macaroon is not issued
+// again if it was already replaced by concurrent issue.
+func (c *graphQLCore) issueMacaroon(ctx context.Context,
+	stale *macaroon.Macaroon) error {
+
+	c.issuer.mtx.Lock()
+	defer c.issuer.mtx.Unlock()
+
+	c.mtx.Lock()
+	if c.macaroon != nil && c.macaroon != stale {
+		c.mtx.Unlock()
+		return nil
+	}
+	if c.jwt == "" {
+		c.mtx.Unlock()
+		return errors.New("failed to issue macaroon: no JWT token")
+	}
+
+	// Stale macaroon is dropped so the issue is authorized with JWT.
+	c.macaroon = nil
+	c.mtx.Unlock()
+
+	reqJSON, err := json.Marshal(request{Query: issueApiTokenQuery})
+	if err != nil {
+		return errors.New("failed to json.Marshal request: " +
+			err.Error())
+	}
+
+	body, err := c.sendRetrying(ctx, true, true, reqJSON)
+	if err != nil {
+		return fmt.Errorf("failed to issue macaroon: %w", err)
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			IssueApiToken string `json:"issueApiToken"`
+		}
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to issue macaroon: %w", decodeError(err))
+	}
+	if err := resp.Error(); err != nil {
+		return fmt.Errorf("failed to issue macaroon: %w",
+			exchangeError(err))
+	}
+
+	m, err := auth.DecodeMacaroon(resp.Data.IssueApiToken)
+	if err != nil {
+		return errors.New("failed to decode issued macaroon: " +
+			err.Error())
+	}
+
+	if err := c.issuer.store(resp.Data.IssueApiToken); err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	c.macaroon = m
+	c.mtx.Unlock()
+
+	return nil
+}
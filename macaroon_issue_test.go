@@ -0,0 +1,180 @@
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bitlum/macaroon-application-auth"
+	"gopkg.in/macaroon.v2"
+)
+
+// issueServer issues macaroons for the JWT token and accepts only the
+// latest issued macaroon.
+type issueServer struct {
+	*httptest.Server
+
+	mtx    sync.Mutex
+	issued []string
+}
+
+func newIssueServer(t *testing.T) *issueServer {
+	s := &issueServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			authorization := r.Header.Get("Authorization")
+
+			s.mtx.Lock()
+			defer s.mtx.Unlock()
+
+			if authorization == "Bearer jwt" &&
+				strings.Contains(string(body), "issueApiToken") {
+
+				id := string(rune('a' + len(s.issued)))
+				m, err := macaroon.New([]byte("root key"), []byte(id), "",
+					macaroon.LatestVersion)
+				if err != nil {
+					t.Errorf("failed to create macaroon: %v", err)
+					return
+				}
+				token, _ := auth.EncodeMacaroon(m)
+				s.issued = append(s.issued, id)
+				w.Write([]byte(`{"data": {"issueApiToken": "` + token +
+					`"}}`))
+				return
+			}
+
+			m, err := auth.DecodeMacaroon(strings.TrimPrefix(authorization,
+				"Macaroon "))
+			if err != nil || len(s.issued) == 0 ||
+				string(m.Id()) != s.issued[len(s.issued)-1] {
+
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"data": {"me": {"id": "user"}}}`))
+		}))
+	return s
+}
+
+// revoke makes the server issue new macaroon and reject issued ones.
+func (s *issueServer) revoke() {
+	s.mtx.Lock()
+	s.issued = append(s.issued, "revoked")
+	s.mtx.Unlock()
+}
+
+func (s *issueServer) issues() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.issued)
+}
+
+func TestWithIssuedMacaroon(t *testing.T) {
+	server := newIssueServer(t)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "macaroon")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "macaroon")
+
+	client, err := NewClient(server.URL, "", "jwt", WithIssuedMacaroon(path))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Me(); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+	}
+	if issues := server.issues(); issues != 1 {
+		t.Errorf("want macaroon issued once but got %v", issues)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("want macaroon cached but got `%v`", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("want cache readable by owner only but got %v",
+			info.Mode().Perm())
+	}
+
+	// Restarted client uses cached macaroon.
+	client, err = NewClient(server.URL, "", "jwt", WithIssuedMacaroon(path))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if _, err := client.Me(); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if issues := server.issues(); issues != 1 {
+		t.Errorf("want cached macaroon used but got %v issues", issues)
+	}
+
+	// Rejected macaroon is issued again.
+	server.revoke()
+	if _, err := client.Me(); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if issues := server.issues(); issues != 3 {
+		t.Errorf("want macaroon issued again but got %v issues", issues)
+	}
+}
+
+func TestWithIssuedMacaroon_errors(t *testing.T) {
+	server := newIssueServer(t)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "macaroon")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "macaroon")
+	if err := ioutil.WriteFile(path, []byte("corrupted"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := NewClient(server.URL, "", "jwt",
+		WithIssuedMacaroon(path)); err == nil {
+
+		t.Error("want error of corrupted cache")
+	}
+
+	client, err := NewClient(server.URL, "", "", WithIssuedMacaroon(""))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if _, err := client.Me(); err == nil || !strings.Contains(err.Error(),
+		"no JWT token") {
+
+		t.Errorf("want error of missing JWT but got `%v`", err)
+	}
+
+	rejecting := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"errors": [{"message": "not authenticated",
+				"extensions": {"code": "UNAUTHENTICATED"}}]}`))
+		}))
+	defer rejecting.Close()
+
+	client, err = NewClient(rejecting.URL, "", "jwt", WithIssuedMacaroon(""))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if _, err := client.Me(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("want ErrUnauthorized but got `%v`", err)
+	}
+}
@@ -0,0 +1,404 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrMaintenanceWindow is returned for mutations made during the
+// exchange maintenance window, see WithMaintenance. It is wrapped in
+// TransportError, so it should be matched with errors.Is.
+var ErrMaintenanceWindow = errors.New("exchange is in maintenance window")
+
+// MaintenanceWindow is the period of the exchange maintenance.
+type MaintenanceWindow struct {
+	// Start is the inclusive start of the window.
+	Start time.Time
+
+	// End is the exclusive end of the window.
+	End time.Time
+
+	// Reason describes the maintenance.
+	Reason string
+}
+
+// contains returns true if the time is within the window.
+func (w MaintenanceWindow) contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// RecurringMaintenance is the maintenance window which repeats on the
+// cron schedule, e.g. every Sunday at 02:00.
+type RecurringMaintenance struct {
+	// Spec is the cron expression of the window starts with five
+	// fields: minute, hour, day of month, month and day of week, e.g.
+	// "0 2 * * 0". Fields are lists of numbers, ranges and steps, e.g.
+	// "1-5", "*/15" or "0,30".
+	Spec string
+
+	// Duration is the duration of every window.
+	Duration time.Duration
+
+	// Location is the time zone of the spec, nil means UTC.
+	Location *time.Location
+}
+
+// MaintenanceConfig is the configuration of the exchange maintenance
+// windows.
+type MaintenanceConfig struct {
+	// Windows is the known one-off maintenance windows.
+	Windows []MaintenanceWindow
+
+	// Recurring is the known recurring maintenance windows.
+	Recurring []RecurringMaintenance
+
+	// FetchInterval is the interval of refreshing maintenance windows
+	// announced by the exchange, see MaintenanceWindows. Zero disables
+	// fetching.
+	FetchInterval time.Duration
+}
+
+// WithMaintenance makes the client aware of the exchange maintenance
+// windows. During the window mutations are rejected locally with
+// ErrMaintenanceWindow and background pollers, e.g. subscriptions
+// served with long polling, WatchAccounts and RecordBalances, are
+// paused until the window ends, after which they resume from where they
+// left off. Invalid recurring spec makes NewClient fail.
+func WithMaintenance(cfg MaintenanceConfig) Option {
+	return func(o *options) {
+		o.maintenance = &cfg
+	}
+}
+
+// maintenance tracks the exchange maintenance windows. Nil maintenance
+// has no windows.
+type maintenance struct {
+	windows   []MaintenanceWindow
+	recurring []cronWindow
+
+	// fetch requests windows announced by the exchange, nil disables
+	// fetching.
+	fetch         func() ([]MaintenanceWindow, error)
+	fetchInterval time.Duration
+
+	now func() time.Time
+
+	mtx       sync.Mutex
+	fetched   []MaintenanceWindow
+	fetchedAt time.Time
+}
+
+// newMaintenance creates new maintenance tracker of the configuration.
+func newMaintenance(cfg MaintenanceConfig) (*maintenance, error) {
+	m := &maintenance{
+		windows:       cfg.Windows,
+		fetchInterval: cfg.FetchInterval,
+		now:           time.Now,
+	}
+
+	for _, r := range cfg.Recurring {
+		if r.Duration <= 0 {
+			return nil, errors.New("duration of maintenance " + r.Spec +
+				" should be positive")
+		}
+
+		schedule, err := parseCron(r.Spec)
+		if err != nil {
+			return nil, errors.New("failed to parse maintenance spec " +
+				r.Spec + ": " + err.Error())
+		}
+
+		location := r.Location
+		if location == nil {
+			location = time.UTC
+		}
+
+		m.recurring = append(m.recurring, cronWindow{
+			spec:     r.Spec,
+			schedule: schedule,
+			duration: r.Duration,
+			location: location,
+		})
+	}
+
+	return m, nil
+}
+
+// active returns the current maintenance window. If several windows
+// overlap, the one which ends last is returned.
+func (m *maintenance) active() (MaintenanceWindow, bool) {
+	if m == nil {
+		return MaintenanceWindow{}, false
+	}
+
+	now := m.now()
+
+	var (
+		active MaintenanceWindow
+		found  bool
+	)
+	consider := func(w MaintenanceWindow) {
+		if w.contains(now) && (!found || w.End.After(active.End)) {
+			active, found = w, true
+		}
+	}
+
+	for _, w := range m.windows {
+		consider(w)
+	}
+	for _, w := range m.announced(now) {
+		consider(w)
+	}
+	for _, r := range m.recurring {
+		if w, ok := r.at(now); ok {
+			consider(w)
+		}
+	}
+
+	return active, found
+}
+
+// announced returns windows announced by the exchange, refreshing them
+// if they are stale. The last known windows are kept if refresh fails.
+func (m *maintenance) announced(now time.Time) []MaintenanceWindow {
+	if m.fetch == nil || m.fetchInterval <= 0 {
+		return nil
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if now.Sub(m.fetchedAt) >= m.fetchInterval {
+		m.fetchedAt = now
+		if windows, err := m.fetch(); err == nil {
+			m.fetched = windows
+		}
+	}
+
+	return m.fetched
+}
+
+// check returns ErrMaintenanceWindow during maintenance windows.
+func (m *maintenance) check() error {
+	if _, ok := m.active(); ok {
+		return ErrMaintenanceWindow
+	}
+	return nil
+}
+
+// wait blocks until the current maintenance window, if any, ends. It
+// returns false if the context is done earlier.
+func (m *maintenance) wait(ctx context.Context) bool {
+	for {
+		w, ok := m.active()
+		if !ok {
+			return ctx.Err() == nil
+		}
+		if !sleep(ctx, w.End.Sub(m.now())) {
+			return false
+		}
+	}
+}
+
+// Maintenance returns the current maintenance window, false is returned
+// if the exchange is not in maintenance, see WithMaintenance.
+func (c *Client) Maintenance() (MaintenanceWindow, bool) {
+	return c.maintenance.active()
+}
+
+// MaintenanceWindows returns current and upcoming maintenance windows
+// announced by the exchange.
+func (c *Client) MaintenanceWindows() ([]MaintenanceWindow, error) {
+	var req request
+
+	req.Query = `
+		query MaintenanceWindows {
+			maintenanceWindows {
+				start
+				end
+				reason
+			}
+		}
+	`
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Windows []struct {
+				Start  timestamp `json:"start"`
+				End    timestamp `json:"end"`
+				Reason string    `json:"reason"`
+			} `json:"maintenanceWindows"`
+		}
+	}{}
+
+	respJSON, err := c.do(false, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	windows := make([]MaintenanceWindow, 0, len(resp.Data.Windows))
+	for _, w := range resp.Data.Windows {
+		windows = append(windows, MaintenanceWindow{
+			Start:  time.Time(w.Start),
+			End:    time.Time(w.End),
+			Reason: w.Reason,
+		})
+	}
+
+	return windows, nil
+}
+
+// cronWindow is the recurring maintenance window.
+type cronWindow struct {
+	spec     string
+	schedule cronSchedule
+	duration time.Duration
+	location *time.Location
+}
+
+// at returns the window of the schedule which contains the time, if
+// any. Starts are looked up minute by minute back over the duration.
+func (w cronWindow) at(t time.Time) (MaintenanceWindow, bool) {
+	t = t.In(w.location)
+	earliest := t.Add(-w.duration)
+	for start := t.Truncate(time.Minute); start.After(earliest); start =
+		start.Add(-time.Minute) {
+
+		if w.schedule.matches(start) {
+			return MaintenanceWindow{
+				Start:  start,
+				End:    start.Add(w.duration),
+				Reason: "recurring maintenance " + w.spec,
+			}, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
+
+// cronSchedule is the parsed cron expression, every field is the set of
+// allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny are true if the day fields are "*", which
+	// changes how days are matched.
+	domAny, dowAny bool
+}
+
+// parseCron parses five field cron expression.
+func parseCron(spec string) (cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return cronSchedule{}, errors.New("want 5 fields but got " +
+			strconv.Itoa(len(fields)))
+	}
+
+	var (
+		s   cronSchedule
+		err error
+	)
+	bounds := []struct {
+		set      *uint64
+		min, max int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dom, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dow, 0, 7},
+	}
+	for i, b := range bounds {
+		if *b.set, err = parseCronField(fields[i], b.min, b.max); err != nil {
+			return cronSchedule{}, err
+		}
+	}
+
+	// Both 0 and 7 are Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny, s.dowAny = fields[2] == "*", fields[4] == "*"
+
+	return s, nil
+}
+
+// parseCronField parses comma separated list of values, ranges and steps
+// into the set of values.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil ||
+				step <= 0 {
+				return 0, errors.New("invalid step in " + part)
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, errors.New("invalid range " + rng)
+			}
+		default:
+			var err error
+			if lo, err = strconv.Atoi(rng); err != nil {
+				return 0, errors.New("invalid value " + rng)
+			}
+			hi = lo
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, errors.New(part + " is out of range " +
+				strconv.Itoa(min) + "-" + strconv.Itoa(max))
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// matches returns true if the minute of the time is in the schedule.
+// If both day fields are restricted, the day matches either of them.
+func (s cronSchedule) matches(t time.Time) bool {
+	has := func(set uint64, v int) bool {
+		return set&(1<<uint(v)) != 0
+	}
+
+	if !has(s.minute, t.Minute()) || !has(s.hour, t.Hour()) ||
+		!has(s.month, int(t.Month())) {
+		return false
+	}
+
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
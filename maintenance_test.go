@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		spec  string
+		time  time.Time
+		match bool
+		err   bool
+	}{
+		{
+			spec:  "0 2 * * 0",
+			time:  time.Date(2018, 10, 21, 2, 0, 0, 0, time.UTC),
+			match: true,
+		},
+		{
+			spec: "0 2 * * 0",
+			time: time.Date(2018, 10, 22, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			spec:  "*/15 9-17 * * 1-5",
+			time:  time.Date(2018, 10, 22, 9, 45, 0, 0, time.UTC),
+			match: true,
+		},
+		{
+			spec: "*/15 9-17 * * 1-5",
+			time: time.Date(2018, 10, 22, 9, 50, 0, 0, time.UTC),
+		},
+		{
+			spec:  "0 0 1 * 7",
+			time:  time.Date(2018, 10, 21, 0, 0, 0, 0, time.UTC),
+			match: true,
+		},
+		{
+			spec:  "0,30 0 1 * 7",
+			time:  time.Date(2018, 11, 1, 0, 30, 0, 0, time.UTC),
+			match: true,
+		},
+		{spec: "0 2 * *", err: true},
+		{spec: "60 2 * * *", err: true},
+		{spec: "0 2 * * */0", err: true},
+		{spec: "0 5-2 * * *", err: true},
+	}
+	for _, test := range tests {
+		s, err := parseCron(test.spec)
+		if test.err {
+			if err == nil {
+				t.Errorf("want error of `%s`", test.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("want no error of `%s` but got `%v`", test.spec, err)
+		}
+		if s.matches(test.time) != test.match {
+			t.Errorf("want `%s` match %v to be %v", test.spec, test.time,
+				test.match)
+		}
+	}
+}
+
+func TestMaintenance_active(t *testing.T) {
+	now := time.Date(2018, 10, 21, 2, 30, 0, 0, time.UTC)
+	m, err := newMaintenance(MaintenanceConfig{
+		Windows: []MaintenanceWindow{{
+			Start: now.Add(-time.Hour),
+			End:   now.Add(time.Minute),
+		}},
+		Recurring: []RecurringMaintenance{{
+			Spec:     "0 2 * * 0",
+			Duration: time.Hour,
+		}},
+		FetchInterval: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	m.now = func() time.Time { return now }
+
+	var fetches int
+	m.fetch = func() ([]MaintenanceWindow, error) {
+		fetches++
+		if fetches > 1 {
+			return nil, errors.New("fail")
+		}
+		return []MaintenanceWindow{{
+			Start:  now,
+			End:    now.Add(2 * time.Hour),
+			Reason: "upgrade",
+		}}, nil
+	}
+
+	w, ok := m.active()
+	if !ok || w.Reason != "upgrade" {
+		t.Fatalf("want announced window ending last but got `%v`", w)
+	}
+
+	// Refresh fails, but the last known windows are kept.
+	now = now.Add(90 * time.Minute)
+	if w, ok := m.active(); !ok || w.Reason != "upgrade" || fetches != 2 {
+		t.Errorf("want last known window kept but got `%v`", w)
+	}
+
+	now = now.Add(time.Hour)
+	if w, ok := m.active(); ok {
+		t.Errorf("want no window but got `%v`", w)
+	}
+
+	now = time.Date(2018, 10, 28, 2, 59, 0, 0, time.UTC)
+	if w, ok := m.active(); !ok || !w.End.Equal(now.Add(time.Minute)) {
+		t.Errorf("want recurring window but got `%v`", w)
+	}
+
+	if _, err := newMaintenance(MaintenanceConfig{
+		Recurring: []RecurringMaintenance{{Spec: "0 2 * *",
+			Duration: time.Hour}},
+	}); err == nil {
+		t.Error("want error of invalid spec")
+	}
+}
+
+func TestClient_maintenance(t *testing.T) {
+	now := time.Now()
+	m, _ := newMaintenance(MaintenanceConfig{Windows: []MaintenanceWindow{{
+		Start: now.Add(-time.Minute),
+		End:   now.Add(time.Minute),
+	}}})
+
+	core := &mockCore{respJSON: `{"data": {}}`}
+	client := &Client{core: core, maintenance: m}
+
+	if _, ok := client.Maintenance(); !ok {
+		t.Error("want client to be in maintenance")
+	}
+	_, err := client.Withdraw("BTC", dec(1), "addr")
+	if !errors.Is(err, ErrMaintenanceWindow) {
+		t.Errorf("want maintenance error but got `%v`", err)
+	}
+	if core.request.Query != "" {
+		t.Error("want mutation not to be sent")
+	}
+	if _, err := client.Info(); err != nil {
+		t.Errorf("want queries to be sent but got `%v`", err)
+	}
+}
+
+func TestSubscriptions_longPoll_maintenance(t *testing.T) {
+	now := time.Now()
+	m, _ := newMaintenance(MaintenanceConfig{Windows: []MaintenanceWindow{{
+		Start: now,
+		End:   now.Add(50 * time.Millisecond),
+	}}})
+
+	s := newSubscriptions(SubscriptionConfig{
+		PollInterval: time.Millisecond,
+	}, nil, nopMetrics{})
+	s.maintenance = m
+
+	var (
+		polls    int32
+		resumed  = make(chan time.Time, 1)
+		ctx, end = context.WithTimeout(context.Background(), time.Second)
+	)
+	defer end()
+
+	s.run(ctx, subscription{
+		name: "test",
+		poll: func(cursor int64) ([]interface{}, int64, error) {
+			if atomic.AddInt32(&polls, 1) == 1 {
+				resumed <- time.Now()
+			}
+			return []interface{}{cursor}, cursor + 1, nil
+		},
+	}, func(interface{}) bool { return false })
+
+	if at := <-resumed; at.Before(now.Add(50 * time.Millisecond)) {
+		t.Errorf("want polls paused until %v but got poll at %v",
+			now.Add(50*time.Millisecond), at)
+	}
+}
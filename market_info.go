@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MarketInfo is the metadata of the market supported by exchange.
+type MarketInfo struct {
+	// Market is the market symbol, e.g. "BTCETH".
+	Market string `json:"market"`
+
+	// Stock is the asset which is traded on the market, e.g. "ETH".
+	Stock string `json:"stock"`
+
+	// Money is the asset which stock is priced in, e.g. "BTC".
+	Money string `json:"money"`
+
+	// PricePrecision is the number of decimal places of the order
+	// price.
+	PricePrecision int32 `json:"pricePrecision"`
+
+	// AmountPrecision is the number of decimal places of the order
+	// amount.
+	AmountPrecision int32 `json:"amountPrecision"`
+
+	// MinAmount is the minimal amount of the order.
+	MinAmount decimal.Decimal `json:"minAmount"`
+
+	// MaxAmount is the maximal amount of the order, zero if it is not
+	// limited.
+	MaxAmount decimal.Decimal `json:"maxAmount"`
+}
+
+// defaultMarketsCacheTTL is the time supported markets are cached for if
+// they are required by other features, e.g. order validation.
+const defaultMarketsCacheTTL = 10 * time.Minute
+
+// marketsCache keeps supported markets for the TTL.
+type marketsCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mtx     sync.Mutex
+	markets []MarketInfo
+	expires time.Time
+}
+
+// newMarketsCache creates new cache of supported markets.
+func newMarketsCache(ttl time.Duration) *marketsCache {
+	return &marketsCache{ttl: ttl, now: time.Now}
+}
+
+// get returns cached markets and whether they are not expired.
+func (c *marketsCache) get() ([]MarketInfo, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.markets == nil || !c.now().Before(c.expires) {
+		return nil, false
+	}
+	return append([]MarketInfo(nil), c.markets...), true
+}
+
+// put caches the markets.
+func (c *marketsCache) put(markets []MarketInfo) {
+	if c == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.markets = append([]MarketInfo(nil), markets...)
+	c.expires = c.now().Add(c.ttl)
+}
+
+// WithSupportedMarketsCache makes the client cache SupportedMarkets
+// results for the TTL. By default markets are requested on every call.
+func WithSupportedMarketsCache(ttl time.Duration) Option {
+	return func(o *options) {
+		o.supportedMarketsTTL = ttl
+	}
+}
+
+// SupportedMarkets requests markets supported by exchange along with
+// their precision and limits. Results are cached if caching is
+// enabled, see WithSupportedMarketsCache.
+func (c *Client) SupportedMarkets(ctx context.Context) ([]MarketInfo, error) {
+	if markets, ok := c.marketsCache.get(); ok {
+		return markets, nil
+	}
+
+	var req request
+
+	req.Query = `
+		query SupportedMarkets {
+			supportedMarkets {
+				market
+				stock
+				money
+				pricePrecision
+				amountPrecision
+				minAmount
+				maxAmount
+			}
+		}
+	`
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Markets []MarketInfo `json:"supportedMarkets"`
+		}
+	}{}
+
+	respJSON, err := c.WithContext(ctx).do(false, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	markets := resp.Data.Markets
+	for i := range markets {
+		markets[i].Market = c.assets.localMarket(markets[i].Market)
+		markets[i].Stock = c.assets.localAsset(markets[i].Stock)
+		markets[i].Money = c.assets.localAsset(markets[i].Money)
+	}
+
+	c.marketsCache.put(markets)
+
+	return markets, nil
+}
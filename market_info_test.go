@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClient_SupportedMarketsCache(t *testing.T) {
+	var calls int
+	backend := CoreFunc(func(query string,
+		variables interface{}) ([]byte, error) {
+
+		calls++
+		return []byte(supportedMarketsJSON), nil
+	})
+
+	now := time.Unix(1000, 0)
+	cache := newMarketsCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	client := &Client{core: backend, marketsCache: cache}
+
+	for i := 0; i < 2; i++ {
+		markets, err := client.SupportedMarkets(context.Background())
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if len(markets) != 4 {
+			t.Fatalf("want 4 markets but got %v", len(markets))
+		}
+	}
+	if calls != 1 {
+		t.Errorf("want markets requested once but got %v", calls)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := client.SupportedMarkets(context.Background()); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if calls != 2 {
+		t.Errorf("want markets requested again after TTL but got %v calls",
+			calls)
+	}
+
+	uncached := &Client{core: backend}
+	uncached.SupportedMarkets(context.Background())
+	uncached.SupportedMarkets(context.Background())
+	if calls != 4 {
+		t.Errorf("want markets requested every call without cache but"+
+			" got %v calls", calls)
+	}
+}
+
+func TestClient_SupportedMarketsError(t *testing.T) {
+	cache := newMarketsCache(time.Minute)
+	client := &Client{
+		core:         &mockCore{respJSON: `{"errors": [{"message": "no"}]}`},
+		marketsCache: cache,
+	}
+
+	if _, err := client.SupportedMarkets(context.Background()); err == nil {
+		t.Fatal("want error but got nil")
+	}
+	if _, ok := cache.get(); ok {
+		t.Error("want failed request not cached")
+	}
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInsaneMarketData is returned if market data validation is enabled
+// in reject mode and the server market data fails sanity checks. The
+// data is returned along with the error, with Anomalies describing the
+// failed checks.
+var ErrInsaneMarketData = errors.New("insane market data")
+
+// defaultMaxChangePercent is the default absolute value of the market
+// change percentage above which the change is considered absurd.
+var defaultMaxChangePercent = decimal.New(1000, 0)
+
+// MarketDataValidation is the configuration of the server market data
+// sanity checks.
+type MarketDataValidation struct {
+	// MaxChangePercent is the maximum absolute value of the market
+	// status change percentages, zero means 1000%.
+	MaxChangePercent decimal.Decimal
+
+	// Reject makes Depth and Markets return ErrInsaneMarketData if
+	// the data fails sanity checks, otherwise the data is only tagged
+	// with Anomalies.
+	Reject bool
+}
+
+// WithMarketDataValidation enables sanity checks of the server market
+// data returned by Depth and Markets: crossed books, negative prices and
+// volumes, non-monotonic price levels and absurd change percentages.
+// This protects strategies from server glitches.
+func WithMarketDataValidation(v MarketDataValidation) Option {
+	return func(o *options) {
+		o.marketDataValidation = &v
+	}
+}
+
+// marketDataValidator checks sanity of the market data. Nil validator
+// checks nothing.
+type marketDataValidator struct {
+	maxChangePercent decimal.Decimal
+	reject           bool
+}
+
+// newMarketDataValidator creates new validator from configuration.
+func newMarketDataValidator(v MarketDataValidation) *marketDataValidator {
+	maxChange := v.MaxChangePercent
+	if maxChange.Sign() <= 0 {
+		maxChange = defaultMaxChangePercent
+	}
+	return &marketDataValidator{
+		maxChangePercent: maxChange,
+		reject:           v.Reject,
+	}
+}
+
+// depth tags the depth with found anomalies and returns
+// ErrInsaneMarketData if there are any and validator rejects them.
+func (v *marketDataValidator) depth(d *Depth) error {
+	if v == nil {
+		return nil
+	}
+
+	d.Anomalies = depthAnomalies(*d)
+	if len(d.Anomalies) > 0 && v.reject {
+		return ErrInsaneMarketData
+	}
+
+	return nil
+}
+
+// marketStatuses tags the statuses with found anomalies and returns
+// ErrInsaneMarketData if there are any and validator rejects them.
+func (v *marketDataValidator) marketStatuses(statuses []MarketStatus) error {
+	if v == nil {
+		return nil
+	}
+
+	var insane bool
+	for i := range statuses {
+		s := &statuses[i]
+		s.Anomalies = marketStatusAnomalies(*s, v.maxChangePercent)
+		insane = insane || len(s.Anomalies) > 0
+	}
+
+	if insane && v.reject {
+		return ErrInsaneMarketData
+	}
+
+	return nil
+}
+
+// depthAnomalies returns descriptions of the depth failed sanity checks.
+func depthAnomalies(d Depth) []string {
+	var anomalies []string
+
+	for i, a := range d.Asks {
+		if a.Price.Sign() <= 0 {
+			anomalies = append(anomalies, "non-positive ask price")
+		}
+		if a.Volume.Sign() < 0 {
+			anomalies = append(anomalies, "negative ask volume")
+		}
+		if i > 0 && a.Price.LessThan(d.Asks[i-1].Price) {
+			anomalies = append(anomalies, "non-monotonic ask prices")
+		}
+	}
+
+	for i, b := range d.Bids {
+		if b.Price.Sign() <= 0 {
+			anomalies = append(anomalies, "non-positive bid price")
+		}
+		if b.Volume.Sign() < 0 {
+			anomalies = append(anomalies, "negative bid volume")
+		}
+		if i > 0 && b.Price.GreaterThan(d.Bids[i-1].Price) {
+			anomalies = append(anomalies, "non-monotonic bid prices")
+		}
+	}
+
+	if len(d.Asks) > 0 && len(d.Bids) > 0 &&
+		d.Bids[0].Price.GreaterThanOrEqual(d.Asks[0].Price) {
+		anomalies = append(anomalies, "crossed book")
+	}
+
+	return anomalies
+}
+
+// marketStatusAnomalies returns descriptions of the market status failed
+// sanity checks.
+func marketStatusAnomalies(s MarketStatus,
+	maxChangePercent decimal.Decimal) []string {
+
+	var anomalies []string
+
+	for _, p := range []decimal.Decimal{s.Open, s.Close, s.High, s.Last,
+		s.Low, s.BestAsk, s.BestBid} {
+
+		if p.Sign() < 0 {
+			anomalies = append(anomalies, "negative price")
+			break
+		}
+	}
+
+	if s.Volume.Sign() < 0 {
+		anomalies = append(anomalies, "negative volume")
+	}
+
+	if s.High.LessThan(s.Low) {
+		anomalies = append(anomalies, "high price is lower than low price")
+	}
+
+	if s.BestAsk.Sign() > 0 && s.BestBid.Sign() > 0 &&
+		s.BestBid.GreaterThanOrEqual(s.BestAsk) {
+		anomalies = append(anomalies, "crossed book")
+	}
+
+	for _, c := range []decimal.Decimal{s.ChangeLast, s.ChangeHigh,
+		s.ChangeLow} {
+
+		if c.Abs().GreaterThan(maxChangePercent) {
+			anomalies = append(anomalies, "absurd change percentage")
+			break
+		}
+	}
+
+	return anomalies
+}
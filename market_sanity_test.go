@@ -0,0 +1,147 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDepthAnomalies(t *testing.T) {
+	tests := []struct {
+		name  string
+		depth Depth
+		want  []string
+	}{
+		{
+			name: "sane depth",
+			depth: Depth{
+				Asks: []Ask{{dec(2), dec(1)}, {dec(3), dec(1)}},
+				Bids: []Bid{{dec(1), dec(1)}, {dec(0.5), dec(1)}},
+			},
+		},
+		{
+			name: "crossed book",
+			depth: Depth{
+				Asks: []Ask{{dec(1), dec(1)}},
+				Bids: []Bid{{dec(1), dec(1)}},
+			},
+			want: []string{"crossed book"},
+		},
+		{
+			name: "negative volumes",
+			depth: Depth{
+				Asks: []Ask{{dec(2), dec(-1)}},
+				Bids: []Bid{{dec(1), dec(-1)}},
+			},
+			want: []string{"negative ask volume", "negative bid volume"},
+		},
+		{
+			name: "non-monotonic prices",
+			depth: Depth{
+				Asks: []Ask{{dec(3), dec(1)}, {dec(2), dec(1)}},
+				Bids: []Bid{{dec(0.5), dec(1)}, {dec(1), dec(1)}},
+			},
+			want: []string{"non-monotonic ask prices",
+				"non-monotonic bid prices"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := depthAnomalies(test.depth)
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("want anomalies `%v` but got `%v`", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMarketStatusAnomalies(t *testing.T) {
+	tests := []struct {
+		name   string
+		status MarketStatus
+		want   []string
+	}{
+		{
+			name: "sane status",
+			status: MarketStatus{High: dec(2), Low: dec(1),
+				BestAsk: dec(2), BestBid: dec(1), ChangeLast: dec(-50)},
+		},
+		{
+			name: "crossed book",
+			status: MarketStatus{High: dec(2), Low: dec(1),
+				BestAsk: dec(1), BestBid: dec(2)},
+			want: []string{"crossed book"},
+		},
+		{
+			name:   "negative volume and price",
+			status: MarketStatus{Last: dec(-1), Volume: dec(-1)},
+			want:   []string{"negative price", "negative volume"},
+		},
+		{
+			name:   "absurd change",
+			status: MarketStatus{ChangeHigh: dec(5000)},
+			want:   []string{"absurd change percentage"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := marketStatusAnomalies(test.status,
+				defaultMaxChangePercent)
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("want anomalies `%v` but got `%v`", test.want, got)
+			}
+		})
+	}
+}
+
+func TestClient_Depth_validation(t *testing.T) {
+	respJSON := `{ "data": { "depth": {
+		"asks": [{ "price": "1", "volume": "1" }],
+		"bids": [{ "price": "2", "volume": "1" }]
+	} } }`
+
+	t.Run("when tagging", func(t *testing.T) {
+		client := &Client{
+			core:      &mockCore{respJSON: respJSON},
+			validator: newMarketDataValidator(MarketDataValidation{}),
+		}
+		depth, err := client.Depth("BTCETH", 1, 0)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if len(depth.Anomalies) != 1 {
+			t.Errorf("want 1 anomaly but got `%v`", depth.Anomalies)
+		}
+	})
+	t.Run("when rejecting", func(t *testing.T) {
+		client := &Client{
+			core: &mockCore{respJSON: respJSON},
+			validator: newMarketDataValidator(MarketDataValidation{
+				Reject: true,
+			}),
+		}
+		if _, err := client.Depth("BTCETH", 1, 0); err != ErrInsaneMarketData {
+			t.Errorf("want ErrInsaneMarketData but got `%v`", err)
+		}
+	})
+}
+
+func TestClient_Markets_validation(t *testing.T) {
+	client := &Client{
+		core: &mockCore{respJSON: `{ "data": { "markets": [
+			{ "market": "BTCETH", "changeLast": "100000" }
+		] } }`},
+		validator: newMarketDataValidator(MarketDataValidation{
+			Reject: true,
+		}),
+	}
+
+	statuses, err := client.Markets([]string{"BTCETH"}, 60)
+	if err != ErrInsaneMarketData {
+		t.Fatalf("want ErrInsaneMarketData but got `%v`", err)
+	}
+	if len(statuses) != 1 || len(statuses[0].Anomalies) != 1 {
+		t.Errorf("want tagged status but got `%#v`", statuses)
+	}
+}
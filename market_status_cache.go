@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// MarketStatusSource tells where the cached market status came from.
+type MarketStatusSource string
+
+// Market status sources.
+const (
+	// MarketStatusSnapshot is the full status requested with Markets.
+	MarketStatusSnapshot MarketStatusSource = "snapshot"
+
+	// MarketStatusStream is the status updated by ticker update from
+	// SubscribeTickers.
+	MarketStatusStream MarketStatusSource = "stream"
+)
+
+// CachedMarketStatus is the last known status of the market.
+type CachedMarketStatus struct {
+	MarketStatus
+
+	// UpdatedAt is the time the status was last updated.
+	UpdatedAt time.Time
+
+	// Source is the source of the last update.
+	Source MarketStatusSource
+}
+
+// MarketStatusCache keeps the latest status of markets by merging
+// ticker updates into periodic full Markets snapshots. Failed refreshes
+// and subscription reconnects leave the last known good status in
+// place, so consumers always get coherent value.
+type MarketStatusCache struct {
+	client  *Client
+	markets []string
+	now     func() time.Time
+
+	mtx      sync.RWMutex
+	statuses map[string]CachedMarketStatus
+}
+
+// NewMarketStatusCache requests statuses of the markets, its error is
+// returned, and keeps them up to date with ticker updates and full
+// refreshes every refresh interval until ctx is done.
+func (c *Client) NewMarketStatusCache(ctx context.Context, markets []string,
+	refresh time.Duration) (*MarketStatusCache, error) {
+
+	if refresh <= 0 {
+		return nil, errors.New("refresh interval should be positive")
+	}
+
+	cache := &MarketStatusCache{
+		client:   c.WithContext(ctx),
+		markets:  markets,
+		now:      time.Now,
+		statuses: make(map[string]CachedMarketStatus, len(markets)),
+	}
+
+	if err := cache.refresh(); err != nil {
+		return nil, err
+	}
+
+	tickers, err := cache.client.SubscribeTickers(markets)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer c.errs.recover("market status cache")
+		for t := range tickers {
+			cache.apply(t)
+		}
+	}()
+
+	go func() {
+		defer c.errs.recover("market status cache refresh")
+		for sleep(ctx, refresh) && c.maintenance.wait(ctx) {
+			c.pool.Do(ctx, func() {
+				cache.refresh()
+			})
+		}
+	}()
+
+	return cache, nil
+}
+
+// Get returns the last known status of the market and whether it is
+// known.
+func (c *MarketStatusCache) Get(market string) (CachedMarketStatus, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	s, ok := c.statuses[market]
+	return s, ok
+}
+
+// refresh replaces cached statuses with the full snapshot.
+func (c *MarketStatusCache) refresh() error {
+	statuses, err := c.client.Markets(c.markets, tickersPollPeriod)
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, s := range statuses {
+		c.statuses[s.Market] = CachedMarketStatus{
+			MarketStatus: s,
+			UpdatedAt:    now,
+			Source:       MarketStatusSnapshot,
+		}
+	}
+	return nil
+}
+
+// apply merges ticker update into the cached status of its market.
+func (c *MarketStatusCache) apply(t Ticker) {
+	now := c.now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	s := c.statuses[t.Market]
+	s.Market = t.Market
+	s.Last = t.Last
+	s.BestAsk = t.BestAsk
+	s.BestBid = t.BestBid
+	s.Volume = t.Volume
+	s.UpdatedAt = now
+	s.Source = MarketStatusStream
+	c.statuses[t.Market] = s
+}
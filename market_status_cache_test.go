@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_NewMarketStatusCache(t *testing.T) {
+	var (
+		mtx  sync.Mutex
+		resp = `{ "data": { "markets": [
+			{ "market": "BTCETH", "stock": "ETH", "last": "0.03" }
+		] } }`
+		fail  bool
+		calls int
+	)
+	setResponse := func(r string, f bool) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		resp, fail = r, f
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &Client{
+		core: CoreFunc(func(string, interface{}) ([]byte, error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			calls++
+			if fail {
+				return nil, errors.New("exchange is down")
+			}
+			return []byte(resp), nil
+		}),
+		subs: newSubscriptions(SubscriptionConfig{
+			PollInterval: time.Millisecond,
+		}, nil, nopMetrics{}),
+	}
+
+	cache, err := client.NewMarketStatusCache(ctx, []string{"BTCETH"},
+		time.Hour)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	s, ok := cache.Get("BTCETH")
+	if !ok || s.Source != MarketStatusSnapshot || !s.Last.Equal(dec(0.03)) {
+		t.Fatalf("want snapshot status but got `%v`", s)
+	}
+
+	// Wait for the first ticker poll, which establishes the baseline.
+	waitFor(t, func() bool {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return calls > 1
+	})
+
+	// Tickers are merged into the snapshot, subscription failures leave
+	// the last known status.
+	setResponse(`{ "data": { "markets": [
+		{ "market": "BTCETH", "stock": "ETH", "last": "0.04" }
+	] } }`, false)
+	waitFor(t, func() bool {
+		s, _ := cache.Get("BTCETH")
+		return s.Source == MarketStatusStream
+	})
+	setResponse("", true)
+
+	s, _ = cache.Get("BTCETH")
+	if !s.Last.Equal(dec(0.04)) {
+		t.Errorf("want last 0.04 but got %v", s.Last)
+	}
+	if s.Stock != "ETH" {
+		t.Errorf("want snapshot fields kept but got stock `%s`", s.Stock)
+	}
+	if s.UpdatedAt.IsZero() {
+		t.Error("want update time set")
+	}
+
+	if _, ok := cache.Get("BTCLTC"); ok {
+		t.Error("want unknown market not to be cached")
+	}
+}
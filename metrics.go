@@ -0,0 +1,26 @@
+package client
+
+// Labels is a set of metric labels (dimensions), e.g. asset or market.
+type Labels map[string]string
+
+// Metrics is a receiver of the client metrics. It allows to plug in any
+// metrics backend (prometheus, statsd, etc.) without making the client
+// depend on it.
+type Metrics interface {
+	// Observe records an observation of the named histogram or summary,
+	// e.g. duration of the operation in seconds.
+	Observe(name string, labels Labels, value float64)
+
+	// Add adds delta to the named counter.
+	Add(name string, labels Labels, delta float64)
+
+	// Set sets the named gauge to the value.
+	Set(name string, labels Labels, value float64)
+}
+
+// nopMetrics is the Metrics implementation which discards all metrics.
+type nopMetrics struct{}
+
+func (nopMetrics) Observe(string, Labels, float64) {}
+func (nopMetrics) Add(string, Labels, float64)     {}
+func (nopMetrics) Set(string, Labels, float64)     {}
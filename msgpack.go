@@ -0,0 +1,281 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// msgpackContentType is the media type of msgpack encoded responses.
+const msgpackContentType = "application/msgpack"
+
+// maxMsgpackDepth is the maximum nesting of msgpack arrays and maps.
+const maxMsgpackDepth = 64
+
+// WithMsgpack makes the client ask the exchange for msgpack encoded
+// responses, which are smaller than JSON ones for market data. JSON
+// stays acceptable, so the exchange may answer with either of them.
+// Msgpack responses are transcoded into JSON, so they are decoded the
+// same way as JSON ones; it trades decoding cost for the payload size,
+// see BenchmarkDecodeDepthJSON and BenchmarkDecodeDepthMsgpack.
+func WithMsgpack() Option {
+	return func(o *options) {
+		o.msgpack = true
+	}
+}
+
+// isMsgpack returns true if the response is msgpack encoded.
+func isMsgpack(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == msgpackContentType ||
+		mediaType == "application/x-msgpack"
+}
+
+// msgpackToJSON transcodes single msgpack value into JSON. Binary
+// values become base64 encoded strings, extension types are not
+// supported.
+func msgpackToJSON(b []byte) ([]byte, error) {
+	d := msgpackDecoder{data: b}
+
+	var buf bytes.Buffer
+	buf.Grow(len(b) * 2)
+
+	if err := d.value(&buf, 0); err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, errors.New("trailing data after msgpack value")
+	}
+	return buf.Bytes(), nil
+}
+
+// msgpackDecoder reads msgpack values from the data.
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+// next returns next n bytes of the data.
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errors.New("unexpected end of msgpack data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads big-endian unsigned integer of n bytes.
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// int reads big-endian signed integer of n bytes.
+func (d *msgpackDecoder) int(n int) (int64, error) {
+	v, err := d.uint(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return int64(int8(v)), nil
+	case 2:
+		return int64(int16(v)), nil
+	case 4:
+		return int64(int32(v)), nil
+	default:
+		return int64(v), nil
+	}
+}
+
+// value transcodes next msgpack value into JSON written to buf.
+func (d *msgpackDecoder) value(buf *bytes.Buffer, depth int) error {
+	if depth > maxMsgpackDepth {
+		return errors.New("msgpack value is nested too deep")
+	}
+
+	head, err := d.next(1)
+	if err != nil {
+		return err
+	}
+	t := head[0]
+
+	switch {
+	case t <= 0x7f:
+		buf.WriteString(strconv.Itoa(int(t)))
+		return nil
+	case t >= 0xe0:
+		buf.WriteString(strconv.Itoa(int(int8(t))))
+		return nil
+	case t&0xf0 == 0x80:
+		return d.mapValue(buf, int(t&0x0f), depth)
+	case t&0xf0 == 0x90:
+		return d.array(buf, int(t&0x0f), depth)
+	case t&0xe0 == 0xa0:
+		return d.str(buf, int(t&0x1f))
+	}
+
+	switch t {
+	case 0xc0:
+		buf.WriteString("null")
+	case 0xc2:
+		buf.WriteString("false")
+	case 0xc3:
+		buf.WriteString("true")
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(1 << (t - 0xcc))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatUint(v, 10))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		v, err := d.int(1 << (t - 0xd0))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case 0xca, 0xcb:
+		// Float32 is formatted with its own precision, so 0.1 isn't
+		// widened to 0.10000000149011612.
+		var f float64
+		bitSize := 64
+		if t == 0xca {
+			v, err := d.uint(4)
+			if err != nil {
+				return err
+			}
+			f, bitSize = float64(math.Float32frombits(uint32(v))), 32
+		} else {
+			v, err := d.uint(8)
+			if err != nil {
+				return err
+			}
+			f = math.Float64frombits(v)
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return errors.New("msgpack float isn't representable in JSON")
+		}
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (t - 0xd9))
+		if err != nil {
+			return err
+		}
+		return d.str(buf, int(n))
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (t - 0xc4))
+		if err != nil {
+			return err
+		}
+		b, err := d.next(int(n))
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('"')
+		buf.WriteString(base64.StdEncoding.EncodeToString(b))
+		buf.WriteByte('"')
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (t - 0xdc))
+		if err != nil {
+			return err
+		}
+		return d.array(buf, int(n), depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (t - 0xde))
+		if err != nil {
+			return err
+		}
+		return d.mapValue(buf, int(n), depth)
+	default:
+		return errors.New("unsupported msgpack type 0x" +
+			strconv.FormatUint(uint64(t), 16))
+	}
+	return nil
+}
+
+// str transcodes string of n bytes.
+func (d *msgpackDecoder) str(buf *bytes.Buffer, n int) error {
+	b, err := d.next(n)
+	if err != nil {
+		return err
+	}
+	s, err := json.Marshal(string(b))
+	if err != nil {
+		return err
+	}
+	buf.Write(s)
+	return nil
+}
+
+// array transcodes array of n values.
+func (d *msgpackDecoder) array(buf *bytes.Buffer, n, depth int) error {
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := d.value(buf, depth+1); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+// mapValue transcodes map of n key-value pairs, keys should be strings.
+func (d *msgpackDecoder) mapValue(buf *bytes.Buffer, n, depth int) error {
+	buf.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		head, err := d.next(1)
+		if err != nil {
+			return err
+		}
+		switch t := head[0]; {
+		case t&0xe0 == 0xa0:
+			err = d.str(buf, int(t&0x1f))
+		case t == 0xd9 || t == 0xda || t == 0xdb:
+			var l uint64
+			if l, err = d.uint(1 << (t - 0xd9)); err == nil {
+				err = d.str(buf, int(l))
+			}
+		default:
+			err = errors.New("msgpack map key should be string")
+		}
+		if err != nil {
+			return err
+		}
+
+		buf.WriteByte(':')
+		if err := d.value(buf, depth+1); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// encodeMsgpack encodes value made of maps, slices, strings, numbers,
+// booleans and nils into msgpack, it is the reference encoder of tests.
+func encodeMsgpack(buf *bytes.Buffer, v interface{}) {
+	writeLen := func(fix, b8, b16, b32 byte, n int, fixMax int) {
+		switch {
+		case n <= fixMax:
+			buf.WriteByte(fix | byte(n))
+		case b8 != 0 && n <= math.MaxUint8:
+			buf.WriteByte(b8)
+			buf.WriteByte(byte(n))
+		case n <= math.MaxUint16:
+			buf.WriteByte(b16)
+			binary.Write(buf, binary.BigEndian, uint16(n))
+		default:
+			buf.WriteByte(b32)
+			binary.Write(buf, binary.BigEndian, uint32(n))
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, int64(v))
+	case float64:
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case string:
+		writeLen(0xa0, 0xd9, 0xda, 0xdb, len(v), 31)
+		buf.WriteString(v)
+	case []interface{}:
+		writeLen(0x90, 0, 0xdc, 0xdd, len(v), 15)
+		for _, e := range v {
+			encodeMsgpack(buf, e)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeLen(0x80, 0, 0xde, 0xdf, len(v), 15)
+		for _, k := range keys {
+			encodeMsgpack(buf, k)
+			encodeMsgpack(buf, v[k])
+		}
+	default:
+		panic("unsupported type")
+	}
+}
+
+// depthResponse returns depth response of n price levels per side.
+func depthResponse(n int) map[string]interface{} {
+	levels := make([]interface{}, n)
+	for i := range levels {
+		levels[i] = map[string]interface{}{
+			"price":  "0.0312345",
+			"volume": "12.5",
+		}
+	}
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"depth": map[string]interface{}{
+				"asks": levels,
+				"bids": levels,
+			},
+		},
+	}
+}
+
+func TestMsgpackToJSON(t *testing.T) {
+	value := map[string]interface{}{
+		"null":   nil,
+		"true":   true,
+		"false":  false,
+		"int":    -123456789,
+		"float":  0.25,
+		"string": "quote \" and ünïcode",
+		"long":   string(bytes.Repeat([]byte("a"), 300)),
+		"array":  []interface{}{"a", 1, nil},
+		"nested": depthResponse(20),
+	}
+
+	var buf bytes.Buffer
+	encodeMsgpack(&buf, value)
+
+	got, err := msgpackToJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	want, _ := json.Marshal(value)
+	var wantValue, gotValue interface{}
+	json.Unmarshal(want, &wantValue)
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("want valid JSON but got `%s`", got)
+	}
+	if string(mustMarshal(wantValue)) != string(mustMarshal(gotValue)) {
+		t.Errorf("want JSON `%s` but got `%s`", want, got)
+	}
+
+	t.Run("fixed and compact integers", func(t *testing.T) {
+		got, err := msgpackToJSON([]byte{0x93, 0x05, 0xff, 0xcd, 0x01, 0x00})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if string(got) != "[5,-1,256]" {
+			t.Errorf("want [5,-1,256] but got `%s`", got)
+		}
+	})
+	t.Run("float32", func(t *testing.T) {
+		// 0.1 as float32 followed by 0.1 as float64.
+		got, err := msgpackToJSON([]byte{0x92, 0xca, 0x3d, 0xcc, 0xcc,
+			0xcd, 0xcb, 0x3f, 0xb9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if string(got) != "[0.1,0.1]" {
+			t.Errorf("want [0.1,0.1] but got `%s`", got)
+		}
+	})
+	t.Run("truncated data", func(t *testing.T) {
+		if _, err := msgpackToJSON(buf.Bytes()[:buf.Len()-1]); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+	t.Run("non-string key", func(t *testing.T) {
+		if _, err := msgpackToJSON([]byte{0x81, 0x01, 0x02}); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestGraphQLCore_msgpack(t *testing.T) {
+	var buf bytes.Buffer
+	encodeMsgpack(&buf, depthResponse(1))
+
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept")
+			w.Header().Set("Content-Type", msgpackContentType)
+			w.Write(buf.Bytes())
+		}))
+	defer server.Close()
+
+	client := &Client{core: &graphQLCore{url: server.URL, msgpack: true}}
+
+	depth, err := client.Depth("BTCETH", 1, 0)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(depth.Asks) != 1 || !depth.Asks[0].Price.Equal(
+		decimal.RequireFromString("0.0312345")) {
+		t.Errorf("want decoded depth but got `%v`", depth)
+	}
+	if accept != msgpackContentType+", application/json;q=0.9" {
+		t.Errorf("want msgpack accepted but got `%s`", accept)
+	}
+}
+
+func BenchmarkDecodeDepthJSON(b *testing.B) {
+	body := mustMarshal(depthResponse(100))
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var resp depthResponseJSON
+		if err := json.Unmarshal(body, &resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeDepthMsgpack(b *testing.B) {
+	var buf bytes.Buffer
+	encodeMsgpack(&buf, depthResponse(100))
+	body := buf.Bytes()
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		jsonBody, err := msgpackToJSON(body)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var resp depthResponseJSON
+		if err := json.Unmarshal(jsonBody, &resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// depthResponseJSON is the depth response decoded by benchmarks.
+type depthResponseJSON struct {
+	Data struct {
+		Depth Depth `json:"depth"`
+	} `json:"data"`
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NonceConfig is the configuration of macaroon nonces of processes
+// sharing one macaroon.
+//
+// Nonces are derived from the current time in nanoseconds and strictly
+// increase within the process. If the macaroon is shared, the nonce
+// space is split into Partitions interleaved partitions: nonce of the
+// process is always congruent to its Partition modulo Partitions, so
+// processes with distinct partitions never produce the same nonce while
+// nonces of all of them still grow with time. E.g. with 4 partitions
+// process 1 produces nonces 1, 5, 9... scaled to the current time.
+//
+// Partitions don't order nonces across processes: if the server
+// requires each nonce to be greater than the last one it accepted,
+// request of one process may still be rejected once another process
+// has sent greater nonce, e.g. because their clocks differ or requests
+// were reordered in flight. Such request is retried once with the new
+// nonce, processes which need every nonce accepted should not share the
+// macaroon.
+type NonceConfig struct {
+	// Partitions is the number of partitions of the nonce space, zero
+	// or one disables partitioning. It should be the same in all
+	// processes sharing the macaroon and should not exceed the number
+	// of them much, as every partition reduces nonce time resolution.
+	Partitions int64
+
+	// Partition is the partition of the process in [0, Partitions).
+	// Negative value means the partition is derived from the process
+	// ID salted with random number, which avoids collisions of
+	// processes started at once without coordination, though distinct
+	// partitions are guaranteed only if they are assigned explicitly.
+	Partition int64
+}
+
+// WithNonces sets the configuration of macaroon nonces. By default the
+// nonce space is not partitioned.
+func WithNonces(cfg NonceConfig) Option {
+	return func(o *options) {
+		o.nonces = &cfg
+	}
+}
+
+// nonceGenerator generates strictly increasing macaroon nonces of the
+// partition.
+type nonceGenerator struct {
+	partitions int64
+	partition  int64
+	now        func() time.Time
+
+	mtx  sync.Mutex
+	last int64
+}
+
+// newNonceGenerator creates new generator of nonces.
+func newNonceGenerator(cfg NonceConfig) *nonceGenerator {
+	g := &nonceGenerator{
+		partitions: cfg.Partitions,
+		partition:  cfg.Partition,
+		now:        time.Now,
+	}
+	if g.partitions < 1 {
+		g.partitions = 1
+	}
+	if g.partition < 0 {
+		salt := rand.New(rand.NewSource(time.Now().UnixNano())).Int63()
+		g.partition = (int64(os.Getpid()) ^ salt) % g.partitions
+	}
+	g.partition %= g.partitions
+	return g
+}
+
+// next returns the next nonce.
+func (g *nonceGenerator) next() int64 {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	seq := g.now().UnixNano() / g.partitions
+	if seq <= g.last {
+		seq = g.last + 1
+	}
+	g.last = seq
+
+	return seq*g.partitions + g.partition
+}
+
+// nonce returns the next nonce of the generator, or the current time in
+// nanoseconds if the generator is nil.
+func (g *nonceGenerator) nonce() int64 {
+	if g == nil {
+		return time.Now().UnixNano()
+	}
+	return g.next()
+}
+
+// isNonceCollision returns true if the server rejected the request
+// because its nonce has already been used, e.g. by another process
+// sharing the macaroon. Rejection is reported either with error status
+// or with GraphQL error in the response body.
+func isNonceCollision(body []byte, err error) bool {
+	var detail string
+	switch e := err.(type) {
+	case nil:
+		if !bytes.Contains(bytes.ToLower(body), []byte("nonce")) {
+			return false
+		}
+		detail = errorDetail(body)
+	case *HTTPStatusError:
+		detail = e.Detail
+	default:
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(detail), "nonce")
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_nonceGenerator_next(t *testing.T) {
+	now := time.Unix(1, 0)
+	clock := func() time.Time { return now }
+
+	// Processes sharing the macaroon at the same instant never produce
+	// the same nonce, though their nonces interleave.
+	seen := make(map[int64]bool)
+	for partition := int64(0); partition < 4; partition++ {
+		g := newNonceGenerator(NonceConfig{Partitions: 4, Partition: partition})
+		g.now = clock
+
+		var last int64
+		for i := 0; i < 100; i++ {
+			nonce := g.next()
+			if nonce <= last {
+				t.Fatalf("want nonce greater than %v but got %v", last, nonce)
+			}
+			if nonce%4 != partition {
+				t.Fatalf("want nonce of partition %v but got %v", partition,
+					nonce)
+			}
+			if seen[nonce] {
+				t.Fatalf("want unique nonce but got %v twice", nonce)
+			}
+			seen[nonce], last = true, nonce
+		}
+	}
+
+	// Nonces keep following the clock.
+	g := newNonceGenerator(NonceConfig{Partitions: 4, Partition: 1})
+	g.now = clock
+	before := g.next()
+	now = now.Add(time.Second)
+	if after := g.next(); after < now.UnixNano()-4 {
+		t.Errorf("want nonce to follow the clock but got %v after %v",
+			after, before)
+	}
+}
+
+func Test_nonceGenerator_unordered(t *testing.T) {
+	now := time.Unix(1, 0)
+
+	// Process with the clock behind produces nonces lower than the ones
+	// already sent by other process, so they are not ordered across
+	// processes.
+	ahead := newNonceGenerator(NonceConfig{Partitions: 2, Partition: 0})
+	ahead.now = func() time.Time { return now.Add(time.Millisecond) }
+	behind := newNonceGenerator(NonceConfig{Partitions: 2, Partition: 1})
+	behind.now = func() time.Time { return now }
+
+	sent := ahead.next()
+	if nonce := behind.next(); nonce >= sent {
+		t.Errorf("want nonce lower than %v but got %v", sent, nonce)
+	}
+}
+
+func Test_nonceGenerator_derivedPartition(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		g := newNonceGenerator(NonceConfig{Partitions: 8, Partition: -1})
+		if g.partition < 0 || g.partition >= 8 {
+			t.Fatalf("want partition in [0, 8) but got %v", g.partition)
+		}
+	}
+
+	g := newNonceGenerator(NonceConfig{Partition: -1})
+	if g.partitions != 1 || g.partition != 0 {
+		t.Errorf("want single partition but got %v of %v", g.partition,
+			g.partitions)
+	}
+}
+
+func Test_nonceGenerator_concurrent(t *testing.T) {
+	g := newNonceGenerator(NonceConfig{Partitions: 2, Partition: 1})
+
+	var (
+		mtx  sync.Mutex
+		wg   sync.WaitGroup
+		seen = make(map[int64]bool)
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				nonce := g.next()
+				mtx.Lock()
+				if seen[nonce] {
+					t.Errorf("want unique nonce but got %v twice", nonce)
+				}
+				seen[nonce] = true
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_isNonceCollision(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want bool
+	}{
+		{
+			name: "graphql error",
+			body: `{"errors": [{"message": "Nonce already used"}]}`,
+			want: true,
+		},
+		{
+			name: "status error",
+			err:  &HTTPStatusError{StatusCode: 401, Detail: "nonce reused"},
+			want: true,
+		},
+		{
+			name: "other status error",
+			err:  &HTTPStatusError{StatusCode: 401, Detail: "expired"},
+		},
+		{
+			name: "data mentioning nonce",
+			body: `{"data": {"nonce": 1}}`,
+		},
+		{
+			name: "success",
+			body: `{"data": {}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := isNonceCollision([]byte(test.body), test.err)
+			if got != test.want {
+				t.Errorf("want %v but got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func Test_graphQLCore_do_nonceCollision(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "nonce already used"}`))
+				return
+			}
+			w.Write([]byte("response body"))
+		}))
+	defer server.Close()
+
+	c := &graphQLCore{url: server.URL, jwt: "token"}
+	body, err := c.do(context.Background(), true, request{})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if string(body) != "response body" {
+		t.Errorf("want `response body` but got `%s`", body)
+	}
+	if requests != 2 {
+		t.Errorf("want 2 requests but got %v", requests)
+	}
+
+	// Public requests carry no nonce and are not retried.
+	requests = 0
+	if _, err := c.do(context.Background(), false, request{}); err == nil {
+		t.Error("want error of rejected public request")
+	}
+	if requests != 1 {
+		t.Errorf("want 1 request but got %v", requests)
+	}
+}
@@ -0,0 +1,290 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Option is a functional option which tunes the client created with
+// NewClient.
+type Option func(o *options)
+
+// options is a set of client settings which may be tuned with Option.
+type options struct {
+	// dialer is the configuration of the dialer used to connect to the
+	// exchange server, nil means default dialer of the http package.
+	dialer *DialerConfig
+
+	// dialContext connects to the exchange server instead of the
+	// dialer, nil means the dialer is used.
+	dialContext DialContextFunc
+
+	// signer signs requests if neither macaroon nor JWT is given.
+	signer Signer
+
+	// reauth obtains fresh credentials if server rejects current ones.
+	reauth ReauthFunc
+
+	// tokenSource obtains JWT tokens, nil means the credentials given
+	// to NewClient are used.
+	tokenSource TokenSource
+
+	// issuedMacaroon issues macaroon for the JWT token given to
+	// NewClient, nil disables the issue.
+	issuedMacaroon *macaroonIssuer
+
+	// restFallbackURL is the base URL of exchange REST fallback
+	// endpoint, empty disables fallback.
+	restFallbackURL string
+
+	// har records request/response pairs, nil disables recording.
+	har *HARRecorder
+
+	// assetCodes maps local asset codes to exchange ones.
+	assetCodes map[string]string
+
+	// withdrawalLimits is per asset daily withdrawal limits, nil
+	// disables withdrawal tracking.
+	withdrawalLimits map[string]decimal.Decimal
+
+	// enforceWithdrawalLimits rejects withdrawals exceeding limits.
+	enforceWithdrawalLimits bool
+
+	// orderCache enables cache of Order lookups.
+	orderCache bool
+
+	// orderCachePendingTTL is the time pending orders are cached for.
+	orderCachePendingTTL time.Duration
+
+	// marketDataValidation is the configuration of market data sanity
+	// checks, nil disables checks.
+	marketDataValidation *MarketDataValidation
+
+	// clockSkew is the configuration of clock skew compensation, nil
+	// disables compensation.
+	clockSkew *ClockSkewConfig
+
+	// subscriptions is the configuration of the client subscriptions.
+	subscriptions SubscriptionConfig
+
+	// timeouts is the set of request timeouts.
+	timeouts Timeouts
+
+	// preTradeChecks are invoked before every order placement.
+	preTradeChecks []PreTradeCheck
+
+	// journal records mutations, nil disables journaling.
+	journal *Journal
+
+	// prober selects the fastest endpoint for public requests, nil
+	// disables latency routing.
+	prober *LatencyProber
+
+	// pool runs background work, nil means a goroutine per task.
+	pool *WorkerPool
+
+	// keepAlive is the configuration of keepalive pings.
+	keepAlive KeepAliveConfig
+
+	// dryRun makes mutations return synthetic results instead of
+	// being sent.
+	dryRun bool
+
+	// mutationProbes makes ProbePermissions probe trading and withdraw
+	// permissions with mutations.
+	mutationProbes bool
+
+	// retry is the configuration of retries of transient failures, nil
+	// disables retries.
+	retry *RetryConfig
+
+	// hedge sends duplicates of slow public requests, nil disables
+	// hedging.
+	hedge *hedger
+
+	// deprecationHandler is invoked once per distinct deprecation
+	// reported by the server, nil means deprecations are logged.
+	deprecationHandler func(d Deprecation)
+
+	// stateAnomalyHandler is invoked on impossible order transitions,
+	// nil means anomalies are logged.
+	stateAnomalyHandler func(a StateAnomaly)
+
+	// httpClient sends requests to the exchange, nil means client
+	// created from the other options.
+	httpClient *http.Client
+
+	// doer sends requests instead of the HTTP core, nil means requests
+	// are sent over HTTP.
+	doer Doer
+
+	// transport is the round tripper of requests, nil means transport
+	// created from the dialer and timeouts options.
+	transport http.RoundTripper
+
+	// serializedRequests makes the client send requests one by one.
+	serializedRequests bool
+
+	// throttle is the configuration of retries of throttled requests,
+	// nil disables them.
+	throttle *ThrottleConfig
+
+	// rateLimit limits requests, nil disables the limit.
+	rateLimit *rateLimiter
+
+	// maxConcurrentAuth and maxConcurrentPublic limit the number of
+	// authenticated and public requests in flight, non-positive
+	// disables the limit.
+	maxConcurrentAuth   int
+	maxConcurrentPublic int
+
+	// logger logs requests, nil disables logging.
+	logger Logger
+
+	// slowCalls is the configuration of slow call logging, nil
+	// disables it.
+	slowCalls *SlowCallConfig
+
+	// msgpack makes the client accept msgpack encoded responses.
+	msgpack bool
+
+	// checksumHeader is the header of the response body checksum,
+	// empty disables checksum verification.
+	checksumHeader string
+
+	// complexity is the query complexity budget, nil if there is
+	// none.
+	complexity *ComplexityConfig
+
+	// strictDecimals is the bound of fraction digits of decimals in
+	// request variables, zero disables strict decimal mode.
+	strictDecimals int32
+
+	// nativeTimeInForce makes the client pass time in force of orders
+	// to the server instead of emulating it.
+	nativeTimeInForce bool
+
+	// supportedMarketsTTL is the time supported markets are cached for,
+	// zero disables caching.
+	supportedMarketsTTL time.Duration
+
+	// validation makes the client validate orders and withdrawals
+	// against market and asset metadata.
+	validation bool
+
+	// maintenance is the configuration of maintenance windows, nil
+	// means there are none.
+	maintenance *MaintenanceConfig
+
+	// nonces is the configuration of macaroon nonces, nil means nonces
+	// are not partitioned.
+	nonces *NonceConfig
+
+	// metrics is a receiver of the client metrics.
+	metrics Metrics
+}
+
+// defaultOptions returns options which are used if no Option is given.
+func defaultOptions() *options {
+	return &options{
+		metrics: nopMetrics{},
+		throttle: &ThrottleConfig{
+			MaxRetries: defaultThrottleRetries,
+			MaxWait:    defaultMaxThrottleWait,
+			Wait:       defaultThrottleWait,
+		},
+	}
+}
+
+// WithMetrics sets the receiver of the client metrics. By default
+// metrics are discarded.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		if m == nil {
+			m = nopMetrics{}
+		}
+		o.metrics = m
+	}
+}
+
+// WithHTTPClient makes the client send requests with the given HTTP
+// client, so its connection pool, proxy, TLS configuration and timeout
+// are reused across requests and may be shared with the rest of the
+// application. Dialer, TLS handshake, response header and total
+// timeouts options do not apply to it.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = hc
+	}
+}
+
+// WithTransport makes the client send requests with the given round
+// tripper, e.g. with custom TLS configuration or proxy. Dialer, TLS
+// handshake and response header timeouts options do not apply to it,
+// total timeout does.
+func WithTransport(t http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = t
+	}
+}
+
+// newHTTPClient creates HTTP client according to the options along with
+// its transport, nil transport means default one.
+func newHTTPClient(o *options) (*http.Client, http.RoundTripper) {
+	if o.httpClient != nil {
+		return o.httpClient, o.httpClient.Transport
+	}
+
+	transport := o.transport
+	if transport == nil {
+		transport = newTransport(o)
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   o.timeouts.Total,
+	}, transport
+}
+
+// newTransport creates http transport according to the options, nil
+// is returned if default transport fits.
+func newTransport(o *options) http.RoundTripper {
+	dialer := dialerConfig(o)
+	if dialer == nil && o.dialContext == nil &&
+		o.timeouts.TLSHandshake <= 0 && o.timeouts.ResponseHeader <= 0 {
+		return nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	switch {
+	case o.dialContext != nil:
+		t.DialContext = o.dialContext
+	case dialer != nil:
+		t.DialContext = newDialer(*dialer, o.metrics).DialContext
+	}
+	if o.timeouts.TLSHandshake > 0 {
+		t.TLSHandshakeTimeout = o.timeouts.TLSHandshake
+	}
+	if o.timeouts.ResponseHeader > 0 {
+		t.ResponseHeaderTimeout = o.timeouts.ResponseHeader
+	}
+	return t
+}
+
+// dialerConfig returns the configuration of the dialer with the dial
+// timeout, nil means default dialer. Dial timeout is merged here rather
+// than in WithTimeouts, so WithDialer given after it doesn't drop the
+// timeout.
+func dialerConfig(o *options) *DialerConfig {
+	if o.timeouts.Dial <= 0 {
+		return o.dialer
+	}
+
+	cfg := DialerConfig{}
+	if o.dialer != nil {
+		cfg = *o.dialer
+	}
+	cfg.Timeout = o.timeouts.Dial
+	return &cfg
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// WithOrderCache enables in-memory cache of Order lookups. Orders in
+// terminal status (finished or canceled) are cached indefinitely as they
+// never change, pending orders are cached for pendingTTL. At most
+// orderCacheSize orders are cached, the earliest cached ones are evicted
+// first. Cached order is replaced with the canceled one on CancelOrder.
+// Cache hits and misses are reported as order_cache_hits_total and
+// order_cache_misses_total metrics.
+func WithOrderCache(pendingTTL time.Duration) Option {
+	return func(o *options) {
+		o.orderCache = true
+		o.orderCachePendingTTL = pendingTTL
+	}
+}
+
+// orderCacheSize is the maximum number of cached orders.
+const orderCacheSize = 10000
+
+// orderCacheEntry is a cached order along with its expiration time,
+// zero expiration time means the entry never expires.
+type orderCacheEntry struct {
+	order   Order
+	expires time.Time
+
+	// seq is the number the entry was cached under, which tells it
+	// apart from the earlier entries of the same order in the queue.
+	seq uint64
+}
+
+// orderCacheItem is the order in the queue of eviction.
+type orderCacheItem struct {
+	id  int64
+	seq uint64
+}
+
+// orderCache caches orders by ID. Nil cache caches nothing.
+type orderCache struct {
+	pendingTTL time.Duration
+	metrics    Metrics
+	now        func() time.Time
+
+	// size is the maximum number of cached orders.
+	size int
+
+	mtx     sync.Mutex
+	entries map[int64]orderCacheEntry
+
+	// queue is the orders in the order they were cached in, it may
+	// hold the orders which are no longer cached.
+	queue []orderCacheItem
+	seq   uint64
+}
+
+// newOrderCache creates new cache which keeps pending orders for
+// pendingTTL.
+func newOrderCache(pendingTTL time.Duration, metrics Metrics) *orderCache {
+	return &orderCache{
+		pendingTTL: pendingTTL,
+		metrics:    metrics,
+		now:        time.Now,
+		size:       orderCacheSize,
+		entries:    make(map[int64]orderCacheEntry),
+	}
+}
+
+// get returns cached order with given id if it is cached and not
+// expired.
+func (c *orderCache) get(id int64) (Order, bool) {
+	if c == nil {
+		return Order{}, false
+	}
+
+	c.mtx.Lock()
+	e, ok := c.entries[id]
+	if ok && !e.expires.IsZero() && !c.now().Before(e.expires) {
+		delete(c.entries, id)
+		ok = false
+	}
+	c.mtx.Unlock()
+
+	if !ok {
+		c.metrics.Add("order_cache_misses_total", nil, 1)
+		return Order{}, false
+	}
+
+	c.metrics.Add("order_cache_hits_total", nil, 1)
+	return e.order, true
+}
+
+// put caches the order, evicting the earliest cached orders if the
+// cache is full. Pending orders are not cached if pending TTL is not
+// positive.
+func (c *orderCache) put(order Order) {
+	if c == nil {
+		return
+	}
+
+	e := orderCacheEntry{order: order}
+	if order.Status != OrderFinished && order.Status != OrderCanceled {
+		if c.pendingTTL <= 0 {
+			return
+		}
+		e.expires = c.now().Add(c.pendingTTL)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.seq++
+	e.seq = c.seq
+	c.entries[order.ID] = e
+	c.queue = append(c.queue, orderCacheItem{id: order.ID, seq: e.seq})
+
+	for len(c.entries) > c.size {
+		item := c.queue[0]
+		c.queue = c.queue[1:]
+		if e, ok := c.entries[item.id]; ok && e.seq == item.seq {
+			delete(c.entries, item.id)
+		}
+	}
+
+	// Queue is compacted once replaced and invalidated orders
+	// outnumber cached ones, so it doesn't grow unbounded.
+	if len(c.queue) > 2*len(c.entries) {
+		queue := make([]orderCacheItem, 0, len(c.entries))
+		for _, item := range c.queue {
+			if e, ok := c.entries[item.id]; ok && e.seq == item.seq {
+				queue = append(queue, item)
+			}
+		}
+		c.queue = queue
+	}
+}
+
+// invalidate removes order with given id from the cache.
+func (c *orderCache) invalidate(id int64) {
+	if c == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	delete(c.entries, id)
+	c.mtx.Unlock()
+}
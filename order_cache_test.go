@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClient_Order_cache(t *testing.T) {
+	orderJSON := func(status string) string {
+		return `{ "data": { "order": { "id": 1, "status": "` + status +
+			`" } } }`
+	}
+
+	t.Run("when order is finished", func(t *testing.T) {
+		backend := &countingCore{
+			mockCore: mockCore{respJSON: orderJSON(OrderFinished)},
+		}
+		metrics := &recordingMetrics{}
+		client := &Client{
+			core:   backend,
+			orders: newOrderCache(0, metrics),
+		}
+
+		for i := 0; i < 3; i++ {
+			order, err := client.Order(1)
+			if err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+			if order.Status != OrderFinished {
+				t.Fatalf("want finished order but got `%s`", order.Status)
+			}
+		}
+
+		if backend.calls != 1 {
+			t.Errorf("want 1 request but got %v", backend.calls)
+		}
+		if n := metrics.count("order_cache_hits_total"); n != 2 {
+			t.Errorf("want 2 cache hits but got %v", n)
+		}
+		if n := metrics.count("order_cache_misses_total"); n != 1 {
+			t.Errorf("want 1 cache miss but got %v", n)
+		}
+	})
+	t.Run("when order is pending", func(t *testing.T) {
+		backend := &countingCore{
+			mockCore: mockCore{respJSON: orderJSON(OrderPending)},
+		}
+		now := time.Now()
+		cache := newOrderCache(time.Second, nopMetrics{})
+		cache.now = func() time.Time { return now }
+		client := &Client{core: backend, orders: cache}
+
+		client.Order(1)
+		client.Order(1)
+		if backend.calls != 1 {
+			t.Fatalf("want 1 request but got %v", backend.calls)
+		}
+
+		now = now.Add(time.Second)
+		client.Order(1)
+		if backend.calls != 2 {
+			t.Errorf("want 2 requests after expiration but got %v",
+				backend.calls)
+		}
+	})
+	t.Run("when pending TTL is zero", func(t *testing.T) {
+		backend := &countingCore{
+			mockCore: mockCore{respJSON: orderJSON(OrderPending)},
+		}
+		client := &Client{
+			core:   backend,
+			orders: newOrderCache(0, nopMetrics{}),
+		}
+
+		client.Order(1)
+		client.Order(1)
+		if backend.calls != 2 {
+			t.Errorf("want 2 requests but got %v", backend.calls)
+		}
+	})
+	t.Run("when order is canceled", func(t *testing.T) {
+		backend := &countingCore{
+			mockCore: mockCore{respJSON: orderJSON(OrderPending)},
+		}
+		client := &Client{
+			core:   backend,
+			orders: newOrderCache(time.Hour, nopMetrics{}),
+		}
+
+		client.Order(1)
+		backend.respJSON = `{ "data": { "cancelOrder": { "id": 1,
+			"status": "canceled" } } }`
+		if _, err := client.CancelOrder(1); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		backend.respJSON = orderJSON(OrderPending)
+		order, err := client.Order(1)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if order.Status != OrderCanceled {
+			t.Errorf("want canceled order but got `%s`", order.Status)
+		}
+		if backend.calls != 2 {
+			t.Errorf("want canceled order cached but got %v requests",
+				backend.calls)
+		}
+	})
+	t.Run("when cancel fails", func(t *testing.T) {
+		backend := &countingCore{
+			mockCore: mockCore{respJSON: orderJSON(OrderPending)},
+		}
+		client := &Client{
+			core:   backend,
+			orders: newOrderCache(time.Hour, nopMetrics{}),
+		}
+
+		client.Order(1)
+		backend.respJSON = `{ "errors": [{ "message": "fail" }] }`
+		if _, err := client.CancelOrder(1); err == nil {
+			t.Fatal("want error but got nil")
+		}
+
+		backend.respJSON = orderJSON(OrderCanceled)
+		order, err := client.Order(1)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if order.Status != OrderCanceled {
+			t.Errorf("want canceled order but got `%s`", order.Status)
+		}
+	})
+}
+
+func TestOrderCache_evict(t *testing.T) {
+	cache := newOrderCache(0, nopMetrics{})
+	cache.size = 2
+
+	for id := int64(1); id <= 3; id++ {
+		cache.put(Order{ID: id, Status: OrderFinished})
+	}
+	// Recached order is evicted after the ones cached earlier.
+	cache.put(Order{ID: 2, Status: OrderFinished})
+	cache.put(Order{ID: 4, Status: OrderFinished})
+
+	for id, want := range map[int64]bool{1: false, 2: true, 3: false,
+		4: true} {
+
+		if _, ok := cache.get(id); ok != want {
+			t.Errorf("want order %v cached %v but got %v", id, want, ok)
+		}
+	}
+	if len(cache.queue) > 2*len(cache.entries) {
+		t.Errorf("want queue compacted but got %v items",
+			len(cache.queue))
+	}
+}
+
+// countingCore is mockCore which counts do() calls.
+type countingCore struct {
+	mockCore
+	calls int
+}
+
+func (c *countingCore) do(ctx context.Context, needAuth bool,
+	r request) ([]byte, error) {
+
+	c.calls++
+	return c.mockCore.do(ctx, needAuth, r)
+}
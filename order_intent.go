@@ -0,0 +1,162 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxOrderTagLength is the maximum length of the order tag and
+// idempotency key.
+const maxOrderTagLength = 64
+
+// OrderIntent is the fully built order which is about to be sent to the
+// exchange. It is the value shared by order placement, pre-trade checks
+// and persistence, its JSON encoding is canonical: equal intents are
+// always encoded into the same bytes.
+type OrderIntent struct {
+	// Market is the market of the order in local asset codes.
+	Market string
+
+	// Side is the side of the order.
+	Side OrderSide
+
+	// Amount is the amount of the order.
+	Amount decimal.Decimal
+
+	// Price is the limit price of the order, zero for market orders.
+	Price decimal.Decimal
+
+	// TimeInForce is the time in force of the order, empty means the
+	// exchange default.
+	TimeInForce TimeInForce
+
+	// Tag is the free form label of the order, e.g. the strategy name.
+	Tag string
+
+	// IdempotencyKey identifies the intent, so it is placed at most once
+	// if it is repeated, e.g. replayed from outbox.
+	IdempotencyKey string
+}
+
+// Validate returns error if the intent is malformed.
+func (i OrderIntent) Validate() error {
+	switch {
+	case i.Market == "":
+		return errors.New("market is not specified")
+	case i.Side != OrderAsk && i.Side != OrderBid:
+		return errors.New("unknown order side: " + string(i.Side))
+	case i.Amount.Sign() <= 0:
+		return errors.New("amount should be positive")
+	case i.Price.Sign() < 0:
+		return errors.New("price should not be negative")
+	}
+
+	switch i.TimeInForce {
+	case "", GoodTillCanceled, ImmediateOrCancel, FillOrKill:
+	default:
+		return errors.New("unknown time in force: " + string(i.TimeInForce))
+	}
+
+	if err := validateOrderLabel("tag", i.Tag); err != nil {
+		return err
+	}
+	return validateOrderLabel("idempotency key", i.IdempotencyKey)
+}
+
+// IsMarket returns true if the intent is the market order.
+func (i OrderIntent) IsMarket() bool {
+	return i.Price.Sign() == 0
+}
+
+// orderIntentJSON is the JSON encoding of the order intent. Fields are
+// kept in the fixed order and optional ones are omitted if empty, which
+// makes the encoding canonical.
+type orderIntentJSON struct {
+	Market         string      `json:"market"`
+	Side           OrderSide   `json:"side"`
+	Amount         string      `json:"amount"`
+	Price          string      `json:"price,omitempty"`
+	TimeInForce    TimeInForce `json:"timeInForce,omitempty"`
+	Tag            string      `json:"tag,omitempty"`
+	IdempotencyKey string      `json:"idempotencyKey,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. Decimals are encoded as
+// strings without trailing zeros.
+func (i OrderIntent) MarshalJSON() ([]byte, error) {
+	v := orderIntentJSON{
+		Market:         i.Market,
+		Side:           i.Side,
+		Amount:         i.Amount.String(),
+		TimeInForce:    i.TimeInForce,
+		Tag:            i.Tag,
+		IdempotencyKey: i.IdempotencyKey,
+	}
+	if !i.IsMarket() {
+		v.Price = i.Price.String()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (i *OrderIntent) UnmarshalJSON(b []byte) error {
+	var v orderIntentJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	amount, err := decimal.NewFromString(v.Amount)
+	if err != nil {
+		return errors.New("failed to parse amount: " + err.Error())
+	}
+
+	var price decimal.Decimal
+	if v.Price != "" {
+		if price, err = decimal.NewFromString(v.Price); err != nil {
+			return errors.New("failed to parse price: " + err.Error())
+		}
+	}
+
+	*i = OrderIntent{
+		Market:         v.Market,
+		Side:           v.Side,
+		Amount:         amount,
+		Price:          price,
+		TimeInForce:    v.TimeInForce,
+		Tag:            v.Tag,
+		IdempotencyKey: v.IdempotencyKey,
+	}
+	return nil
+}
+
+// orderVariables converts the intent into the variables of order
+// creation mutation. Time in force is set only if the server supports
+// it.
+func (c *Client) orderVariables(i OrderIntent) createOrderRequestVariables {
+	v := createOrderRequestVariables{
+		Market: c.assets.market(i.Market),
+		Amount: i.Amount,
+		Side:   i.Side,
+	}
+	if c.nativeTimeInForce {
+		v.TimeInForce = i.TimeInForce
+	}
+	return v
+}
+
+// validateOrderLabel returns error if the label is too long or contains
+// non printable characters.
+func validateOrderLabel(name, label string) error {
+	if len(label) > maxOrderTagLength {
+		return errors.New(name + " is too long")
+	}
+	for _, r := range label {
+		if !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return errors.New(name + " contains invalid characters")
+		}
+	}
+	return nil
+}
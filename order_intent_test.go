@@ -0,0 +1,138 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestOrderIntent_Validate(t *testing.T) {
+	valid := OrderIntent{
+		Market:         "BTCETH",
+		Side:           OrderBid,
+		Amount:         dec(1),
+		Price:          dec(0.03),
+		TimeInForce:    ImmediateOrCancel,
+		Tag:            "mm-1",
+		IdempotencyKey: "8f14e45f",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(i *OrderIntent)
+	}{
+		{"no market", func(i *OrderIntent) { i.Market = "" }},
+		{"side", func(i *OrderIntent) { i.Side = "buy" }},
+		{"zero amount", func(i *OrderIntent) { i.Amount = dec(0) }},
+		{"negative price", func(i *OrderIntent) { i.Price = dec(-1) }},
+		{"time in force", func(i *OrderIntent) { i.TimeInForce = "GTD" }},
+		{"long tag", func(i *OrderIntent) {
+			i.Tag = strings.Repeat("a", maxOrderTagLength+1)
+		}},
+		{"key with space", func(i *OrderIntent) {
+			i.IdempotencyKey = "a b"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intent := valid
+			tt.modify(&intent)
+			if err := intent.Validate(); err == nil {
+				t.Error("want error but got nil")
+			}
+		})
+	}
+}
+
+func TestOrderIntent_JSON(t *testing.T) {
+	intent := OrderIntent{
+		Market:         "BTCETH",
+		Side:           OrderAsk,
+		Amount:         decimal.RequireFromString("1.500"),
+		Price:          decimal.RequireFromString("0.0300"),
+		TimeInForce:    FillOrKill,
+		Tag:            "mm-1",
+		IdempotencyKey: "8f14e45f",
+	}
+
+	b, err := json.Marshal(intent)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	const want = `{"market":"BTCETH","side":"ask","amount":"1.5",` +
+		`"price":"0.03","timeInForce":"FOK","tag":"mm-1",` +
+		`"idempotencyKey":"8f14e45f"}`
+	if string(b) != want {
+		t.Errorf("want `%v` but got `%v`", want, string(b))
+	}
+
+	var decoded OrderIntent
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	again, _ := json.Marshal(decoded)
+	if string(again) != want {
+		t.Errorf("want round trip `%v` but got `%v`", want, string(again))
+	}
+	if !decoded.Price.Equal(intent.Price) ||
+		!decoded.Amount.Equal(intent.Amount) {
+		t.Errorf("want decoded `%v` but got `%v`", intent, decoded)
+	}
+
+	market, _ := json.Marshal(OrderIntent{Market: "BTCETH",
+		Side: OrderBid, Amount: dec(1)})
+	const wantMarket = `{"market":"BTCETH","side":"bid","amount":"1"}`
+	if string(market) != wantMarket {
+		t.Errorf("want `%v` but got `%v`", wantMarket, string(market))
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":"x"}`), &decoded); err == nil {
+		t.Error("want error on malformed amount")
+	}
+}
+
+func TestClient_PlaceOrder(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{ "data": { "createMarketOrder": { "id": 1 } } }`,
+	}
+	client := &Client{core: backend}
+
+	intent := OrderIntent{Market: "BTCETH", Side: OrderAsk, Amount: dec(1)}
+	if _, err := client.PlaceOrder(intent); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	wantVariables := createOrderRequestVariables{
+		Market: "BTCETH",
+		Amount: dec(1),
+		Side:   OrderAsk,
+	}
+	if !reflect.DeepEqual(wantVariables, backend.request.Variables) {
+		t.Errorf("want variables `%#v` but got `%#v`",
+			wantVariables, backend.request.Variables)
+	}
+
+	backend.request = request{}
+
+	limit := intent
+	limit.Price = dec(0.03)
+	if _, err := client.PlaceOrder(limit); err == nil {
+		t.Error("want error on limit order")
+	}
+
+	invalid := intent
+	invalid.Amount = dec(0)
+	if _, err := client.PlaceOrder(invalid); err == nil {
+		t.Error("want error on invalid order")
+	}
+
+	if backend.request.Query != "" {
+		t.Error("want rejected orders not sent")
+	}
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// maxTrackedOrders is the number of orders which last observed states
+// are kept to check transitions, the oldest ones are forgotten first.
+const maxTrackedOrders = 10000
+
+// OrderState is the state of the order lifecycle.
+type OrderState string
+
+// Order states. Order is pending until it is partially filled, partial
+// until it is finished or canceled. Finished and canceled states are
+// terminal.
+const (
+	OrderStatePending  OrderState = "pending"
+	OrderStatePartial  OrderState = "partial"
+	OrderStateFinished OrderState = "finished"
+	OrderStateCanceled OrderState = "canceled"
+)
+
+// orderTransitions is the legal transitions of order states, staying in
+// the same state is always legal.
+var orderTransitions = map[OrderState][]OrderState{
+	OrderStatePending: {OrderStatePartial, OrderStateFinished,
+		OrderStateCanceled},
+	OrderStatePartial: {OrderStateFinished, OrderStateCanceled},
+}
+
+// State returns the lifecycle state of the order derived from its
+// status and fills.
+func (o Order) State() OrderState {
+	switch o.Status {
+	case OrderFinished:
+		return OrderStateFinished
+	case OrderCanceled:
+		return OrderStateCanceled
+	}
+
+	if o.DealStock.Sign() > 0 {
+		return OrderStatePartial
+	}
+	return OrderStatePending
+}
+
+// CanTransition returns true if the order may move from the state to
+// the other one.
+func (s OrderState) CanTransition(to OrderState) bool {
+	if s == to {
+		return true
+	}
+	for _, next := range orderTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StateAnomaly is the impossible change of the order observed by the
+// client, e.g. canceled order reported as finished afterwards or fills
+// going backwards. It is caused either by the server or by missed
+// updates and is worth investigating.
+type StateAnomaly struct {
+	// OrderID is the ID of the order.
+	OrderID int64
+
+	// From and To is the states of the previous and the current
+	// observations of the order.
+	From OrderState
+	To   OrderState
+
+	// Previous and Current is the previous and the current
+	// observations of the order.
+	Previous Order
+	Current  Order
+
+	// Reason describes the anomaly.
+	Reason string
+}
+
+func (a StateAnomaly) String() string {
+	return fmt.Sprintf("order %v: %s (%s -> %s)", a.OrderID, a.Reason,
+		a.From, a.To)
+}
+
+// WithStateAnomalyHandler sets the function invoked on every impossible
+// order transition observed by the client in orders returned by the
+// exchange. By default anomalies are logged with the standard logger.
+// Anomalies are reported as order_state_anomalies_total metric either
+// way.
+func WithStateAnomalyHandler(h func(a StateAnomaly)) Option {
+	return func(o *options) {
+		o.stateAnomalyHandler = h
+	}
+}
+
+// logStateAnomaly is the default state anomaly handler.
+func logStateAnomaly(a StateAnomaly) {
+	log.Printf("exchange order state anomaly: %s", a)
+}
+
+// orderStates tracks last observed orders to detect impossible
+// transitions. Nil tracker tracks nothing.
+type orderStates struct {
+	handler func(a StateAnomaly)
+	metrics Metrics
+
+	mtx    sync.Mutex
+	orders map[int64]Order
+
+	// ids is the IDs of tracked orders in the order of appearance.
+	ids []int64
+}
+
+// newOrderStates creates new tracker invoking handler on anomalies, nil
+// handler means logStateAnomaly.
+func newOrderStates(handler func(a StateAnomaly),
+	metrics Metrics) *orderStates {
+
+	if handler == nil {
+		handler = logStateAnomaly
+	}
+	return &orderStates{
+		handler: handler,
+		metrics: metrics,
+		orders:  make(map[int64]Order),
+	}
+}
+
+// observe checks the order against its previous observation and
+// remembers it.
+func (s *orderStates) observe(order Order) {
+	if s == nil || order.ID == 0 {
+		return
+	}
+
+	s.mtx.Lock()
+	prev, ok := s.orders[order.ID]
+	if !ok {
+		s.ids = append(s.ids, order.ID)
+		if len(s.ids) > maxTrackedOrders {
+			delete(s.orders, s.ids[0])
+			s.ids = s.ids[1:]
+		}
+	}
+	s.orders[order.ID] = order
+	s.mtx.Unlock()
+
+	if !ok {
+		return
+	}
+
+	anomaly := StateAnomaly{
+		OrderID:  order.ID,
+		From:     prev.State(),
+		To:       order.State(),
+		Previous: prev,
+		Current:  order,
+	}
+
+	switch {
+	case !anomaly.From.CanTransition(anomaly.To):
+		anomaly.Reason = "illegal transition"
+	case order.DealStock.LessThan(prev.DealStock):
+		anomaly.Reason = "filled amount decreased"
+	case order.Left.GreaterThan(prev.Left):
+		anomaly.Reason = "left amount increased"
+	default:
+		return
+	}
+
+	s.metrics.Add("order_state_anomalies_total", nil, 1)
+	s.handler(anomaly)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestOrder_State(t *testing.T) {
+	tests := []struct {
+		order Order
+		want  OrderState
+	}{
+		{Order{Status: OrderPending}, OrderStatePending},
+		{Order{Status: OrderPending, DealStock: dec(1)}, OrderStatePartial},
+		{Order{Status: OrderFinished, DealStock: dec(1)}, OrderStateFinished},
+		{Order{Status: OrderCanceled}, OrderStateCanceled},
+	}
+
+	for _, test := range tests {
+		if state := test.order.State(); state != test.want {
+			t.Errorf("want state %v but got %v", test.want, state)
+		}
+	}
+}
+
+func TestOrderState_CanTransition(t *testing.T) {
+	tests := []struct {
+		from, to OrderState
+		want     bool
+	}{
+		{OrderStatePending, OrderStatePartial, true},
+		{OrderStatePending, OrderStateCanceled, true},
+		{OrderStatePartial, OrderStatePartial, true},
+		{OrderStatePartial, OrderStateFinished, true},
+		{OrderStatePartial, OrderStatePending, false},
+		{OrderStateFinished, OrderStateCanceled, false},
+		{OrderStateCanceled, OrderStatePending, false},
+	}
+
+	for _, test := range tests {
+		if ok := test.from.CanTransition(test.to); ok != test.want {
+			t.Errorf("want %v -> %v legal %v but got %v", test.from,
+				test.to, test.want, ok)
+		}
+	}
+}
+
+func TestClient_OrderStateAnomaly(t *testing.T) {
+	responses := []string{
+		`{"data": {"order": {"id": 1, "status": "pending",
+			"dealStock": "1", "left": "1"}}}`,
+		`{"data": {"order": {"id": 1, "status": "pending",
+			"dealStock": "2", "left": "0"}}}`,
+		`{"data": {"order": {"id": 1, "status": "pending",
+			"dealStock": "1", "left": "1"}}}`,
+		`{"data": {"cancelOrder": {"id": 1, "status": "canceled",
+			"dealStock": "1", "left": "1"}}}`,
+		`{"data": {"order": {"id": 1, "status": "finished",
+			"dealStock": "1", "left": "0"}}}`,
+	}
+
+	var anomalies []StateAnomaly
+	metrics := &recordingMetrics{}
+	client := &Client{
+		core: CoreFunc(func(string, interface{}) ([]byte, error) {
+			resp := responses[0]
+			responses = responses[1:]
+			return []byte(resp), nil
+		}),
+		orderStates: newOrderStates(func(a StateAnomaly) {
+			anomalies = append(anomalies, a)
+		}, metrics),
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Order(1); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+	}
+	if _, err := client.CancelOrder(1); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if _, err := client.Order(1); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if len(anomalies) != 2 {
+		t.Fatalf("want 2 anomalies but got `%v`", anomalies)
+	}
+	if a := anomalies[0]; a.Reason != "filled amount decreased" ||
+		a.From != OrderStatePartial || a.To != OrderStatePartial {
+		t.Errorf("want fill regression but got `%v`", a)
+	}
+	if a := anomalies[1]; a.Reason != "illegal transition" ||
+		a.From != OrderStateCanceled || a.To != OrderStateFinished {
+		t.Errorf("want canceled -> finished transition but got `%v`", a)
+	}
+	if n := metrics.count("order_state_anomalies_total"); n != 2 {
+		t.Errorf("want 2 anomalies reported but got %v", n)
+	}
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"errors"
+)
+
+// pageMeta is the pagination metadata which the server may return in
+// response extensions alongside lists, supposed to be embedded into
+// specific responses.
+type pageMeta struct {
+	Extensions struct {
+		TotalCount *int64 `json:"totalCount"`
+		PageInfo   *struct {
+			HasNextPage bool `json:"hasNextPage"`
+		} `json:"pageInfo"`
+	} `json:"extensions"`
+}
+
+// page returns the total count and next page presence of the list with
+// n items fetched with given offset and probe limit, which is one more
+// than requested limit. Metadata reported by the server takes
+// precedence, otherwise next page presence is learned from the probe
+// item and the total count is unknown.
+func (m pageMeta) page(offset, limit int64, n int) (int64, bool) {
+	total := int64(-1)
+	hasNext := int64(n) > limit
+
+	if m.Extensions.TotalCount != nil {
+		total = *m.Extensions.TotalCount
+		hasNext = offset+limit < total
+	}
+	if m.Extensions.PageInfo != nil {
+		hasNext = m.Extensions.PageInfo.HasNextPage
+	}
+
+	return total, hasNext
+}
+
+// checkPage returns an error if the offset or the limit of the page is
+// negative.
+func checkPage(offset, limit int64) error {
+	if offset < 0 || limit < 0 {
+		return errors.New("offset and limit should not be negative")
+	}
+	return nil
+}
+
+// DepositsPage is the page of account deposits.
+type DepositsPage struct {
+	// Items is the deposits of the page.
+	Items []Deposit
+
+	// TotalCount is the total number of deposits, -1 if the server
+	// doesn't report it.
+	TotalCount int64
+
+	// HasNext is true if there are deposits after the page.
+	HasNext bool
+}
+
+// DepositsPage returns the page of account deposits in given offset and
+// limit with pagination metadata, so the next page presence is known
+// without extra count queries.
+func (c *Client) DepositsPage(asset string, offset,
+	limit int64) (DepositsPage, error) {
+
+	var page DepositsPage
+
+	if err := checkPage(offset, limit); err != nil {
+		return page, err
+	}
+
+	deposits, meta, err := c.depositRecords(asset, offset, limit+1)
+	if err != nil {
+		return page, err
+	}
+
+	page.TotalCount, page.HasNext = meta.page(offset, limit, len(deposits))
+	if int64(len(deposits)) > limit {
+		deposits = deposits[:limit]
+	}
+	page.Items = deposits
+
+	return page, nil
+}
+
+// WithdrawalsPage is the page of account withdrawals.
+type WithdrawalsPage struct {
+	// Items is the withdrawals of the page.
+	Items []WithdrawalRecord
+
+	// TotalCount is the total number of withdrawals, -1 if the server
+	// doesn't report it.
+	TotalCount int64
+
+	// HasNext is true if there are withdrawals after the page.
+	HasNext bool
+}
+
+// WithdrawalsPage returns the page of account withdrawals in given
+// offset and limit with pagination metadata, see DepositsPage.
+func (c *Client) WithdrawalsPage(asset string, offset,
+	limit int64) (WithdrawalsPage, error) {
+
+	var page WithdrawalsPage
+
+	if err := checkPage(offset, limit); err != nil {
+		return page, err
+	}
+
+	withdrawals, meta, err := c.withdrawalRecords(asset, offset, limit+1)
+	if err != nil {
+		return page, err
+	}
+
+	page.TotalCount, page.HasNext = meta.page(offset, limit,
+		len(withdrawals))
+	if int64(len(withdrawals)) > limit {
+		withdrawals = withdrawals[:limit]
+	}
+	page.Items = withdrawals
+
+	return page, nil
+}
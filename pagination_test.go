@@ -0,0 +1,133 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClient_DepositsPage(t *testing.T) {
+	deposits := `[
+		{ "paymentID": "1", "change": "1", "time": 1 },
+		{ "paymentID": "2", "change": "2", "time": 2 },
+		{ "paymentID": "3", "change": "3", "time": 3 }
+	]`
+
+	t.Run("when core error", func(t *testing.T) {
+		client := &Client{core: &mockCore{error: errors.New("fail")}}
+		if _, err := client.DepositsPage("BTC", 0, 2); err == nil {
+			t.Fatal("want error but got no error")
+		}
+	})
+	t.Run("when server reports no metadata", func(t *testing.T) {
+		backend := &mockCore{respJSON: `{ "data": {
+			"balanceUpdateRecords": ` + deposits + ` } }`}
+		client := &Client{core: backend}
+
+		page, err := client.DepositsPage("BTC", 0, 2)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		vars := backend.request.Variables.(depositRequestVariables)
+		if vars.Limit != 3 {
+			t.Errorf("want probe limit 3 but got %d", vars.Limit)
+		}
+		if len(page.Items) != 2 || page.Items[1].PaymentID != "2" {
+			t.Errorf("want 2 deposits but got `%v`", page.Items)
+		}
+		if !page.HasNext || page.TotalCount != -1 {
+			t.Errorf("want next page and unknown total but got %v and %d",
+				page.HasNext, page.TotalCount)
+		}
+	})
+	t.Run("when last page", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: `{ "data": {
+			"balanceUpdateRecords": ` + deposits + ` } }`}}
+
+		page, err := client.DepositsPage("BTC", 10, 3)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if len(page.Items) != 3 || page.HasNext {
+			t.Errorf("want 3 deposits and no next page but got %d and %v",
+				len(page.Items), page.HasNext)
+		}
+	})
+	t.Run("when server reports total count", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: `{
+			"data": { "balanceUpdateRecords": ` + deposits + ` },
+			"extensions": { "totalCount": 4 } }`}}
+
+		page, err := client.DepositsPage("BTC", 2, 2)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if page.TotalCount != 4 || page.HasNext {
+			t.Errorf("want total 4 and no next page but got %d and %v",
+				page.TotalCount, page.HasNext)
+		}
+	})
+	t.Run("when server reports page info", func(t *testing.T) {
+		client := &Client{core: &mockCore{respJSON: `{
+			"data": { "balanceUpdateRecords": ` + deposits + ` },
+			"extensions": { "totalCount": 10,
+				"pageInfo": { "hasNextPage": false } } }`}}
+
+		page, err := client.DepositsPage("BTC", 0, 2)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if page.TotalCount != 10 || page.HasNext {
+			t.Errorf("want total 10 and no next page but got %d and %v",
+				page.TotalCount, page.HasNext)
+		}
+	})
+}
+
+func TestClient_WithdrawalsPage(t *testing.T) {
+	backend := &mockCore{respJSON: `{
+		"data": { "balanceUpdateRecords": [
+			{ "paymentID": "1", "change": "1", "time": 1 },
+			{ "paymentID": "2", "change": "2", "time": 2 }
+		] },
+		"extensions": { "totalCount": 2 } }`}
+	client := &Client{core: backend}
+
+	page, err := client.WithdrawalsPage("BTC", 0, 1)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	vars := backend.request.Variables.(withdrawalsRequestVariables)
+	if vars.Limit != 2 {
+		t.Errorf("want probe limit 2 but got %d", vars.Limit)
+	}
+	if len(page.Items) != 1 || page.Items[0].PaymentID != "1" {
+		t.Errorf("want first withdrawal but got `%v`", page.Items)
+	}
+	if page.TotalCount != 2 || !page.HasNext {
+		t.Errorf("want total 2 and next page but got %d and %v",
+			page.TotalCount, page.HasNext)
+	}
+}
+
+func TestClient_Page_negative(t *testing.T) {
+	backend := &countingCore{}
+	client := &Client{core: backend}
+
+	for _, bounds := range [][2]int64{{0, -1}, {-1, 1}} {
+		if _, err := client.DepositsPage("BTC", bounds[0],
+			bounds[1]); err == nil {
+
+			t.Errorf("want deposits error of %v but got nil", bounds)
+		}
+		if _, err := client.WithdrawalsPage("BTC", bounds[0],
+			bounds[1]); err == nil {
+
+			t.Errorf("want withdrawals error of %v but got nil", bounds)
+		}
+	}
+	if backend.calls != 0 {
+		t.Errorf("want no requests but got %v", backend.calls)
+	}
+}
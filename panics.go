@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// errorsBuffer is the capacity of the client background errors channel.
+const errorsBuffer = 16
+
+// PanicError is the panic recovered in the client background goroutine,
+// e.g. in subscription or watcher.
+type PanicError struct {
+	// Goroutine is the name of the background subsystem which
+	// panicked, e.g. "tickers".
+	Goroutine string
+
+	// Value is the value the goroutine panicked with.
+	Value interface{}
+
+	// Stack is the stack trace of the panicked goroutine.
+	Stack []byte
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s: %v", e.Goroutine, e.Value)
+}
+
+// errorReporter delivers errors of background goroutines. Nil reporter
+// doesn't recover panics.
+type errorReporter struct {
+	errs    chan error
+	metrics Metrics
+}
+
+// newErrorReporter creates new error reporter.
+func newErrorReporter(metrics Metrics) *errorReporter {
+	return &errorReporter{
+		errs:    make(chan error, errorsBuffer),
+		metrics: metrics,
+	}
+}
+
+// report delivers the error without blocking, it is dropped if nobody
+// reads errors and the buffer is full.
+func (r *errorReporter) report(err error) {
+	if r == nil {
+		return
+	}
+
+	r.metrics.Add("background_errors_total", nil, 1)
+	select {
+	case r.errs <- err:
+	default:
+		r.metrics.Add("background_errors_dropped_total", nil, 1)
+	}
+}
+
+// recover recovers the panic of the goroutine named name and reports it
+// as PanicError. It should be deferred at the start of the goroutine.
+func (r *errorReporter) recover(name string) {
+	if r == nil {
+		return
+	}
+
+	if v := recover(); v != nil {
+		r.report(&PanicError{
+			Goroutine: name,
+			Value:     v,
+			Stack:     debug.Stack(),
+		})
+	}
+}
+
+// Errors returns the channel of errors of the client background
+// goroutines: subscriptions, watchers and caches. Panics of them are
+// recovered and delivered as *PanicError, the failed subsystem stops
+// and closes its channels instead of crashing the process. Errors are
+// dropped if the channel isn't read and its buffer is full.
+func (c *Client) Errors() <-chan error {
+	if c.errs == nil {
+		return nil
+	}
+	return c.errs.errs
+}
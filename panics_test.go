@@ -0,0 +1,56 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Errors(t *testing.T) {
+	client := &Client{
+		core: CoreFunc(func(string, interface{}) ([]byte, error) {
+			panic("broken core")
+		}),
+		subs: newSubscriptions(SubscriptionConfig{
+			PollInterval: time.Millisecond,
+		}, nil, nopMetrics{}),
+		errs: newErrorReporter(nopMetrics{}),
+	}
+
+	deals, err := client.SubscribeDeals([]string{"BTCETH"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	select {
+	case err := <-client.Errors():
+		p, ok := err.(*PanicError)
+		if !ok {
+			t.Fatalf("want panic error but got `%v`", err)
+		}
+		if p.Goroutine != "deals" || p.Value != "broken core" {
+			t.Errorf("want deals panic but got `%v`", p)
+		}
+		if !strings.Contains(string(p.Stack), "TestClient_Errors") {
+			t.Errorf("want stack trace of the panic but got `%s`", p.Stack)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want panic error but got timeout")
+	}
+
+	if _, ok := <-deals; ok {
+		t.Error("want deals channel closed after panic")
+	}
+}
+
+func TestErrorReporter_report(t *testing.T) {
+	metrics := &recordingMetrics{}
+	r := newErrorReporter(metrics)
+
+	for i := 0; i < errorsBuffer+1; i++ {
+		r.report(&PanicError{})
+	}
+	if metrics.count("background_errors_dropped_total") != 1 {
+		t.Error("want error dropped once buffer is full")
+	}
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Capabilities is the set of operations permitted to the client
+// credentials.
+type Capabilities struct {
+	// MarketData is true if public market data may be requested.
+	MarketData bool
+
+	// Account is true if account data, e.g. balances and orders, may
+	// be requested.
+	Account bool
+
+	// Trading is true if orders may be created and canceled, it is
+	// meaningful only if MutationsProbed is true.
+	Trading bool
+
+	// Withdraw is true if funds may be withdrawn, it is meaningful only
+	// if MutationsProbed is true.
+	Withdraw bool
+
+	// MutationsProbed is true if trading and withdraw permissions were
+	// probed, see WithMutationProbes.
+	MutationsProbed bool
+
+	// ProbedAt is the time the capabilities were probed.
+	ProbedAt time.Time
+}
+
+// permissions caches probed capabilities of the client credentials.
+type permissions struct {
+	mtx  sync.RWMutex
+	caps *Capabilities
+}
+
+// Exchange codes of the market and asset probe requests are made with.
+const (
+	probeMarket = "BTCETH"
+	probeAsset  = "BTC"
+)
+
+// probeAmount is the amount of probe mutations, which is invalid, so
+// the exchange never executes them but still checks permissions first.
+var probeAmount = decimal.New(-1, 0)
+
+// probeOrderQuery is the order mutation used to probe trading
+// permission.
+const probeOrderQuery = `
+	mutation ProbeCreateMarketOrder($market: Market!, $amount: String!,
+$side: MarketSide!) {
+		createMarketOrder(amount: $amount, market: $market, side: $side) {
+			id
+		}
+	}
+`
+
+// probeWithdrawQuery is the withdrawal mutation used to probe withdraw
+// permission.
+const probeWithdrawQuery = `
+	mutation ProbeWithdraw($asset: Asset!, $amount: String!,
+$address: String!) {
+		withdrawWithBlockchain(asset: $asset, amount: $amount,
+			address: $address) {
+			paymentID
+		}
+	}
+`
+
+// WithMutationProbes makes ProbePermissions probe trading and withdraw
+// permissions too. The exchange provides no way to query permissions,
+// so they are probed with real order and withdrawal mutations of
+// negative amount, which the exchange is expected to reject either as
+// unauthorized or as invalid without executing them. Probe mutations
+// bypass the journal and maintenance windows, and are never sent in dry
+// run mode.
+func WithMutationProbes() Option {
+	return func(o *options) {
+		o.mutationProbes = true
+	}
+}
+
+// ProbePermissions finds out operations permitted to the client
+// credentials and caches them, see Capabilities. Read permissions are
+// probed with queries. Trading and withdraw permissions are probed only
+// if the client is created WithMutationProbes and not in dry run mode,
+// see Capabilities.MutationsProbed. Errors other than rejected
+// credentials are returned.
+func (c *Client) ProbePermissions(ctx context.Context) (Capabilities, error) {
+	client := c.WithContext(ctx)
+	caps := Capabilities{
+		ProbedAt:        time.Now(),
+		MutationsProbed: c.mutationProbes && c.dryRun == nil,
+	}
+
+	probes := []struct {
+		permitted *bool
+		needAuth  bool
+		mutation  bool
+		req       request
+	}{{
+		permitted: &caps.MarketData,
+		req: request{
+			Query: topOfBookQuery,
+			Variables: depthRequestVariables{
+				Market: probeMarket,
+				Limit:  1,
+			},
+		},
+	}, {
+		permitted: &caps.Account,
+		needAuth:  true,
+		req:       request{Query: `query ProbeAccount { me { id } }`},
+	}, {
+		permitted: &caps.Trading,
+		needAuth:  true,
+		mutation:  true,
+		req: request{
+			Query: probeOrderQuery,
+			Variables: createOrderRequestVariables{
+				Market: probeMarket,
+				Amount: probeAmount,
+				Side:   OrderBid,
+			},
+		},
+	}, {
+		permitted: &caps.Withdraw,
+		needAuth:  true,
+		mutation:  true,
+		req: request{
+			Query: probeWithdrawQuery,
+			Variables: withdrawRequestVariables{
+				Asset:  probeAsset,
+				Amount: probeAmount,
+			},
+		},
+	}}
+
+	for _, p := range probes {
+		if p.mutation && !caps.MutationsProbed {
+			continue
+		}
+		permitted, err := client.probe(p.needAuth, p.req)
+		if err != nil {
+			return Capabilities{}, err
+		}
+		*p.permitted = permitted
+	}
+
+	if c.permissions != nil {
+		c.permissions.mtx.Lock()
+		c.permissions.caps = &caps
+		c.permissions.mtx.Unlock()
+	}
+
+	return caps, nil
+}
+
+// Capabilities returns capabilities found by the last ProbePermissions
+// and whether they were probed.
+func (c *Client) Capabilities() (Capabilities, bool) {
+	if c.permissions == nil {
+		return Capabilities{}, false
+	}
+
+	c.permissions.mtx.RLock()
+	defer c.permissions.mtx.RUnlock()
+
+	if c.permissions.caps == nil {
+		return Capabilities{}, false
+	}
+	return *c.permissions.caps, true
+}
+
+// probe sends the request and returns whether the exchange permitted
+// it. Any error other than rejected credentials means the operation is
+// permitted, as the exchange checks permissions first.
+func (c *Client) probe(needAuth bool, r request) (bool, error) {
+	respJSON, err := c.send(needAuth, r)
+	if err != nil {
+		err = transportError(err)
+		if errors.Is(err, ErrUnauthorized) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var resp responseBase
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return false, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return !errors.Is(exchangeError(err), ErrUnauthorized), nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_ProbePermissions(t *testing.T) {
+	// Server of market data only credentials.
+	var mutations int
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+
+			if strings.Contains(string(body), "mutation") {
+				mutations++
+			}
+
+			switch {
+			case strings.Contains(string(body), "ProbeAccount"):
+				w.Write([]byte(`{ "data": { "me": { "id": "1" } } }`))
+			case strings.Contains(string(body), "ProbeWithdraw"):
+				w.WriteHeader(http.StatusForbidden)
+			case strings.Contains(string(body), "ProbeCreateMarketOrder"):
+				w.Write([]byte(`{ "errors": [{ "message": "forbidden",
+					"extensions": { "code": "FORBIDDEN" } }] }`))
+			default:
+				w.Write([]byte(`{ "data": { "depth": {} } }`))
+			}
+		}))
+	defer server.Close()
+
+	// Mutations are not probed by default.
+	client, err := NewClient(server.URL, "", "token")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	caps, err := client.ProbePermissions(context.Background())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !caps.MarketData || !caps.Account || caps.MutationsProbed ||
+		mutations != 0 {
+
+		t.Errorf("want queries probed only but got `%+v` and %v "+
+			"mutations", caps, mutations)
+	}
+
+	// Dry run never sends probe mutations.
+	client, err = NewClient(server.URL, "", "token", WithMutationProbes(),
+		WithDryRun())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	caps, err = client.ProbePermissions(context.Background())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if caps.MutationsProbed || mutations != 0 {
+		t.Errorf("want no mutations in dry run but got `%+v` and %v "+
+			"mutations", caps, mutations)
+	}
+
+	client, err = NewClient(server.URL, "", "token", WithMutationProbes())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if _, ok := client.Capabilities(); ok {
+		t.Error("want no capabilities before probing")
+	}
+
+	caps, err = client.ProbePermissions(context.Background())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !caps.MarketData || !caps.Account || caps.Trading || caps.Withdraw ||
+		!caps.MutationsProbed {
+
+		t.Errorf("want market data and account only but got `%+v`", caps)
+	}
+
+	cached, ok := client.Capabilities()
+	if !ok || cached != caps {
+		t.Errorf("want probed capabilities cached but got `%+v`", cached)
+	}
+
+	t.Run("invalid probe is permitted", func(t *testing.T) {
+		client := &Client{core: &mockCore{
+			respJSON: `{ "errors": [{ "message": "amount too small" }] }`,
+		}}
+
+		permitted, err := client.probe(true, request{
+			Query: probeOrderQuery,
+		})
+		if err != nil || !permitted {
+			t.Errorf("want permitted but got %v, `%v`", permitted, err)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+// Package positions maintains signed positions and average entry prices
+// per market from the exchange fills, so strategies share one audited
+// position source. Positions are measured in stock (right asset of the
+// market), prices and PnL in money (left asset).
+package positions
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/bitlum/exchange-graphql-client"
+	"github.com/shopspring/decimal"
+)
+
+// Order sides.
+const (
+	// Bid buys stock for money and increases position.
+	Bid = "bid"
+
+	// Ask sells stock for money and decreases position.
+	Ask = "ask"
+)
+
+// Fill is an execution of the order.
+type Fill struct {
+	// Market is the market of the fill.
+	Market string
+
+	// Side is the side of the order, either bid or ask.
+	Side string
+
+	// Stock is the amount of stock which has been dealt.
+	Stock decimal.Decimal
+
+	// Money is the amount of money which has been dealt.
+	Money decimal.Decimal
+}
+
+// OrderFill returns fill between two polled states of the market order
+// with given side, prev is zero Order for the first observed state. As
+// order deal amounts are cumulative, the fill is their difference.
+func OrderFill(market, side string, prev, cur client.Order) Fill {
+	return Fill{
+		Market: market,
+		Side:   side,
+		Stock:  cur.DealStock.Abs().Sub(prev.DealStock.Abs()),
+		Money:  cur.DealMoney.Abs().Sub(prev.DealMoney.Abs()),
+	}
+}
+
+// Position is the position in the market.
+type Position struct {
+	// Market is the market of the position.
+	Market string `json:"market"`
+
+	// Size is the signed amount of stock, positive if long and
+	// negative if short.
+	Size decimal.Decimal `json:"size"`
+
+	// AvgEntryPrice is the average price of the open position, zero
+	// if the position is flat.
+	AvgEntryPrice decimal.Decimal `json:"avgEntryPrice"`
+
+	// RealizedPnL is the money realized by reducing the position.
+	RealizedPnL decimal.Decimal `json:"realizedPnL"`
+}
+
+// Tracker maintains positions per market. It is safe for concurrent use.
+type Tracker struct {
+	mtx       sync.Mutex
+	positions map[string]Position
+}
+
+// NewTracker creates new tracker with flat positions.
+func NewTracker() *Tracker {
+	return &Tracker{
+		positions: make(map[string]Position),
+	}
+}
+
+// Apply updates the market position with the fill.
+func (t *Tracker) Apply(f Fill) error {
+	if f.Side != Bid && f.Side != Ask {
+		return errors.New("unknown side: " + f.Side)
+	}
+	if f.Stock.Sign() < 0 || f.Money.Sign() < 0 {
+		return errors.New("negative fill amount")
+	}
+	if f.Stock.Sign() == 0 {
+		return nil
+	}
+
+	price := f.Money.Div(f.Stock)
+	qty := f.Stock
+	if f.Side == Ask {
+		qty = qty.Neg()
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	p := t.positions[f.Market]
+	p.Market = f.Market
+
+	if p.Size.Sign() == 0 || p.Size.Sign() == qty.Sign() {
+		// Increasing position, entry price is averaged.
+		size := p.Size.Add(qty)
+		p.AvgEntryPrice = p.Size.Abs().Mul(p.AvgEntryPrice).
+			Add(qty.Abs().Mul(price)).Div(size.Abs())
+		p.Size = size
+	} else {
+		// Reducing position, possibly flipping it.
+		closed := decimal.Min(p.Size.Abs(), qty.Abs())
+		pnl := price.Sub(p.AvgEntryPrice).Mul(closed)
+		if p.Size.Sign() < 0 {
+			pnl = pnl.Neg()
+		}
+		p.RealizedPnL = p.RealizedPnL.Add(pnl)
+
+		size := p.Size.Add(qty)
+		switch {
+		case size.Sign() == 0:
+			p.AvgEntryPrice = decimal.Zero
+		case size.Sign() != p.Size.Sign():
+			p.AvgEntryPrice = price
+		}
+		p.Size = size
+	}
+
+	t.positions[f.Market] = p
+	return nil
+}
+
+// Position returns position in the market.
+func (t *Tracker) Position(market string) Position {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	p := t.positions[market]
+	p.Market = market
+	return p
+}
+
+// Snapshot returns all positions sorted by market, it may be persisted
+// and restored later with Restore.
+func (t *Tracker) Snapshot() []Position {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	res := make([]Position, 0, len(t.positions))
+	for _, p := range t.positions {
+		res = append(res, p)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Market < res[j].Market
+	})
+	return res
+}
+
+// Restore replaces all positions with the snapshot.
+func (t *Tracker) Restore(snapshot []Position) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.positions = make(map[string]Position, len(snapshot))
+	for _, p := range snapshot {
+		t.positions[p.Market] = p
+	}
+}
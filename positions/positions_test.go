@@ -0,0 +1,101 @@
+package positions
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bitlum/exchange-graphql-client"
+	"github.com/shopspring/decimal"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestTracker_Apply(t *testing.T) {
+	tracker := NewTracker()
+
+	steps := []struct {
+		fill     Fill
+		size     string
+		avgPrice string
+		pnl      string
+	}{
+		// Open long 2 at 10, add 2 at 20.
+		{Fill{"BTCETH", Bid, d("2"), d("20")}, "2", "10", "0"},
+		{Fill{"BTCETH", Bid, d("2"), d("40")}, "4", "15", "0"},
+		// Reduce 1 at 25: +10 realized.
+		{Fill{"BTCETH", Ask, d("1"), d("25")}, "3", "15", "10"},
+		// Flip to short 1 at 5: -30 realized on closing 3.
+		{Fill{"BTCETH", Ask, d("4"), d("20")}, "-1", "5", "-20"},
+		// Close short at 4: +1 realized.
+		{Fill{"BTCETH", Bid, d("1"), d("4")}, "0", "0", "-19"},
+	}
+
+	for i, step := range steps {
+		if err := tracker.Apply(step.fill); err != nil {
+			t.Fatalf("step %v: want no error but got `%v`", i, err)
+		}
+		p := tracker.Position("BTCETH")
+		if !p.Size.Equal(d(step.size)) {
+			t.Errorf("step %v: want size %v but got %v", i, step.size,
+				p.Size)
+		}
+		if !p.AvgEntryPrice.Equal(d(step.avgPrice)) {
+			t.Errorf("step %v: want avg price %v but got %v", i,
+				step.avgPrice, p.AvgEntryPrice)
+		}
+		if !p.RealizedPnL.Equal(d(step.pnl)) {
+			t.Errorf("step %v: want PnL %v but got %v", i, step.pnl,
+				p.RealizedPnL)
+		}
+	}
+
+	if err := tracker.Apply(Fill{Market: "BTCETH", Side: "buy"}); err == nil {
+		t.Error("want unknown side error but got no error")
+	}
+}
+
+func TestOrderFill(t *testing.T) {
+	prev := client.Order{DealStock: d("1"), DealMoney: d("10")}
+	cur := client.Order{DealStock: d("3"), DealMoney: d("40")}
+
+	f := OrderFill("BTCETH", Bid, prev, cur)
+	if !f.Stock.Equal(d("2")) || !f.Money.Equal(d("30")) {
+		t.Errorf("want fill of 2 stock for 30 money but got `%#v`", f)
+	}
+}
+
+func TestTracker_SnapshotRestore(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Apply(Fill{"BTCETH", Bid, d("2"), d("20")})
+	tracker.Apply(Fill{"BTCLTC", Ask, d("1"), d("5")})
+
+	data, err := json.Marshal(tracker.Snapshot())
+	if err != nil {
+		t.Fatalf("failed to marshal snapshot: %v", err)
+	}
+
+	var snapshot []Position
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("failed to unmarshal snapshot: %v", err)
+	}
+
+	restored := NewTracker()
+	restored.Restore(snapshot)
+
+	want, got := tracker.Snapshot(), restored.Snapshot()
+	if len(got) != 2 || !reflect.DeepEqual(marketsOf(want),
+		marketsOf(got)) || !got[1].Size.Equal(d("-1")) {
+		t.Errorf("want restored snapshot `%v` but got `%v`", want, got)
+	}
+}
+
+func marketsOf(positions []Position) []string {
+	var markets []string
+	for _, p := range positions {
+		markets = append(markets, p.Market)
+	}
+	return markets
+}
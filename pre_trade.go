@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"errors"
+)
+
+// PreTradeCheck is a risk check invoked with every order intent before
+// it is sent. Returning an error aborts the order placement, which lets
+// risk teams plug compliance checks (restricted markets, position
+// limits) into every order placement path centrally.
+type PreTradeCheck interface {
+	CheckOrder(ctx context.Context, intent OrderIntent) error
+}
+
+// PreTradeCheckFunc is a function which implements PreTradeCheck.
+type PreTradeCheckFunc func(ctx context.Context, intent OrderIntent) error
+
+// CheckOrder implements PreTradeCheck.
+func (f PreTradeCheckFunc) CheckOrder(ctx context.Context,
+	intent OrderIntent) error {
+
+	return f(ctx, intent)
+}
+
+// WithPreTradeCheck adds the checks which are invoked in order before
+// every order placement.
+func WithPreTradeCheck(checks ...PreTradeCheck) Option {
+	return func(o *options) {
+		o.preTradeChecks = append(o.preTradeChecks, checks...)
+	}
+}
+
+// checkOrder runs pre-trade checks of the order intent.
+func (c *Client) checkOrder(intent OrderIntent) error {
+	for _, check := range c.preTradeChecks {
+		if err := check.CheckOrder(c.context(), intent); err != nil {
+			return errors.New("pre-trade check failed: " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClient_createOrder_preTradeCheck(t *testing.T) {
+	restricted := PreTradeCheckFunc(func(ctx context.Context,
+		intent OrderIntent) error {
+
+		if intent.Market == "BTCXMR" {
+			return errors.New("restricted market")
+		}
+		return nil
+	})
+
+	var got OrderIntent
+	recorder := PreTradeCheckFunc(func(ctx context.Context,
+		intent OrderIntent) error {
+
+		got = intent
+		return nil
+	})
+
+	t.Run("when check fails", func(t *testing.T) {
+		backend := &countingCore{}
+		client := &Client{
+			core:           backend,
+			preTradeChecks: []PreTradeCheck{restricted, recorder},
+		}
+
+		_, err := client.CreateOrderAsk("BTCXMR", dec(1))
+		if err == nil || !strings.Contains(err.Error(),
+			"restricted market") {
+			t.Fatalf("want restricted market error but got `%v`", err)
+		}
+		if backend.calls != 0 {
+			t.Errorf("want no requests but got %v", backend.calls)
+		}
+	})
+	t.Run("when checks pass", func(t *testing.T) {
+		backend := &countingCore{mockCore: mockCore{
+			respJSON: `{ "data": { "createMarketOrder": { "id": 1 } } }`,
+		}}
+		client := &Client{
+			core:           backend,
+			preTradeChecks: []PreTradeCheck{restricted, recorder},
+		}
+
+		if _, err := client.CreateOrderBid("BTCETH", dec(1)); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if got.Market != "BTCETH" || got.Side != "bid" ||
+			!got.Amount.Equal(dec(1)) {
+			t.Errorf("want BTCETH bid intent but got `%#v`", got)
+		}
+		if backend.calls != 1 {
+			t.Errorf("want 1 request but got %v", backend.calls)
+		}
+	})
+}
@@ -0,0 +1,396 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// defaultListSize is the assumed number of items of list fields which
+// size is not known from arguments, see ComplexityConfig.
+const defaultListSize = 10
+
+// listFields is the fields of the exchange schema which are lists.
+var listFields = map[string]struct{}{
+	"accounts":             {},
+	"transactions":         {},
+	"balanceUpdateRecords": {},
+	"deals":                {},
+	"asks":                 {},
+	"bids":                 {},
+	"markets":              {},
+	"candles":              {},
+	"maintenanceWindows":   {},
+}
+
+// ComplexityConfig is the query complexity budget of the client.
+type ComplexityConfig struct {
+	// Budget is the maximum estimated complexity of a query the server
+	// accepts.
+	Budget int
+
+	// ListSize is the assumed number of items of list fields which size
+	// is not known from limit or list arguments, zero means 10.
+	ListSize int
+}
+
+// WithComplexityBudget makes the client estimate complexity of queries
+// and split the ones exceeding the budget, as the server rejects too
+// complex queries. Every field costs one and list fields multiply the
+// cost of their selection by the number of items, which is the limit
+// argument, the length of a list argument, e.g. of assets, or
+// ListSize. For now Accounts is split, pending transactions are fetched
+// separately per asset.
+func WithComplexityBudget(cfg ComplexityConfig) Option {
+	return func(o *options) {
+		if cfg.ListSize <= 0 {
+			cfg.ListSize = defaultListSize
+		}
+		o.complexity = &cfg
+	}
+}
+
+// exceeds returns true if the request estimate exceeds the budget. Nil
+// config has no budget.
+func (cfg *ComplexityConfig) exceeds(r request) bool {
+	if cfg == nil {
+		return false
+	}
+	cost, err := queryComplexity(r, cfg.ListSize)
+	return err == nil && cost > cfg.Budget
+}
+
+// accountsBaseQuery is the query of accounts without their pending
+// transactions.
+const accountsBaseQuery = `
+		query Accounts($assets: [Asset!]!) {
+			accounts(assets: $assets) {
+				asset
+				address
+				available
+				estimation
+				freezed
+				pending {
+					amount
+				}
+			}
+		}
+	`
+
+// pendingTransactionsQuery is the query of pending transactions of
+// accounts.
+const pendingTransactionsQuery = `
+		query PendingTransactions($assets: [Asset!]!) {
+			accounts(assets: $assets) {
+				asset
+				pending {
+					transactions {
+						confirmationsLeft
+						confirmations
+						address
+						amount
+						txid
+					}
+				}
+			}
+		}
+	`
+
+// splitAccounts returns accounts like Accounts, but pending
+// transactions are fetched separately per asset.
+func (c *Client) splitAccounts(assets []string) ([]Account, error) {
+	accounts, err := c.accounts(request{
+		Query:     accountsBaseQuery,
+		Variables: accountsRequest{Assets: c.assets.assets(assets)},
+	})
+	if err != nil {
+		return accounts, err
+	}
+
+	for i := range accounts {
+		pending, err := c.accounts(request{
+			Query: pendingTransactionsQuery,
+			Variables: accountsRequest{
+				Assets: []string{c.assets.asset(accounts[i].Asset)},
+			},
+		})
+		if err != nil {
+			return accounts, err
+		}
+		for _, p := range pending {
+			if p.Asset == accounts[i].Asset {
+				accounts[i].Pending.Transactions = p.Pending.Transactions
+			}
+		}
+	}
+
+	return accounts, nil
+}
+
+// queryComplexity returns the estimated complexity of the request query.
+func queryComplexity(r request, listSize int) (int, error) {
+	vars := make(map[string]json.RawMessage)
+	if r.Variables != nil {
+		data, err := json.Marshal(r.Variables)
+		if err != nil {
+			return 0, err
+		}
+		if err := json.Unmarshal(data, &vars); err != nil {
+			return 0, err
+		}
+	}
+
+	p := &complexityParser{
+		tokens:   tokenizeQuery(r.Query),
+		vars:     vars,
+		listSize: listSize,
+	}
+
+	// Skip the operation header up to its selection set.
+	for p.pos < len(p.tokens) && p.tokens[p.pos] != "{" {
+		if p.tokens[p.pos] == "(" {
+			p.skipGroup("(", ")")
+			continue
+		}
+		p.pos++
+	}
+	if p.pos == len(p.tokens) {
+		return 0, errors.New("query has no selection set")
+	}
+	return p.selection()
+}
+
+// complexityParser estimates complexity of the tokenized query.
+type complexityParser struct {
+	tokens   []string
+	pos      int
+	vars     map[string]json.RawMessage
+	listSize int
+}
+
+// selection returns the cost of the selection set starting at the
+// current token, which is "{".
+func (p *complexityParser) selection() (int, error) {
+	p.pos++
+
+	var cost int
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		switch tok {
+		case "}":
+			p.pos++
+			return cost, nil
+
+		case "...":
+			// Inline fragment costs as much as its selection.
+			p.pos++
+			for p.pos < len(p.tokens) && p.tokens[p.pos] != "{" &&
+				p.tokens[p.pos] != "}" {
+				p.pos++
+			}
+			if p.pos < len(p.tokens) && p.tokens[p.pos] == "{" {
+				c, err := p.selection()
+				if err != nil {
+					return 0, err
+				}
+				cost += c
+			}
+			continue
+		}
+
+		c, err := p.field()
+		if err != nil {
+			return 0, err
+		}
+		cost += c
+	}
+	return 0, errors.New("unterminated selection set")
+}
+
+// field returns the cost of the field starting at the current token.
+func (p *complexityParser) field() (int, error) {
+	name := p.tokens[p.pos]
+	p.pos++
+
+	// Alias is followed by the field name.
+	if p.peek() == ":" {
+		p.pos++
+		name = p.tokens[p.pos]
+		p.pos++
+	}
+
+	items := -1
+	if p.peek() == "(" {
+		items = p.arguments()
+	}
+
+	if p.peek() != "{" {
+		return 1, nil
+	}
+
+	children, err := p.selection()
+	if err != nil {
+		return 0, err
+	}
+
+	if items < 0 {
+		items = 1
+		if _, ok := listFields[name]; ok {
+			items = p.listSize
+		}
+	}
+	return 1 + items*children, nil
+}
+
+// arguments skips the arguments of the field and returns the number of
+// items it requests, which is the limit argument or the length of a
+// list argument, -1 if it is not known.
+func (p *complexityParser) arguments() int {
+	p.pos++
+
+	limit, list := -1, -1
+	for p.pos < len(p.tokens) && p.tokens[p.pos] != ")" {
+		name := p.tokens[p.pos]
+		p.pos++
+		if p.peek() != ":" {
+			continue
+		}
+		p.pos++
+
+		switch value := p.peek(); {
+		case value == "[":
+			if n := p.skipGroup("[", "]"); n > list {
+				list = n
+			}
+			continue
+
+		case value == "{":
+			p.skipGroup("{", "}")
+			continue
+
+		case name == "limit":
+			if n, ok := p.intValue(value); ok {
+				limit = n
+			}
+
+		case len(value) > 1 && value[0] == '$':
+			var items []json.RawMessage
+			if err := json.Unmarshal(p.vars[value[1:]], &items); err == nil &&
+				len(items) > list {
+				list = len(items)
+			}
+		}
+		p.pos++
+	}
+	p.pos++
+
+	if limit >= 0 {
+		return limit
+	}
+	return list
+}
+
+// intValue returns the integer value of the literal or variable.
+func (p *complexityParser) intValue(value string) (int, bool) {
+	if len(value) > 1 && value[0] == '$' {
+		var n int
+		if err := json.Unmarshal(p.vars[value[1:]], &n); err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	n, err := strconv.Atoi(value)
+	return n, err == nil
+}
+
+// skipGroup skips tokens up to the closing token of the group starting
+// at the current token and returns the number of top level items.
+func (p *complexityParser) skipGroup(open, close string) int {
+	p.pos++
+
+	depth, items := 0, 0
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		p.pos++
+		switch {
+		case tok == close && depth == 0:
+			return items
+		case tok == open:
+			if depth == 0 {
+				items++
+			}
+			depth++
+		case tok == close:
+			depth--
+		case depth == 0:
+			items++
+		}
+	}
+	return items
+}
+
+// peek returns the current token, empty at the end of the query.
+func (p *complexityParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// tokenizeQuery splits the GraphQL query into names, values and
+// punctuators, ignoring commas and comments. Strings are kept with
+// their quotes.
+func tokenizeQuery(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' ||
+			ch == ',':
+			i++
+
+		case ch == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+
+		case ch == '"':
+			j := i + 1
+			for j < len(query) && query[j] != '"' {
+				if query[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(query) {
+				j++
+			}
+			tokens = append(tokens, query[i:j])
+			i = j
+
+		case ch == '.' && i+2 < len(query) && query[i:i+3] == "...":
+			tokens = append(tokens, "...")
+			i += 3
+
+		case isNameChar(ch) || ch == '$' || ch == '-':
+			j := i + 1
+			for j < len(query) && (isNameChar(query[j]) || query[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, query[i:j])
+			i = j
+
+		default:
+			tokens = append(tokens, query[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+// isNameChar returns true if the character may be a part of a name or a
+// number.
+func isNameChar(ch byte) bool {
+	return ch == '_' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' ||
+		ch >= 'A' && ch <= 'Z'
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryComplexity(t *testing.T) {
+	tests := []struct {
+		name string
+		req  request
+		want int
+	}{{
+		name: "accounts of assets",
+		req: request{
+			Query:     accountsQuery,
+			Variables: accountsRequest{Assets: []string{"BTC", "ETH"}},
+		},
+		// accounts: 1 + 2 * (5 scalars + pending: 1 + (amount +
+		// transactions: 1 + 10 * 5)).
+		want: 117,
+	}, {
+		name: "accounts without transactions",
+		req: request{
+			Query:     accountsBaseQuery,
+			Variables: accountsRequest{Assets: []string{"BTC", "ETH"}},
+		},
+		want: 15,
+	}, {
+		name: "limit takes precedence over list arguments",
+		req: request{
+			Query: `query Deals($markets: [Market!]!, $limit: Int!) {
+				deals(markets: $markets, limit: $limit) { id price }
+			}`,
+			Variables: map[string]interface{}{
+				"markets": []string{"BTCETH", "BTCLTC", "ETHLTC"},
+				"limit":   5,
+			},
+		},
+		want: 11,
+	}, {
+		name: "literal list and fragments",
+		req: request{
+			Query: `{
+				a: balanceUpdateRecords(assets: [BTC, ETH], offset: 0) {
+					__typename
+					... on Deposit { change time }
+				}
+			}`,
+		},
+		want: 7,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cost, err := queryComplexity(test.req, defaultListSize)
+			if err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+			if cost != test.want {
+				t.Errorf("want complexity %v but got %v", test.want, cost)
+			}
+		})
+	}
+}
+
+func TestClient_AccountsComplexityBudget(t *testing.T) {
+	var queries []string
+	client := &Client{
+		core: CoreFunc(func(query string, _ interface{}) ([]byte, error) {
+			queries = append(queries, query)
+			if strings.Contains(query, "PendingTransactions") {
+				return []byte(`{"data": {"accounts": [{"asset": "BTC",
+					"pending": {"transactions": [{"txid": "tx"}]}}]}}`), nil
+			}
+			return []byte(`{"data": {"accounts": [{"asset": "BTC",
+				"available": "1", "pending": {"amount": "2"}}]}}`), nil
+		}),
+		complexity: &ComplexityConfig{Budget: 100, ListSize: 10},
+	}
+
+	accounts, err := client.Accounts([]string{"BTC", "ETH"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(queries) != 2 || strings.Contains(queries[0], "transactions") {
+		t.Errorf("want pending transactions fetched separately but got "+
+			"%v queries", len(queries))
+	}
+	if len(accounts) != 1 || !accounts[0].Available.Equal(dec(1)) ||
+		len(accounts[0].Pending.Transactions) != 1 {
+		t.Errorf("want accounts merged but got `%v`", accounts)
+	}
+
+	// Query within the budget is not split.
+	queries = nil
+	if _, err := client.Accounts([]string{"BTC"}); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(queries) != 1 || !strings.Contains(queries[0], "transactions") {
+		t.Errorf("want single accounts query but got %v", len(queries))
+	}
+}
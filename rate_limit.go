@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits requests the client sends to the exchange to rps
+// requests per second on average with bursts of up to burst requests,
+// so heavy polling loops don't trip the exchange throttling. Requests
+// exceeding the limit wait for their turn or until their context is
+// done. Non-positive rps disables the limit, burst less than one means
+// one.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *options) {
+		if rps <= 0 {
+			o.rateLimit = nil
+			return
+		}
+		o.rateLimit = newRateLimiter(rps, burst)
+	}
+}
+
+// rateLimiter is the token bucket rate limiter. Nil limiter doesn't
+// limit.
+type rateLimiter struct {
+	rps   float64
+	burst float64
+	now   func() time.Time
+
+	mtx sync.Mutex
+	// tokens is the number of available tokens, negative if requests
+	// are waiting for them.
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates limiter with the full bucket.
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rps:    rps,
+		burst:  float64(burst),
+		now:    time.Now,
+		tokens: float64(burst),
+	}
+}
+
+// reserve takes the token and returns the time to wait before it is
+// available.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rps
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rps * float64(time.Second))
+}
+
+// cancel returns the token of the request which didn't wait for it.
+func (l *rateLimiter) cancel() {
+	l.mtx.Lock()
+	l.tokens++
+	l.mtx.Unlock()
+}
+
+// wait blocks until the request may be sent. It returns the context
+// error if the context is done earlier.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	d := l.reserve()
+	if d <= 0 {
+		return nil
+	}
+	if !sleep(ctx, d) {
+		l.cancel()
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_reserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	// Burst is sent at once, the next request waits for the token.
+	for i, want := range []time.Duration{0, 0, 500 * time.Millisecond,
+		time.Second} {
+
+		if got := l.reserve(); got != want {
+			t.Errorf("request %v: want wait %v but got %v", i, want, got)
+		}
+	}
+
+	// Tokens are refilled but not above the burst.
+	now = now.Add(10 * time.Second)
+	for i, want := range []time.Duration{0, 0, 500 * time.Millisecond} {
+		if got := l.reserve(); got != want {
+			t.Errorf("request %v after pause: want wait %v but got %v", i,
+				want, got)
+		}
+	}
+}
+
+func TestRateLimiter_wait(t *testing.T) {
+	l := newRateLimiter(1, 1)
+
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+
+	if err := l.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("want deadline exceeded but got `%v`", err)
+	}
+
+	// Token of canceled request is returned.
+	if l.tokens < -0.1 {
+		t.Errorf("want token of canceled request returned but got %v",
+			l.tokens)
+	}
+
+	var nilLimiter *rateLimiter
+	if err := nilLimiter.wait(ctx); err != nil {
+		t.Errorf("want nil limiter not to limit but got `%v`", err)
+	}
+}
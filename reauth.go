@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bitlum/macaroon-application-auth"
+	"gopkg.in/macaroon.v2"
+)
+
+// ErrReauthFailed is returned if server rejects credentials and fresh
+// credentials can not be obtained or are rejected too.
+var ErrReauthFailed = errors.New("reauthorization failed")
+
+// Credentials is a set of client credentials, either hex encoded binary
+// macaroon or JWT token.
+type Credentials struct {
+	Macaroon string
+	JWT      string
+}
+
+// ReauthFunc obtains fresh credentials when server rejects current
+// ones, e.g. after token rotation.
+type ReauthFunc func(ctx context.Context) (Credentials, error)
+
+// WithReauth sets the function which is invoked to obtain fresh
+// credentials if server responds with 401 or 403 status code. The
+// rejected request is retried once with fresh credentials. It lets
+// long-running services survive token rotation without restart.
+func WithReauth(f ReauthFunc) Option {
+	return func(o *options) {
+		o.reauth = f
+	}
+}
+
+// isAuthRejected returns true if the error means that server rejected
+// credentials.
+func isAuthRejected(err error) bool {
+	e, ok := err.(*HTTPStatusError)
+	if !ok {
+		return false
+	}
+	return e.StatusCode == http.StatusUnauthorized ||
+		e.StatusCode == http.StatusForbidden
+}
+
+// reauthorize obtains fresh credentials with reauth function and
+// replaces current ones.
+func (c *graphQLCore) reauthorize(ctx context.Context) error {
+	creds, err := c.reauth(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrReauthFailed, err)
+	}
+
+	if creds.Macaroon == "" && creds.JWT == "" {
+		return fmt.Errorf("%w: no credentials obtained", ErrReauthFailed)
+	}
+
+	var m *macaroon.Macaroon
+	if creds.Macaroon != "" {
+		m, err = auth.DecodeMacaroon(creds.Macaroon)
+		if err != nil {
+			return fmt.Errorf("%w: failed to decode macaroon: %v",
+				ErrReauthFailed, err)
+		}
+	}
+
+	c.mtx.Lock()
+	c.macaroon = m
+	c.jwt = creds.JWT
+	c.mtx.Unlock()
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_graphQLCore_do_reauth(t *testing.T) {
+	// Server accepts only fresh token.
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte("response body"))
+		}))
+	defer server.Close()
+
+	t.Run("when reauth succeeds", func(t *testing.T) {
+		requests = 0
+		c := &graphQLCore{
+			url: server.URL,
+			jwt: "rotated",
+			reauth: func(ctx context.Context) (Credentials, error) {
+				return Credentials{JWT: "fresh"}, nil
+			},
+		}
+		body, err := c.do(context.Background(), true, request{})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if string(body) != "response body" {
+			t.Errorf("want `response body` but got `%s`", body)
+		}
+		if requests != 2 {
+			t.Errorf("want 2 requests but got %v", requests)
+		}
+		if c.jwt != "fresh" {
+			t.Errorf("want fresh JWT to be stored but got `%s`", c.jwt)
+		}
+	})
+	t.Run("when reauth fails", func(t *testing.T) {
+		c := &graphQLCore{
+			url: server.URL,
+			jwt: "rotated",
+			reauth: func(ctx context.Context) (Credentials, error) {
+				return Credentials{}, errors.New("fail")
+			},
+		}
+		_, err := c.do(context.Background(), true, request{})
+		if !errors.Is(err, ErrReauthFailed) {
+			t.Fatalf("want reauth error but got `%v`", err)
+		}
+	})
+	t.Run("when fresh credentials are rejected", func(t *testing.T) {
+		requests = 0
+		c := &graphQLCore{
+			url: server.URL,
+			jwt: "rotated",
+			reauth: func(ctx context.Context) (Credentials, error) {
+				return Credentials{JWT: "rotated-again"}, nil
+			},
+		}
+		_, err := c.do(context.Background(), true, request{})
+		if !errors.Is(err, ErrReauthFailed) {
+			t.Fatalf("want reauth error but got `%v`", err)
+		}
+		if requests != 2 {
+			t.Errorf("want 2 requests but got %v", requests)
+		}
+	})
+	t.Run("when reauth is not set", func(t *testing.T) {
+		requests = 0
+		c := &graphQLCore{
+			url: server.URL,
+			jwt: "rotated",
+		}
+		_, err := c.do(context.Background(), true, request{})
+		if err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if requests != 1 {
+			t.Errorf("want 1 request but got %v", requests)
+		}
+	})
+}
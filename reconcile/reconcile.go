@@ -0,0 +1,193 @@
+// Package reconcile continuously compares balances of the local ledger
+// with the exchange accounts and exports the drift between them as
+// metrics, so accounting bugs and unnoticed exchange operations show up
+// before they grow.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bitlum/exchange-graphql-client"
+	"github.com/shopspring/decimal"
+)
+
+// Ledger is the local ledger which balances are expected to match the
+// exchange ones.
+type Ledger interface {
+	// Balances returns balances of the assets by asset.
+	Balances(assets []string) (map[string]decimal.Decimal, error)
+}
+
+// Accounts is the source of the exchange accounts, e.g. *client.Client.
+type Accounts interface {
+	Accounts(assets []string) ([]client.Account, error)
+}
+
+// Drift is the difference between the exchange and the local balance of
+// the asset.
+type Drift struct {
+	// Asset is the asset of the balance.
+	Asset string
+
+	// Local is the balance of the local ledger.
+	Local decimal.Decimal
+
+	// Exchange is the exchange balance, both available and freezed
+	// funds.
+	Exchange decimal.Decimal
+
+	// Drift is the exchange balance minus the local one.
+	Drift decimal.Decimal
+
+	// Time is the time the drift was computed.
+	Time time.Time
+}
+
+// Config is the configuration of the drift gauge.
+type Config struct {
+	// Assets is the assets which balances are compared.
+	Assets []string
+
+	// Interval is the interval between comparisons made by Run.
+	Interval time.Duration
+
+	// Thresholds is the maximum absolute drift by asset, exceeding
+	// drift triggers OnAlert. Assets without threshold are not alerted.
+	Thresholds map[string]decimal.Decimal
+
+	// Metrics receives balance_drift gauge and balance_drift_alerts_total
+	// counter labeled by asset, nil discards them.
+	Metrics client.Metrics
+
+	// OnAlert is called once drift of the asset exceeds its threshold.
+	// It is called again only after the drift went back within the
+	// threshold and exceeded it once more.
+	OnAlert func(Drift)
+}
+
+// DriftGauge computes the drift between the local ledger and the
+// exchange accounts.
+type DriftGauge struct {
+	ledger   Ledger
+	accounts Accounts
+	cfg      Config
+	now      func() time.Time
+
+	mtx      sync.Mutex
+	drifts   map[string]Drift
+	alerting map[string]bool
+}
+
+// NewDriftGauge creates new drift gauge of the ledger and the exchange
+// accounts.
+func NewDriftGauge(ledger Ledger, accounts Accounts,
+	cfg Config) *DriftGauge {
+
+	return &DriftGauge{
+		ledger:   ledger,
+		accounts: accounts,
+		cfg:      cfg,
+		now:      time.Now,
+		drifts:   make(map[string]Drift),
+		alerting: make(map[string]bool),
+	}
+}
+
+// Check compares the balances once, exports and returns the drifts.
+func (g *DriftGauge) Check() ([]Drift, error) {
+	accounts, err := g.accounts.Accounts(g.cfg.Assets)
+	if err != nil {
+		return nil, errors.New("failed to get accounts: " + err.Error())
+	}
+
+	local, err := g.ledger.Balances(g.cfg.Assets)
+	if err != nil {
+		return nil, errors.New("failed to get ledger balances: " +
+			err.Error())
+	}
+
+	exchange := make(map[string]decimal.Decimal, len(accounts))
+	for _, a := range accounts {
+		exchange[a.Asset] = a.Available.Add(a.Freezed)
+	}
+
+	now := g.now()
+	drifts := make([]Drift, 0, len(g.cfg.Assets))
+	for _, asset := range g.cfg.Assets {
+		d := Drift{
+			Asset:    asset,
+			Local:    local[asset],
+			Exchange: exchange[asset],
+			Time:     now,
+		}
+		d.Drift = d.Exchange.Sub(d.Local)
+		drifts = append(drifts, d)
+		g.record(d)
+	}
+
+	return drifts, nil
+}
+
+// record exports the drift and alerts if it exceeds the threshold.
+func (g *DriftGauge) record(d Drift) {
+	labels := client.Labels{"asset": d.Asset}
+	if g.cfg.Metrics != nil {
+		drift, _ := d.Drift.Float64()
+		g.cfg.Metrics.Set("balance_drift", labels, drift)
+	}
+
+	threshold, ok := g.cfg.Thresholds[d.Asset]
+	exceeded := ok && d.Drift.Abs().GreaterThan(threshold)
+
+	g.mtx.Lock()
+	g.drifts[d.Asset] = d
+	alert := exceeded && !g.alerting[d.Asset]
+	g.alerting[d.Asset] = exceeded
+	g.mtx.Unlock()
+
+	if !alert {
+		return
+	}
+	if g.cfg.Metrics != nil {
+		g.cfg.Metrics.Add("balance_drift_alerts_total", labels, 1)
+	}
+	if g.cfg.OnAlert != nil {
+		g.cfg.OnAlert(d)
+	}
+}
+
+// Drift returns the last computed drift of the asset and whether it was
+// computed.
+func (g *DriftGauge) Drift(asset string) (Drift, bool) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	d, ok := g.drifts[asset]
+	return d, ok
+}
+
+// Run checks the balances every interval until ctx is done. Check
+// errors are passed to onError if it isn't nil.
+func (g *DriftGauge) Run(ctx context.Context, onError func(error)) error {
+	if g.cfg.Interval <= 0 {
+		return errors.New("interval should be positive")
+	}
+
+	t := time.NewTicker(g.cfg.Interval)
+	defer t.Stop()
+
+	for {
+		if _, err := g.Check(); err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitlum/exchange-graphql-client"
+	"github.com/shopspring/decimal"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+type ledgerFunc func(assets []string) (map[string]decimal.Decimal, error)
+
+func (f ledgerFunc) Balances(assets []string) (map[string]decimal.Decimal,
+	error) {
+	return f(assets)
+}
+
+type accountsFunc func(assets []string) ([]client.Account, error)
+
+func (f accountsFunc) Accounts(assets []string) ([]client.Account, error) {
+	return f(assets)
+}
+
+type gaugeMetrics struct {
+	mtx    sync.Mutex
+	gauges map[string]float64
+	alerts int
+}
+
+func (m *gaugeMetrics) Observe(string, client.Labels, float64) {}
+
+func (m *gaugeMetrics) Add(name string, labels client.Labels, v float64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.alerts += int(v)
+}
+
+func (m *gaugeMetrics) Set(name string, labels client.Labels, v float64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.gauges[name+"/"+labels["asset"]] = v
+}
+
+func TestDriftGauge_Check(t *testing.T) {
+	local := map[string]decimal.Decimal{"BTC": d("1"), "ETH": d("10")}
+	ledger := ledgerFunc(func([]string) (map[string]decimal.Decimal, error) {
+		return local, nil
+	})
+	accounts := accountsFunc(func([]string) ([]client.Account, error) {
+		return []client.Account{
+			{Asset: "BTC", Available: d("0.5"), Freezed: d("0.5")},
+			{Asset: "ETH", Available: d("9"), Freezed: d("0.5")},
+		}, nil
+	})
+
+	metrics := &gaugeMetrics{gauges: make(map[string]float64)}
+	var alerts []Drift
+	g := NewDriftGauge(ledger, accounts, Config{
+		Assets:     []string{"BTC", "ETH"},
+		Thresholds: map[string]decimal.Decimal{"ETH": d("0.1")},
+		Metrics:    metrics,
+		OnAlert:    func(d Drift) { alerts = append(alerts, d) },
+	})
+
+	drifts, err := g.Check()
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(drifts) != 2 || drifts[0].Drift.Sign() != 0 ||
+		!drifts[1].Drift.Equal(d("-0.5")) {
+		t.Fatalf("want drifts 0 and -0.5 but got `%v`", drifts)
+	}
+	if metrics.gauges["balance_drift/ETH"] != -0.5 {
+		t.Errorf("want ETH drift gauge -0.5 but got %v",
+			metrics.gauges["balance_drift/ETH"])
+	}
+	if len(alerts) != 1 || alerts[0].Asset != "ETH" {
+		t.Errorf("want ETH alert but got `%v`", alerts)
+	}
+
+	// Drift which stays exceeded isn't alerted again.
+	g.Check()
+	if len(alerts) != 1 || metrics.alerts != 1 {
+		t.Errorf("want single alert but got %v", len(alerts))
+	}
+
+	// Drift which exceeds threshold again after recovery is alerted.
+	local["ETH"] = d("9.5")
+	g.Check()
+	local["ETH"] = d("10")
+	g.Check()
+	if len(alerts) != 2 {
+		t.Errorf("want second alert but got %v", len(alerts))
+	}
+
+	if drift, ok := g.Drift("ETH"); !ok || !drift.Local.Equal(d("10")) {
+		t.Errorf("want last ETH drift but got `%v`", drift)
+	}
+}
+
+func TestDriftGauge_Run(t *testing.T) {
+	g := NewDriftGauge(
+		ledgerFunc(func([]string) (map[string]decimal.Decimal, error) {
+			return nil, nil
+		}),
+		accountsFunc(func([]string) ([]client.Account, error) {
+			return nil, errors.New("exchange is down")
+		}),
+		Config{Assets: []string{"BTC"}, Interval: time.Millisecond},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := 0
+	err := g.Run(ctx, func(error) {
+		errs++
+		if errs == 3 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Errorf("want context canceled but got `%v`", err)
+	}
+	if errs != 3 {
+		t.Errorf("want 3 check errors but got %v", errs)
+	}
+}
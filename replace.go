@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// replaceVerifyAttempts is the number of attempts to learn the state of
+// the order if its cancel fails.
+const replaceVerifyAttempts = 3
+
+// replaceVerifyBackoff is the delay before the first attempt to learn
+// the order state, it doubles with every attempt. It is variable to be
+// shortened in tests.
+var replaceVerifyBackoff = 100 * time.Millisecond
+
+// ErrCancelStateUnknown is returned by Replace if the order cancel
+// failed and the order state can not be learned, so the new order is
+// not placed to avoid doubling the exposure.
+var ErrCancelStateUnknown = errors.New("cancel state is unknown")
+
+// Replacement is the result of Replace.
+type Replacement struct {
+	// Canceled is the replaced order in its final state, either
+	// canceled or finished if it was filled before cancel.
+	Canceled Order
+
+	// Placed is the new order, zero if it is not placed.
+	Placed Order
+}
+
+// Replace cancels the order and places the new one in its stead. The
+// new order is placed only once the replaced order is known to be off
+// the book: if cancel fails, the order state is requested with backoff
+// and if it is still pending or unknown the new order is not placed and
+// ErrCancelStateUnknown is returned. Replaced order which got filled
+// before cancel is replaced as well, its final state is returned, so
+// the caller may account the fill.
+func (c *Client) Replace(ctx context.Context, cancelID int64,
+	newOrder OrderIntent) (Replacement, error) {
+
+	var res Replacement
+
+	client := c.WithContext(ctx)
+
+	canceled, err := client.CancelOrder(cancelID)
+	if err != nil || canceled.Status == OrderPending {
+		canceled, err = client.verifyOffBook(ctx, cancelID)
+		if err != nil {
+			return res, err
+		}
+	}
+	res.Canceled = canceled
+
+	placed, err := client.PlaceOrder(newOrder)
+	if err != nil {
+		return res, errors.New("failed to place new order: " +
+			err.Error())
+	}
+	res.Placed = placed
+
+	return res, nil
+}
+
+// verifyOffBook requests the order state with backoff until it is
+// known to be off the book. ErrCancelStateUnknown is returned if the
+// order is still pending or its state can not be requested.
+func (c *Client) verifyOffBook(ctx context.Context, id int64) (Order,
+	error) {
+
+	backoff := replaceVerifyBackoff
+	for i := 0; i < replaceVerifyAttempts; i++ {
+		if !sleep(ctx, backoff) {
+			return Order{}, ctx.Err()
+		}
+		backoff *= 2
+
+		// Cached state is not trusted as it is the one being verified.
+		c.orders.invalidate(id)
+
+		order, err := c.Order(id)
+		if err == nil && order.Status != OrderPending {
+			return order, nil
+		}
+	}
+	return Order{}, ErrCancelStateUnknown
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replaceCore fakes exchange which responds to cancel with cancelResp
+// or cancelErr and to order lookups with orderResp.
+func replaceCore(cancelResp string, cancelErr error, orderResp string,
+	placed *int) CoreFunc {
+
+	return func(query string, variables interface{}) ([]byte, error) {
+		switch {
+		case strings.Contains(query, "cancelOrder"):
+			return []byte(`{"data": {"cancelOrder": ` + cancelResp +
+				`}}`), cancelErr
+		case strings.Contains(query, "createMarketOrder"):
+			*placed++
+			return []byte(`{"data": {"createMarketOrder": {"id": 2,
+				"status": "pending"}}}`), nil
+		default:
+			return []byte(`{"data": {"order": ` + orderResp + `}}`), nil
+		}
+	}
+}
+
+func TestClient_Replace(t *testing.T) {
+	defer func(backoff time.Duration) {
+		replaceVerifyBackoff = backoff
+	}(replaceVerifyBackoff)
+	replaceVerifyBackoff = time.Millisecond
+
+	intent := OrderIntent{Market: "BTCETH", Side: "bid", Amount: dec(1)}
+
+	t.Run("when canceled", func(t *testing.T) {
+		var placed int
+		client := &Client{core: replaceCore(
+			`{"id": 1, "status": "canceled"}`, nil, ``, &placed)}
+
+		res, err := client.Replace(context.Background(), 1, intent)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if res.Canceled.Status != OrderCanceled || res.Placed.ID != 2 ||
+			placed != 1 {
+			t.Errorf("want order 1 replaced with 2 but got `%v`", res)
+		}
+	})
+	t.Run("when cancel failed but order is filled", func(t *testing.T) {
+		var placed int
+		client := &Client{core: replaceCore(`null`,
+			errors.New("timeout"), `{"id": 1, "status": "finished"}`,
+			&placed)}
+
+		res, err := client.Replace(context.Background(), 1, intent)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if res.Canceled.Status != OrderFinished || placed != 1 {
+			t.Errorf("want filled order replaced but got `%v`", res)
+		}
+	})
+	t.Run("when cancel state is unknown", func(t *testing.T) {
+		var placed int
+		client := &Client{core: replaceCore(`null`,
+			errors.New("timeout"), `{"id": 1, "status": "pending"}`,
+			&placed)}
+
+		_, err := client.Replace(context.Background(), 1, intent)
+		if err != ErrCancelStateUnknown {
+			t.Errorf("want ErrCancelStateUnknown but got `%v`", err)
+		}
+		if placed != 0 {
+			t.Errorf("want no order placed but got %d", placed)
+		}
+	})
+}
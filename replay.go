@@ -0,0 +1,76 @@
+package client
+
+import (
+	"time"
+)
+
+// replayEvent is the event kept for replay along with the time it was
+// published at.
+type replayEvent struct {
+	at    time.Time
+	event interface{}
+}
+
+// replayBuffer is the ring buffer of recent events of the shared
+// subscription, so consumers attaching late may replay them. Nil buffer
+// keeps nothing. It is not safe for concurrent use, the fan-out guards
+// it.
+type replayBuffer struct {
+	events []replayEvent
+	next   int
+	full   bool
+}
+
+// newReplayBuffer creates new buffer of the size, nil if the size is
+// not positive.
+func newReplayBuffer(size int) *replayBuffer {
+	if size <= 0 {
+		return nil
+	}
+	return &replayBuffer{events: make([]replayEvent, size)}
+}
+
+// add adds the event published at the time, evicting the oldest one if
+// the buffer is full.
+func (b *replayBuffer) add(at time.Time, event interface{}) {
+	if b == nil {
+		return
+	}
+
+	b.events[b.next] = replayEvent{at: at, event: event}
+	b.next++
+	if b.next == len(b.events) {
+		b.next = 0
+		b.full = true
+	}
+}
+
+// since returns the events published at or after the time in the order
+// of publishing.
+func (b *replayBuffer) since(t time.Time) []interface{} {
+	if b == nil {
+		return nil
+	}
+
+	ordered := b.events[:b.next]
+	if b.full {
+		ordered = append(append([]replayEvent{}, b.events[b.next:]...),
+			ordered...)
+	}
+
+	// Events are published in chronological order, so the first one
+	// which isn't older than the time is searched.
+	first := len(ordered)
+	for i, e := range ordered {
+		if !e.at.Before(t) {
+			first = i
+			break
+		}
+	}
+
+	events := make([]interface{}, 0, len(ordered)-first)
+	for _, e := range ordered[first:] {
+		events = append(events, e.event)
+	}
+	return events
+}
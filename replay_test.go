@@ -0,0 +1,44 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReplayBuffer(t *testing.T) {
+	start := time.Now()
+	at := func(i int) time.Time {
+		return start.Add(time.Duration(i) * time.Second)
+	}
+
+	b := newReplayBuffer(3)
+	if got := b.since(time.Time{}); len(got) != 0 {
+		t.Errorf("want no events but got %v", got)
+	}
+
+	for i := 1; i <= 5; i++ {
+		b.add(at(i), i)
+	}
+
+	tests := []struct {
+		since time.Time
+		want  []interface{}
+	}{
+		{time.Time{}, []interface{}{3, 4, 5}},
+		{at(4), []interface{}{4, 5}},
+		{at(5).Add(time.Millisecond), []interface{}{}},
+	}
+	for _, test := range tests {
+		if got := b.since(test.since); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("want %v since %v but got %v", test.want,
+				test.since.Sub(start), got)
+		}
+	}
+
+	disabled := newReplayBuffer(0)
+	disabled.add(at(1), 1)
+	if got := disabled.since(time.Time{}); got != nil {
+		t.Errorf("want nothing replayed but got %v", got)
+	}
+}
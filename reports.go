@@ -0,0 +1,193 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// maxReportErrorBody is the maximum size of the error response body of
+// the report download which is kept in the error.
+const maxReportErrorBody = 4096
+
+// ReportStatus is the status of the report generation.
+type ReportStatus string
+
+// Report statuses.
+const (
+	ReportPending ReportStatus = "pending"
+	ReportReady   ReportStatus = "ready"
+	ReportFailed  ReportStatus = "failed"
+)
+
+// ErrReportNotReady is returned by DownloadReport if the report is
+// still being generated or its generation failed.
+var ErrReportNotReady = errors.New("report isn't ready")
+
+// Report is the exchange document generated on request, e.g. tax
+// report.
+type Report struct {
+	// ID is the exchange specific report ID.
+	ID string `json:"id"`
+
+	// Year is the year the report covers.
+	Year int `json:"year"`
+
+	// Status is the status of the report generation.
+	Status ReportStatus `json:"status"`
+
+	// DownloadURL is the short-lived link to the report file, empty
+	// until the report is ready.
+	DownloadURL string `json:"downloadURL"`
+}
+
+// taxReportRequestVariables is a query variables used in request in
+// client RequestTaxReport method.
+type taxReportRequestVariables struct {
+	Year int `json:"year"`
+}
+
+// reportRequestVariables is a query variables used in request in
+// client ReportStatus method.
+type reportRequestVariables struct {
+	ID string `json:"id"`
+}
+
+// RequestTaxReport requests generation of the tax report of the user
+// for the year. The report is generated asynchronously, its status is
+// checked with ReportStatus.
+func (c *Client) RequestTaxReport(year int) (Report, error) {
+	var req request
+
+	req.Query = `
+		mutation RequestTaxReport($year: Int!) {
+			requestTaxReport(year: $year) {
+				id
+				year
+				status
+				downloadURL
+			}
+		}
+	`
+
+	req.Variables = taxReportRequestVariables{
+		Year: year,
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Report Report `json:"requestTaxReport"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return Report{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return Report{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return Report{}, exchangeError(err)
+	}
+
+	return resp.Data.Report, nil
+}
+
+// ReportStatus returns the report with given ID along with its
+// generation status.
+func (c *Client) ReportStatus(id string) (Report, error) {
+	var req request
+
+	req.Query = `
+		query Report($id: ID!) {
+			report(id: $id) {
+				id
+				year
+				status
+				downloadURL
+			}
+		}
+	`
+
+	req.Variables = reportRequestVariables{
+		ID: id,
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Report Report `json:"report"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return Report{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return Report{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return Report{}, exchangeError(err)
+	}
+
+	return resp.Data.Report, nil
+}
+
+// DownloadReport streams the file of the ready report with given ID to
+// w and returns the number of bytes written. ErrReportNotReady is
+// returned if the report is not generated yet.
+func (c *Client) DownloadReport(id string, w io.Writer) (int64, error) {
+	report, err := c.ReportStatus(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if report.Status != ReportReady || report.DownloadURL == "" {
+		return 0, ErrReportNotReady
+	}
+
+	httpReq, err := http.NewRequestWithContext(c.context(), "GET",
+		report.DownloadURL, nil)
+	if err != nil {
+		return 0, errors.New("failed to http.NewRequestWithContext: " +
+			err.Error())
+	}
+
+	httpReq.Header.Set(ClientVersionHeader, Version)
+
+	httpClient := http.DefaultClient
+	if core, ok := c.core.(*graphQLCore); ok && core.httpClient != nil {
+		httpClient = core.httpClient
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return 0, transportError(err)
+	}
+
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(httpResp.Body,
+			maxReportErrorBody))
+		return 0, newHTTPStatusError(httpResp, body)
+	}
+
+	n, err := io.Copy(w, httpResp.Body)
+	if err != nil {
+		return n, errors.New("failed to download report after " +
+			strconv.FormatInt(n, 10) + " bytes: " + err.Error())
+	}
+
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_RequestTaxReport(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `{ "data": { "requestTaxReport": {
+			"id": "r1", "year": 2018, "status": "pending"
+		} } }`,
+	}
+	client := &Client{core: backend}
+
+	report, err := client.RequestTaxReport(2018)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	want := Report{ID: "r1", Year: 2018, Status: ReportPending}
+	if report != want {
+		t.Errorf("want report `%v` but got `%v`", want, report)
+	}
+
+	wantVariables := taxReportRequestVariables{Year: 2018}
+	if !reflect.DeepEqual(wantVariables, backend.request.Variables) {
+		t.Errorf("want variables `%#v` but got `%#v`", wantVariables,
+			backend.request.Variables)
+	}
+}
+
+func TestClient_DownloadReport(t *testing.T) {
+	file := []byte("date,asset,amount\n2018-01-01,BTC,1\n")
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/reports/r1.csv" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(file)
+		}))
+	defer server.Close()
+
+	t.Run("when report is ready", func(t *testing.T) {
+		client := &Client{core: &mockCore{
+			respJSON: `{ "data": { "report": { "id": "r1",
+				"status": "ready",
+				"downloadURL": "` + server.URL + `/reports/r1.csv" } } }`,
+		}}
+
+		var buf bytes.Buffer
+		n, err := client.DownloadReport("r1", &buf)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if n != int64(len(file)) || !bytes.Equal(buf.Bytes(), file) {
+			t.Errorf("want report file but got %v bytes `%s`", n,
+				buf.Bytes())
+		}
+	})
+	t.Run("when report is pending", func(t *testing.T) {
+		client := &Client{core: &mockCore{
+			respJSON: `{ "data": { "report": { "id": "r1",
+				"status": "pending" } } }`,
+		}}
+
+		_, err := client.DownloadReport("r1", &bytes.Buffer{})
+		if err != ErrReportNotReady {
+			t.Errorf("want ErrReportNotReady but got `%v`", err)
+		}
+	})
+	t.Run("when download fails", func(t *testing.T) {
+		client := &Client{core: &mockCore{
+			respJSON: `{ "data": { "report": { "id": "r1",
+				"status": "ready",
+				"downloadURL": "` + server.URL + `/reports/gone.csv" } } }`,
+		}}
+
+		_, err := client.DownloadReport("r1", &bytes.Buffer{})
+		if e, ok := err.(*HTTPStatusError); !ok ||
+			e.StatusCode != http.StatusNotFound {
+			t.Errorf("want not found status error but got `%v`", err)
+		}
+	})
+}
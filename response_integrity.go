@@ -0,0 +1,61 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrTruncatedResponse is returned if the response body is shorter than
+// the server declared or doesn't match the checksum sent by the server,
+// e.g. because a load balancer cut the connection. Partial JSON may
+// still parse, so such responses are rejected instead of being decoded.
+var ErrTruncatedResponse = errors.New("truncated response")
+
+// WithResponseChecksum makes the client verify the response body
+// against the hex encoded SHA-256 checksum the server sends in the
+// header, e.g. "X-Body-SHA256". Responses without the header are
+// accepted, the Content-Length is verified regardless of the option.
+func WithResponseChecksum(header string) Option {
+	return func(o *options) {
+		o.checksumHeader = header
+	}
+}
+
+// verifyBody checks the body read from the response with the read
+// error, and returns ErrTruncatedResponse if the body is incomplete or
+// the read error otherwise. Empty checksum header disables checksum
+// verification.
+func verifyBody(resp *http.Response, body []byte, readErr error,
+	checksumHeader string) error {
+
+	if readErr == io.ErrUnexpectedEOF {
+		return ErrTruncatedResponse
+	}
+	if readErr != nil {
+		return readErr
+	}
+
+	// Content length is unknown if the body is chunked or was
+	// decompressed by the transport.
+	if resp.ContentLength >= 0 && int64(len(body)) != resp.ContentLength {
+		return ErrTruncatedResponse
+	}
+
+	if checksumHeader == "" {
+		return nil
+	}
+	checksum := strings.TrimSpace(resp.Header.Get(checksumHeader))
+	if checksum == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(body)
+	if !strings.EqualFold(checksum, hex.EncodeToString(sum[:])) {
+		return ErrTruncatedResponse
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+const integrityResponse = `{"data": {"info": {"time": "1539597600"}}}`
+
+func TestGraphQLCore_truncatedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length",
+				strconv.Itoa(len(integrityResponse)+10))
+			w.Write([]byte(integrityResponse))
+		}))
+	defer server.Close()
+
+	client := &Client{core: &graphQLCore{url: server.URL}}
+
+	_, err := client.Info()
+	if !errors.Is(err, ErrTruncatedResponse) {
+		t.Fatalf("want truncated response error but got `%v`", err)
+	}
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Errorf("want transport error but got `%T`", err)
+	}
+}
+
+func TestGraphQLCore_responseChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte(integrityResponse))
+
+	checksum := hex.EncodeToString(sum[:])
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Body-SHA256", checksum)
+			w.Write([]byte(integrityResponse))
+		}))
+	defer server.Close()
+
+	client := &Client{core: &graphQLCore{url: server.URL,
+		checksumHeader: "X-Body-SHA256"}}
+
+	if _, err := client.Info(); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	checksum = hex.EncodeToString(make([]byte, sha256.Size))
+	if _, err := client.Info(); !errors.Is(err, ErrTruncatedResponse) {
+		t.Errorf("want truncated response error but got `%v`", err)
+	}
+}
+
+func TestVerifyBody(t *testing.T) {
+	body := []byte(integrityResponse)
+	sum := sha256.Sum256(body)
+
+	tests := []struct {
+		name          string
+		contentLength int64
+		checksum      string
+		want          error
+	}{{
+		name:          "complete",
+		contentLength: int64(len(body)),
+	}, {
+		name:          "unknown length",
+		contentLength: -1,
+	}, {
+		name:          "short body",
+		contentLength: int64(len(body)) + 1,
+		want:          ErrTruncatedResponse,
+	}, {
+		name:          "checksum",
+		contentLength: -1,
+		checksum:      hex.EncodeToString(sum[:]),
+	}, {
+		name:          "checksum mismatch",
+		contentLength: -1,
+		checksum:      hex.EncodeToString(sum[1:]),
+		want:          ErrTruncatedResponse,
+	}}
+
+	for _, test := range tests {
+		resp := &http.Response{
+			Header:        http.Header{},
+			ContentLength: test.contentLength,
+		}
+		if test.checksum != "" {
+			resp.Header.Set("X-Body-SHA256", test.checksum)
+		}
+
+		err := verifyBody(resp, body, nil, "X-Body-SHA256")
+		if err != test.want {
+			t.Errorf("%v: want `%v` but got `%v`", test.name, test.want,
+				err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DataSource is a source which the data was received from.
+type DataSource int
+
+const (
+	// PrimarySource means the data was received from exchange GraphQL
+	// API.
+	PrimarySource DataSource = iota
+
+	// DegradedSource means GraphQL API was unavailable and the data was
+	// received from exchange REST fallback endpoint, which may be less
+	// fresh or complete.
+	DegradedSource
+)
+
+// String returns the name of the data source.
+func (s DataSource) String() string {
+	switch s {
+	case PrimarySource:
+		return "primary"
+	case DegradedSource:
+		return "degraded"
+	default:
+		return "unknown"
+	}
+}
+
+// WithRESTFallback enables fallback to the exchange minimal REST
+// endpoint located at baseURL for read-only operations, currently
+// Markets and Info. The fallback is used automatically if GraphQL
+// request fails, results received through it are marked with
+// DegradedSource.
+func WithRESTFallback(baseURL string) Option {
+	return func(o *options) {
+		o.restFallbackURL = baseURL
+	}
+}
+
+// restFallback is the client of exchange minimal REST API which stays
+// up during GraphQL layer outages.
+type restFallback struct {
+	baseURL    string
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+}
+
+// markets requests statuses of the markets for the given period.
+func (f *restFallback) markets(ctx context.Context, markets []string,
+	period int32) ([]MarketStatus, error) {
+
+	query := url.Values{}
+	query.Set("markets", strings.Join(markets, ","))
+	query.Set("period", strconv.Itoa(int(period)))
+
+	var resp []MarketStatus
+	if err := f.get(ctx, "/markets?"+query.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	for i := range resp {
+		resp[i].Source = DegradedSource
+	}
+
+	return resp, nil
+}
+
+// info requests general information about service state.
+func (f *restFallback) info(ctx context.Context) (*Info, error) {
+	var resp Info
+	if err := f.get(ctx, "/info", &resp); err != nil {
+		return nil, err
+	}
+
+	resp.Source = DegradedSource
+
+	return &resp, nil
+}
+
+// get performs GET request on the path and decodes JSON response into
+// resp.
+func (f *restFallback) get(ctx context.Context, path string,
+	resp interface{}) error {
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET",
+		strings.TrimSuffix(f.baseURL, "/")+path, nil)
+	if err != nil {
+		return errors.New("failed to http.NewRequestWithContext: " +
+			err.Error())
+	}
+
+	httpReq.Header.Set(ClientVersionHeader, Version)
+
+	httpClient := f.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Transport: f.transport,
+			Timeout:   f.timeout,
+		}
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return errors.New("failed to do http request: " + err.Error())
+	}
+
+	defer httpResp.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		return newHTTPStatusError(httpResp, body)
+	}
+
+	if err != nil {
+		return errors.New("failed to read response body: " + err.Error())
+	}
+
+	if err := json.Unmarshal(body, resp); err != nil {
+		return decodeError(err)
+	}
+
+	return nil
+}
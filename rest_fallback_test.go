@@ -0,0 +1,96 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Markets_restFallback(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/markets" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			gotQuery = r.URL.RawQuery
+			w.Write([]byte(`[{ "market": "BTCETH", "last": "0.1" }]`))
+		}))
+	defer server.Close()
+
+	client := &Client{
+		core:     &mockCore{error: errors.New("fail")},
+		fallback: &restFallback{baseURL: server.URL},
+	}
+
+	statuses, err := client.Markets([]string{"BTCETH", "BTCLTC"}, 60)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("want 1 status but got %v", len(statuses))
+	}
+	if statuses[0].Source != DegradedSource {
+		t.Errorf("want degraded source but got %v", statuses[0].Source)
+	}
+	if !statuses[0].Last.Equal(dec(0.1)) {
+		t.Errorf("want last 0.1 but got %v", statuses[0].Last)
+	}
+	if want := "markets=BTCETH%2CBTCLTC&period=60"; gotQuery != want {
+		t.Errorf("want query `%s` but got `%s`", want, gotQuery)
+	}
+}
+
+func TestClient_Info_restFallback(t *testing.T) {
+	t.Run("when fallback succeeds", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{ "network": "simnet" }`))
+			}))
+		defer server.Close()
+
+		client := &Client{
+			core:     &mockCore{error: errors.New("fail")},
+			fallback: &restFallback{baseURL: server.URL},
+		}
+		info, err := client.Info()
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if info.Network != "simnet" || info.Source != DegradedSource {
+			t.Errorf("want degraded simnet info but got `%#v`", info)
+		}
+	})
+	t.Run("when fallback fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+		defer server.Close()
+
+		client := &Client{
+			core:     &mockCore{error: errors.New("fail")},
+			fallback: &restFallback{baseURL: server.URL},
+		}
+		if _, err := client.Info(); err == nil {
+			t.Fatal("want error but got no error")
+		}
+	})
+	t.Run("when GraphQL succeeds", func(t *testing.T) {
+		client := &Client{
+			core: &mockCore{
+				respJSON: `{ "data": { "info": { "network": "mainnet" } } }`,
+			},
+			fallback: &restFallback{baseURL: "http://unreachable"},
+		}
+		info, err := client.Info()
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if info.Source != PrimarySource {
+			t.Errorf("want primary source but got %v", info.Source)
+		}
+	})
+}
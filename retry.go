@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// defaultRetryBackoff is the default delay before the first retry.
+	defaultRetryBackoff = 100 * time.Millisecond
+
+	// defaultMaxRetryBackoff is the default maximum delay between
+	// retries.
+	defaultMaxRetryBackoff = 10 * time.Second
+)
+
+// RetryConfig is the configuration of retries of requests failed with
+// transient errors: network errors, 429 and 5xx responses.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts of the request,
+	// including the first one.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, it doubles with
+	// every retry. Zero means 100ms.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries, zero means 10s.
+	MaxBackoff time.Duration
+
+	// Budget is the maximum total delay between retries of single
+	// request, the request fails once the next delay would exceed it.
+	// Zero means no budget.
+	Budget time.Duration
+}
+
+// WithRetry makes the client retry queries failed with transient errors
+// with jittered exponential backoff. Mutations are retried only if they
+// are made with context marked by WithRetrySafe, as retry of mutation
+// which reached the exchange may duplicate it.
+func WithRetry(cfg RetryConfig) Option {
+	return func(o *options) {
+		if cfg.Backoff <= 0 {
+			cfg.Backoff = defaultRetryBackoff
+		}
+		if cfg.MaxBackoff <= 0 {
+			cfg.MaxBackoff = defaultMaxRetryBackoff
+		}
+		o.retry = &cfg
+	}
+}
+
+// retrySafeKey is the context key of the retry safe mark.
+type retrySafeKey struct{}
+
+// WithRetrySafe returns the copy of the context which marks mutations
+// made with it as safe to retry, e.g. because the exchange deduplicates
+// them.
+func WithRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+// idempotent returns true if the request may be repeated, i.e. retried
+// or hedged.
+func idempotent(ctx context.Context, r request) bool {
+	if !isMutation(r.Query) {
+		return true
+	}
+	safe, _ := ctx.Value(retrySafeKey{}).(bool)
+	return safe
+}
+
+// delay returns jittered delay before the retry with given number.
+func (cfg *RetryConfig) delay(retry int) time.Duration {
+	d := cfg.Backoff
+	for i := 1; i < retry && d < cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > cfg.MaxBackoff {
+		d = cfg.MaxBackoff
+	}
+
+	// Jitter spreads retries of concurrent requests, so they don't hit
+	// recovering server at once.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// isTransient returns true if the request error is likely to go away on
+// retry: network errors, truncated responses, 429 and 5xx responses.
+// Local failures, e.g. to sign the request or to verify the server
+// certificate, are not retried as they repeat on every attempt.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests ||
+			statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, ErrTruncatedResponse) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	// Every error of http client is url.Error, which implements
+	// net.Error, so its cause is checked instead.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Timeout() {
+			return true
+		}
+		err = urlErr.Err
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// sendRetrying sends marshalled GraphQL request retrying transient
+// failures of idempotent requests according to the retry
+// configuration.
+func (c *graphQLCore) sendRetrying(ctx context.Context, needAuth,
+	idempotent bool, reqJSON []byte) ([]byte, error) {
+
+	if c.retry == nil || !idempotent {
+		return c.sendThrottled(ctx, needAuth, idempotent, reqJSON)
+	}
+
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		body, err := c.sendThrottled(ctx, needAuth, idempotent, reqJSON)
+		if err == nil || attempt >= c.retry.MaxAttempts ||
+			!isTransient(ctx, err) {
+			return body, err
+		}
+
+		delay := c.retry.delay(attempt)
+		if e, ok := err.(*HTTPStatusError); ok && e.RetryAfter > delay {
+			delay = e.RetryAfter
+		}
+		if c.retry.Budget > 0 && waited+delay > c.retry.Budget {
+			return body, err
+		}
+		if !sleep(ctx, delay) {
+			return body, err
+		}
+		waited += delay
+	}
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryConfig_delay(t *testing.T) {
+	cfg := &RetryConfig{
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	}
+
+	tests := []struct {
+		retry int
+		max   time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{10, time.Second},
+	}
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			d := cfg.delay(test.retry)
+			if d < test.max/2 || d > test.max {
+				t.Fatalf("want delay of retry %d within [%v, %v] but "+
+					"got %v", test.retry, test.max/2, test.max, d)
+			}
+		}
+	}
+}
+
+func TestGraphQLCore_retry(t *testing.T) {
+	// newServer creates server which responds with the statuses in
+	// turn and 200 afterwards.
+	newServer := func(statuses ...int) (*httptest.Server, *int32) {
+		var calls int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+
+			n := atomic.AddInt32(&calls, 1)
+			if int(n) <= len(statuses) {
+				w.WriteHeader(statuses[n-1])
+				return
+			}
+			w.Write([]byte(`{"data": {}}`))
+		}))
+		return s, &calls
+	}
+
+	retry := &RetryConfig{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}
+
+	query := request{Query: "query Q { x }"}
+	mutation := request{Query: "mutation M { x }"}
+
+	tests := []struct {
+		name      string
+		statuses  []int
+		ctx       context.Context
+		req       request
+		retry     *RetryConfig
+		wantErr   bool
+		wantCalls int32
+	}{{
+		name:      "query recovers after transient failures",
+		statuses:  []int{503, 429},
+		req:       query,
+		retry:     retry,
+		wantCalls: 3,
+	}, {
+		name:      "query exhausts attempts",
+		statuses:  []int{500, 502, 503},
+		req:       query,
+		retry:     retry,
+		wantErr:   true,
+		wantCalls: 3,
+	}, {
+		name:      "query is not retried on client error",
+		statuses:  []int{400},
+		req:       query,
+		retry:     retry,
+		wantErr:   true,
+		wantCalls: 1,
+	}, {
+		name:      "mutation is not retried",
+		statuses:  []int{503},
+		req:       mutation,
+		retry:     retry,
+		wantErr:   true,
+		wantCalls: 1,
+	}, {
+		name:      "safe mutation is retried",
+		statuses:  []int{503},
+		ctx:       WithRetrySafe(context.Background()),
+		req:       mutation,
+		retry:     retry,
+		wantCalls: 2,
+	}, {
+		name:      "retries are disabled",
+		statuses:  []int{503},
+		req:       query,
+		wantErr:   true,
+		wantCalls: 1,
+	}, {
+		name:     "budget is exceeded",
+		statuses: []int{503, 503},
+		req:      query,
+		retry: &RetryConfig{
+			MaxAttempts: 3,
+			Backoff:     20 * time.Millisecond,
+			MaxBackoff:  20 * time.Millisecond,
+			Budget:      5 * time.Millisecond,
+		},
+		wantErr:   true,
+		wantCalls: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, calls := newServer(test.statuses...)
+			defer s.Close()
+
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			c := &graphQLCore{url: s.URL, retry: test.retry}
+			_, err := c.do(ctx, false, test.req)
+			if (err != nil) != test.wantErr {
+				t.Errorf("want error %v but got `%v`", test.wantErr, err)
+			}
+			if got := atomic.LoadInt32(calls); got != test.wantCalls {
+				t.Errorf("want %d calls but got %d", test.wantCalls, got)
+			}
+		})
+	}
+
+	t.Run("network error", func(t *testing.T) {
+		s, _ := newServer()
+		s.Close()
+
+		c := &graphQLCore{url: s.URL, retry: retry}
+		started := time.Now()
+		if _, err := c.do(context.Background(), false, query); err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if time.Since(started) < time.Millisecond {
+			t.Error("want request retried with backoff")
+		}
+	})
+	t.Run("signing error", func(t *testing.T) {
+		s, calls := newServer()
+		defer s.Close()
+
+		signer := &failingSigner{}
+		c := &graphQLCore{url: s.URL, retry: retry, signer: signer}
+		if _, err := c.do(context.Background(), true, query); err == nil {
+			t.Fatal("want error but got no error")
+		}
+		if signer.attempts != 1 {
+			t.Errorf("want 1 signing attempt but got %d", signer.attempts)
+		}
+		if got := atomic.LoadInt32(calls); got != 0 {
+			t.Errorf("want no calls but got %d", got)
+		}
+	})
+}
+
+// failingSigner is Signer which fails to sign requests.
+type failingSigner struct {
+	attempts int
+}
+
+func (s *failingSigner) Sign(http.Header, []byte) error {
+	s.attempts++
+	return errors.New("no key")
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// PendingWithdrawal is the withdrawal which is not yet completed, its
+// funds are already deducted from the account.
+type PendingWithdrawal struct {
+	WithdrawalRecord
+
+	// Asset is the asset of the withdrawal.
+	Asset string `json:"asset"`
+}
+
+// OpenOrders returns orders of the user which are pending on the
+// market.
+func (c *Client) OpenOrders() ([]Order, error) {
+	var req request
+
+	req.Query = `
+		query OpenOrders {
+			openOrders {
+				id
+				status
+				amount
+				price
+				dealStock
+				dealMoney
+				left
+			}
+		}
+	`
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Orders []Order `json:"openOrders"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	return resp.Data.Orders, nil
+}
+
+// pendingWithdrawalsRequestVariables is a query variables used in
+// request in client PendingWithdrawals method.
+type pendingWithdrawalsRequestVariables struct {
+	Assets []string `json:"assets"`
+}
+
+// PendingWithdrawals returns withdrawals of the assets which are not yet
+// completed.
+func (c *Client) PendingWithdrawals(assets []string) ([]PendingWithdrawal,
+	error) {
+
+	var req request
+
+	req.Query = `
+		query PendingWithdrawals($assets: [Asset!]!) {
+			pendingWithdrawals(assets: $assets) {
+				asset
+				change
+				time
+				paymentID
+				paymentAddr
+			}
+		}
+	`
+
+	req.Variables = pendingWithdrawalsRequestVariables{
+		Assets: c.assets.assets(assets),
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Withdrawals []PendingWithdrawal `json:"pendingWithdrawals"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return nil, exchangeError(err)
+	}
+
+	for i := range resp.Data.Withdrawals {
+		resp.Data.Withdrawals[i].Asset = c.assets.localAsset(
+			resp.Data.Withdrawals[i].Asset)
+	}
+
+	return resp.Data.Withdrawals, nil
+}
+
+// RiskSnapshot is the view of the account for risk checks.
+type RiskSnapshot struct {
+	// Accounts is the balances of the assets.
+	Accounts []Account
+
+	// OpenOrders is the orders pending on the market.
+	OpenOrders []Order
+
+	// PendingWithdrawals is the withdrawals which are not yet
+	// completed.
+	PendingWithdrawals []PendingWithdrawal
+
+	// ServerTime is the server time the snapshot is taken at.
+	ServerTime time.Time
+
+	// Spread is the time between the first and the last read of the
+	// snapshot, zero if the snapshot is read with a single request.
+	Spread time.Duration
+}
+
+// RiskSnapshot returns accounts of the assets, open orders and pending
+// withdrawals read as close together as possible, so pre-trade risk
+// checks operate on one coherent view. They are read with a single
+// batch request, see NewBatch; if the server rejects the batch, e.g.
+// because it doesn't support batching of some of the fields, they are
+// read one after another and Spread tells the time between the reads.
+func (c *Client) RiskSnapshot(ctx context.Context,
+	assets []string) (RiskSnapshot, error) {
+
+	b := c.NewBatch()
+	accounts := b.AddAccounts(assets)
+	orders := b.AddOpenOrders()
+	withdrawals := b.AddPendingWithdrawals(assets)
+	serverTime := b.AddServerTime()
+
+	err := b.Do(ctx)
+	var exchangeErr *ExchangeError
+	if errors.As(err, &exchangeErr) {
+		return c.sequentialRiskSnapshot(ctx, assets)
+	}
+	if err != nil {
+		return RiskSnapshot{}, err
+	}
+
+	for _, err := range []error{accounts.Err, orders.Err, withdrawals.Err,
+		serverTime.Err} {
+
+		if err != nil {
+			return RiskSnapshot{}, err
+		}
+	}
+
+	return RiskSnapshot{
+		Accounts:           accounts.Accounts,
+		OpenOrders:         orders.Orders,
+		PendingWithdrawals: withdrawals.Withdrawals,
+		ServerTime:         serverTime.Time,
+	}, nil
+}
+
+// sequentialRiskSnapshot reads the risk snapshot with separate requests.
+// Server time is read in the middle of the reads.
+func (c *Client) sequentialRiskSnapshot(ctx context.Context,
+	assets []string) (RiskSnapshot, error) {
+
+	client := c.WithContext(ctx)
+	started := time.Now()
+
+	var (
+		snapshot RiskSnapshot
+		err      error
+	)
+	if snapshot.Accounts, err = client.Accounts(assets); err != nil {
+		return RiskSnapshot{}, err
+	}
+
+	info, err := client.Info()
+	if err != nil {
+		return RiskSnapshot{}, err
+	}
+	if snapshot.ServerTime, err = info.ServerTime(); err != nil {
+		return RiskSnapshot{}, errors.New("failed to parse server time: " +
+			err.Error())
+	}
+
+	if snapshot.OpenOrders, err = client.OpenOrders(); err != nil {
+		return RiskSnapshot{}, err
+	}
+	snapshot.PendingWithdrawals, err = client.PendingWithdrawals(assets)
+	if err != nil {
+		return RiskSnapshot{}, err
+	}
+
+	snapshot.Spread = time.Since(started)
+	return snapshot, nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_RiskSnapshot(t *testing.T) {
+	var queries []string
+	client := &Client{
+		core: CoreFunc(func(query string, _ interface{}) ([]byte, error) {
+			queries = append(queries, query)
+			return []byte(`{"data": {
+				"op0": [{"asset": "BTC", "available": "1"}],
+				"op1": [{"id": 1, "status": "pending"}],
+				"op2": [{"asset": "BTC", "change": "-0.5",
+					"paymentID": "tx"}],
+				"op3": {"time": "2018-10-15T10:00:00Z"}
+			}}`), nil
+		}),
+	}
+
+	snapshot, err := client.RiskSnapshot(context.Background(),
+		[]string{"BTC"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(queries) != 1 {
+		t.Errorf("want single batch request but got %v", len(queries))
+	}
+
+	if len(snapshot.Accounts) != 1 || len(snapshot.OpenOrders) != 1 ||
+		len(snapshot.PendingWithdrawals) != 1 {
+		t.Fatalf("want snapshot filled but got `%v`", snapshot)
+	}
+	if w := snapshot.PendingWithdrawals[0]; w.Asset != "BTC" ||
+		w.PaymentID != "tx" {
+		t.Errorf("want pending withdrawal of BTC but got `%v`", w)
+	}
+	want := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+	if !snapshot.ServerTime.Equal(want) || snapshot.Spread != 0 {
+		t.Errorf("want server time %v but got %v", want,
+			snapshot.ServerTime)
+	}
+}
+
+func TestClient_RiskSnapshotSequential(t *testing.T) {
+	var queries []string
+	client := &Client{
+		core: CoreFunc(func(query string, _ interface{}) ([]byte, error) {
+			queries = append(queries, query)
+			switch {
+			case strings.Contains(query, "query Batch"):
+				return []byte(`{"errors": [{"message":
+					"batching is not supported"}]}`), nil
+			case strings.Contains(query, "accounts("):
+				return []byte(`{"data": {"accounts": [
+					{"asset": "BTC"}]}}`), nil
+			case strings.Contains(query, "info {"):
+				return []byte(`{"data": {"info": {
+					"time": "1539597600"}}}`), nil
+			case strings.Contains(query, "openOrders {"):
+				return []byte(`{"data": {"openOrders": [{"id": 1}]}}`), nil
+			default:
+				return []byte(`{"data": {"pendingWithdrawals": []}}`), nil
+			}
+		}),
+	}
+
+	snapshot, err := client.RiskSnapshot(context.Background(),
+		[]string{"BTC"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(queries) != 5 {
+		t.Errorf("want batch and 4 separate requests but got %v",
+			len(queries))
+	}
+	if len(snapshot.Accounts) != 1 || len(snapshot.OpenOrders) != 1 {
+		t.Errorf("want snapshot filled but got `%v`", snapshot)
+	}
+	if want := time.Unix(1539597600, 0); !snapshot.ServerTime.Equal(want) {
+		t.Errorf("want server time %v but got %v", want,
+			snapshot.ServerTime)
+	}
+}
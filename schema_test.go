@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// queryFieldRe matches field names in graphql query text.
+var queryFieldRe = regexp.MustCompile(`[_A-Za-z][_0-9A-Za-z]*`)
+
+// TestResponseStructsBoundToQuery ensures every exported field of
+// response structs has an explicit json tag and the tag name is
+// selected by the query which fills the struct, so renaming a field on
+// either side doesn't silently leave it zero.
+func TestResponseStructsBoundToQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client)
+		resp interface{}
+	}{{
+		name: "Me",
+		call: func(c *Client) { c.Me() },
+		resp: Me{},
+	}, {
+		name: "Depth",
+		call: func(c *Client) { c.Depth("BTCETH", 10, 0) },
+		resp: Depth{},
+	}, {
+		name: "Deposits",
+		call: func(c *Client) { c.Deposits("BTC", 0, 10) },
+		resp: Deposit{},
+	}, {
+		name: "Withdrawals",
+		call: func(c *Client) { c.Withdrawals("BTC", 0, 10) },
+		resp: WithdrawalRecord{},
+	}, {
+		name: "Order",
+		call: func(c *Client) { c.Order(1) },
+		resp: Order{},
+	}, {
+		name: "CancelOrder",
+		call: func(c *Client) { c.CancelOrder(1) },
+		resp: Order{},
+	}, {
+		name: "CreateOrder",
+		call: func(c *Client) { c.CreateOrder("BTCETH", dec(1)) },
+		resp: Order{},
+	}, {
+		name: "Withdraw",
+		call: func(c *Client) { c.Withdraw("BTC", dec(1), "addr") },
+		resp: Withdrawal{},
+	}, {
+		name: "Info",
+		call: func(c *Client) { c.Info() },
+		resp: Info{},
+	}, {
+		name: "LightningWithdraw",
+		call: func(c *Client) { c.LightningWithdraw("BTC", "lnbc") },
+		resp: LightningWithdrawal{},
+	}, {
+		name: "Accounts",
+		call: func(c *Client) { c.Accounts([]string{"BTC"}) },
+		resp: Account{},
+	}, {
+		name: "Markets",
+		call: func(c *Client) { c.Markets([]string{"BTCETH"}, 86400) },
+		resp: MarketStatus{},
+	}, {
+		name: "SupportedMarkets",
+		call: func(c *Client) { c.SupportedMarkets(context.Background()) },
+		resp: MarketInfo{},
+	}, {
+		name: "Deals",
+		call: func(c *Client) { c.Deals([]string{"BTCETH"}, 10) },
+		resp: MarketDeal{},
+	}, {
+		name: "MyDeals",
+		call: func(c *Client) { c.MyDeals("BTCETH", 0, 10) },
+		resp: MyDeal{},
+	}, {
+		name: "Notifications",
+		call: func(c *Client) { c.Notifications(0, 10) },
+		resp: Notification{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := &mockCore{respJSON: `{}`}
+			test.call(&Client{core: backend})
+
+			selected := make(map[string]bool)
+			for _, f := range queryFieldRe.FindAllString(
+				backend.request.Query, -1) {
+				selected[f] = true
+			}
+
+			for _, field := range unboundFields(
+				reflect.TypeOf(test.resp), selected) {
+				t.Errorf("field %s is not bound to query", field)
+			}
+		})
+	}
+}
+
+// unboundFields returns names of exported fields of struct type t and
+// its nested structs which either have no json tag or which tag name is
+// not in selected query fields. Fields tagged with "-" are skipped.
+func unboundFields(t reflect.Type, selected map[string]bool) []string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(decimal.Decimal{}) {
+		return nil
+	}
+
+	var res []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag, ok := f.Tag.Lookup("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && !ok {
+			// Fields of embedded struct are promoted.
+			res = append(res, unboundFields(f.Type, selected)...)
+			continue
+		}
+		if !ok || name == "" {
+			res = append(res, t.Name()+"."+f.Name+" (no json tag)")
+			continue
+		}
+		if !selected[name] {
+			res = append(res, t.Name()+"."+f.Name)
+		}
+
+		for _, nested := range unboundFields(f.Type, selected) {
+			res = append(res, nested)
+		}
+	}
+	return res
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader is the http header which holds request signature.
+	SignatureHeader = "X-Signature"
+
+	// TimestampHeader is the http header which holds time of request
+	// signing as unix time in milliseconds.
+	TimestampHeader = "X-Timestamp"
+
+	// KeyIDHeader is the http header which holds ID of the key used to
+	// sign the request.
+	KeyIDHeader = "X-Key-ID"
+)
+
+var (
+	// ErrSignatureMismatch is returned if the request signature does
+	// not match the request body.
+	ErrSignatureMismatch = errors.New("signature mismatch")
+
+	// ErrTimestampOutOfWindow is returned if the request was signed
+	// too long ago or too far in future, it protects from replay
+	// attack.
+	ErrTimestampOutOfWindow = errors.New("timestamp is out of window")
+)
+
+// defaultSignatureWindow is the default window within which signed
+// request timestamp is considered valid.
+const defaultSignatureWindow = 30 * time.Second
+
+// Signer signs requests to the exchange. It is an alternative to
+// macaroon and JWT authorization.
+type Signer interface {
+	// Sign sets signature headers of the request with given marshalled
+	// body.
+	Sign(header http.Header, body []byte) error
+}
+
+// WithSigner sets the signer used to authorize requests if neither
+// macaroon nor JWT token is given.
+func WithSigner(s Signer) Option {
+	return func(o *options) {
+		o.signer = s
+	}
+}
+
+// HMACSigner is the Signer which signs requests with HMAC-SHA256 over
+// the request timestamp and body.
+type HMACSigner struct {
+	// KeyID is the ID of the key given by exchange, sent along with
+	// the signature to let exchange find the secret.
+	KeyID string
+
+	// Secret is the secret key of the HMAC.
+	Secret []byte
+
+	// Window is the maximum allowed difference between signing and
+	// verification time, zero means 30 seconds.
+	Window time.Duration
+
+	// now is used to get current time, replaced in tests.
+	now func() time.Time
+}
+
+// NewHMACSigner creates new HMAC-SHA256 signer with given key.
+func NewHMACSigner(keyID string, secret []byte) *HMACSigner {
+	return &HMACSigner{
+		KeyID:  keyID,
+		Secret: secret,
+		now:    time.Now,
+	}
+}
+
+// Sign implements Signer. It sets signature, timestamp and key ID
+// headers.
+func (s *HMACSigner) Sign(header http.Header, body []byte) error {
+	if len(s.Secret) == 0 {
+		return errors.New("empty HMAC secret")
+	}
+
+	timestamp := strconv.FormatInt(s.currentTime().UnixNano()/
+		int64(time.Millisecond), 10)
+
+	header.Set(TimestampHeader, timestamp)
+	header.Set(SignatureHeader, s.signature(timestamp, body))
+	if s.KeyID != "" {
+		header.Set(KeyIDHeader, s.KeyID)
+	}
+
+	return nil
+}
+
+// Verify checks that the request headers hold valid signature of the
+// body and that the request is signed within the window. It is the
+// counterpart of Sign, used by servers and tests.
+func (s *HMACSigner) Verify(header http.Header, body []byte) error {
+	timestamp := header.Get(TimestampHeader)
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("failed to parse timestamp: " + err.Error())
+	}
+
+	want := s.signature(timestamp, body)
+	got := header.Get(SignatureHeader)
+	if !hmac.Equal([]byte(want), []byte(got)) {
+		return ErrSignatureMismatch
+	}
+
+	window := s.Window
+	if window == 0 {
+		window = defaultSignatureWindow
+	}
+
+	signedAt := time.Unix(0, ms*int64(time.Millisecond))
+	diff := s.currentTime().Sub(signedAt)
+	if diff > window || diff < -window {
+		return ErrTimestampOutOfWindow
+	}
+
+	return nil
+}
+
+// signature returns hex encoded HMAC-SHA256 of the timestamp and body
+// separated by dot.
+func (s *HMACSigner) signature(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// currentTime returns current time.
+func (s *HMACSigner) currentTime() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
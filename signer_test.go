@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner_SignVerify(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	signer := NewHMACSigner("key-id", []byte("secret"))
+	signer.now = func() time.Time { return now }
+
+	body := []byte(`{"query":"query"}`)
+
+	t.Run("when valid signature", func(t *testing.T) {
+		h := http.Header{}
+		if err := signer.Sign(h, body); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if h.Get(KeyIDHeader) != "key-id" {
+			t.Errorf("want key ID header `key-id` but got `%s`",
+				h.Get(KeyIDHeader))
+		}
+		if err := signer.Verify(h, body); err != nil {
+			t.Errorf("want no error but got `%v`", err)
+		}
+	})
+	t.Run("when body is modified", func(t *testing.T) {
+		h := http.Header{}
+		if err := signer.Sign(h, body); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		err := signer.Verify(h, []byte(`{"query":"mutation"}`))
+		if err != ErrSignatureMismatch {
+			t.Errorf("want ErrSignatureMismatch but got `%v`", err)
+		}
+	})
+	t.Run("when timestamp is out of window", func(t *testing.T) {
+		h := http.Header{}
+		if err := signer.Sign(h, body); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		verifier := NewHMACSigner("key-id", []byte("secret"))
+		verifier.now = func() time.Time { return now.Add(time.Minute) }
+		if err := verifier.Verify(h, body); err != ErrTimestampOutOfWindow {
+			t.Errorf("want ErrTimestampOutOfWindow but got `%v`", err)
+		}
+	})
+	t.Run("when empty secret", func(t *testing.T) {
+		if err := NewHMACSigner("", nil).Sign(http.Header{},
+			body); err == nil {
+			t.Error("want error but got no error")
+		}
+	})
+}
+
+func Test_graphQLCore_do_signer(t *testing.T) {
+	s := newMockBackendServer()
+	defer s.stop()
+	s.response.code = 200
+
+	signer := NewHMACSigner("key-id", []byte("secret"))
+	c := &graphQLCore{
+		url:    s.url(),
+		signer: signer,
+	}
+	_, err := c.do(context.Background(), true, request{Query: "query"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if err := signer.Verify(s.request.header, s.request.body); err != nil {
+		t.Errorf("want valid signature but got `%v`", err)
+	}
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// RequestIDHeader is the response header which carries the ID the
+	// server assigned to the request.
+	RequestIDHeader = "X-Request-Id"
+
+	// defaultSlowCallThreshold is the default latency above which
+	// calls are considered slow.
+	defaultSlowCallThreshold = time.Second
+)
+
+// SlowCallConfig is the configuration of slow call logging.
+type SlowCallConfig struct {
+	// Threshold is the latency above which the call is considered
+	// slow, zero means one second.
+	Threshold time.Duration
+
+	// SampleRate is the fraction of slow calls which are logged, in
+	// (0, 1]. Zero means every slow call is logged.
+	SampleRate float64
+
+	// Logger receives entries of sampled slow calls. Nil means slow
+	// calls are only counted in metrics and marked in entries of the
+	// client logger, see WithLogger.
+	Logger Logger
+}
+
+// WithSlowCallLogging makes the client log calls which take longer than
+// the threshold, sampling them to keep the log volume low. Entries of
+// slow calls are marked with Slow and carry the market, the number of
+// attempts and the server request ID, so slow endpoints of the exchange
+// can be caught without logging every request with WithLogger.
+// Durations of all calls are observed in request_duration_seconds
+// metric and all slow calls, sampled or not, are counted in
+// slow_calls_total metric.
+func WithSlowCallLogging(cfg SlowCallConfig) Option {
+	return func(o *options) {
+		o.slowCalls = &cfg
+	}
+}
+
+// slowCallLogger logs sampled slow calls.
+type slowCallLogger struct {
+	cfg     SlowCallConfig
+	metrics Metrics
+
+	// sample returns pseudo-random number in [0, 1).
+	sample func() float64
+}
+
+// newSlowCallLogger creates new slow call logger with defaults applied.
+func newSlowCallLogger(cfg SlowCallConfig,
+	metrics Metrics) *slowCallLogger {
+
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultSlowCallThreshold
+	}
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		cfg.SampleRate = 1
+	}
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	return &slowCallLogger{
+		cfg:     cfg,
+		metrics: metrics,
+		sample:  rand.Float64,
+	}
+}
+
+// observe records duration of the call, marks the entry if the call is
+// slow and logs it if it is sampled.
+func (l *slowCallLogger) observe(e *LogEntry) {
+	if l == nil {
+		return
+	}
+
+	labels := Labels{"operation": e.Operation}
+	l.metrics.Observe("request_duration_seconds", labels,
+		e.Duration.Seconds())
+
+	if e.Duration <= l.cfg.Threshold {
+		return
+	}
+	e.Slow = true
+	l.metrics.Add("slow_calls_total", labels, 1)
+
+	if l.cfg.Logger != nil && l.sample() < l.cfg.SampleRate {
+		l.cfg.Logger.LogRequest(*e)
+	}
+}
+
+// callStats collects details of the call which are known only to the
+// core, e.g. the number of attempts.
+type callStats struct {
+	attempts int32
+
+	mtx       sync.Mutex
+	requestID string
+}
+
+// callStatsKey is the context key of the call stats.
+type callStatsKey struct{}
+
+// withCallStats returns the copy of the context which collects stats of
+// the call made with it.
+func withCallStats(ctx context.Context) (context.Context, *callStats) {
+	stats := &callStats{}
+	return context.WithValue(ctx, callStatsKey{}, stats), stats
+}
+
+// contextCallStats returns the call stats stored in the context, nil if
+// there are none.
+func contextCallStats(ctx context.Context) *callStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*callStats)
+	return stats
+}
+
+// attempt counts the attempt to send the request.
+func (s *callStats) attempt() {
+	if s != nil {
+		atomic.AddInt32(&s.attempts, 1)
+	}
+}
+
+// respond records the request ID of the server response, empty IDs are
+// ignored.
+func (s *callStats) respond(requestID string) {
+	if s == nil || requestID == "" {
+		return
+	}
+	s.mtx.Lock()
+	s.requestID = requestID
+	s.mtx.Unlock()
+}
+
+// fill sets the attempts and the request ID of the log entry.
+func (s *callStats) fill(e *LogEntry) {
+	if s == nil {
+		return
+	}
+	e.Attempts = int(atomic.LoadInt32(&s.attempts))
+	s.mtx.Lock()
+	e.RequestID = s.requestID
+	s.mtx.Unlock()
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSlowCallLogger_observe(t *testing.T) {
+	var entries []LogEntry
+	metrics := &recordingMetrics{}
+	l := newSlowCallLogger(SlowCallConfig{
+		Threshold:  time.Second,
+		SampleRate: 0.5,
+		Logger: LoggerFunc(func(e LogEntry) {
+			entries = append(entries, e)
+		}),
+	}, metrics)
+
+	samples := []float64{0.9, 0.1}
+	l.sample = func() float64 {
+		s := samples[0]
+		samples = samples[1:]
+		return s
+	}
+
+	fast := LogEntry{Operation: "GetDepth", Duration: time.Millisecond}
+	l.observe(&fast)
+	if fast.Slow {
+		t.Error("want fast call not to be marked slow")
+	}
+
+	for i := 0; i < 2; i++ {
+		e := LogEntry{Operation: "GetDepth", Duration: 2 * time.Second}
+		l.observe(&e)
+		if !e.Slow {
+			t.Error("want slow call to be marked")
+		}
+	}
+
+	if len(entries) != 1 || !entries[0].Slow {
+		t.Errorf("want single sampled slow entry but got `%v`", entries)
+	}
+	if n := metrics.count("slow_calls_total"); n != 2 {
+		t.Errorf("want 2 slow calls counted but got %v", n)
+	}
+	if n := metrics.count("request_duration_seconds"); n != 3 {
+		t.Errorf("want 3 durations observed but got %v", n)
+	}
+}
+
+func TestClient_slowCallLogging(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+			w.Header().Set(RequestIDHeader, "req-42")
+			w.Write([]byte(`{"data": {"depth": {"asks": [], "bids": []}}}`))
+		}))
+	defer server.Close()
+
+	entries := make(chan LogEntry, 1)
+	client, err := NewClient(server.URL, "", "",
+		WithRetry(RetryConfig{MaxAttempts: 2, Backoff: time.Millisecond}),
+		WithSlowCallLogging(SlowCallConfig{
+			Threshold: 10 * time.Millisecond,
+			Logger: LoggerFunc(func(e LogEntry) {
+				entries <- e
+			}),
+		}))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if _, err := client.Depth("BTCETH", 10, 0); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	select {
+	case e := <-entries:
+		if e.Operation != "GetBestAskBid" || e.Market != "BTCETH" {
+			t.Errorf("want depth of BTCETH logged but got `%s` `%s`",
+				e.Operation, e.Market)
+		}
+		if e.Attempts != 2 || e.RequestID != "req-42" {
+			t.Errorf("want 2 attempts of req-42 but got %v of `%s`",
+				e.Attempts, e.RequestID)
+		}
+	default:
+		t.Fatal("want slow call to be logged")
+	}
+}
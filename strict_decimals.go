@@ -0,0 +1,156 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultMaxFractionDigits is the default bound of fraction digits of
+// decimals in strict decimal mode, see WithStrictDecimals.
+const DefaultMaxFractionDigits = 18
+
+// decimalType is the reflected type of decimals.
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+
+// WithStrictDecimals makes the client marshal request variables
+// canonically, as some server parsers reject numbers like "1e-8".
+// Numbers are written as plain fixed-point ones, never in exponent
+// notation, and decimals of more than maxFractionDigits significant
+// fraction digits are rejected instead of being silently rounded.
+// Non-positive maxFractionDigits means DefaultMaxFractionDigits.
+func WithStrictDecimals(maxFractionDigits int32) Option {
+	return func(o *options) {
+		if maxFractionDigits <= 0 {
+			maxFractionDigits = DefaultMaxFractionDigits
+		}
+		o.strictDecimals = maxFractionDigits
+	}
+}
+
+// marshalRequest marshals the request, canonically if strict decimal
+// mode is enabled, i.e. maxFractionDigits is positive.
+func marshalRequest(r request, maxFractionDigits int32) ([]byte, error) {
+	if maxFractionDigits <= 0 {
+		return json.Marshal(r)
+	}
+
+	if err := checkFractionDigits(reflect.ValueOf(r.Variables),
+		maxFractionDigits); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	v, err = canonicalNumbers(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// checkFractionDigits returns error if any decimal reachable from v has
+// more than max significant fraction digits.
+func checkFractionDigits(v reflect.Value, max int32) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Type() == decimalType {
+		d := v.Interface().(decimal.Decimal)
+		if !d.Equal(d.Truncate(max)) {
+			return errors.New("decimal " + d.String() + " has more than " +
+				strconv.Itoa(int(max)) + " fraction digits")
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return checkFractionDigits(v.Elem(), max)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if err := checkFractionDigits(v.Field(i), max); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkFractionDigits(v.Index(i), max); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if err := checkFractionDigits(v.MapIndex(k), max); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// canonicalNumbers rewrites numbers of the decoded JSON value as plain
+// fixed-point ones.
+func canonicalNumbers(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return canonicalNumber(v)
+
+	case []interface{}:
+		for i, e := range v {
+			e, err := canonicalNumbers(e)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = e
+		}
+
+	case map[string]interface{}:
+		for k, e := range v {
+			e, err := canonicalNumbers(e)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = e
+		}
+	}
+
+	return v, nil
+}
+
+// canonicalNumber returns the number in fixed-point notation.
+func canonicalNumber(n json.Number) (json.Number, error) {
+	if !bytes.ContainsAny([]byte(n), "eE") {
+		return n, nil
+	}
+
+	d, err := decimal.NewFromString(string(n))
+	if err != nil {
+		return "", errors.New("failed to parse number " + string(n) + ": " +
+			err.Error())
+	}
+	return json.Number(d.String()), nil
+}
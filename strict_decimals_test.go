@@ -0,0 +1,87 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMarshalRequestStrictDecimals(t *testing.T) {
+	tests := []struct {
+		name      string
+		variables interface{}
+		want      string
+	}{{
+		name:      "tiny decimal",
+		variables: withdrawRequestVariables{Amount: decimal.New(1, -18)},
+		want:      `"amount":"0.000000000000000001"`,
+	}, {
+		name:      "huge decimal",
+		variables: withdrawRequestVariables{Amount: decimal.New(15, 40)},
+		want:      `"amount":"150000000000000000000000000000000000000000"`,
+	}, {
+		name:      "negative decimal",
+		variables: withdrawRequestVariables{Amount: decimal.New(-123, -10)},
+		want:      `"amount":"-0.0000000123"`,
+	}, {
+		name: "trailing zeros beyond the bound",
+		variables: withdrawRequestVariables{
+			Amount: decimal.RequireFromString("1.50000000000000000000000"),
+		},
+		want: `"amount":"1.5`,
+	}, {
+		name:      "tiny float",
+		variables: map[string]float64{"time": 1e-8},
+		want:      `"time":0.00000001`,
+	}, {
+		name:      "huge float",
+		variables: map[string]float64{"time": 1.5e21},
+		want:      `"time":1500000000000000000000`,
+	}, {
+		name:      "number",
+		variables: map[string]json.Number{"n": "-2.5E-20"},
+		want:      `"n":-0.000000000000000000025`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := marshalRequest(request{Variables: test.variables},
+				DefaultMaxFractionDigits+2)
+			if err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+			if !strings.Contains(string(data), test.want) {
+				t.Errorf("want %s but got %s", test.want, data)
+			}
+		})
+	}
+}
+
+func TestMarshalRequestFractionDigits(t *testing.T) {
+	r := request{Variables: createOrderRequestVariables{
+		Amount: decimal.New(1, -19),
+	}}
+
+	if _, err := marshalRequest(r, DefaultMaxFractionDigits); err == nil {
+		t.Error("want error of too many fraction digits")
+	}
+
+	// Strict mode is disabled.
+	data, err := marshalRequest(r, 0)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !strings.Contains(string(data), "0.0000000000000000001") {
+		t.Errorf("want amount marshalled as is but got %s", data)
+	}
+}
+
+func TestWithStrictDecimals(t *testing.T) {
+	var o options
+	WithStrictDecimals(0)(&o)
+	if o.strictDecimals != DefaultMaxFractionDigits {
+		t.Errorf("want default bound but got %v", o.strictDecimals)
+	}
+}
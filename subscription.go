@@ -0,0 +1,370 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultPollInterval is the default interval between long polling
+	// requests of the subscriptions.
+	defaultPollInterval = time.Second
+
+	// defaultMaxDialAttempts is the default number of consecutive failed
+	// WebSocket dial attempts after which subscription switches to long
+	// polling.
+	defaultMaxDialAttempts = 3
+
+	// defaultPollLimit is the default number of records requested by
+	// single long polling request.
+	defaultPollLimit = 100
+)
+
+// SubscriptionConfig is the configuration of the client subscriptions.
+type SubscriptionConfig struct {
+	// PollInterval is the interval between long polling requests, zero
+	// means one second.
+	PollInterval time.Duration
+
+	// PollLimit is the maximum number of records requested by single
+	// long polling request, zero means 100.
+	PollLimit int32
+
+	// MaxDialAttempts is the number of consecutive failed WebSocket
+	// dial attempts after which subscription falls back to long
+	// polling, zero means 3.
+	MaxDialAttempts int
+
+	// Buffer is the capacity of subscription channels.
+	Buffer int
+
+	// ReplayBuffer is the number of recent events each shared feed,
+	// e.g. DealsFeed, keeps for consumers attaching late, zero disables
+	// replay.
+	ReplayBuffer int
+
+	// WebSocketURL is the exchange GraphQL over WebSocket endpoint,
+	// empty means it is the client URL with ws(s) scheme.
+	WebSocketURL string
+}
+
+// WithSubscriptions sets the configuration of the client subscriptions.
+func WithSubscriptions(cfg SubscriptionConfig) Option {
+	return func(o *options) {
+		o.subscriptions = cfg
+	}
+}
+
+// eventStream is a stream of GraphQL subscription events.
+type eventStream interface {
+	// next blocks until next event payload is received.
+	next(ctx context.Context) (json.RawMessage, error)
+
+	// close closes the stream.
+	close() error
+}
+
+// streamDialer opens streams of GraphQL subscription events, e.g. over
+// WebSocket.
+type streamDialer interface {
+	dial(ctx context.Context, r request) (eventStream, error)
+}
+
+// subscriptions serves client subscriptions either with GraphQL
+// subscriptions over streams or with long polling. Nil subscriptions
+// uses default configuration and long polling only.
+type subscriptions struct {
+	cfg     SubscriptionConfig
+	dialer  streamDialer
+	metrics Metrics
+
+	// pool runs polls, nil means polls run in subscription goroutine.
+	pool *WorkerPool
+
+	// keepAlive is the interval between stream pings, zero disables
+	// pings.
+	keepAlive time.Duration
+
+	// maintenance pauses polls during maintenance windows, nil means
+	// polls are never paused.
+	maintenance *maintenance
+
+	// streams is the number of subscriptions served with streams,
+	// accessed atomically.
+	streams int32
+
+	// polls is the number of subscriptions served with long polling,
+	// accessed atomically.
+	polls int32
+}
+
+// newSubscriptions creates new subscriptions with defaults applied.
+func newSubscriptions(cfg SubscriptionConfig, dialer streamDialer,
+	metrics Metrics) *subscriptions {
+
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.PollLimit <= 0 {
+		cfg.PollLimit = defaultPollLimit
+	}
+	if cfg.MaxDialAttempts <= 0 {
+		cfg.MaxDialAttempts = defaultMaxDialAttempts
+	}
+	return &subscriptions{
+		cfg:     cfg,
+		dialer:  dialer,
+		metrics: metrics,
+	}
+}
+
+// subscription describes the subscription which may be served either
+// with GraphQL subscription or with long polling.
+type subscription struct {
+	// name is the name of the subscription used in metrics.
+	name string
+
+	// request is the GraphQL subscription request, empty query means
+	// the subscription is served with long polling only.
+	request request
+
+	// decode decodes GraphQL subscription event payload into events.
+	decode func(payload json.RawMessage) ([]interface{}, error)
+
+	// poll requests events which are newer than the cursor, returning
+	// them in chronological order along with the new cursor. Events of
+	// the first poll are not delivered, it only establishes the cursor.
+	// Nil poll means the subscription is served with streams only and
+	// never falls back to long polling.
+	poll func(cursor int64) ([]interface{}, int64, error)
+}
+
+// run serves the subscription until the context is done, passing events
+// to deliver. It tries to open GraphQL subscription stream first and
+// switches to long polling if dialing fails repeatedly, stream-only
+// subscriptions keep dialing instead.
+func (s *subscriptions) run(ctx context.Context, sub subscription,
+	deliver func(event interface{}) bool) {
+
+	if s == nil {
+		s = newSubscriptions(SubscriptionConfig{}, nil, nopMetrics{})
+	}
+	labels := Labels{"subscription": sub.name}
+
+	if s.dialer != nil && sub.request.Query != "" {
+		for failures := 0; failures < s.cfg.MaxDialAttempts ||
+			sub.poll == nil; {
+			stream, err := s.dialer.dial(ctx, sub.request)
+			if err != nil {
+				failures++
+				s.metrics.Add("subscription_dial_errors_total", labels, 1)
+				if !sleep(ctx, s.pollInterval(ctx)) {
+					return
+				}
+				continue
+			}
+
+			failures = 0
+			streamCtx, cancel := context.WithCancel(ctx)
+			go keepStreamAlive(streamCtx, stream, s.keepAlive)
+			atomic.AddInt32(&s.streams, 1)
+			ok := s.stream(ctx, stream, sub, deliver)
+			atomic.AddInt32(&s.streams, -1)
+			cancel()
+			stream.close()
+			if !ok {
+				return
+			}
+		}
+		s.metrics.Add("subscription_poll_fallbacks_total", labels, 1)
+	}
+
+	if sub.poll == nil {
+		return
+	}
+
+	atomic.AddInt32(&s.polls, 1)
+	defer atomic.AddInt32(&s.polls, -1)
+
+	s.longPoll(ctx, sub, labels, deliver)
+}
+
+// stream delivers stream events until the stream breaks. It returns
+// false if the subscription should be stopped.
+func (s *subscriptions) stream(ctx context.Context, stream eventStream,
+	sub subscription, deliver func(event interface{}) bool) bool {
+
+	for {
+		payload, err := stream.next(ctx)
+		if err != nil {
+			return ctx.Err() == nil
+		}
+
+		events, err := sub.decode(payload)
+		if err != nil {
+			s.metrics.Add("subscription_decode_errors_total",
+				Labels{"subscription": sub.name}, 1)
+			continue
+		}
+
+		for _, e := range events {
+			if !deliver(e) {
+				return false
+			}
+		}
+	}
+}
+
+// longPoll repeatedly polls events newer than the cursor until the
+// context is done. Polls are paused during maintenance windows.
+func (s *subscriptions) longPoll(ctx context.Context, sub subscription,
+	labels Labels, deliver func(event interface{}) bool) {
+
+	var (
+		cursor  int64
+		started bool
+	)
+	for {
+		if !s.maintenance.wait(ctx) {
+			return
+		}
+
+		var (
+			events []interface{}
+			next   int64
+			err    error
+		)
+		if perr := s.pool.Do(ctx, func() {
+			events, next, err = sub.poll(cursor)
+		}); perr != nil {
+			err = perr
+		}
+		if err != nil {
+			s.metrics.Add("subscription_poll_errors_total", labels, 1)
+		} else {
+			if started {
+				for _, e := range events {
+					if !deliver(e) {
+						return
+					}
+				}
+			}
+			cursor, started = next, true
+		}
+
+		if !sleep(ctx, s.pollInterval(ctx)) {
+			return
+		}
+	}
+}
+
+// sleep waits for the duration and returns false if the context is done
+// earlier.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// pollIntervalKey is the context key of the poll interval override.
+type pollIntervalKey struct{}
+
+// withPollInterval returns the copy of the context which makes
+// subscriptions run with it poll with given interval instead of the
+// configured one.
+func withPollInterval(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, pollIntervalKey{}, d)
+}
+
+// pollInterval returns the interval between polls of subscriptions run
+// with the context.
+func (s *subscriptions) pollInterval(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(pollIntervalKey{}).(time.Duration); ok && d > 0 {
+		return d
+	}
+	if s == nil {
+		return defaultPollInterval
+	}
+	return s.cfg.PollInterval
+}
+
+// buffer returns the capacity of subscription channels.
+func (s *subscriptions) buffer() int {
+	if s == nil {
+		return 0
+	}
+	return s.cfg.Buffer
+}
+
+// replayBuffer returns the number of events kept for replay by shared
+// feeds.
+func (s *subscriptions) replayBuffer() int {
+	if s == nil {
+		return 0
+	}
+	return s.cfg.ReplayBuffer
+}
+
+// pollLimit returns the number of records requested by single polling
+// request.
+func (s *subscriptions) pollLimit() int32 {
+	if s == nil {
+		return defaultPollLimit
+	}
+	return s.cfg.PollLimit
+}
+
+// SubscribeDeals returns the channel of new deals on the markets. Deals
+// are delivered in the order of their IDs, the channel is closed once
+// the client context is done, see WithContext.
+func (c *Client) SubscribeDeals(markets []string) (<-chan MarketDeal, error) {
+	ctx := c.context()
+	deals := make(chan MarketDeal, c.subs.buffer())
+
+	sub := subscription{
+		name: "deals",
+		poll: func(cursor int64) ([]interface{}, int64, error) {
+			resp, err := c.Deals(markets, c.subs.pollLimit())
+			if err != nil {
+				return nil, cursor, err
+			}
+
+			sort.Slice(resp, func(i, j int) bool {
+				return resp[i].ID < resp[j].ID
+			})
+
+			var events []interface{}
+			next := cursor
+			for _, d := range resp {
+				if int64(d.ID) > cursor {
+					events = append(events, d)
+					next = int64(d.ID)
+				}
+			}
+			return events, next, nil
+		},
+	}
+
+	go func() {
+		defer close(deals)
+		defer c.errs.recover("deals")
+		c.subs.run(ctx, sub, func(event interface{}) bool {
+			select {
+			case deals <- event.(MarketDeal):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return deals, nil
+}
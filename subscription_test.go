@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_SubscribeDeals(t *testing.T) {
+	var (
+		mtx   sync.Mutex
+		polls int
+	)
+	responses := []string{
+		`{ "data": { "deals": [{ "id": 1 }] } }`,
+		`{ "data": { "deals": [{ "id": 3 }, { "id": 2 }, { "id": 1 }] } }`,
+		`{ "data": { "deals": [{ "id": 3 }, { "id": 2 }] } }`,
+		`{ "data": { "deals": [{ "id": 4 }, { "id": 3 }] } }`,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := (&Client{
+		core: CoreFunc(func(string, interface{}) ([]byte, error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			resp := responses[len(responses)-1]
+			if polls < len(responses) {
+				resp = responses[polls]
+			}
+			polls++
+			return []byte(resp), nil
+		}),
+		subs: newSubscriptions(SubscriptionConfig{
+			PollInterval: time.Millisecond,
+		}, nil, nopMetrics{}),
+	}).WithContext(ctx)
+
+	deals, err := client.SubscribeDeals([]string{"BTCETH"})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	for _, wantID := range []int32{2, 3, 4} {
+		select {
+		case d := <-deals:
+			if d.ID != wantID {
+				t.Fatalf("want deal %v but got %v", wantID, d.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("want deal %v but got timeout", wantID)
+		}
+	}
+
+	cancel()
+	for range deals {
+	}
+}
+
+func TestSubscriptions_run_fallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dialer := &failingDialer{}
+	metrics := &recordingMetrics{}
+	s := newSubscriptions(SubscriptionConfig{
+		PollInterval:    time.Millisecond,
+		MaxDialAttempts: 2,
+	}, dialer, metrics)
+
+	var cursor int64
+	sub := subscription{
+		name:    "test",
+		request: request{Query: "subscription { test }"},
+		decode: func(json.RawMessage) ([]interface{}, error) {
+			return nil, errors.New("unexpected stream event")
+		},
+		poll: func(int64) ([]interface{}, int64, error) {
+			cursor++
+			return []interface{}{cursor}, cursor, nil
+		},
+	}
+
+	var got interface{}
+	s.run(ctx, sub, func(event interface{}) bool {
+		got = event
+		return false
+	})
+
+	if dialer.attempts != 2 {
+		t.Errorf("want 2 dial attempts but got %v", dialer.attempts)
+	}
+	if got != int64(2) {
+		t.Errorf("want polled event 2 but got %v", got)
+	}
+	if metrics.count("subscription_poll_fallbacks_total") != 1 {
+		t.Errorf("want poll fallback to be reported")
+	}
+}
+
+// failingDialer is streamDialer which always fails.
+type failingDialer struct {
+	attempts int
+}
+
+func (d *failingDialer) dial(context.Context, request) (eventStream, error) {
+	d.attempts++
+	return nil, errors.New("websocket is blocked")
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultThrottleRetries is the default number of retries of
+	// throttled request.
+	defaultThrottleRetries = 3
+
+	// defaultMaxThrottleWait is the default maximum delay before retry
+	// of throttled request.
+	defaultMaxThrottleWait = 30 * time.Second
+
+	// defaultThrottleWait is the delay before retry of throttled
+	// request if the exchange doesn't give Retry-After.
+	defaultThrottleWait = time.Second
+)
+
+// ThrottleConfig is the configuration of retries of requests throttled
+// by the exchange with 429 Too Many Requests.
+type ThrottleConfig struct {
+	// MaxRetries is the maximum number of retries of throttled request,
+	// zero disables retries.
+	MaxRetries int
+
+	// MaxWait is the maximum delay before retry, request which
+	// Retry-After exceeds it fails at once. Zero means 30s.
+	MaxWait time.Duration
+
+	// Wait is the delay before retry if the exchange doesn't give
+	// Retry-After, zero means 1s.
+	Wait time.Duration
+}
+
+// WithThrottleRetries sets the configuration of retries of throttled
+// requests. By default requests rejected with 429 Too Many Requests are
+// retried up to 3 times after the delay given in Retry-After header,
+// one second if it is absent. As throttled requests are not processed
+// by the exchange, mutations are retried too.
+func WithThrottleRetries(cfg ThrottleConfig) Option {
+	return func(o *options) {
+		if cfg.MaxRetries <= 0 {
+			o.throttle = nil
+			return
+		}
+		if cfg.MaxWait <= 0 {
+			cfg.MaxWait = defaultMaxThrottleWait
+		}
+		if cfg.Wait <= 0 {
+			cfg.Wait = defaultThrottleWait
+		}
+		o.throttle = &cfg
+	}
+}
+
+// sendThrottled sends marshalled GraphQL request retrying it after the
+// delay requested by the exchange if it is throttled.
+func (c *graphQLCore) sendThrottled(ctx context.Context, needAuth,
+	idempotent bool, reqJSON []byte) ([]byte, error) {
+
+	for retry := 0; ; retry++ {
+		body, err := c.send(ctx, needAuth, idempotent, reqJSON)
+
+		e, ok := err.(*HTTPStatusError)
+		if !ok || e.StatusCode != http.StatusTooManyRequests ||
+			c.throttle == nil || retry >= c.throttle.MaxRetries {
+			return body, err
+		}
+
+		delay := e.RetryAfter
+		if delay <= 0 {
+			delay = c.throttle.Wait
+		}
+		if delay > c.throttle.MaxWait || !sleep(ctx, delay) {
+			return body, err
+		}
+	}
+}
+
+// retryAfter parses Retry-After header value given either as number of
+// seconds or HTTP date, zero is returned if it is absent or invalid.
+func retryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+	return date.Sub(now)
+}
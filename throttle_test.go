@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{"Tue, 01 Jan 2019 00:00:05 GMT", 5 * time.Second},
+		{"Mon, 31 Dec 2018 23:59:00 GMT", 0},
+	}
+
+	for _, test := range tests {
+		if got := retryAfter(test.value, now); got != test.want {
+			t.Errorf("want %v of `%s` but got %v", test.want, test.value,
+				got)
+		}
+	}
+}
+
+func TestGraphQLCore_throttled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) <= 2 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"data": {}}`))
+		}))
+	defer server.Close()
+
+	t.Run("mutation is retried", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		c := &graphQLCore{
+			url: server.URL,
+			throttle: &ThrottleConfig{MaxRetries: 3, MaxWait: time.Second,
+				Wait: time.Millisecond},
+		}
+
+		_, err := c.do(context.Background(), false,
+			request{Query: "mutation M { x }"})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if n := atomic.LoadInt32(&calls); n != 3 {
+			t.Errorf("want 3 calls but got %v", n)
+		}
+	})
+	t.Run("retries are exhausted", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		c := &graphQLCore{
+			url: server.URL,
+			throttle: &ThrottleConfig{MaxRetries: 1, MaxWait: time.Second,
+				Wait: time.Millisecond},
+		}
+
+		_, err := c.do(context.Background(), false,
+			request{Query: "query Q { x }"})
+		if e, ok := err.(*HTTPStatusError); !ok ||
+			e.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("want too many requests error but got `%v`", err)
+		}
+		if n := atomic.LoadInt32(&calls); n != 2 {
+			t.Errorf("want 2 calls but got %v", n)
+		}
+	})
+	t.Run("context is done while waiting", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "10")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+		defer server.Close()
+
+		c := &graphQLCore{
+			url:      server.URL,
+			throttle: &ThrottleConfig{MaxRetries: 3, MaxWait: time.Minute},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			20*time.Millisecond)
+		defer cancel()
+
+		started := time.Now()
+		if _, err := c.do(ctx, false, request{Query: "query Q { x }"}); err == nil {
+			t.Error("want error but got no error")
+		}
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("want wait canceled with context but it took %v",
+				elapsed)
+		}
+	})
+}
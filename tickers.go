@@ -0,0 +1,148 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// tickersPollPeriod is the period of market statuses requested by
+// tickers long polling.
+const tickersPollPeriod = 86400
+
+// Ticker is the live state of the market.
+type Ticker struct {
+	// Market is the market of the ticker, e.g. "BTCETH".
+	Market string `json:"market"`
+
+	// Last is the price of the most recent deal.
+	Last decimal.Decimal `json:"last"`
+
+	// BestAsk is the lowest price the stock may be bought right now.
+	BestAsk decimal.Decimal `json:"bestAsk"`
+
+	// BestBid is the highest price the stock may be sold right now.
+	BestBid decimal.Decimal `json:"bestBid"`
+
+	// Volume is the amount of stock traded during the last day.
+	Volume decimal.Decimal `json:"volume"`
+}
+
+// equal returns true if tickers are the same.
+func (t Ticker) equal(o Ticker) bool {
+	return t.Market == o.Market && t.Last.Equal(o.Last) &&
+		t.BestAsk.Equal(o.BestAsk) && t.BestBid.Equal(o.BestBid) &&
+		t.Volume.Equal(o.Volume)
+}
+
+// tickersRequestVariables is a subscription variables used in request
+// in client SubscribeTickers method.
+type tickersRequestVariables struct {
+	Markets []string `json:"markets"`
+}
+
+// SubscribeTickers returns the channel of ticker updates of the
+// markets. Updates are streamed over the exchange GraphQL WebSocket
+// endpoint, which is reconnected if the connection breaks; if dialing
+// fails repeatedly the subscription falls back to long polling of
+// Markets, which delivers tickers once they change. The channel is
+// closed once the client context is done, see WithContext.
+func (c *Client) SubscribeTickers(markets []string) (<-chan Ticker, error) {
+	if len(markets) == 0 {
+		return nil, errors.New("markets should be given")
+	}
+
+	ctx := c.context()
+	tickers := make(chan Ticker, c.subs.buffer())
+
+	var req request
+	req.Query = `
+		subscription Tickers($markets: [Market!]!) {
+			tickers(markets: $markets) {
+				market
+				last
+				bestAsk
+				bestBid
+				volume
+			}
+		}
+	`
+	req.Variables = tickersRequestVariables{
+		Markets: c.assets.markets(markets),
+	}
+
+	prev := make(map[string]Ticker)
+
+	sub := subscription{
+		name:    "tickers",
+		request: req,
+		decode: func(payload json.RawMessage) ([]interface{}, error) {
+			data := struct {
+				Tickers json.RawMessage `json:"tickers"`
+			}{}
+			if err := json.Unmarshal(payload, &data); err != nil {
+				return nil, err
+			}
+
+			// Server may send either single ticker or a batch.
+			var batch []Ticker
+			if len(data.Tickers) > 0 && data.Tickers[0] == '[' {
+				if err := json.Unmarshal(data.Tickers, &batch); err != nil {
+					return nil, err
+				}
+			} else {
+				var t Ticker
+				if err := json.Unmarshal(data.Tickers, &t); err != nil {
+					return nil, err
+				}
+				batch = append(batch, t)
+			}
+
+			events := make([]interface{}, len(batch))
+			for i, t := range batch {
+				t.Market = c.assets.localMarket(t.Market)
+				events[i] = t
+			}
+			return events, nil
+		},
+		poll: func(cursor int64) ([]interface{}, int64, error) {
+			statuses, err := c.Markets(markets, tickersPollPeriod)
+			if err != nil {
+				return nil, cursor, err
+			}
+
+			var events []interface{}
+			for _, s := range statuses {
+				t := Ticker{
+					Market:  s.Market,
+					Last:    s.Last,
+					BestAsk: s.BestAsk,
+					BestBid: s.BestBid,
+					Volume:  s.Volume,
+				}
+				if p, ok := prev[t.Market]; ok && p.equal(t) {
+					continue
+				}
+				prev[t.Market] = t
+				events = append(events, t)
+			}
+			return events, cursor + 1, nil
+		},
+	}
+
+	go func() {
+		defer close(tickers)
+		defer c.errs.recover("tickers")
+		c.subs.run(ctx, sub, func(event interface{}) bool {
+			select {
+			case tickers <- event.(Ticker):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return tickers, nil
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"errors"
+)
+
+// TimeInForce tells how long the order stays on the book.
+type TimeInForce string
+
+// Time in force options.
+const (
+	// GoodTillCanceled keeps the order on the book until it is filled
+	// or canceled.
+	GoodTillCanceled TimeInForce = "GTC"
+
+	// ImmediateOrCancel fills what is possible immediately and cancels
+	// the remainder.
+	ImmediateOrCancel TimeInForce = "IOC"
+
+	// FillOrKill fills the whole order immediately or cancels it.
+	FillOrKill TimeInForce = "FOK"
+)
+
+// ErrTimeInForceUnsupported is returned if the time in force of the
+// order can not be emulated on the client side and server support is
+// not enabled, see WithNativeTimeInForce.
+var ErrTimeInForceUnsupported = errors.New("time in force is not supported")
+
+// createMarketOrderTIFQuery is the market order creation mutation which
+// passes time in force to the server.
+const createMarketOrderTIFQuery = `
+	mutation CreateMarketOrder($market: Market!, $amount: String!,
+		$side: MarketSide!, $timeInForce: TimeInForce) {
+  			createMarketOrder(amount: $amount, market: $market, side: $side,
+				timeInForce: $timeInForce) {
+    			id
+    			status
+    			amount
+				price
+    			dealStock
+				dealMoney
+    			left
+  			}
+		}
+	`
+
+// WithNativeTimeInForce makes the client pass time in force of orders to
+// the server. By default the server is assumed to lack the support, so
+// immediate-or-cancel is emulated by canceling the remainder right
+// after placement and fill-or-kill is rejected.
+func WithNativeTimeInForce() Option {
+	return func(o *options) {
+		o.nativeTimeInForce = true
+	}
+}
+
+// cancelRemainder cancels the order if it is still pending with
+// unfilled remainder and returns its final state. Order which is
+// already off the book is returned as is.
+func (c *Client) cancelRemainder(order Order) (Order, error) {
+	if order.Status != OrderPending || order.Left.Sign() == 0 {
+		return order, nil
+	}
+
+	canceled, err := c.CancelOrder(order.ID)
+	if err != nil {
+		return order, errors.New("failed to cancel remainder of " +
+			"immediate-or-cancel order: " + err.Error())
+	}
+
+	return canceled, nil
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClient_PlaceOrderTimeInForce(t *testing.T) {
+	const (
+		pendingJSON = `{ "data": { "createMarketOrder": { "id": 1,
+			"status": "pending", "left": "0.5" } } }`
+		filledJSON = `{ "data": { "createMarketOrder": { "id": 1,
+			"status": "finished", "left": "0" } } }`
+		canceledJSON = `{ "data": { "cancelOrder": { "id": 1,
+			"status": "canceled", "left": "0.5" } } }`
+	)
+
+	tests := []struct {
+		name        string
+		native      bool
+		tif         TimeInForce
+		createJSON  string
+		cancelErr   error
+		wantErr     error
+		wantStatus  string
+		wantQueries []string
+	}{
+		{
+			name:        "emulated ioc cancels remainder",
+			tif:         ImmediateOrCancel,
+			createJSON:  pendingJSON,
+			wantStatus:  OrderCanceled,
+			wantQueries: []string{"createMarketOrder", "cancelOrder"},
+		},
+		{
+			name:        "emulated ioc filled",
+			tif:         ImmediateOrCancel,
+			createJSON:  filledJSON,
+			wantStatus:  OrderFinished,
+			wantQueries: []string{"createMarketOrder"},
+		},
+		{
+			name:        "emulated ioc cancel failed",
+			tif:         ImmediateOrCancel,
+			createJSON:  pendingJSON,
+			cancelErr:   errors.New("refused"),
+			wantStatus:  OrderPending,
+			wantQueries: []string{"createMarketOrder", "cancelOrder"},
+		},
+		{
+			name:        "gtc",
+			tif:         GoodTillCanceled,
+			createJSON:  pendingJSON,
+			wantStatus:  OrderPending,
+			wantQueries: []string{"createMarketOrder"},
+		},
+		{
+			name:    "emulated fok",
+			tif:     FillOrKill,
+			wantErr: ErrTimeInForceUnsupported,
+		},
+		{
+			name:        "native",
+			native:      true,
+			tif:         FillOrKill,
+			createJSON:  pendingJSON,
+			wantStatus:  OrderPending,
+			wantQueries: []string{"timeInForce: $timeInForce"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				queries   []string
+				variables []interface{}
+			)
+			backend := CoreFunc(func(query string,
+				vars interface{}) ([]byte, error) {
+
+				queries = append(queries, query)
+				variables = append(variables, vars)
+				if strings.Contains(query, "cancelOrder") {
+					return []byte(canceledJSON), tt.cancelErr
+				}
+				return []byte(tt.createJSON), nil
+			})
+			client := &Client{core: backend, nativeTimeInForce: tt.native}
+
+			order, err := client.PlaceOrder(OrderIntent{
+				Market:      "BTCETH",
+				Side:        OrderBid,
+				Amount:      dec(1),
+				TimeInForce: tt.tif,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want error `%v` but got `%v`", tt.wantErr, err)
+				}
+				if len(queries) != 0 {
+					t.Errorf("want no requests but got %v", len(queries))
+				}
+				return
+			}
+			if tt.cancelErr != nil {
+				if err == nil {
+					t.Fatal("want error but got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+
+			if order.Status != tt.wantStatus {
+				t.Errorf("want status %v but got %v", tt.wantStatus,
+					order.Status)
+			}
+
+			if len(queries) != len(tt.wantQueries) {
+				t.Fatalf("want %v requests but got %v",
+					len(tt.wantQueries), len(queries))
+			}
+			for i, want := range tt.wantQueries {
+				if !strings.Contains(queries[i], want) {
+					t.Errorf("want request %v to contain `%v`", i, want)
+				}
+			}
+
+			v := variables[0].(createOrderRequestVariables)
+			wantTIF := TimeInForce("")
+			if tt.native {
+				wantTIF = tt.tif
+			}
+			if v.TimeInForce != wantTIF {
+				t.Errorf("want time in force variable %q but got %q",
+					wantTIF, v.TimeInForce)
+			}
+		})
+	}
+}
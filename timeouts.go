@@ -0,0 +1,37 @@
+package client
+
+import (
+	"time"
+)
+
+// Timeouts is a set of timeouts of the exchange requests. Zero value of
+// a timeout keeps the default one: the timeout of the dialer, see
+// WithDialer, or 30 seconds of the default dialer, 10 seconds of TLS
+// handshake of http.DefaultTransport, and no response header and total
+// timeouts.
+type Timeouts struct {
+	// Dial is the maximum amount of time a single connection attempt
+	// may take.
+	Dial time.Duration
+
+	// TLSHandshake is the maximum amount of time waiting for TLS
+	// handshake.
+	TLSHandshake time.Duration
+
+	// ResponseHeader is the maximum amount of time waiting for the
+	// server response headers after the request is written.
+	ResponseHeader time.Duration
+
+	// Total is the maximum amount of time of the whole request,
+	// including connection, redirects and reading the response body.
+	Total time.Duration
+}
+
+// WithTimeouts sets timeouts of the exchange requests. Non-zero dial
+// timeout overrides the dialer timeout, see WithDialer, regardless of
+// the order the options are given in.
+func WithTimeouts(t Timeouts) Option {
+	return func(o *options) {
+		o.timeouts = t
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClient_withTimeouts(t *testing.T) {
+	client, err := NewClient("http://exchange.test", "", "",
+		WithTimeouts(Timeouts{
+			Dial:           time.Second,
+			TLSHandshake:   2 * time.Second,
+			ResponseHeader: 3 * time.Second,
+			Total:          4 * time.Second,
+		}))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	core := client.core.(*graphQLCore)
+	if core.timeout != 4*time.Second {
+		t.Errorf("want total timeout 4s but got %v", core.timeout)
+	}
+
+	transport, ok := core.transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("want *http.Transport but got %T", core.transport)
+	}
+	if transport.TLSHandshakeTimeout != 2*time.Second {
+		t.Errorf("want TLS handshake timeout 2s but got %v",
+			transport.TLSHandshakeTimeout)
+	}
+	if transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("want response header timeout 3s but got %v",
+			transport.ResponseHeaderTimeout)
+	}
+}
+
+func Test_graphQLCore_do_responseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+	defer server.Close()
+	defer close(release)
+
+	o := defaultOptions()
+	WithTimeouts(Timeouts{ResponseHeader: 10 * time.Millisecond})(o)
+	c := &graphQLCore{
+		url:       server.URL,
+		transport: newTransport(o),
+	}
+
+	_, err := c.do(context.Background(), false, request{})
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("want timeout error but got `%v`", err)
+	}
+}
+
+func Test_dialerConfig(t *testing.T) {
+	timeouts := WithTimeouts(Timeouts{Dial: time.Second})
+	dialer := WithDialer(DialerConfig{
+		Timeout:    time.Minute,
+		Preference: PreferIPv4,
+	})
+
+	for name, opts := range map[string][]Option{
+		"timeouts first": {timeouts, dialer},
+		"dialer first":   {dialer, timeouts},
+	} {
+		o := defaultOptions()
+		for _, opt := range opts {
+			opt(o)
+		}
+
+		cfg := dialerConfig(o)
+		if cfg == nil || cfg.Timeout != time.Second ||
+			cfg.Preference != PreferIPv4 {
+
+			t.Errorf("want dialer with dial timeout when %s but got %+v",
+				name, cfg)
+		}
+	}
+
+	// Zero dial timeout keeps the dialer timeout.
+	o := defaultOptions()
+	WithTimeouts(Timeouts{Total: time.Second})(o)
+	dialer(o)
+	if cfg := dialerConfig(o); cfg == nil || cfg.Timeout != time.Minute {
+		t.Errorf("want dialer timeout kept but got %+v", cfg)
+	}
+
+	if cfg := dialerConfig(defaultOptions()); cfg != nil {
+		t.Errorf("want default dialer but got %+v", cfg)
+	}
+}
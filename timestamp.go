@@ -0,0 +1,245 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts is the layouts of timestamp strings reported by the
+// server. Besides RFC 3339, legacy server versions report timestamps in
+// the format of Go time.Time String method, with or without zone, and
+// timestamps without zone are in UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// ParseTimestamp parses the timestamp reported by the server, either
+// RFC 3339 one, one of the legacy formats or unix time in seconds,
+// milliseconds, microseconds or nanoseconds, which is told by its
+// magnitude. Fractional unix time may use either point or comma as the
+// decimal separator. The time is returned in UTC.
+func ParseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	// Go time.Time String method appends monotonic clock reading.
+	if i := strings.Index(s, " m="); i >= 0 {
+		s = s[:i]
+	}
+
+	if t, ok := parseUnixTimestamp(s); ok {
+		return t, nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, errors.New("unknown time format: " + s)
+}
+
+// parseUnixTimestamp parses unix time of the magnitude specific units,
+// returns false if the string is not a decimal number or is out of
+// range. The fraction is parsed exactly up to nanoseconds.
+func parseUnixTimestamp(s string) (time.Time, bool) {
+	s = strings.Replace(s, ",", ".", 1)
+
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || digits == "." ||
+		strings.Trim(digits, "0123456789.") != "" ||
+		strings.Count(digits, ".") > 1 {
+
+		return time.Time{}, false
+	}
+
+	whole, frac := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		whole, frac = digits[:i], digits[i+1:]
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	v, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	negative := s[0] == '-'
+	if negative {
+		v = -v
+	}
+	t, unit := unixTimestamp(v), unixTimestampUnit(v)
+
+	if len(frac) > 9 {
+		frac = frac[:9]
+	}
+	frac += strings.Repeat("0", 9-len(frac))
+	n, _ := strconv.ParseInt(frac, 10, 64)
+
+	offset := time.Duration(n) * unit / time.Second
+	if negative {
+		offset = -offset
+	}
+	return t.Add(offset), true
+}
+
+// unixTimestamp returns UTC time of unix time which units are told by
+// its magnitude: nanoseconds starting from 1e17, microseconds from
+// 1e14, milliseconds from 1e11 and seconds below, so seconds cover
+// years up to 5138 and milliseconds start from 1973.
+func unixTimestamp(v int64) time.Time {
+	switch unixTimestampUnit(v) {
+	case time.Nanosecond:
+		return time.Unix(0, v).UTC()
+	case time.Microsecond:
+		return time.Unix(v/1e6, v%1e6*1e3).UTC()
+	case time.Millisecond:
+		return time.Unix(v/1e3, v%1e3*1e6).UTC()
+	default:
+		return time.Unix(v, 0).UTC()
+	}
+}
+
+// unixTimestampUnit returns the units of unix time, see unixTimestamp.
+func unixTimestampUnit(v int64) time.Duration {
+	abs := v
+	if abs < 0 {
+		abs = -abs
+	}
+
+	switch {
+	case abs >= 1e17 || abs < 0:
+		return time.Nanosecond
+	case abs >= 1e14:
+		return time.Microsecond
+	case abs >= 1e11:
+		return time.Millisecond
+	default:
+		return time.Second
+	}
+}
+
+// unixTimestampFloat returns UTC time of fractional unix time, see
+// unixTimestamp, false if the time is out of range.
+func unixTimestampFloat(v float64) (time.Time, bool) {
+	abs := math.Abs(v)
+
+	var sec float64
+	switch {
+	case math.IsNaN(v) || abs >= 1e19:
+		return time.Time{}, false
+	case abs >= 1e17:
+		sec = v / 1e9
+	case abs >= 1e14:
+		sec = v / 1e6
+	case abs >= 1e11:
+		sec = v / 1e3
+	default:
+		sec = v
+	}
+
+	whole, frac := math.Modf(sec)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC(), true
+}
+
+// timestamp is the time reported by the server either as JSON number
+// of unix time or as string of any format ParseTimestamp accepts.
+type timestamp time.Time
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return errors.New("timestamp should be either string or " +
+				"number: " + string(data))
+		}
+		s = n.String()
+
+		// Numbers in exponent notation are not accepted by
+		// ParseTimestamp.
+		if strings.ContainsAny(s, "eE") {
+			f, err := n.Float64()
+			if err != nil {
+				return err
+			}
+			parsed, ok := unixTimestampFloat(f)
+			if !ok {
+				return errors.New("timestamp is out of range: " + s)
+			}
+			*t = timestamp(parsed)
+			return nil
+		}
+	}
+
+	parsed, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	*t = timestamp(parsed)
+	return nil
+}
+
+// ServerTime returns the time on the server.
+func (i Info) ServerTime() (time.Time, error) {
+	return ParseTimestamp(i.Time)
+}
+
+// recordTimestamp returns UTC time of the fractional unix time reported
+// in the record, see unixTimestampFloat, or an error if it is out of
+// range.
+func recordTimestamp(v float64) (time.Time, error) {
+	t, ok := unixTimestampFloat(v)
+	if !ok {
+		return time.Time{}, errors.New("timestamp is out of range: " +
+			strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return t, nil
+}
+
+// Timestamp returns the time the deposit was registered at in UTC.
+func (d Deposit) Timestamp() (time.Time, error) {
+	return recordTimestamp(d.Time)
+}
+
+// Timestamp returns the time the withdrawal was registered at in UTC.
+func (w WithdrawalRecord) Timestamp() (time.Time, error) {
+	return recordTimestamp(w.Time)
+}
+
+// Timestamp returns the time of the deal in UTC. The time is reported
+// with single precision, so it is accurate to about two minutes only.
+func (d MarketDeal) Timestamp() (time.Time, error) {
+	return recordTimestamp(float64(d.Time))
+}
+
+// Timestamp returns the time of the deal in UTC.
+func (d MyDeal) Timestamp() (time.Time, error) {
+	return recordTimestamp(d.Time)
+}
+
+// Timestamp returns the time the notification was created at in UTC.
+func (n Notification) Timestamp() (time.Time, error) {
+	return recordTimestamp(n.Time)
+}
+
+// Timestamp returns the time the payment was registered at in UTC.
+func (e WebhookEvent) Timestamp() (time.Time, error) {
+	return recordTimestamp(e.Time)
+}
@@ -0,0 +1,67 @@
+//go:build go1.18
+// +build go1.18
+
+package client
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func FuzzParseTimestamp(f *testing.F) {
+	for _, s := range []string{"2018-10-15T10:00:00Z",
+		"2018-10-15 12:00:00.123 +0200 CEST", "1539597600",
+		"1539597600,123", "1539597600123000000", "-9223372036854775808",
+		"99999999999999999999", "", "."} {
+
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := ParseTimestamp(s)
+		if err != nil {
+			return
+		}
+		if got.Location() != time.UTC {
+			t.Errorf("want UTC of `%v` but got %v", s, got.Location())
+		}
+	})
+}
+
+func FuzzParseTimestamp_roundTrip(f *testing.F) {
+	f.Add(int64(1539597600123456789))
+	f.Add(int64(0))
+	f.Add(int64(-1))
+
+	f.Fuzz(func(t *testing.T, nsec int64) {
+		at := time.Unix(0, nsec).UTC()
+
+		formats := map[string]time.Time{
+			at.Format(time.RFC3339Nano): at,
+			at.String():                 at,
+		}
+
+		// Units of unix times are told by their magnitude, so only
+		// times of the magnitude of their units round-trip.
+		if nsec >= 1e17 || nsec <= -1e17 {
+			formats[strconv.FormatInt(nsec, 10)] = at
+		}
+		if sec := at.Unix(); sec >= 1e8 && sec < 1e11 {
+			ms := at.Truncate(time.Millisecond)
+			formats[strconv.FormatInt(ms.UnixNano()/1e6, 10)] = ms
+			formats[strconv.FormatInt(sec, 10)] = at.Truncate(time.Second)
+		}
+
+		for s, want := range formats {
+			got, err := ParseTimestamp(s)
+			if err != nil {
+				t.Errorf("want no error of `%v` but got `%v`", s, err)
+				continue
+			}
+			if !got.Equal(want) {
+				t.Errorf("want %v of `%v` but got %v", want, s, got)
+			}
+		}
+	})
+}
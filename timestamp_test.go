@@ -0,0 +1,122 @@
+package client
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+	wantFrac := want.Add(123 * time.Millisecond)
+
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"2018-10-15T10:00:00Z", want},
+		{"2018-10-15T12:00:00+02:00", want},
+		{"2018-10-15T10:00:00.123Z", wantFrac},
+		{"2018-10-15T10:00:00", want},
+		{"2018-10-15 10:00:00", want},
+		{"2018-10-15 10:00:00.123", wantFrac},
+		{"2018-10-15 12:00:00+02:00", want},
+		{"2018-10-15 10:00:00 +0000 UTC", want},
+		{"2018-10-15 12:00:00.123 +0200 CEST", wantFrac},
+		{"2018-10-15 10:00:00 +0000 UTC m=+0.000000001", want},
+		{"1539597600", want},
+		{" 1539597600 ", want},
+		{"1539597600.123", wantFrac},
+		{"1539597600,123", wantFrac},
+		{"1539597600123", wantFrac},
+		{"1539597600123000", wantFrac},
+		{"1539597600123000000", wantFrac},
+		{"0", time.Unix(0, 0)},
+		{"-86400", time.Unix(-86400, 0)},
+	}
+
+	for _, test := range tests {
+		got, err := ParseTimestamp(test.s)
+		if err != nil {
+			t.Errorf("want no error of `%v` but got `%v`", test.s, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("want %v of `%v` but got %v", test.want, test.s, got)
+		}
+		if got.Location() != time.UTC {
+			t.Errorf("want UTC of `%v` but got %v", test.s, got.Location())
+		}
+	}
+
+	for _, s := range []string{"", "yesterday", ".", "-", "1.2.3", "0x10",
+		"1e9", "NaN", "Inf", "15/10/2018", "1539597600.123.4"} {
+
+		if _, err := ParseTimestamp(s); err == nil {
+			t.Errorf("want error of unknown format `%v`", s)
+		}
+	}
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	want := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	for _, data := range []string{`"2018-10-15T10:00:00Z"`, `"1539597600"`,
+		`1539597600`, `1539597600000`, `1.5395976e9`} {
+
+		var ts timestamp
+		if err := json.Unmarshal([]byte(data), &ts); err != nil {
+			t.Errorf("want no error of %v but got `%v`", data, err)
+			continue
+		}
+		if got := time.Time(ts); !got.Equal(want) {
+			t.Errorf("want %v of %v but got %v", want, data, got)
+		}
+	}
+
+	for _, data := range []string{`"yesterday"`, `true`, `{}`} {
+		var ts timestamp
+		if err := json.Unmarshal([]byte(data), &ts); err == nil {
+			t.Errorf("want error of %v", data)
+		}
+	}
+}
+
+func TestRecordTimestamps(t *testing.T) {
+	want := time.Date(2018, 10, 15, 10, 0, 0, 500e6, time.UTC)
+
+	records := map[string]interface {
+		Timestamp() (time.Time, error)
+	}{
+		"deposit":      Deposit{Time: 1539597600.5},
+		"withdrawal":   WithdrawalRecord{Time: 1539597600.5},
+		"my deal":      MyDeal{Time: 1539597600500},
+		"notification": Notification{Time: 1539597600.5},
+		"webhook":      WebhookEvent{Time: 1539597600.5},
+	}
+	for name, record := range records {
+		got, err := record.Timestamp()
+		if err != nil {
+			t.Errorf("want no error of %v but got `%v`", name, err)
+		}
+		if !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("want %v of %v but got %v", want, name, got)
+		}
+	}
+
+	// Deal time is single precision.
+	got, err := MarketDeal{Time: 1539597600}.Timestamp()
+	if err != nil {
+		t.Errorf("want no error but got `%v`", err)
+	}
+	if d := got.Sub(want); d < -2*time.Minute || d > 2*time.Minute {
+		t.Errorf("want deal time about %v but got %v", want, got)
+	}
+
+	for _, v := range []float64{1e20, math.NaN()} {
+		if _, err := (Deposit{Time: v}).Timestamp(); err == nil {
+			t.Errorf("want error of out of range time %v", v)
+		}
+	}
+}
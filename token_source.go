@@ -0,0 +1,213 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tokenExpiryLeeway is the time before the token expiry the token is
+// refreshed at, so requests in flight don't carry expired token.
+const tokenExpiryLeeway = 30 * time.Second
+
+// Token is the JWT token with its expiry.
+type Token struct {
+	// JWT is the token itself.
+	JWT string
+
+	// Expiry is the time the token expires at, zero means the expiry
+	// is taken from the "exp" claim of the token, if there is none the
+	// token is refreshed only when server rejects it.
+	Expiry time.Time
+}
+
+// expired returns true if the token expires before the leeway.
+func (t Token) expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Add(tokenExpiryLeeway).Before(t.Expiry)
+}
+
+// TokenSource obtains JWT tokens, like oauth2.TokenSource. It is
+// invoked by the client when it has no token, the token is about to
+// expire or server rejects it.
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenSourceFunc is the function implementing TokenSource, e.g. the
+// callback which logs in with the user credentials.
+type TokenSourceFunc func(ctx context.Context) (Token, error)
+
+// Token implements TokenSource.
+func (f TokenSourceFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
+
+// WithTokenSource makes the client authorize requests with JWT tokens
+// obtained from the source instead of the credentials given to
+// NewClient. The token is refreshed before its expiry, and if server
+// responds with 401 or 403 status code or reports that the token is
+// expired, in which case the rejected request is retried once with the
+// fresh token. Concurrent requests share single refresh. It takes
+// precedence over WithReauth.
+func WithTokenSource(ts TokenSource) Option {
+	return func(o *options) {
+		o.tokenSource = ts
+	}
+}
+
+// loginTokenSource obtains tokens with the login mutation.
+type loginTokenSource struct {
+	core      core
+	mutation  string
+	variables interface{}
+}
+
+// NewLoginTokenSource returns the token source which sends the login
+// mutation with the variables to the exchange GraphQL server at the url.
+// The mutation should select single field returning the JWT token, the
+// token expiry is read from its "exp" claim. The options configuring
+// the HTTP client, e.g. WithHTTPClient, WithTransport, WithDialContext
+// and WithTimeouts, apply to the login requests, the rest are ignored.
+//
+//	ts := client.NewLoginTokenSource(url, `
+//		mutation Login($email: String!, $password: String!) {
+//			login(email: $email, password: $password)
+//		}
+//	`, loginVariables)
+func NewLoginTokenSource(url, mutation string, variables interface{},
+	opts ...Option) TokenSource {
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	httpClient, transport := newHTTPClient(o)
+
+	return &loginTokenSource{
+		core: &graphQLCore{
+			url:        url,
+			httpClient: httpClient,
+			transport:  transport,
+			timeout:    o.timeouts.Total,
+		},
+		mutation:  mutation,
+		variables: variables,
+	}
+}
+
+// Token implements TokenSource.
+func (s *loginTokenSource) Token(ctx context.Context) (Token, error) {
+	var req request
+	req.Query = s.mutation
+	req.Variables = s.variables
+
+	resp := struct {
+		responseBase
+		Data map[string]json.RawMessage
+	}{}
+
+	respJSON, err := s.core.do(ctx, false, req)
+	if err != nil {
+		return Token{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return Token{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return Token{}, exchangeError(err)
+	}
+
+	if len(resp.Data) != 1 {
+		return Token{}, errors.New("login mutation should select " +
+			"single field")
+	}
+
+	var jwt string
+	for _, field := range resp.Data {
+		if err := json.Unmarshal(field, &jwt); err != nil {
+			return Token{}, decodeError(err)
+		}
+	}
+	if jwt == "" {
+		return Token{}, errors.New("login mutation returned empty token")
+	}
+
+	return Token{JWT: jwt, Expiry: jwtExpiry(jwt)}, nil
+}
+
+// isTokenExpired returns true if the response or the error tells that
+// the token is expired.
+func isTokenExpired(body []byte, err error) bool {
+	var detail string
+	switch e := err.(type) {
+	case nil:
+		if !bytes.Contains(bytes.ToLower(body), []byte("expired")) {
+			return false
+		}
+		detail = errorDetail(body)
+	case *HTTPStatusError:
+		detail = e.Detail
+	default:
+		return false
+	}
+
+	detail = strings.ToLower(detail)
+	return strings.Contains(detail, "token") &&
+		strings.Contains(detail, "expired")
+}
+
+// ensureToken obtains the token from the token source if the core has
+// no token or it is about to expire.
+func (c *graphQLCore) ensureToken(ctx context.Context) error {
+	c.mtx.RLock()
+	token := Token{JWT: c.jwt, Expiry: c.jwtExpiry}
+	c.mtx.RUnlock()
+
+	if token.JWT != "" && !token.expired(time.Now()) {
+		return nil
+	}
+	return c.refreshToken(ctx, token.JWT)
+}
+
+// refreshToken replaces the stale token with the one obtained from the
+// token source. Refreshes are serialized, and the token is not
+// refreshed again if it was already replaced by concurrent refresh.
+func (c *graphQLCore) refreshToken(ctx context.Context, stale string) error {
+	c.tokenMtx.Lock()
+	defer c.tokenMtx.Unlock()
+
+	c.mtx.RLock()
+	current := Token{JWT: c.jwt, Expiry: c.jwtExpiry}
+	c.mtx.RUnlock()
+
+	if current.JWT != "" && current.JWT != stale &&
+		!current.expired(time.Now()) {
+
+		return nil
+	}
+
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrReauthFailed, err)
+	}
+	if token.JWT == "" {
+		return fmt.Errorf("%w: no token obtained", ErrReauthFailed)
+	}
+	if token.Expiry.IsZero() {
+		token.Expiry = jwtExpiry(token.JWT)
+	}
+
+	c.mtx.Lock()
+	c.macaroon = nil
+	c.jwt = token.JWT
+	c.jwtExpiry = token.Expiry
+	c.mtx.Unlock()
+
+	return nil
+}
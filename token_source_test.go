@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJWT returns unsigned JWT token with the claims.
+func testJWT(claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		enc.EncodeToString([]byte(claims)) + ".sig"
+}
+
+// tokenServer accepts only the token, and reports other tokens as
+// expired either with 401 status or with GraphQL error.
+func tokenServer(token *atomic.Value, graphQLError bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "Bearer "+
+				token.Load().(string) {
+
+				w.Write([]byte(`{"data": {}}`))
+				return
+			}
+			if graphQLError {
+				w.Write([]byte(`{"errors": [{"message": "token expired"}]}`))
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+}
+
+func TestGraphQLCore_tokenSource(t *testing.T) {
+	for _, graphQLError := range []bool{false, true} {
+		var token atomic.Value
+		token.Store("t1")
+		server := tokenServer(&token, graphQLError)
+
+		var obtained int32
+		c := &graphQLCore{
+			url: server.URL,
+			tokens: TokenSourceFunc(func(ctx context.Context) (Token,
+				error) {
+
+				atomic.AddInt32(&obtained, 1)
+				return Token{JWT: token.Load().(string)}, nil
+			}),
+		}
+
+		if _, err := c.do(context.Background(), true, request{}); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if _, err := c.do(context.Background(), true, request{}); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if atomic.LoadInt32(&obtained) != 1 {
+			t.Errorf("want token obtained once but got %v", obtained)
+		}
+
+		// Server rotates the token, it is refreshed once for
+		// concurrent requests.
+		token.Store("t2")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.do(context.Background(), true, request{})
+				if err != nil {
+					t.Errorf("want no error but got `%v`", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if n := atomic.LoadInt32(&obtained); n != 2 {
+			t.Errorf("want token refreshed once but got %v", n-1)
+		}
+		server.Close()
+	}
+}
+
+func TestGraphQLCore_tokenSourceExpiry(t *testing.T) {
+	var token atomic.Value
+	token.Store("t1")
+	server := tokenServer(&token, false)
+	defer server.Close()
+
+	var obtained int
+	c := &graphQLCore{
+		url: server.URL,
+		tokens: TokenSourceFunc(func(ctx context.Context) (Token, error) {
+			obtained++
+			return Token{
+				JWT:    "t1",
+				Expiry: time.Now().Add(tokenExpiryLeeway / 2),
+			}, nil
+		}),
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.do(context.Background(), true, request{}); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+	}
+	if obtained != 2 {
+		t.Errorf("want token about to expire refreshed but got %v "+
+			"obtained", obtained)
+	}
+}
+
+func TestGraphQLCore_tokenSourceFails(t *testing.T) {
+	var token atomic.Value
+	token.Store("t1")
+	server := tokenServer(&token, true)
+	defer server.Close()
+
+	c := &graphQLCore{
+		url: server.URL,
+		jwt: "t1",
+		tokens: TokenSourceFunc(func(ctx context.Context) (Token, error) {
+			return Token{JWT: "t0"}, nil
+		}),
+	}
+
+	token.Store("t2")
+	_, err := c.do(context.Background(), true, request{})
+	if !errors.Is(err, ErrReauthFailed) {
+		t.Fatalf("want reauth error but got `%v`", err)
+	}
+
+	c.tokens = TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		return Token{}, errors.New("fail")
+	})
+	_, err = c.do(context.Background(), true, request{})
+	if !errors.Is(err, ErrReauthFailed) {
+		t.Fatalf("want reauth error but got `%v`", err)
+	}
+}
+
+func TestLoginTokenSource(t *testing.T) {
+	jwt := testJWT(`{"sub":"user","exp":1539597600}`)
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				t.Error("want login without authorization")
+			}
+			w.Write([]byte(`{"data": {"login": "` + jwt + `"}}`))
+		}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	ts := NewLoginTokenSource(server.URL, `
+		mutation Login($email: String!) {
+			login(email: $email)
+		}
+	`, map[string]string{"email": "user@example.com"},
+		WithTransport(transport))
+
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if n := atomic.LoadInt32(&transport.requests); n != 1 {
+		t.Errorf("want login through given transport but got %v "+
+			"requests", n)
+	}
+	if token.JWT != jwt {
+		t.Errorf("want token `%v` but got `%v`", jwt, token.JWT)
+	}
+	if !token.Expiry.Equal(time.Unix(1539597600, 0)) {
+		t.Errorf("want expiry from claims but got %v", token.Expiry)
+	}
+}
+
+func TestIsTokenExpired(t *testing.T) {
+	tests := []struct {
+		body string
+		err  error
+		want bool
+	}{
+		{`{"errors": [{"message": "Token expired"}]}`, nil, true},
+		{`{"errors": [{"message": "order expired"}]}`, nil, false},
+		{`{"data": {"expired": true}}`, nil, false},
+		{"", &HTTPStatusError{StatusCode: 400,
+			Detail: "jwt token is expired"}, true},
+		{"", errors.New("token expired"), false},
+	}
+
+	for _, test := range tests {
+		got := isTokenExpired([]byte(test.body), test.err)
+		if got != test.want {
+			t.Errorf("want %v of `%v`, `%v` but got %v", test.want,
+				test.body, test.err, got)
+		}
+	}
+}
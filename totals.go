@@ -0,0 +1,184 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// totalsPageSize is the page size of history paged to aggregate totals
+// if the server can not aggregate them.
+const totalsPageSize = 100
+
+// FlowTotal is the aggregate of the balance changes of one direction.
+type FlowTotal struct {
+	// Count is the number of changes.
+	Count int64 `json:"count"`
+
+	// Sum is the sum of absolute amounts of changes.
+	Sum decimal.Decimal `json:"sum"`
+}
+
+// Totals is the aggregate of deposits and withdrawals of the asset over
+// the time range.
+type Totals struct {
+	// Asset is the asset of the totals.
+	Asset string
+
+	// From is the inclusive start of the range.
+	From time.Time
+
+	// To is the exclusive end of the range.
+	To time.Time
+
+	// Deposits is the total of deposits.
+	Deposits FlowTotal
+
+	// Withdrawals is the total of withdrawals.
+	Withdrawals FlowTotal
+}
+
+// balanceTotalsRequestVariables is a query variables used in request
+// in client DepositTotals method.
+type balanceTotalsRequestVariables struct {
+	Asset string  `json:"asset"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+}
+
+// DepositTotals returns count and sum of deposits and withdrawals of the
+// asset registered in [from, to). Totals are aggregated by the server if
+// it supports it, otherwise the whole history is paged and aggregated
+// locally.
+func (c *Client) DepositTotals(asset string, from,
+	to time.Time) (Totals, error) {
+
+	if !from.Before(to) {
+		return Totals{}, errors.New("from should be before to")
+	}
+
+	totals, err := c.serverTotals(asset, from, to)
+	var exchangeErr *ExchangeError
+	if err == nil || !errors.As(err, &exchangeErr) {
+		return totals, err
+	}
+
+	// Server rejected the aggregation query, most likely it doesn't
+	// support it.
+	return c.pagedTotals(asset, from, to)
+}
+
+// serverTotals requests totals aggregated by the server.
+func (c *Client) serverTotals(asset string, from,
+	to time.Time) (Totals, error) {
+
+	totals := Totals{Asset: asset, From: from, To: to}
+
+	var req request
+
+	req.Query = `
+		query BalanceUpdateTotals($asset: Asset!, $from: Float!,
+			$to: Float!) {
+			balanceUpdateTotals(asset: $asset, from: $from, to: $to) {
+				deposits {
+					count
+					sum
+				}
+				withdrawals {
+					count
+					sum
+				}
+			}
+		}
+	`
+
+	req.Variables = balanceTotalsRequestVariables{
+		Asset: c.assets.asset(asset),
+		From:  unixSeconds(from),
+		To:    unixSeconds(to),
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Totals struct {
+				Deposits    FlowTotal `json:"deposits"`
+				Withdrawals FlowTotal `json:"withdrawals"`
+			} `json:"balanceUpdateTotals"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return totals, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return totals, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return totals, exchangeError(err)
+	}
+
+	totals.Deposits = resp.Data.Totals.Deposits
+	totals.Withdrawals = resp.Data.Totals.Withdrawals
+
+	return totals, nil
+}
+
+// pagedTotals pages deposits and withdrawals history and aggregates the
+// records in the range.
+func (c *Client) pagedTotals(asset string, from,
+	to time.Time) (Totals, error) {
+
+	totals := Totals{Asset: asset, From: from, To: to}
+
+	add := func(total *FlowTotal, t float64, change decimal.Decimal) {
+		if at := unixTime(t); !at.Before(from) && at.Before(to) {
+			total.Count++
+			total.Sum = total.Sum.Add(change.Abs())
+		}
+	}
+
+	for offset := int64(0); ; offset += totalsPageSize {
+		page, err := c.DepositsPage(asset, offset, totalsPageSize)
+		if err != nil {
+			return totals, err
+		}
+		for _, d := range page.Items {
+			add(&totals.Deposits, d.Time, d.Change)
+		}
+		if !page.HasNext {
+			break
+		}
+	}
+
+	for offset := int64(0); ; offset += totalsPageSize {
+		page, err := c.WithdrawalsPage(asset, offset, totalsPageSize)
+		if err != nil {
+			return totals, err
+		}
+		for _, w := range page.Items {
+			add(&totals.Withdrawals, w.Time, w.Change)
+		}
+		if !page.HasNext {
+			break
+		}
+	}
+
+	return totals, nil
+}
+
+// unixSeconds returns the time as fractional unix seconds, which is
+// how the server represents time.
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// unixTime returns the time of fractional unix seconds.
+func unixTime(sec float64) time.Time {
+	return time.Unix(0, int64(sec*float64(time.Second)))
+}
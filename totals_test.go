@@ -0,0 +1,120 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_DepositTotals(t *testing.T) {
+	from := time.Unix(1000, 0)
+	to := time.Unix(2000, 0)
+
+	t.Run("server side", func(t *testing.T) {
+		backend := &mockCore{respJSON: `{"data": {"balanceUpdateTotals": {
+			"deposits": {"count": 3, "sum": "1.5"},
+			"withdrawals": {"count": 1, "sum": "0.2"}
+		}}}`}
+		client := &Client{core: backend}
+
+		totals, err := client.DepositTotals("BTC", from, to)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if totals.Deposits.Count != 3 || !totals.Deposits.Sum.Equal(dec(1.5)) ||
+			totals.Withdrawals.Count != 1 ||
+			!totals.Withdrawals.Sum.Equal(dec(0.2)) {
+			t.Errorf("want server totals but got `%v`", totals)
+		}
+
+		want := balanceTotalsRequestVariables{Asset: "BTC", From: 1000,
+			To: 2000}
+		if backend.request.Variables != want {
+			t.Errorf("want variables `%v` but got `%v`", want,
+				backend.request.Variables)
+		}
+	})
+
+	t.Run("paged", func(t *testing.T) {
+		// Deposits span two pages, record at 2000 is out of range.
+		var deposits []string
+		for i := 0; i < totalsPageSize; i++ {
+			deposits = append(deposits, `{"time": 500, "change": "1"}`)
+		}
+		deposits = append(deposits,
+			`{"time": 1000, "change": "1"}`,
+			`{"time": 1500.5, "change": "0.5"}`,
+			`{"time": 2000, "change": "1"}`)
+
+		page := func(records []string, offset int64) []byte {
+			end := offset + totalsPageSize + 1
+			if end > int64(len(records)) {
+				end = int64(len(records))
+			}
+			return []byte(`{"data": {"balanceUpdateRecords": [` +
+				strings.Join(records[offset:end], ",") + `]}}`)
+		}
+
+		var requests int
+		backend := CoreFunc(func(query string,
+			variables interface{}) ([]byte, error) {
+
+			requests++
+			switch v := variables.(type) {
+			case depositRequestVariables:
+				return page(deposits, v.Offset), nil
+			case withdrawalsRequestVariables:
+				return page([]string{
+					`{"time": 1200, "change": "-0.3"}`,
+				}, v.Offset), nil
+			}
+			return []byte(`{"errors": [{"message": "Cannot query field` +
+				` balanceUpdateTotals"}]}`), nil
+		})
+		client := &Client{core: backend}
+
+		totals, err := client.DepositTotals("BTC", from, to)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if totals.Deposits.Count != 2 || !totals.Deposits.Sum.Equal(dec(1.5)) {
+			t.Errorf("want 2 deposits of 1.5 but got `%v`", totals.Deposits)
+		}
+		if totals.Withdrawals.Count != 1 ||
+			!totals.Withdrawals.Sum.Equal(dec(0.3)) {
+			t.Errorf("want 1 withdrawal of 0.3 but got `%v`",
+				totals.Withdrawals)
+		}
+		if requests != 4 {
+			t.Errorf("want 4 requests but got %v", requests)
+		}
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		client := &Client{core: &mockCore{error: errors.New("refused")}}
+		_, err := client.DepositTotals("BTC", from, to)
+		var transportErr *TransportError
+		if !errors.As(err, &transportErr) {
+			t.Errorf("want transport error but got `%v`", err)
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		client := &Client{core: &mockCore{}}
+		if _, err := client.DepositTotals("BTC", to, from); err == nil {
+			t.Error("want error but got nil")
+		}
+	})
+}
+
+func TestUnixTime(t *testing.T) {
+	at := time.Unix(1500, int64(500*time.Millisecond))
+	if got := unixTime(unixSeconds(at)); !got.Equal(at) {
+		t.Errorf("want %v but got %v", at, got)
+	}
+	if s := fmt.Sprint(unixSeconds(at)); s != "1500.5" {
+		t.Errorf("want 1500.5 seconds but got %v", s)
+	}
+}
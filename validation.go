@@ -0,0 +1,141 @@
+package client
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// ValidationError is returned if the order or withdrawal violates the
+// market or asset limits. It is detected locally before the request is
+// sent, see WithValidation.
+type ValidationError struct {
+	// Subject is the market of the order or the asset of the
+	// withdrawal.
+	Subject string
+
+	// Field is the name of the violating field, e.g. "amount".
+	Field string
+
+	// Value is the value of the field after rounding.
+	Value decimal.Decimal
+
+	// Reason describes the violation.
+	Reason string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return "invalid " + e.Field + " " + e.Value.String() + " of " +
+		e.Subject + ": " + e.Reason
+}
+
+// WithValidation makes the client validate orders and withdrawals
+// against market and asset metadata before sending them. Order amounts
+// and prices are rounded to the market precision and checked against
+// its limits, withdrawal amounts are rounded to the asset precision,
+// see LookupAssetInfo. Violations are reported with ValidationError.
+// Market metadata is requested with SupportedMarkets and cached, see
+// WithSupportedMarketsCache.
+func WithValidation() Option {
+	return func(o *options) {
+		o.validation = true
+	}
+}
+
+// validateOrder rounds the order amount down and the price to the
+// less aggressive side, i.e. bids down and asks up, to the market
+// precision and checks the amount limits. It returns the intent as is
+// if validation is disabled.
+func (c *Client) validateOrder(intent OrderIntent) (OrderIntent, error) {
+	if !c.validation {
+		return intent, nil
+	}
+
+	markets, err := c.SupportedMarkets(c.context())
+	if err != nil {
+		return intent, err
+	}
+
+	var (
+		info  MarketInfo
+		found bool
+	)
+	for _, m := range markets {
+		if m.Market == intent.Market {
+			info, found = m, true
+			break
+		}
+	}
+	if !found {
+		return intent, &ValidationError{
+			Subject: intent.Market,
+			Field:   "market",
+			Reason:  "market is not supported",
+		}
+	}
+
+	fail := func(field string, value decimal.Decimal,
+		reason string) (OrderIntent, error) {
+
+		return intent, &ValidationError{
+			Subject: intent.Market,
+			Field:   field,
+			Value:   value,
+			Reason:  reason,
+		}
+	}
+
+	amount := intent.Amount.Truncate(info.AmountPrecision)
+	switch {
+	case amount.Sign() <= 0:
+		return fail("amount", amount, "amount is zero after rounding to "+
+			"market precision")
+	case amount.LessThan(info.MinAmount):
+		return fail("amount", amount, "amount is less than minimum "+
+			info.MinAmount.String())
+	case info.MaxAmount.Sign() > 0 && amount.GreaterThan(info.MaxAmount):
+		return fail("amount", amount, "amount exceeds maximum "+
+			info.MaxAmount.String())
+	}
+	intent.Amount = amount
+
+	if !intent.IsMarket() {
+		price := roundPrice(intent.Price, info.PricePrecision,
+			intent.Side == OrderAsk)
+		if price.Sign() <= 0 {
+			return fail("price", price, "price is zero after rounding "+
+				"to market precision")
+		}
+		intent.Price = price
+	}
+
+	return intent, nil
+}
+
+// validateWithdrawal rounds the withdrawal amount down to the asset
+// precision. It returns the amount as is if validation is disabled.
+func (c *Client) validateWithdrawal(asset string,
+	amount decimal.Decimal) (decimal.Decimal, error) {
+
+	if !c.validation {
+		return amount, nil
+	}
+
+	rounded := amount.Truncate(LookupAssetInfo(asset).Precision)
+	if rounded.Sign() <= 0 {
+		return amount, &ValidationError{
+			Subject: asset,
+			Field:   "amount",
+			Value:   rounded,
+			Reason:  "amount is zero after rounding to asset precision",
+		}
+	}
+
+	return rounded, nil
+}
+
+// roundPrice rounds the price to the precision, up or down.
+func roundPrice(price decimal.Decimal, precision int32,
+	up bool) decimal.Decimal {
+
+	return roundToTick(price, decimal.New(1, -precision), up)
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestClient_validateOrder(t *testing.T) {
+	backend := CoreFunc(func(query string,
+		variables interface{}) ([]byte, error) {
+
+		return []byte(`{"data": {"supportedMarkets": [
+			{"market": "BTCETH", "pricePrecision": 2,
+				"amountPrecision": 3, "minAmount": "0.01",
+				"maxAmount": "100"}
+		]}}`), nil
+	})
+	client := &Client{
+		core:         backend,
+		validation:   true,
+		marketsCache: newMarketsCache(time.Minute),
+	}
+
+	d := decimal.RequireFromString
+
+	tests := []struct {
+		name       string
+		intent     OrderIntent
+		wantAmount string
+		wantPrice  string
+		wantField  string
+	}{
+		{
+			name: "rounded bid",
+			intent: OrderIntent{Market: "BTCETH", Side: OrderBid,
+				Amount: d("1.23456"), Price: d("0.12345")},
+			wantAmount: "1.234",
+			wantPrice:  "0.12",
+		},
+		{
+			name: "rounded ask",
+			intent: OrderIntent{Market: "BTCETH", Side: OrderAsk,
+				Amount: d("1"), Price: d("0.12345")},
+			wantAmount: "1",
+			wantPrice:  "0.13",
+		},
+		{
+			name: "below minimum",
+			intent: OrderIntent{Market: "BTCETH", Side: OrderAsk,
+				Amount: d("0.0099")},
+			wantField: "amount",
+		},
+		{
+			name: "zero after rounding",
+			intent: OrderIntent{Market: "BTCETH", Side: OrderAsk,
+				Amount: d("0.0001")},
+			wantField: "amount",
+		},
+		{
+			name: "above maximum",
+			intent: OrderIntent{Market: "BTCETH", Side: OrderAsk,
+				Amount: d("100.001")},
+			wantField: "amount",
+		},
+		{
+			name: "price zero after rounding",
+			intent: OrderIntent{Market: "BTCETH", Side: OrderBid,
+				Amount: d("1"), Price: d("0.001")},
+			wantField: "price",
+		},
+		{
+			name: "unknown market",
+			intent: OrderIntent{Market: "BTCXRP", Side: OrderBid,
+				Amount: d("1")},
+			wantField: "market",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intent, err := client.validateOrder(tt.intent)
+			if tt.wantField != "" {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("want validation error but got `%v`", err)
+				}
+				if validationErr.Field != tt.wantField {
+					t.Errorf("want %v violated but got %v", tt.wantField,
+						validationErr.Field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want no error but got `%v`", err)
+			}
+			if !intent.Amount.Equal(d(tt.wantAmount)) {
+				t.Errorf("want amount %v but got %v", tt.wantAmount,
+					intent.Amount)
+			}
+			if !intent.Price.Equal(d(tt.wantPrice)) {
+				t.Errorf("want price %v but got %v", tt.wantPrice,
+					intent.Price)
+			}
+		})
+	}
+}
+
+func TestClient_PlaceOrderValidation(t *testing.T) {
+	var orders []createOrderRequestVariables
+	backend := CoreFunc(func(query string,
+		variables interface{}) ([]byte, error) {
+
+		if strings.Contains(query, "supportedMarkets") {
+			return []byte(supportedMarketsJSON), nil
+		}
+		orders = append(orders, variables.(createOrderRequestVariables))
+		return []byte(`{"data": {"createMarketOrder": {"id": 1}}}`), nil
+	})
+	client := &Client{core: backend, validation: true}
+
+	if _, err := client.CreateOrderBid("BTCETH",
+		decimal.RequireFromString("0.123456")); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if len(orders) != 1 || !orders[0].Amount.Equal(dec(0.1234)) {
+		t.Errorf("want order amount rounded to 0.1234 but got `%v`", orders)
+	}
+
+	_, err := client.CreateOrderBid("BTCETH", dec(0.001))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("want validation error but got `%v`", err)
+	}
+	if len(orders) != 1 {
+		t.Error("want invalid order not sent")
+	}
+}
+
+func TestClient_validateWithdrawal(t *testing.T) {
+	client := &Client{validation: true}
+
+	amount, err := client.validateWithdrawal("BTC",
+		decimal.RequireFromString("0.123456789"))
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !amount.Equal(decimal.RequireFromString("0.12345678")) {
+		t.Errorf("want amount rounded to 0.12345678 but got %v", amount)
+	}
+
+	_, err = client.validateWithdrawal("BTC",
+		decimal.RequireFromString("0.000000001"))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("want validation error but got `%v`", err)
+	}
+
+	unvalidated := &Client{}
+	amount, _ = unvalidated.validateWithdrawal("BTC",
+		decimal.RequireFromString("0.123456789"))
+	if !amount.Equal(decimal.RequireFromString("0.123456789")) {
+		t.Errorf("want amount unchanged without validation but got %v",
+			amount)
+	}
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceChange is the change of the account balance field.
+type BalanceChange struct {
+	Old decimal.Decimal
+	New decimal.Decimal
+}
+
+// Delta returns the difference between new and old values.
+func (c BalanceChange) Delta() decimal.Decimal {
+	return c.New.Sub(c.Old)
+}
+
+// AddressChange is the change of the account deposit address.
+type AddressChange struct {
+	Old string
+	New string
+}
+
+// AccountDelta is the change of the account between two polls. Only
+// changed fields are set, unchanged ones are nil or empty.
+type AccountDelta struct {
+	// Asset is the asset of the account.
+	Asset string
+
+	// Available is the change of the funds which can be used in
+	// trading.
+	Available *BalanceChange
+
+	// Freezed is the change of the funds occupied in trades.
+	Freezed *BalanceChange
+
+	// Pending is the change of the funds awaiting confirmation.
+	Pending *BalanceChange
+
+	// Address is the change of the deposit address, e.g. because the
+	// exchange rotated it.
+	Address *AddressChange
+
+	// NewTransactions is the pending transactions which appeared since
+	// the previous poll.
+	NewTransactions []Transaction
+
+	// GoneTransactions is the IDs of pending transactions which
+	// disappeared since the previous poll, usually because they got
+	// enough confirmations and were enrolled.
+	GoneTransactions []string
+}
+
+// WatchAccounts polls Accounts of the assets every interval and emits
+// deltas of changed accounts only. Accounts are requested once before
+// returning to establish the baseline, its error is returned. The
+// channel is closed once ctx is done, poll errors are skipped.
+func (c *Client) WatchAccounts(ctx context.Context, assets []string,
+	interval time.Duration) (<-chan AccountDelta, error) {
+
+	if interval <= 0 {
+		return nil, errors.New("interval should be positive")
+	}
+
+	client := c.WithContext(ctx)
+
+	accounts, err := client.Accounts(assets)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := make(map[string]Account, len(accounts))
+	for _, a := range accounts {
+		prev[a.Asset] = a
+	}
+
+	deltas := make(chan AccountDelta, c.subs.buffer())
+
+	go func() {
+		defer close(deltas)
+		defer c.errs.recover("accounts watcher")
+
+		for sleep(ctx, interval) && c.maintenance.wait(ctx) {
+			var (
+				accounts []Account
+				err      error
+			)
+			if perr := c.pool.Do(ctx, func() {
+				accounts, err = client.Accounts(assets)
+			}); perr != nil || err != nil {
+				continue
+			}
+
+			for _, a := range accounts {
+				delta, changed := accountDelta(prev[a.Asset], a)
+				prev[a.Asset] = a
+				if !changed {
+					continue
+				}
+
+				select {
+				case deltas <- delta:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return deltas, nil
+}
+
+// accountDelta returns delta between previous and current account states and
+// whether anything has changed.
+func accountDelta(prev, cur Account) (AccountDelta, bool) {
+	d := AccountDelta{Asset: cur.Asset}
+	changed := false
+
+	balance := func(from, to decimal.Decimal) *BalanceChange {
+		if from.Equal(to) {
+			return nil
+		}
+		changed = true
+		return &BalanceChange{Old: from, New: to}
+	}
+
+	d.Available = balance(prev.Available, cur.Available)
+	d.Freezed = balance(prev.Freezed, cur.Freezed)
+	d.Pending = balance(prev.Pending.Amount, cur.Pending.Amount)
+
+	if prev.Address != cur.Address {
+		d.Address = &AddressChange{Old: prev.Address, New: cur.Address}
+		changed = true
+	}
+
+	prevTxs := make(map[string]struct{}, len(prev.Pending.Transactions))
+	for _, tx := range prev.Pending.Transactions {
+		prevTxs[tx.TxID] = struct{}{}
+	}
+
+	curTxs := make(map[string]struct{}, len(cur.Pending.Transactions))
+	for _, tx := range cur.Pending.Transactions {
+		curTxs[tx.TxID] = struct{}{}
+		if _, ok := prevTxs[tx.TxID]; !ok {
+			d.NewTransactions = append(d.NewTransactions, tx)
+			changed = true
+		}
+	}
+
+	for _, tx := range prev.Pending.Transactions {
+		if _, ok := curTxs[tx.TxID]; !ok {
+			d.GoneTransactions = append(d.GoneTransactions, tx.TxID)
+			changed = true
+		}
+	}
+
+	return d, changed
+}
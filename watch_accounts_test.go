@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAccountDelta(t *testing.T) {
+	old := Account{
+		Asset:     "BTC",
+		Available: dec(1),
+		Freezed:   dec(0.5),
+		Pending: PendingInfo{
+			Amount:       dec(0.1),
+			Transactions: []Transaction{{TxID: "a"}},
+		},
+	}
+
+	t.Run("when nothing changed", func(t *testing.T) {
+		if _, changed := accountDelta(old, old); changed {
+			t.Error("want no changes but got changed")
+		}
+	})
+	t.Run("when transaction enrolled", func(t *testing.T) {
+		new := old
+		new.Available = dec(1.1)
+		new.Pending = PendingInfo{
+			Amount:       dec(0.2),
+			Transactions: []Transaction{{TxID: "b"}},
+		}
+
+		d, changed := accountDelta(old, new)
+		if !changed {
+			t.Fatal("want changes but got nothing changed")
+		}
+		if d.Available == nil || !d.Available.Delta().Equal(dec(0.1)) {
+			t.Errorf("want available delta 0.1 but got `%v`", d.Available)
+		}
+		if d.Freezed != nil {
+			t.Errorf("want no freezed change but got `%v`", d.Freezed)
+		}
+		if d.Pending == nil || !d.Pending.New.Equal(dec(0.2)) {
+			t.Errorf("want pending change to 0.2 but got `%v`", d.Pending)
+		}
+		if len(d.NewTransactions) != 1 || d.NewTransactions[0].TxID != "b" {
+			t.Errorf("want new transaction b but got `%v`",
+				d.NewTransactions)
+		}
+		if len(d.GoneTransactions) != 1 || d.GoneTransactions[0] != "a" {
+			t.Errorf("want gone transaction a but got `%v`",
+				d.GoneTransactions)
+		}
+	})
+	t.Run("when address rotated", func(t *testing.T) {
+		new := old
+		new.Address = "new-address"
+
+		d, changed := accountDelta(old, new)
+		if !changed {
+			t.Fatal("want changes but got nothing changed")
+		}
+		if d.Address == nil || d.Address.New != "new-address" {
+			t.Errorf("want address change but got `%v`", d.Address)
+		}
+	})
+}
+
+func TestClient_WatchAccounts(t *testing.T) {
+	var (
+		mtx   sync.Mutex
+		polls int
+	)
+	responses := []string{
+		`{ "data": { "accounts": [{ "asset": "BTC", "available": "1" },
+			{ "asset": "ETH", "available": "1" }] } }`,
+		`{ "data": { "accounts": [{ "asset": "BTC", "available": "1" },
+			{ "asset": "ETH", "available": "1" }] } }`,
+		`{ "data": { "accounts": [{ "asset": "BTC", "available": "1" },
+			{ "asset": "ETH", "available": "2" }] } }`,
+	}
+
+	client := &Client{
+		core: CoreFunc(func(string, interface{}) ([]byte, error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			resp := responses[len(responses)-1]
+			if polls < len(responses) {
+				resp = responses[polls]
+			}
+			polls++
+			return []byte(resp), nil
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deltas, err := client.WatchAccounts(ctx, []string{"BTC", "ETH"},
+		time.Millisecond)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	select {
+	case d := <-deltas:
+		if d.Asset != "ETH" || d.Available == nil ||
+			!d.Available.New.Equal(dec(2)) {
+			t.Errorf("want ETH available change to 2 but got `%#v`", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want delta but got timeout")
+	}
+
+	cancel()
+	for range deltas {
+	}
+}
@@ -0,0 +1,256 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WatchSettings is the settings of the market in the watchlist.
+type WatchSettings struct {
+	// Tickers makes the watcher deliver ticker updates of the market.
+	Tickers bool
+
+	// Deals makes the watcher deliver new deals of the market.
+	Deals bool
+
+	// Depth makes the watcher maintain the order book of the market,
+	// see Watcher.Book.
+	Depth bool
+
+	// PollInterval is the interval between polls of the market if its
+	// subscriptions fall back to long polling, zero means the interval
+	// of the client subscriptions, see WithSubscriptions.
+	PollInterval time.Duration
+}
+
+// Watchlist is the set of watched markets along with their settings.
+type Watchlist map[string]WatchSettings
+
+// WatchEvent is the update of the watched market.
+type WatchEvent struct {
+	// Market is the market of the update.
+	Market string
+
+	// Data is either Ticker or MarketDeal.
+	Data interface{}
+}
+
+// Watcher watches markets of the watchlist, which may be replaced at
+// runtime, e.g. when markets are listed or delisted intraday. It is
+// safe for concurrent use.
+type Watcher struct {
+	client *Client
+	ctx    context.Context
+	events chan WatchEvent
+
+	mtx     sync.Mutex
+	list    Watchlist
+	markets map[string]*watchedMarket
+}
+
+// watchedMarket is the running subscriptions of the watched market.
+type watchedMarket struct {
+	settings WatchSettings
+	cancel   context.CancelFunc
+	book     *OrderBookStream
+
+	// done is closed once all subscriptions of the market are stopped.
+	done chan struct{}
+}
+
+// Watch starts watching markets of the watchlist until ctx is done. The
+// events channel is closed afterwards, see Watcher.Events.
+func (c *Client) Watch(ctx context.Context, list Watchlist) (*Watcher,
+	error) {
+
+	w := &Watcher{
+		client:  c,
+		ctx:     ctx,
+		events:  make(chan WatchEvent, c.subs.buffer()),
+		markets: make(map[string]*watchedMarket),
+	}
+
+	if err := w.SetWatchlist(list); err != nil {
+		w.stop()
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		w.stop()
+	}()
+
+	return w, nil
+}
+
+// Events returns the channel of updates of the watched markets. It is
+// closed once the watcher context is done.
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Watchlist returns the copy of the current watchlist.
+func (w *Watcher) Watchlist() Watchlist {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	list := make(Watchlist, len(w.list))
+	for market, settings := range w.list {
+		list[market] = settings
+	}
+	return list
+}
+
+// Book returns the order book of the market which is watched with
+// Depth, false is returned otherwise.
+func (w *Watcher) Book(market string) (*OrderBookStream, bool) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	m, ok := w.markets[market]
+	if !ok || m.book == nil {
+		return nil, false
+	}
+	return m.book, true
+}
+
+// SetWatchlist replaces the watchlist. Subscriptions of removed markets
+// are stopped, subscriptions of added markets are started and markets
+// which settings changed are resubscribed, while unchanged markets keep
+// their subscriptions. If subscribing fails, markets subscribed so far
+// keep running and the error is returned.
+func (w *Watcher) SetWatchlist(list Watchlist) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.ctx.Err() != nil {
+		return errors.New("watcher is stopped")
+	}
+
+	for market, m := range w.markets {
+		if settings, ok := list[market]; !ok || settings != m.settings {
+			m.cancel()
+			<-m.done
+			delete(w.markets, market)
+		}
+	}
+
+	w.list = make(Watchlist, len(list))
+	for market, settings := range list {
+		if _, ok := w.markets[market]; ok {
+			w.list[market] = settings
+			continue
+		}
+
+		m, err := w.watch(market, settings)
+		if err != nil {
+			return errors.New("failed to watch " + market + ": " +
+				err.Error())
+		}
+		w.markets[market] = m
+		w.list[market] = settings
+	}
+
+	return nil
+}
+
+// watch starts subscriptions of the market.
+func (w *Watcher) watch(market string,
+	settings WatchSettings) (*watchedMarket, error) {
+
+	ctx, cancel := context.WithCancel(
+		withPollInterval(w.ctx, settings.PollInterval))
+	client := w.client.WithContext(ctx)
+
+	m := &watchedMarket{
+		settings: settings,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	forward := func(events <-chan WatchEvent) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range events {
+				select {
+				case w.events <- e:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	fail := func(err error) (*watchedMarket, error) {
+		cancel()
+		wg.Wait()
+		return nil, err
+	}
+
+	if settings.Tickers {
+		tickers, err := client.SubscribeTickers([]string{market})
+		if err != nil {
+			return fail(err)
+		}
+		events := make(chan WatchEvent)
+		go func() {
+			defer close(events)
+			for t := range tickers {
+				events <- WatchEvent{Market: market, Data: t}
+			}
+		}()
+		forward(events)
+	}
+
+	if settings.Deals {
+		deals, err := client.SubscribeDeals([]string{market})
+		if err != nil {
+			return fail(err)
+		}
+		events := make(chan WatchEvent)
+		go func() {
+			defer close(events)
+			for d := range deals {
+				events <- WatchEvent{Market: market, Data: d}
+			}
+		}()
+		forward(events)
+	}
+
+	if settings.Depth {
+		book, err := client.SubscribeDepth(market)
+		if err != nil {
+			return fail(err)
+		}
+		m.book = book
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-book.Done()
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(m.done)
+	}()
+
+	return m, nil
+}
+
+// stop stops subscriptions of all markets and closes the events
+// channel.
+func (w *Watcher) stop() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	for market, m := range w.markets {
+		m.cancel()
+		<-m.done
+		delete(w.markets, market)
+	}
+	close(w.events)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Watch(t *testing.T) {
+	var dealID int32
+	client := &Client{
+		core: CoreFunc(func(query string, _ interface{}) ([]byte, error) {
+			if strings.Contains(query, "depth") {
+				return []byte(`{"data": {"depth": {
+					"asks": [{"price": "2", "volume": "1"}]}}}`), nil
+			}
+			id := atomic.AddInt32(&dealID, 1)
+			return []byte(fmt.Sprintf(`{"data": {"deals": [
+				{"id": %v, "market": "BTCETH"}]}}`, id)), nil
+		}),
+		subs: newSubscriptions(SubscriptionConfig{
+			PollInterval: time.Hour,
+		}, nil, nopMetrics{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := client.Watch(ctx, Watchlist{
+		"BTCETH": {Deals: true, PollInterval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	select {
+	case e := <-w.Events():
+		if _, ok := e.Data.(MarketDeal); !ok || e.Market != "BTCETH" {
+			t.Errorf("want deal of BTCETH but got `%v`", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want deal polled with market interval but got timeout")
+	}
+
+	if err := w.SetWatchlist(Watchlist{"BTCLTC": {Depth: true}}); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if _, ok := w.Book("BTCETH"); ok {
+		t.Error("want removed market not to be watched")
+	}
+	book, ok := w.Book("BTCLTC")
+	if !ok {
+		t.Fatal("want order book of added market")
+	}
+	if ask, ok := book.BestAsk(); !ok || !ask.Price.Equal(dec(2)) {
+		t.Errorf("want order book baseline but got `%v`", ask)
+	}
+
+	// Deals of the removed market are not delivered anymore.
+	delivered := atomic.LoadInt32(&dealID)
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt32(&dealID); n != delivered {
+		t.Errorf("want polling of removed market stopped but got %v polls",
+			n-delivered)
+	}
+
+	if list := w.Watchlist(); len(list) != 1 || !list["BTCLTC"].Depth {
+		t.Errorf("want new watchlist but got `%v`", list)
+	}
+
+	cancel()
+	for range w.Events() {
+	}
+	<-book.Done()
+
+	if err := w.SetWatchlist(Watchlist{}); err == nil {
+		t.Error("want error of stopped watcher")
+	}
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Webhook event types.
+const (
+	WebhookDeposit    = "deposit"
+	WebhookWithdrawal = "withdrawal"
+)
+
+// ErrDuplicateWebhook is returned by WebhookVerifier if the event with
+// the same payment ID has already been verified, e.g. webhook is
+// replayed or redelivered.
+var ErrDuplicateWebhook = errors.New("duplicate webhook")
+
+// WebhookEvent is a deposit or withdrawal event delivered by the
+// exchange webhook.
+type WebhookEvent struct {
+	// Type is the event type, either deposit or withdrawal.
+	Type string `json:"type"`
+
+	// Asset is the asset of the payment.
+	Asset string `json:"asset"`
+
+	// PaymentID is system specific payment operation ID, transaction ID
+	// in blockchain or payment hash in lightning network.
+	PaymentID string `json:"paymentID"`
+
+	// Change is an amount on which balance has been changed.
+	Change decimal.Decimal `json:"change"`
+
+	// Time when the payment was registered.
+	Time float64 `json:"time"`
+}
+
+// WebhookStore is the store of processed webhook events used for
+// deduplication, e.g. backed by database to survive restarts.
+type WebhookStore interface {
+	// MarkSeen marks the key as seen and returns true if it has
+	// already been marked. It should be atomic.
+	MarkSeen(key string) (bool, error)
+}
+
+// MemoryWebhookStore is in-memory WebhookStore.
+type MemoryWebhookStore struct {
+	mtx  sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewMemoryWebhookStore creates new empty in-memory store.
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		seen: make(map[string]struct{}),
+	}
+}
+
+// MarkSeen implements WebhookStore.
+func (s *MemoryWebhookStore) MarkSeen(key string) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, ok := s.seen[key]; ok {
+		return true, nil
+	}
+	s.seen[key] = struct{}{}
+	return false, nil
+}
+
+// WebhookVerifier verifies exchange webhooks: signature, timestamp
+// freshness and uniqueness of the payment.
+type WebhookVerifier struct {
+	// Signer verifies the signature and timestamp of the webhook
+	// signed in the same way as the client requests, see HMACSigner.
+	Signer *HMACSigner
+
+	// Store deduplicates events by payment ID, nil disables
+	// deduplication.
+	Store WebhookStore
+}
+
+// NewWebhookVerifier creates new verifier with given webhook secret and
+// deduplication store.
+func NewWebhookVerifier(secret []byte, store WebhookStore) *WebhookVerifier {
+	return &WebhookVerifier{
+		Signer: NewHMACSigner("", secret),
+		Store:  store,
+	}
+}
+
+// Verify reads webhook request body, verifies it and returns the event.
+// ErrSignatureMismatch, ErrTimestampOutOfWindow or ErrDuplicateWebhook
+// are returned if the webhook should be rejected.
+func (v *WebhookVerifier) Verify(r *http.Request) (WebhookEvent, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return WebhookEvent{}, errors.New("failed to read body: " +
+			err.Error())
+	}
+	return v.VerifyPayload(r.Header, body)
+}
+
+// VerifyPayload verifies webhook headers and body and returns the
+// event, see Verify.
+func (v *WebhookVerifier) VerifyPayload(header http.Header,
+	body []byte) (WebhookEvent, error) {
+
+	if err := v.Signer.Verify(header, body); err != nil {
+		return WebhookEvent{}, err
+	}
+
+	var e WebhookEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return WebhookEvent{}, errors.New("failed to json.Unmarshal " +
+			"event: " + err.Error())
+	}
+
+	if e.PaymentID == "" {
+		return WebhookEvent{}, errors.New("empty payment ID")
+	}
+
+	if v.Store != nil {
+		seen, err := v.Store.MarkSeen(e.Type + ":" + e.PaymentID)
+		if err != nil {
+			return WebhookEvent{}, errors.New("failed to mark event " +
+				"seen: " + err.Error())
+		}
+		if seen {
+			return WebhookEvent{}, ErrDuplicateWebhook
+		}
+	}
+
+	return e, nil
+}
+
+// ConfirmDeposit confirms deposit webhook event against account
+// deposits history, looking through the latest limit deposits. It
+// returns true if the deposit with the same payment ID and amount is
+// found.
+func (c *Client) ConfirmDeposit(e WebhookEvent, limit int64) (bool, error) {
+	if e.Type != WebhookDeposit {
+		return false, errors.New("not a deposit event")
+	}
+
+	deposits, err := c.Deposits(e.Asset, 0, limit)
+	if err != nil {
+		return false, err
+	}
+
+	for _, d := range deposits {
+		if d.PaymentID == e.PaymentID && d.Change.Equal(e.Change) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
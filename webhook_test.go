@@ -0,0 +1,93 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWebhookVerifier_VerifyPayload(t *testing.T) {
+	secret := []byte("webhook secret")
+	body := []byte(`{ "type": "deposit", "asset": "BTC",
+		"paymentID": "tx1", "change": "0.1" }`)
+
+	sign := func(body []byte, at time.Time) http.Header {
+		signer := NewHMACSigner("", secret)
+		signer.now = func() time.Time { return at }
+		header := http.Header{}
+		if err := signer.Sign(header, body); err != nil {
+			t.Fatalf("failed to sign webhook: %v", err)
+		}
+		return header
+	}
+
+	t.Run("when valid webhook", func(t *testing.T) {
+		v := NewWebhookVerifier(secret, NewMemoryWebhookStore())
+		e, err := v.VerifyPayload(sign(body, time.Now()), body)
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if e.Type != WebhookDeposit || e.PaymentID != "tx1" ||
+			!e.Change.Equal(dec(0.1)) {
+			t.Errorf("want deposit tx1 event but got `%#v`", e)
+		}
+	})
+	t.Run("when replayed webhook", func(t *testing.T) {
+		v := NewWebhookVerifier(secret, NewMemoryWebhookStore())
+		header := sign(body, time.Now())
+		if _, err := v.VerifyPayload(header, body); err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		if _, err := v.VerifyPayload(header, body); err != ErrDuplicateWebhook {
+			t.Errorf("want ErrDuplicateWebhook but got `%v`", err)
+		}
+	})
+	t.Run("when stale webhook", func(t *testing.T) {
+		v := NewWebhookVerifier(secret, nil)
+		_, err := v.VerifyPayload(sign(body, time.Now().Add(-time.Hour)),
+			body)
+		if err != ErrTimestampOutOfWindow {
+			t.Errorf("want ErrTimestampOutOfWindow but got `%v`", err)
+		}
+	})
+	t.Run("when tampered webhook", func(t *testing.T) {
+		v := NewWebhookVerifier(secret, nil)
+		header := sign(body, time.Now())
+		tampered := []byte(`{ "type": "deposit", "asset": "BTC",
+			"paymentID": "tx1", "change": "100" }`)
+		if _, err := v.VerifyPayload(header, tampered); err != ErrSignatureMismatch {
+			t.Errorf("want ErrSignatureMismatch but got `%v`", err)
+		}
+	})
+}
+
+func TestClient_ConfirmDeposit(t *testing.T) {
+	client := &Client{core: &mockCore{respJSON: `{ "data": {
+		"balanceUpdateRecords": [{ "paymentID": "tx1", "change": "0.1" }]
+	} }`}}
+
+	ok, err := client.ConfirmDeposit(WebhookEvent{
+		Type:      WebhookDeposit,
+		Asset:     "BTC",
+		PaymentID: "tx1",
+		Change:    dec(0.1),
+	}, 10)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !ok {
+		t.Error("want deposit to be confirmed")
+	}
+
+	ok, err = client.ConfirmDeposit(WebhookEvent{
+		Type:      WebhookDeposit,
+		Asset:     "BTC",
+		PaymentID: "tx2",
+	}, 10)
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if ok {
+		t.Error("want unknown deposit not to be confirmed")
+	}
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// graphQLWSProtocol is the WebSocket subprotocol of GraphQL
+// subscriptions, see
+// https://github.com/apollographql/subscriptions-transport-ws.
+const graphQLWSProtocol = "graphql-ws"
+
+// GraphQL over WebSocket message types.
+const (
+	wsConnectionInit  = "connection_init"
+	wsConnectionAck   = "connection_ack"
+	wsConnectionError = "connection_error"
+	wsKeepAlive       = "ka"
+	wsStart           = "start"
+	wsStop            = "stop"
+	wsData            = "data"
+	wsError           = "error"
+	wsComplete        = "complete"
+)
+
+// wsSubscriptionID is the ID of the operation started over connection,
+// every subscription has its own connection.
+const wsSubscriptionID = "1"
+
+// wsMessage is the GraphQL over WebSocket protocol message.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsURL returns WebSocket URL of GraphQL endpoint given by http(s) URL.
+func wsURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "https://"):
+		return "wss://" + strings.TrimPrefix(url, "https://")
+	case strings.HasPrefix(url, "http://"):
+		return "ws://" + strings.TrimPrefix(url, "http://")
+	default:
+		return url
+	}
+}
+
+// wsDialer opens GraphQL subscription streams over WebSocket.
+type wsDialer struct {
+	url    string
+	core   *graphQLCore
+	dialer websocket.Dialer
+}
+
+// newWSDialer creates dialer of the WebSocket endpoint which
+// authenticates with credentials of the core and dials connections with
+// the transport if it is given.
+func newWSDialer(url string, core *graphQLCore,
+	transport http.RoundTripper) *wsDialer {
+
+	d := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: core.timeout,
+		Subprotocols:     []string{graphQLWSProtocol},
+	}
+	if t, ok := transport.(*http.Transport); ok {
+		d.Proxy = t.Proxy
+		d.NetDialContext = t.DialContext
+		d.TLSClientConfig = t.TLSClientConfig
+		if t.TLSHandshakeTimeout > 0 {
+			d.HandshakeTimeout = t.TLSHandshakeTimeout
+		}
+	}
+
+	return &wsDialer{
+		url:    url,
+		core:   core,
+		dialer: d,
+	}
+}
+
+// dial implements streamDialer. It connects to the endpoint, passes
+// the connection_init/connection_ack handshake and starts the
+// subscription.
+func (d *wsDialer) dial(ctx context.Context, r request) (eventStream,
+	error) {
+
+	header := http.Header{}
+	header.Set(ClientVersionHeader, Version)
+
+	conn, _, err := d.dialer.DialContext(ctx, d.url, header)
+	if err != nil {
+		return nil, errors.New("failed to dial websocket: " + err.Error())
+	}
+
+	s := newWSStream(conn)
+
+	// Handshake is bound to the context as reads block otherwise.
+	stop := closeOnDone(ctx, s)
+	err = d.handshake(s, r)
+	stop()
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// handshake initializes the connection and starts the subscription.
+func (d *wsDialer) handshake(s *wsStream, r request) error {
+	init, err := d.initPayload()
+	if err != nil {
+		return err
+	}
+
+	if err := s.write(wsMessage{Type: wsConnectionInit,
+		Payload: init}); err != nil {
+		return err
+	}
+
+	for {
+		var msg wsMessage
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			return errors.New("failed to read connection ack: " +
+				err.Error())
+		}
+
+		switch msg.Type {
+		case wsConnectionAck:
+		case wsKeepAlive:
+			continue
+		case wsConnectionError:
+			return errors.New("connection rejected: " +
+				string(msg.Payload))
+		default:
+			return errors.New("unexpected message " + msg.Type +
+				" instead of connection ack")
+		}
+		break
+	}
+
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return errors.New("failed to json.Marshal request: " +
+			err.Error())
+	}
+
+	return s.write(wsMessage{ID: wsSubscriptionID, Type: wsStart,
+		Payload: payload})
+}
+
+// initPayload returns connection_init payload with authorization
+// headers of the core, empty payload if the core has no credentials
+// as public subscriptions don't need them.
+func (d *wsDialer) initPayload() (json.RawMessage, error) {
+	d.core.mtx.RLock()
+	hasCreds := d.core.macaroon != nil || d.core.jwt != "" ||
+		d.core.signer != nil
+	d.core.mtx.RUnlock()
+
+	payload := make(map[string]string)
+	if hasCreds {
+		req := &http.Request{Header: http.Header{}}
+		if err := d.core.authorize(req, nil); err != nil {
+			return nil, err
+		}
+		for key := range req.Header {
+			if key != "Content-Type" {
+				payload[key] = req.Header.Get(key)
+			}
+		}
+	}
+
+	return json.Marshal(payload)
+}
+
+// wsStream is the stream of the subscription events over WebSocket
+// connection.
+type wsStream struct {
+	conn *websocket.Conn
+
+	// writeMtx serializes writes as connection supports one concurrent
+	// writer.
+	writeMtx sync.Mutex
+
+	// pongs receives pong of every ping.
+	pongs chan struct{}
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// newWSStream creates stream over the connection.
+func newWSStream(conn *websocket.Conn) *wsStream {
+	s := &wsStream{
+		conn:  conn,
+		pongs: make(chan struct{}, 1),
+	}
+	conn.SetPongHandler(func(string) error {
+		select {
+		case s.pongs <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	return s
+}
+
+// write sends the message.
+func (s *wsStream) write(msg wsMessage) error {
+	s.writeMtx.Lock()
+	defer s.writeMtx.Unlock()
+
+	if err := s.conn.WriteJSON(msg); err != nil {
+		return errors.New("failed to write " + msg.Type + " message: " +
+			err.Error())
+	}
+	return nil
+}
+
+// next implements eventStream. It skips keepalive messages and returns
+// the data of the next subscription event.
+func (s *wsStream) next(ctx context.Context) (json.RawMessage, error) {
+	stop := closeOnDone(ctx, s)
+	defer stop()
+
+	for {
+		var msg wsMessage
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			return nil, err
+		}
+
+		switch msg.Type {
+		case wsData:
+			resp := struct {
+				responseBase
+				Data json.RawMessage `json:"data"`
+			}{}
+			if err := json.Unmarshal(msg.Payload, &resp); err != nil {
+				return nil, errors.New("failed to json.Unmarshal " +
+					"payload: " + err.Error())
+			}
+			if err := resp.Error(); err != nil {
+				return nil, exchangeError(err)
+			}
+			return resp.Data, nil
+		case wsError:
+			return nil, errors.New("subscription error: " +
+				string(msg.Payload))
+		case wsComplete:
+			return nil, io.EOF
+		}
+	}
+}
+
+// ping implements pinger.
+func (s *wsStream) ping(ctx context.Context) error {
+	s.writeMtx.Lock()
+	err := s.conn.WriteMessage(websocket.PingMessage, nil)
+	s.writeMtx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-s.pongs:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close implements eventStream. It stops the subscription and closes
+// the connection.
+func (s *wsStream) close() error {
+	s.closeOnce.Do(func() {
+		s.write(wsMessage{ID: wsSubscriptionID, Type: wsStop})
+		s.closeErr = s.conn.Close()
+	})
+	return s.closeErr
+}
+
+// closeOnDone closes the stream if ctx is done before returned stop
+// function is called, which unblocks pending reads.
+func closeOnDone(ctx context.Context, s *wsStream) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
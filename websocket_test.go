@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// mockWSServer is GraphQL over WebSocket server which serves every
+// connection with given function after the handshake.
+func mockWSServer(t *testing.T, reject bool,
+	serve func(conn *websocket.Conn, n int32)) (*httptest.Server,
+	chan string) {
+
+	var (
+		upgrader = websocket.Upgrader{
+			Subprotocols: []string{graphQLWSProtocol},
+		}
+		conns int32
+		auths = make(chan string, 10)
+	)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var init wsMessage
+		if err := conn.ReadJSON(&init); err != nil ||
+			init.Type != wsConnectionInit {
+			t.Errorf("want connection_init but got `%v`", init)
+			return
+		}
+		var payload map[string]string
+		json.Unmarshal(init.Payload, &payload)
+		auths <- payload["Authorization"]
+
+		if reject {
+			conn.WriteJSON(wsMessage{Type: wsConnectionError,
+				Payload: json.RawMessage(`{"message":"unauthorized"}`)})
+			return
+		}
+		conn.WriteJSON(wsMessage{Type: wsConnectionAck})
+
+		var start wsMessage
+		if err := conn.ReadJSON(&start); err != nil ||
+			start.Type != wsStart {
+			t.Errorf("want start but got `%v`", start)
+			return
+		}
+
+		serve(conn, atomic.AddInt32(&conns, 1))
+	}))
+
+	return s, auths
+}
+
+func TestWSURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:3000/graphql": "ws://localhost:3000/graphql",
+		"https://exchange.test/graphql": "wss://exchange.test/graphql",
+		"ws://exchange.test":            "ws://exchange.test",
+	}
+	for url, want := range tests {
+		if got := wsURL(url); got != want {
+			t.Errorf("want `%s` but got `%s`", want, got)
+		}
+	}
+}
+
+func TestClient_SubscribeTickers(t *testing.T) {
+	t.Run("when streamed over websocket", func(t *testing.T) {
+		s, auths := mockWSServer(t, false, func(conn *websocket.Conn,
+			n int32) {
+
+			conn.WriteJSON(wsMessage{Type: wsKeepAlive})
+			conn.WriteJSON(wsMessage{ID: wsSubscriptionID, Type: wsData,
+				Payload: json.RawMessage(`{"data": {"tickers": [
+					{"market": "BTCETH", "last": "1"}]}}`)})
+			if n == 1 {
+				// Connection breaks and should be reconnected.
+				return
+			}
+			conn.WriteJSON(wsMessage{ID: wsSubscriptionID, Type: wsData,
+				Payload: json.RawMessage(`{"data": {"tickers":
+					{"market": "BTCETH", "last": "2"}}}`)})
+
+			var stop wsMessage
+			conn.ReadJSON(&stop)
+		})
+		defer s.Close()
+
+		client, err := NewClient(s.URL, "", "token",
+			WithSubscriptions(SubscriptionConfig{
+				PollInterval: 10 * time.Millisecond,
+			}))
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			5*time.Second)
+		defer cancel()
+
+		tickers, err := client.WithContext(ctx).
+			SubscribeTickers([]string{"BTCETH"})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+
+		for _, want := range []float64{1, 1, 2} {
+			ticker, ok := <-tickers
+			if !ok {
+				t.Fatal("want ticker but channel is closed")
+			}
+			if ticker.Market != "BTCETH" || !ticker.Last.Equal(dec(want)) {
+				t.Errorf("want BTCETH ticker with last %v but got `%v`",
+					want, ticker)
+			}
+		}
+
+		if auth := <-auths; auth != "Bearer token" {
+			t.Errorf("want JWT in connection_init but got `%s`", auth)
+		}
+
+		cancel()
+		for range tickers {
+		}
+	})
+	t.Run("when connection is rejected", func(t *testing.T) {
+		s, _ := mockWSServer(t, true, nil)
+		defer s.Close()
+
+		core := &graphQLCore{url: s.URL, jwt: "token"}
+		dialer := newWSDialer(wsURL(s.URL), core, nil)
+		_, err := dialer.dial(context.Background(), request{
+			Query: "subscription { tickers }",
+		})
+		if err == nil || !strings.Contains(err.Error(), "rejected") {
+			t.Errorf("want connection rejected error but got `%v`", err)
+		}
+	})
+	t.Run("when stream pings", func(t *testing.T) {
+		s, _ := mockWSServer(t, false, func(conn *websocket.Conn,
+			n int32) {
+
+			// Control frames are handled while reading.
+			var msg wsMessage
+			conn.ReadJSON(&msg)
+		})
+		defer s.Close()
+
+		core := &graphQLCore{url: s.URL}
+		dialer := newWSDialer(wsURL(s.URL), core, nil)
+		stream, err := dialer.dial(context.Background(), request{
+			Query: "subscription { tickers }",
+		})
+		if err != nil {
+			t.Fatalf("want no error but got `%v`", err)
+		}
+		defer stream.close()
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			time.Second)
+		defer cancel()
+		go stream.next(ctx)
+
+		if err := stream.(pinger).ping(ctx); err != nil {
+			t.Errorf("want pong but got `%v`", err)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// withdrawalLimitWindow is the rolling window of the exchange daily
+// withdrawal limits.
+const withdrawalLimitWindow = 24 * time.Hour
+
+// ErrWithdrawalLimitExceeded is returned if withdrawal limits are
+// enforced and the withdrawal exceeds remaining daily allowance.
+var ErrWithdrawalLimitExceeded = errors.New("withdrawal limit exceeded")
+
+// WithWithdrawalLimits enables client-side tracking of withdrawn amounts
+// per asset in a rolling 24h window against given limits, which should
+// mirror exchange-side daily limits. If enforce is true withdrawals
+// exceeding the remaining allowance are rejected locally with
+// ErrWithdrawalLimitExceeded.
+func WithWithdrawalLimits(limits map[string]decimal.Decimal,
+	enforce bool) Option {
+
+	return func(o *options) {
+		o.withdrawalLimits = limits
+		o.enforceWithdrawalLimits = enforce
+	}
+}
+
+// withdrawalRecord is a single withdrawal registered by the tracker.
+type withdrawalRecord struct {
+	amount decimal.Decimal
+	time   time.Time
+}
+
+// WithdrawalTracker tracks how much has been withdrawn per asset in a
+// rolling 24h window. It is fed by the client withdrawals and may be
+// additionally fed from the withdrawal history, e.g. after restart.
+type WithdrawalTracker struct {
+	limits map[string]decimal.Decimal
+	now    func() time.Time
+
+	mtx     sync.Mutex
+	records map[string][]withdrawalRecord
+}
+
+// NewWithdrawalTracker creates new tracker with given per asset limits.
+func NewWithdrawalTracker(limits map[string]decimal.Decimal) *WithdrawalTracker {
+	l := make(map[string]decimal.Decimal, len(limits))
+	for asset, limit := range limits {
+		l[asset] = limit
+	}
+	return &WithdrawalTracker{
+		limits:  l,
+		now:     time.Now,
+		records: make(map[string][]withdrawalRecord),
+	}
+}
+
+// Track registers withdrawal of the amount of the asset at given time.
+// Withdrawals which are out of the window are ignored.
+func (t *WithdrawalTracker) Track(asset string, amount decimal.Decimal,
+	at time.Time) {
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !at.After(t.now().Add(-withdrawalLimitWindow)) {
+		return
+	}
+
+	t.records[asset] = append(t.records[asset], withdrawalRecord{
+		amount: amount.Abs(),
+		time:   at,
+	})
+}
+
+// Withdrawn returns the amount of the asset withdrawn within the window.
+func (t *WithdrawalTracker) Withdrawn(asset string) decimal.Decimal {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.withdrawn(asset)
+}
+
+// Remaining returns the amount of the asset which still may be
+// withdrawn within the window. It returns false if there is no limit
+// for the asset.
+func (t *WithdrawalTracker) Remaining(asset string) (decimal.Decimal, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	limit, ok := t.limits[asset]
+	if !ok {
+		return decimal.Zero, false
+	}
+
+	remaining := limit.Sub(t.withdrawn(asset))
+	if remaining.Sign() < 0 {
+		remaining = decimal.Zero
+	}
+	return remaining, true
+}
+
+// Check returns ErrWithdrawalLimitExceeded if withdrawal of the amount
+// exceeds remaining allowance of the asset or if the allowance is
+// exhausted. The latter is used if amount is not known beforehand, e.g.
+// for lightning withdrawals.
+func (t *WithdrawalTracker) Check(asset string, amount decimal.Decimal) error {
+	remaining, ok := t.Remaining(asset)
+	if !ok {
+		return nil
+	}
+	if remaining.Sign() == 0 || amount.Abs().GreaterThan(remaining) {
+		return ErrWithdrawalLimitExceeded
+	}
+	return nil
+}
+
+// withdrawn sums amounts of the asset within the window and drops
+// records which are out of it. Must be called with mutex held.
+func (t *WithdrawalTracker) withdrawn(asset string) decimal.Decimal {
+	since := t.now().Add(-withdrawalLimitWindow)
+
+	var (
+		records []withdrawalRecord
+		sum     = decimal.Zero
+	)
+	for _, r := range t.records[asset] {
+		if !r.time.After(since) {
+			continue
+		}
+		records = append(records, r)
+		sum = sum.Add(r.amount)
+	}
+	t.records[asset] = records
+
+	return sum
+}
+
+// WithdrawalTracker returns the tracker of withdrawn amounts, nil if
+// withdrawal limits are not configured. It may be used to feed the
+// tracker from the withdrawal history.
+func (c *Client) WithdrawalTracker() *WithdrawalTracker {
+	return c.withdrawals
+}
+
+// RemainingWithdrawalAllowance returns the amount of the asset which
+// still may be withdrawn within rolling 24h window according to
+// configured withdrawal limits.
+func (c *Client) RemainingWithdrawalAllowance(asset string) (decimal.Decimal,
+	error) {
+
+	if c.withdrawals == nil {
+		return decimal.Zero, errors.New("withdrawal limits are not " +
+			"configured")
+	}
+
+	remaining, ok := c.withdrawals.Remaining(asset)
+	if !ok {
+		return decimal.Zero, errors.New("no withdrawal limit for " +
+			"asset " + asset)
+	}
+
+	return remaining, nil
+}
+
+// checkWithdrawal checks the withdrawal against the limits if they are
+// enforced.
+func (c *Client) checkWithdrawal(asset string, amount decimal.Decimal) error {
+	if c.withdrawals == nil || !c.enforceWithdrawalLimits {
+		return nil
+	}
+	return c.withdrawals.Check(asset, amount)
+}
+
+// trackWithdrawal registers successful withdrawal if limits are
+// configured.
+func (c *Client) trackWithdrawal(asset string, amount decimal.Decimal) {
+	if c.withdrawals == nil || amount.Sign() == 0 {
+		return
+	}
+	c.withdrawals.Track(asset, amount, c.withdrawals.now())
+}
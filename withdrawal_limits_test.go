@@ -0,0 +1,97 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithdrawalTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewWithdrawalTracker(map[string]decimal.Decimal{
+		"BTC": dec(1),
+	})
+	tracker.now = func() time.Time { return now }
+
+	tracker.Track("BTC", dec(0.3), now.Add(-25*time.Hour))
+	tracker.Track("BTC", dec(-0.4), now.Add(-23*time.Hour))
+	tracker.Track("BTC", dec(0.2), now.Add(-time.Hour))
+
+	if got := tracker.Withdrawn("BTC"); !got.Equal(dec(0.6)) {
+		t.Errorf("want withdrawn 0.6 but got %v", got)
+	}
+
+	remaining, ok := tracker.Remaining("BTC")
+	if !ok {
+		t.Fatal("want limit for BTC")
+	}
+	if !remaining.Equal(dec(0.4)) {
+		t.Errorf("want remaining 0.4 but got %v", remaining)
+	}
+
+	if _, ok := tracker.Remaining("ETH"); ok {
+		t.Error("want no limit for ETH")
+	}
+
+	if err := tracker.Check("BTC", dec(0.5)); err != ErrWithdrawalLimitExceeded {
+		t.Errorf("want ErrWithdrawalLimitExceeded but got `%v`", err)
+	}
+	if err := tracker.Check("BTC", dec(0.4)); err != nil {
+		t.Errorf("want no error but got `%v`", err)
+	}
+	if err := tracker.Check("ETH", dec(100)); err != nil {
+		t.Errorf("want no error for asset without limit but got `%v`", err)
+	}
+
+	// Withdrawal made 23 hours ago leaves the window.
+	now = now.Add(2 * time.Hour)
+	remaining, _ = tracker.Remaining("BTC")
+	if !remaining.Equal(dec(0.8)) {
+		t.Errorf("want remaining 0.8 but got %v", remaining)
+	}
+}
+
+func TestClient_Withdraw_limits(t *testing.T) {
+	backend := &mockCore{
+		respJSON: `
+			{ "data": { "withdrawWithBlockchain": {
+				"paymentID": "some-id",
+				"paymentAddr": "some-address",
+				"change": "-0.6"
+			} } }
+		`,
+	}
+	client := &Client{
+		core: backend,
+		withdrawals: NewWithdrawalTracker(map[string]decimal.Decimal{
+			"BTC": dec(1),
+		}),
+		enforceWithdrawalLimits: true,
+	}
+
+	if _, err := client.Withdraw("BTC", dec(0.6), "some-address"); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	remaining, err := client.RemainingWithdrawalAllowance("BTC")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !remaining.Equal(dec(0.4)) {
+		t.Errorf("want remaining 0.4 but got %v", remaining)
+	}
+
+	backend.request = request{}
+	_, err = client.Withdraw("BTC", dec(0.6), "some-address")
+	if err != ErrWithdrawalLimitExceeded {
+		t.Fatalf("want ErrWithdrawalLimitExceeded but got `%v`", err)
+	}
+	if backend.request.Query != "" {
+		t.Error("want no request to exchange")
+	}
+
+	if _, err := (&Client{}).RemainingWithdrawalAllowance("BTC"); err == nil {
+		t.Error("want error if limits are not configured")
+	}
+}
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNoWithdrawalRail is returned by PlanWithdrawal if the amount can be
+// withdrawn neither on-chain nor with lightning network.
+var ErrNoWithdrawalRail = errors.New("no withdrawal rail is available")
+
+// Rail is the way funds are withdrawn.
+type Rail string
+
+// Withdrawal rails.
+const (
+	// RailOnChain withdraws with blockchain transaction, see Withdraw.
+	RailOnChain Rail = "onchain"
+
+	// RailLightning withdraws with lightning network payment, see
+	// LightningWithdraw.
+	RailLightning Rail = "lightning"
+)
+
+// WithdrawalFees is the fees of withdrawals of the asset.
+type WithdrawalFees struct {
+	// Blockchain is the fee of on-chain withdrawal.
+	Blockchain decimal.Decimal `json:"blockchain"`
+
+	// Lightning is the estimated routing fee of lightning withdrawal.
+	Lightning decimal.Decimal `json:"lightning"`
+}
+
+// withdrawalFeesRequestVariables is a query variables used in request
+// in client WithdrawalFees method.
+type withdrawalFeesRequestVariables struct {
+	Asset string `json:"asset"`
+}
+
+// WithdrawalFees returns the current fees of withdrawals of the asset.
+func (c *Client) WithdrawalFees(asset string) (WithdrawalFees, error) {
+	var req request
+
+	req.Query = `
+		query WithdrawalFees($asset: Asset!) {
+			withdrawalFees(asset: $asset) {
+				blockchain
+				lightning
+			}
+		}
+	`
+
+	req.Variables = withdrawalFeesRequestVariables{c.assets.asset(asset)}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Fees WithdrawalFees `json:"withdrawalFees"`
+		}
+	}{}
+
+	respJSON, err := c.do(false, req)
+	if err != nil {
+		return WithdrawalFees{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return WithdrawalFees{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return WithdrawalFees{}, exchangeError(err)
+	}
+
+	return resp.Data.Fees, nil
+}
+
+// WithdrawalPlan is the recommended way to withdraw the amount of the
+// asset, see PlanWithdrawal.
+type WithdrawalPlan struct {
+	// Asset is the asset of the withdrawal.
+	Asset string
+
+	// Amount is the amount of the withdrawal.
+	Amount decimal.Decimal
+
+	// Rail is the recommended rail.
+	Rail Rail
+
+	// Fee is the estimated fee of the withdrawal with the rail.
+	Fee decimal.Decimal
+
+	// Unavailable is the reasons the rails are not available for,
+	// empty if both are.
+	Unavailable map[Rail]string
+}
+
+// PlanWithdrawal recommends whether to withdraw the amount of the asset
+// on-chain or with lightning network. Lightning is available if the
+// exchange node operates the asset, is synced, has active channels and
+// the amount fits its payment limits, see Info; on-chain withdrawal is
+// available if the amount exceeds its fee, see WithdrawalFees. The
+// preferred rail is recommended if it is available, otherwise or if no
+// rail is preferred the cheaper one is. ErrNoWithdrawalRail is returned
+// if neither is available. The plan may be executed with
+// ExecuteWithdrawal.
+func (c *Client) PlanWithdrawal(asset string, amount decimal.Decimal,
+	prefer Rail) (WithdrawalPlan, error) {
+
+	if amount.Sign() <= 0 {
+		return WithdrawalPlan{}, errors.New("amount should be positive")
+	}
+
+	fees, err := c.WithdrawalFees(asset)
+	if err != nil {
+		return WithdrawalPlan{}, err
+	}
+
+	info, err := c.Info()
+	if err != nil {
+		return WithdrawalPlan{}, err
+	}
+
+	plan := WithdrawalPlan{
+		Asset:       asset,
+		Amount:      amount,
+		Unavailable: make(map[Rail]string),
+	}
+
+	if !amount.GreaterThan(fees.Blockchain) {
+		plan.Unavailable[RailOnChain] = "amount does not exceed fee " +
+			fees.Blockchain.String()
+	}
+	if reason := lightningUnavailable(info.Lightning, asset,
+		amount); reason != "" {
+		plan.Unavailable[RailLightning] = reason
+	}
+
+	available := func(r Rail) bool {
+		_, ok := plan.Unavailable[r]
+		return !ok
+	}
+	fee := map[Rail]decimal.Decimal{
+		RailOnChain:   fees.Blockchain,
+		RailLightning: fees.Lightning,
+	}
+
+	switch {
+	case prefer != "" && available(prefer):
+		plan.Rail = prefer
+	case available(RailOnChain) && available(RailLightning):
+		plan.Rail = RailLightning
+		if fees.Blockchain.LessThan(fees.Lightning) {
+			plan.Rail = RailOnChain
+		}
+	case available(RailOnChain):
+		plan.Rail = RailOnChain
+	case available(RailLightning):
+		plan.Rail = RailLightning
+	default:
+		return plan, ErrNoWithdrawalRail
+	}
+	plan.Fee = fee[plan.Rail]
+
+	return plan, nil
+}
+
+// lightningUnavailable returns the reason the amount of the asset can
+// not be withdrawn with the lightning node, empty if it can.
+func lightningUnavailable(node *LightningNodeInfo, asset string,
+	amount decimal.Decimal) string {
+
+	switch {
+	case node == nil || node.Asset != asset:
+		return "lightning network is not available for " + asset
+	case !node.SyncedToChain:
+		return "lightning node is not synced to chain"
+	case node.NumActiveChannels == 0:
+		return "lightning node has no active channels"
+	case amount.LessThan(node.MinAmount):
+		return "amount is less than minimum " + node.MinAmount.String()
+	case node.MaxAmount.Sign() > 0 && amount.GreaterThan(node.MaxAmount):
+		return "amount exceeds maximum " + node.MaxAmount.String()
+	}
+	return ""
+}
+
+// ExecuteWithdrawal withdraws according to the plan, to the address if
+// the plan rail is on-chain or paying the invoice if it is lightning.
+// The invoice amount should be the plan amount.
+func (c *Client) ExecuteWithdrawal(plan WithdrawalPlan, address,
+	invoice string) (Withdrawal, error) {
+
+	switch plan.Rail {
+	case RailOnChain:
+		if address == "" {
+			return Withdrawal{}, errors.New("address should be given")
+		}
+		return c.Withdraw(plan.Asset, plan.Amount, address)
+
+	case RailLightning:
+		decoded, err := c.DecodeLightningInvoice(plan.Asset, invoice)
+		if err != nil {
+			return Withdrawal{}, err
+		}
+		if !decoded.Amount.Equal(plan.Amount) {
+			return Withdrawal{}, errors.New("invoice amount " +
+				decoded.Amount.String() + " differs from plan amount " +
+				plan.Amount.String())
+		}
+
+		w, err := c.LightningWithdraw(plan.Asset, invoice)
+		return w.Withdrawal, err
+
+	default:
+		return Withdrawal{}, errors.New("unknown withdrawal rail: " +
+			string(plan.Rail))
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// withdrawalPlanCore returns the core serving withdrawal planning
+// queries with given fees and lightning node state.
+func withdrawalPlanCore(blockchainFee, lightningFee string, synced bool,
+	calls *[]string) CoreFunc {
+
+	return func(query string, _ interface{}) ([]byte, error) {
+		switch {
+		case strings.Contains(query, "withdrawalFees("):
+			return []byte(fmt.Sprintf(`{"data": {"withdrawalFees": {
+				"blockchain": %q, "lightning": %q}}}`, blockchainFee,
+				lightningFee)), nil
+		case strings.Contains(query, "info {"):
+			return []byte(fmt.Sprintf(`{"data": {"info": {"lightning": {
+				"asset": "BTC", "syncedToChain": %v, "numActiveChannels": 2,
+				"minAmount": "0.00001", "maxAmount": "0.04"}}}}`, synced)), nil
+		case strings.Contains(query, "decodeLightningInvoice("):
+			return []byte(`{"data": {"decodeLightningInvoice": {
+				"paymentHash": "hash", "amount": "0.01"}}}`), nil
+		case strings.Contains(query, "withdrawWithLightning("):
+			*calls = append(*calls, "lightning")
+			return []byte(`{"data": {"withdrawWithLightning": {
+				"paymentID": "hash"}}}`), nil
+		case strings.Contains(query, "withdrawWithBlockchain("):
+			*calls = append(*calls, "onchain")
+			return []byte(`{"data": {"withdrawWithBlockchain": {
+				"paymentID": "tx"}}}`), nil
+		}
+		return nil, fmt.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestClient_PlanWithdrawal(t *testing.T) {
+	tests := []struct {
+		name          string
+		blockchainFee string
+		lightningFee  string
+		synced        bool
+		amount        float64
+		prefer        Rail
+		want          Rail
+		wantErr       error
+	}{{
+		name:          "cheaper lightning",
+		blockchainFee: "0.0005",
+		lightningFee:  "0.00001",
+		synced:        true,
+		amount:        0.01,
+		want:          RailLightning,
+	}, {
+		name:          "preferred on-chain",
+		blockchainFee: "0.0005",
+		lightningFee:  "0.00001",
+		synced:        true,
+		amount:        0.01,
+		prefer:        RailOnChain,
+		want:          RailOnChain,
+	}, {
+		name:          "amount above lightning maximum",
+		blockchainFee: "0.0005",
+		lightningFee:  "0.00001",
+		synced:        true,
+		amount:        1,
+		prefer:        RailLightning,
+		want:          RailOnChain,
+	}, {
+		name:          "lightning node is not synced",
+		blockchainFee: "0.0005",
+		lightningFee:  "0.00001",
+		amount:        0.01,
+		want:          RailOnChain,
+	}, {
+		name:          "no rail",
+		blockchainFee: "0.0005",
+		lightningFee:  "0.00001",
+		amount:        0.0001,
+		wantErr:       ErrNoWithdrawalRail,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &Client{core: withdrawalPlanCore(test.blockchainFee,
+				test.lightningFee, test.synced, new([]string))}
+
+			plan, err := client.PlanWithdrawal("BTC", dec(test.amount),
+				test.prefer)
+			if err != test.wantErr {
+				t.Fatalf("want error `%v` but got `%v`", test.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if plan.Rail != test.want {
+				t.Errorf("want rail %v but got %v, unavailable: %v",
+					test.want, plan.Rail, plan.Unavailable)
+			}
+		})
+	}
+}
+
+func TestClient_ExecuteWithdrawal(t *testing.T) {
+	var calls []string
+	client := &Client{core: withdrawalPlanCore("0.0005", "0.00001", true,
+		&calls)}
+
+	plan, err := client.PlanWithdrawal("BTC", dec(0.01), "")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	w, err := client.ExecuteWithdrawal(plan, "", "lnbc")
+	if err != nil || w.PaymentID != "hash" {
+		t.Fatalf("want lightning withdrawal but got %v, `%v`", w, err)
+	}
+
+	plan.Amount = dec(0.02)
+	if _, err := client.ExecuteWithdrawal(plan, "", "lnbc"); err == nil {
+		t.Error("want error of invoice amount mismatch")
+	}
+
+	plan.Rail = RailOnChain
+	if _, err := client.ExecuteWithdrawal(plan, "", ""); err == nil {
+		t.Error("want error of missing address")
+	}
+	w, err = client.ExecuteWithdrawal(plan, "addr", "")
+	if err != nil || w.PaymentID != "tx" {
+		t.Fatalf("want on-chain withdrawal but got %v, `%v`", w, err)
+	}
+
+	if strings.Join(calls, ",") != "lightning,onchain" {
+		t.Errorf("want lightning and on-chain withdrawals but got %v", calls)
+	}
+}
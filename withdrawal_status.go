@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrWithdrawalFailed is returned by WaitForWithdrawalConfirmations if
+// the exchange reports the withdrawal as failed.
+var ErrWithdrawalFailed = errors.New("withdrawal failed")
+
+// Withdrawal statuses reported by exchange.
+const (
+	WithdrawalPending   = "pending"
+	WithdrawalConfirmed = "confirmed"
+	WithdrawalFailed    = "failed"
+)
+
+// WithdrawalStatus is the status of the on-chain withdrawal.
+type WithdrawalStatus struct {
+	// PaymentID is the transaction ID of the withdrawal.
+	PaymentID string `json:"paymentID"`
+
+	// Status is withdrawal status: pending, confirmed or failed.
+	Status string `json:"status"`
+
+	// Confirmations is the number of confirmations of the withdrawal
+	// transaction.
+	Confirmations int `json:"confirmations"`
+
+	// ConfirmationsLeft is the number of confirmations left until the
+	// exchange considers the withdrawal confirmed.
+	ConfirmationsLeft int `json:"confirmationsLeft"`
+}
+
+// withdrawalStatusRequestVariables is a query variables used in request
+// in client WithdrawalStatus method.
+type withdrawalStatusRequestVariables struct {
+	Asset     string `json:"asset"`
+	PaymentID string `json:"paymentID"`
+}
+
+// WithdrawalStatus returns the status of the asset withdrawal with
+// given payment ID.
+func (c *Client) WithdrawalStatus(asset, paymentID string) (WithdrawalStatus,
+	error) {
+
+	var req request
+
+	req.Query = `
+		query WithdrawalStatus($asset: Asset!, $paymentID: String!) {
+			withdrawalStatus(asset: $asset, paymentID: $paymentID) {
+				paymentID
+				status
+				confirmations
+				confirmationsLeft
+			}
+		}
+	`
+
+	req.Variables = withdrawalStatusRequestVariables{
+		Asset:     c.assets.asset(asset),
+		PaymentID: paymentID,
+	}
+
+	resp := struct {
+		responseBase
+		Data struct {
+			Status WithdrawalStatus `json:"withdrawalStatus"`
+		}
+	}{}
+
+	respJSON, err := c.do(true, req)
+	if err != nil {
+		return WithdrawalStatus{}, transportError(err)
+	}
+
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		return WithdrawalStatus{}, decodeError(err)
+	}
+
+	if err := resp.Error(); err != nil {
+		return WithdrawalStatus{}, exchangeError(err)
+	}
+
+	return resp.Data.Status, nil
+}
+
+// WaitForWithdrawalConfirmations polls the status of the asset
+// withdrawal with given payment ID until its transaction has at least
+// nConfs confirmations, or until the exchange considers it confirmed
+// if nConfs is not positive, and returns the last status. Transport
+// failures are retried on the next poll, ErrWithdrawalFailed is
+// returned if the withdrawal fails. Polls are as frequent as those of
+// subscriptions, see WithSubscriptions.
+func (c *Client) WaitForWithdrawalConfirmations(ctx context.Context, asset,
+	paymentID string, nConfs int) (WithdrawalStatus, error) {
+
+	for {
+		status, err := c.WithdrawalStatus(asset, paymentID)
+		var transportErr *TransportError
+		switch {
+		case errors.As(err, &transportErr):
+		case err != nil:
+			return status, err
+		case status.Status == WithdrawalFailed:
+			return status, ErrWithdrawalFailed
+		case nConfs > 0 && status.Confirmations >= nConfs:
+			return status, nil
+		case nConfs <= 0 && status.Status == WithdrawalConfirmed:
+			return status, nil
+		}
+
+		if !sleep(ctx, c.subs.pollInterval(ctx)) {
+			return status, ctx.Err()
+		}
+	}
+}
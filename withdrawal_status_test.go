@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_WithdrawalStatus(t *testing.T) {
+	core := &mockCore{respJSON: `{"data": {"withdrawalStatus": {
+		"paymentID": "tx", "status": "pending", "confirmations": 2,
+		"confirmationsLeft": 1}}}`}
+	client := &Client{core: core}
+
+	status, err := client.WithdrawalStatus("BTC", "tx")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	want := WithdrawalStatus{PaymentID: "tx", Status: WithdrawalPending,
+		Confirmations: 2, ConfirmationsLeft: 1}
+	if status != want {
+		t.Errorf("want %v but got %v", want, status)
+	}
+
+	vars := core.request.Variables.(withdrawalStatusRequestVariables)
+	if vars.Asset != "BTC" || vars.PaymentID != "tx" {
+		t.Errorf("want BTC tx variables but got %v", vars)
+	}
+}
+
+func TestClient_WaitForWithdrawalConfirmations(t *testing.T) {
+	ctx := withPollInterval(context.Background(), time.Millisecond)
+
+	newClient := func(statuses ...string) *Client {
+		confs := 0
+		return &Client{
+			core: CoreFunc(func(query string, _ interface{}) ([]byte, error) {
+				status := statuses[0]
+				if len(statuses) > 1 {
+					statuses = statuses[1:]
+				}
+				if status == "" {
+					return nil, errors.New("connection refused")
+				}
+				confs++
+				return []byte(fmt.Sprintf(`{"data": {"withdrawalStatus": {
+					"status": %q, "confirmations": %v}}}`, status, confs)), nil
+			}),
+		}
+	}
+
+	client := newClient("pending", "", "pending", "pending")
+	status, err := client.WaitForWithdrawalConfirmations(ctx, "BTC", "tx", 3)
+	if err != nil || status.Confirmations != 3 {
+		t.Errorf("want 3 confirmations but got %v, `%v`", status, err)
+	}
+
+	client = newClient("pending", "confirmed")
+	status, err = client.WaitForWithdrawalConfirmations(ctx, "BTC", "tx", 0)
+	if err != nil || status.Status != WithdrawalConfirmed {
+		t.Errorf("want confirmed withdrawal but got %v, `%v`", status, err)
+	}
+
+	client = newClient("pending", "failed")
+	_, err = client.WaitForWithdrawalConfirmations(ctx, "BTC", "tx", 6)
+	if err != ErrWithdrawalFailed {
+		t.Errorf("want ErrWithdrawalFailed but got `%v`", err)
+	}
+
+	client = &Client{core: CoreFunc(func(string, interface{}) ([]byte, error) {
+		return []byte(`{"errors": [{"message": "unknown payment"}]}`), nil
+	})}
+	_, err = client.WaitForWithdrawalConfirmations(ctx, "BTC", "tx", 6)
+	if err == nil || !strings.Contains(err.Error(), "unknown payment") {
+		t.Errorf("want exchange error but got `%v`", err)
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"sync"
+)
+
+const (
+	// withdrawalLogSize is the number of the latest session withdrawal
+	// results kept for tracing.
+	withdrawalLogSize = 1000
+
+	// traceSearchDepth is the number of the latest withdrawal records
+	// searched for the traced withdrawal.
+	traceSearchDepth = 100
+)
+
+// WithdrawalTrace stitches together everything known about the
+// withdrawal, for support and audits.
+type WithdrawalTrace struct {
+	// Asset is the asset of the withdrawal.
+	Asset string
+
+	// PaymentID is the withdrawal payment ID, transaction ID in
+	// blockchain or payment hash in lightning network.
+	PaymentID string
+
+	// Result is the withdrawal mutation result if the withdrawal has
+	// been made by this client in the current session, nil otherwise.
+	Result *Withdrawal
+
+	// Record is the balance update record of the withdrawal, nil if it
+	// is not found among the latest records.
+	Record *WithdrawalRecord
+
+	// Transaction is the blockchain transaction with confirmation
+	// status if it is still pending, nil otherwise.
+	Transaction *Transaction
+}
+
+// TraceWithdrawal returns trace of the asset withdrawal with given
+// payment ID: the withdrawal mutation result, the corresponding balance
+// update record and pending blockchain transaction status.
+func (c *Client) TraceWithdrawal(asset, paymentID string) (WithdrawalTrace,
+	error) {
+
+	trace := WithdrawalTrace{
+		Asset:     asset,
+		PaymentID: paymentID,
+		Result:    c.withdrawalLog.get(paymentID),
+	}
+
+	records, err := c.Withdrawals(asset, 0, traceSearchDepth)
+	if err != nil {
+		return trace, err
+	}
+	for i := range records {
+		if records[i].PaymentID == paymentID {
+			trace.Record = &records[i]
+			break
+		}
+	}
+
+	accounts, err := c.Accounts([]string{asset})
+	if err != nil {
+		return trace, err
+	}
+	for _, a := range accounts {
+		for i := range a.Pending.Transactions {
+			if a.Pending.Transactions[i].TxID == paymentID {
+				trace.Transaction = &a.Pending.Transactions[i]
+			}
+		}
+	}
+
+	return trace, nil
+}
+
+// withdrawalLog keeps the latest withdrawal results by payment ID. Nil
+// log keeps nothing.
+type withdrawalLog struct {
+	size int
+
+	mtx     sync.Mutex
+	results map[string]Withdrawal
+	order   []string
+}
+
+// newWithdrawalLog creates new log keeping size latest results.
+func newWithdrawalLog(size int) *withdrawalLog {
+	return &withdrawalLog{
+		size:    size,
+		results: make(map[string]Withdrawal, size),
+	}
+}
+
+// record keeps the withdrawal result.
+func (l *withdrawalLog) record(w Withdrawal) {
+	if l == nil || w.PaymentID == "" {
+		return
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if _, ok := l.results[w.PaymentID]; !ok {
+		l.order = append(l.order, w.PaymentID)
+	}
+	l.results[w.PaymentID] = w
+
+	if len(l.order) > l.size {
+		delete(l.results, l.order[0])
+		l.order = l.order[1:]
+	}
+}
+
+// get returns kept withdrawal result with given payment ID.
+func (l *withdrawalLog) get(paymentID string) *Withdrawal {
+	if l == nil {
+		return nil
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	w, ok := l.results[paymentID]
+	if !ok {
+		return nil
+	}
+	return &w
+}
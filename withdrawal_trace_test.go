@@ -0,0 +1,63 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClient_TraceWithdrawal(t *testing.T) {
+	client := &Client{
+		core: CoreFunc(func(query string, _ interface{}) ([]byte, error) {
+			if strings.Contains(query, "balanceUpdateRecords") {
+				return []byte(`{ "data": { "balanceUpdateRecords": [
+					{ "paymentID": "tx0", "change": "-1" },
+					{ "paymentID": "tx1", "change": "-0.1" }
+				] } }`), nil
+			}
+			return []byte(`{ "data": { "accounts": [{
+				"asset": "BTC",
+				"pending": { "transactions": [
+					{ "txID": "tx1", "confirmations": 2 }
+				] }
+			}] } }`), nil
+		}),
+		withdrawalLog: newWithdrawalLog(1),
+	}
+	client.withdrawalLog.record(Withdrawal{PaymentID: "tx1",
+		Change: dec(-0.1)})
+
+	trace, err := client.TraceWithdrawal("BTC", "tx1")
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if trace.Result == nil || !trace.Result.Change.Equal(dec(-0.1)) {
+		t.Errorf("want session result but got `%v`", trace.Result)
+	}
+	if trace.Record == nil || trace.Record.PaymentID != "tx1" {
+		t.Errorf("want balance record tx1 but got `%v`", trace.Record)
+	}
+	if trace.Transaction == nil || trace.Transaction.Confirmations != 2 {
+		t.Errorf("want pending transaction with 2 confirmations but "+
+			"got `%v`", trace.Transaction)
+	}
+}
+
+func TestWithdrawalLog(t *testing.T) {
+	l := newWithdrawalLog(2)
+	l.record(Withdrawal{PaymentID: "a"})
+	l.record(Withdrawal{PaymentID: "b"})
+	l.record(Withdrawal{PaymentID: "c"})
+
+	if l.get("a") != nil {
+		t.Error("want the oldest result to be evicted")
+	}
+	if l.get("b") == nil || l.get("c") == nil {
+		t.Error("want the latest results to be kept")
+	}
+
+	var nilLog *withdrawalLog
+	nilLog.record(Withdrawal{PaymentID: "a"})
+	if nilLog.get("a") != nil {
+		t.Error("want nil log to keep nothing")
+	}
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrQueueFull is returned by WorkerPool.TrySubmit if the queue is
+	// full.
+	ErrQueueFull = errors.New("work queue is full")
+
+	// ErrPoolClosed is returned if the task is submitted to the closed
+	// pool.
+	ErrPoolClosed = errors.New("worker pool is closed")
+)
+
+// WorkerPool is a bounded pool of workers with bounded task queue. It
+// runs background work of the client, e.g. subscription and watcher
+// polls, so the client has predictable resource usage. Queue depth is
+// reported as worker_pool_queue_depth gauge and rejected tasks as
+// worker_pool_rejected_total counter.
+type WorkerPool struct {
+	tasks   chan func()
+	metrics Metrics
+	wg      sync.WaitGroup
+
+	mtx    sync.RWMutex
+	closed bool
+}
+
+// NewWorkerPool creates and starts pool with given number of workers
+// and queue capacity.
+func NewWorkerPool(workers, queue int, metrics Metrics) *WorkerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queue < 0 {
+		queue = 0
+	}
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+
+	p := &WorkerPool{
+		tasks:   make(chan func(), queue),
+		metrics: metrics,
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// WithWorkerPool makes the client run its background work in the pool.
+// By default every background task runs in its own goroutine.
+func WithWorkerPool(p *WorkerPool) Option {
+	return func(o *options) {
+		o.pool = p
+	}
+}
+
+// WithSerializedRequests makes the client send requests one by one in
+// the order they are made, including requests of background pollers,
+// so recordings of the client traffic are reproducible. It is a
+// debugging option, as slow request delays all the others.
+func WithSerializedRequests() Option {
+	return func(o *options) {
+		o.serializedRequests = true
+	}
+}
+
+// work runs tasks until the pool is closed.
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		p.metrics.Set("worker_pool_queue_depth", nil,
+			float64(len(p.tasks)))
+		task()
+	}
+}
+
+// Submit queues the task, blocking while the queue is full, which
+// provides back-pressure to the submitter. It returns the context error
+// if the context is done before the task is queued.
+func (p *WorkerPool) Submit(ctx context.Context, task func()) error {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.tasks <- task:
+		p.metrics.Set("worker_pool_queue_depth", nil,
+			float64(len(p.tasks)))
+		return nil
+	case <-ctx.Done():
+		p.metrics.Add("worker_pool_rejected_total", nil, 1)
+		return ctx.Err()
+	}
+}
+
+// TrySubmit queues the task without blocking, ErrQueueFull is returned
+// if the queue is full.
+func (p *WorkerPool) TrySubmit(task func()) error {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.tasks <- task:
+		p.metrics.Set("worker_pool_queue_depth", nil,
+			float64(len(p.tasks)))
+		return nil
+	default:
+		p.metrics.Add("worker_pool_rejected_total", nil, 1)
+		return ErrQueueFull
+	}
+}
+
+// Do runs the task in the pool and waits for its completion. Nil pool
+// runs the task in the calling goroutine.
+func (p *WorkerPool) Do(ctx context.Context, task func()) error {
+	if p == nil {
+		task()
+		return nil
+	}
+
+	done := make(chan struct{})
+	if err := p.Submit(ctx, func() {
+		defer close(done)
+		task()
+	}); err != nil {
+		return err
+	}
+
+	<-done
+	return nil
+}
+
+// Close stops accepting tasks and waits until queued tasks are done.
+func (p *WorkerPool) Close() {
+	p.mtx.Lock()
+	if p.closed {
+		p.mtx.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+	p.mtx.Unlock()
+
+	p.wg.Wait()
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	metrics := &recordingMetrics{}
+	p := NewWorkerPool(1, 1, metrics)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	if err := p.TrySubmit(func() {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	<-started
+
+	// Worker is busy, the task occupies the queue.
+	var done int32
+	if err := p.TrySubmit(func() { atomic.AddInt32(&done, 1) }); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	if err := p.TrySubmit(func() {}); err != ErrQueueFull {
+		t.Errorf("want ErrQueueFull but got `%v`", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, func() {}); err != context.DeadlineExceeded {
+		t.Errorf("want deadline exceeded but got `%v`", err)
+	}
+	if metrics.count("worker_pool_rejected_total") != 2 {
+		t.Errorf("want 2 rejected tasks to be reported")
+	}
+
+	close(release)
+
+	var ran bool
+	if err := p.Do(context.Background(), func() { ran = true }); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !ran {
+		t.Error("want task to be run by Do")
+	}
+
+	p.Close()
+	if atomic.LoadInt32(&done) != 1 {
+		t.Error("want queued task to be done before close")
+	}
+	if err := p.TrySubmit(func() {}); err != ErrPoolClosed {
+		t.Errorf("want ErrPoolClosed but got `%v`", err)
+	}
+}
+
+func TestWorkerPool_Do_nil(t *testing.T) {
+	var p *WorkerPool
+	var ran bool
+	if err := p.Do(context.Background(), func() { ran = true }); err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+	if !ran {
+		t.Error("want task to be run inline")
+	}
+}
+
+func TestNewClient_withSerializedRequests(t *testing.T) {
+	var active, maxActive int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			for {
+				max := atomic.LoadInt32(&maxActive)
+				if n <= max || atomic.CompareAndSwapInt32(&maxActive, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			w.Write([]byte(`{ "data": { "me": { "id": "1" } } }`))
+		}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "token",
+		WithSerializedRequests())
+	if err != nil {
+		t.Fatalf("want no error but got `%v`", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Me(); err != nil {
+				t.Errorf("want no error but got `%v`", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&maxActive); n != 1 {
+		t.Errorf("want requests sent one by one but got %v at once", n)
+	}
+}